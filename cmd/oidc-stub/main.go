@@ -0,0 +1,249 @@
+// oidc-stub - минимальный провайдер OpenID Connect для локальной проверки входа
+// через SSO. Не для продакшена: пользователь вводит любое имя без пароля.
+//
+//	go run ./cmd/oidc-stub -addr :9000 -issuer http://localhost:9000
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+const keyId = "stub-key"
+
+type authRequest struct {
+	clientId      string
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+	username      string
+	expiresAt     time.Time
+}
+
+type stub struct {
+	issuer       string
+	clientId     string
+	clientSecret string
+	key          *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]authRequest
+}
+
+var loginPage = template.Must(template.New("login").Parse(`<!doctype html>
+<html><body>
+<h1>OIDC stub</h1>
+<form method="post">
+  {{range $k, $v := .}}<input type="hidden" name="{{$k}}" value="{{index $v 0}}">{{end}}
+  <input name="username" placeholder="username" autofocus required>
+  <label><input type="checkbox" name="email_verified" value="true" checked> email verified</label>
+  <button type="submit">Sign in</button>
+</form>
+</body></html>`))
+
+func main() {
+	addr := flag.String("addr", ":9000", "listen address")
+	issuer := flag.String("issuer", "http://localhost:9000", "issuer URL as seen by the browser and the app")
+	clientId := flag.String("client-id", "todo", "expected client_id")
+	clientSecret := flag.String("client-secret", "secret", "expected client_secret")
+	flag.Parse()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		log.Fatalf("generate key: %v", err)
+	}
+
+	s := &stub{
+		issuer:       *issuer,
+		clientId:     *clientId,
+		clientSecret: *clientSecret,
+		key:          key,
+		codes:        map[string]authRequest{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("/authorize", s.authorize)
+	mux.HandleFunc("/token", s.token)
+	mux.HandleFunc("/jwks", s.jwks)
+
+	log.Printf("OIDC stub listening on %s, issuer %s", *addr, *issuer)
+	log.Fatal(http.ListenAndServe(*addr, mux))
+}
+
+func (s *stub) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                s.issuer,
+		"authorization_endpoint":                s.issuer + "/authorize",
+		"token_endpoint":                        s.issuer + "/token",
+		"jwks_uri":                              s.issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (s *stub) authorize(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return
+	}
+	params := r.Form
+
+	if params.Get("client_id") != s.clientId || params.Get("response_type") != "code" {
+		http.Error(w, "invalid client_id or response_type", http.StatusBadRequest)
+		return
+	}
+	if params.Get("code_challenge_method") != "S256" || params.Get("code_challenge") == "" {
+		http.Error(w, "PKCE S256 is required", http.StatusBadRequest)
+		return
+	}
+	redirect, err := url.Parse(params.Get("redirect_uri"))
+	if err != nil || redirect.Scheme == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		w.Header().Set("Content-Type", "text/html")
+		loginPage.Execute(w, r.URL.Query())
+		return
+	}
+
+	username := params.Get("username")
+	if username == "" {
+		http.Error(w, "username is required", http.StatusBadRequest)
+		return
+	}
+
+	code := randomString()
+	s.mu.Lock()
+	s.codes[code] = authRequest{
+		clientId:      params.Get("client_id"),
+		redirectURI:   params.Get("redirect_uri"),
+		nonce:         params.Get("nonce"),
+		codeChallenge: params.Get("code_challenge"),
+		username:      username,
+		expiresAt:     time.Now().Add(time.Minute),
+	}
+	s.mu.Unlock()
+
+	query := redirect.Query()
+	query.Set("code", code)
+	query.Set("state", params.Get("state"))
+	redirect.RawQuery = query.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (s *stub) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, "invalid_request")
+		return
+	}
+
+	clientId, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientId, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientId != s.clientId || clientSecret != s.clientSecret {
+		tokenError(w, "invalid_client")
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	s.mu.Lock()
+	req, found := s.codes[code]
+	delete(s.codes, code)
+	s.mu.Unlock()
+
+	if !found || time.Now().After(req.expiresAt) || req.redirectURI != r.PostForm.Get("redirect_uri") {
+		tokenError(w, "invalid_grant")
+		return
+	}
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != req.codeChallenge {
+		tokenError(w, "invalid_grant")
+		return
+	}
+
+	now := time.Now()
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss":                s.issuer,
+		"sub":                "stub|" + req.username,
+		"aud":                req.clientId,
+		"exp":                now.Add(5 * time.Minute).Unix(),
+		"iat":                now.Unix(),
+		"nonce":              req.nonce,
+		"preferred_username": req.username,
+		"email":              req.username + "@example.com",
+		"email_verified":     r.PostForm.Get("email_verified") != "false",
+	})
+
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.RS256, Key: s.key},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", keyId),
+	)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	signed, err := signer.Sign(claims)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	idToken, err := signed.CompactSerialize()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+func (s *stub) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{
+		Key:       &s.key.PublicKey,
+		KeyID:     keyId,
+		Algorithm: string(jose.RS256),
+		Use:       "sig",
+	}}})
+}
+
+func tokenError(w http.ResponseWriter, code string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": code})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func randomString() string {
+	b := make([]byte, 24)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,167 @@
+// s3-stub - минимальный S3-совместимый сервер для локальной проверки хранения
+// вложений в S3. Не для продакшена: объекты лежат в памяти, бакеты создаются
+// автоматически. Проверяет подпись AWS Signature V4 с заданными ключами.
+//
+//	go run ./cmd/s3-stub -addr :9002 -access-key todo -secret-key secret
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type object struct {
+	data        []byte
+	contentType string
+}
+
+type stub struct {
+	accessKey string
+	secretKey string
+
+	mu      sync.RWMutex
+	objects map[string]object // bucket/key -> объект
+}
+
+func main() {
+	addr := flag.String("addr", ":9002", "listen address")
+	accessKey := flag.String("access-key", "todo", "expected access key")
+	secretKey := flag.String("secret-key", "secret", "secret key used to verify signatures")
+	flag.Parse()
+
+	s := &stub{
+		accessKey: *accessKey,
+		secretKey: *secretKey,
+		objects:   map[string]object{},
+	}
+
+	log.Printf("S3 stub listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, s))
+}
+
+func (s *stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	if !strings.Contains(path, "/") {
+		s3Error(w, http.StatusBadRequest, "InvalidRequest", "path-style object key expected: /bucket/key")
+		return
+	}
+	if err := s.verify(r); err != nil {
+		log.Printf("%s %s: %v", r.Method, r.URL.Path, err)
+		s3Error(w, http.StatusForbidden, "SignatureDoesNotMatch", err.Error())
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			s3Error(w, http.StatusBadRequest, "IncompleteBody", err.Error())
+			return
+		}
+		s.mu.Lock()
+		s.objects[path] = object{data: data, contentType: r.Header.Get("Content-Type")}
+		s.mu.Unlock()
+		sum := md5.Sum(data)
+		w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:])+`"`)
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet, http.MethodHead:
+		s.mu.RLock()
+		obj, found := s.objects[path]
+		s.mu.RUnlock()
+		if !found {
+			s3Error(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+			return
+		}
+		w.Header().Set("Content-Type", obj.contentType)
+		w.Header().Set("Content-Length", fmt.Sprint(len(obj.data)))
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			w.Write(obj.data)
+		}
+	case http.MethodDelete:
+		s.mu.Lock()
+		delete(s.objects, path)
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		s3Error(w, http.StatusMethodNotAllowed, "MethodNotAllowed", "method not supported by the stub")
+	}
+}
+
+// verify пересчитывает подпись Signature V4 по заголовкам из SignedHeaders
+func (s *stub) verify(r *http.Request) error {
+	auth := strings.TrimPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ")
+	fields := map[string]string{}
+	for _, part := range strings.Split(auth, ",") {
+		if k, v, ok := strings.Cut(strings.TrimSpace(part), "="); ok {
+			fields[k] = v
+		}
+	}
+
+	credential := strings.SplitN(fields["Credential"], "/", 2)
+	if len(credential) != 2 || credential[0] != s.accessKey {
+		return fmt.Errorf("unknown access key")
+	}
+	scope := credential[1]
+	scopeParts := strings.Split(scope, "/")
+	if len(scopeParts) != 4 {
+		return fmt.Errorf("invalid credential scope %q", scope)
+	}
+
+	signedHeaders := strings.Split(fields["SignedHeaders"], ";")
+	sort.Strings(signedHeaders)
+	var canonicalHeaders strings.Builder
+	for _, name := range signedHeaders {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+
+	payloadHash := r.Header.Get("X-Amz-Content-Sha256")
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		r.URL.EscapedPath(),
+		r.URL.RawQuery,
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+
+	sum := sha256.Sum256([]byte(canonicalRequest))
+	amzDate := r.Header.Get("X-Amz-Date")
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(sum[:])
+
+	key := []byte("AWS4" + s.secretKey)
+	for _, part := range scopeParts {
+		key = hmacSHA256(key, part)
+	}
+	expected := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	if !hmac.Equal([]byte(expected), []byte(fields["Signature"])) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func s3Error(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<Error><Code>%s</Code><Message>%s</Message></Error>\n", code, message)
+}
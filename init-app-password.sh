@@ -0,0 +1,16 @@
+#!/bin/sh
+# Задаёт пароль роли todo_app, которую создаёт init.sql. Пароль берётся из
+# TODO_APP_PASSWORD или из файла TODO_APP_PASSWORD_FILE (Docker secrets).
+set -e
+
+if [ -n "$TODO_APP_PASSWORD_FILE" ]; then
+    TODO_APP_PASSWORD=$(cat "$TODO_APP_PASSWORD_FILE")
+fi
+if [ -z "$TODO_APP_PASSWORD" ]; then
+    echo "TODO_APP_PASSWORD or TODO_APP_PASSWORD_FILE is required" >&2
+    exit 1
+fi
+
+psql -v ON_ERROR_STOP=1 -U "$POSTGRES_USER" -d "$POSTGRES_DB" -v password="$TODO_APP_PASSWORD" <<'EOSQL'
+ALTER ROLE todo_app PASSWORD :'password';
+EOSQL
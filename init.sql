@@ -1,6 +1,148 @@
 -- init.sql
+-- Скрипт можно выполнять повторно: он создаёт недостающие таблицы и обновляет
+-- базы, созданные более ранними версиями приложения.
+CREATE TABLE IF NOT EXISTS users (
+    id TEXT PRIMARY KEY,
+    username TEXT NOT NULL UNIQUE,
+    email TEXT UNIQUE,
+    -- argon2id в формате PHC; пусто у пользователей, входящих только через SSO
+    password_hash TEXT NOT NULL DEFAULT '',
+    -- системная роль, права ролей заданы в коде (пакет policy)
+    role TEXT NOT NULL DEFAULT 'user' CHECK (role IN ('admin', 'user', 'readonly')),
+    -- NULL - активен; заблокированный пользователь не может войти
+    disabled_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- Привязка внешних учётных записей OIDC (iss + sub) к локальным пользователям
+CREATE TABLE IF NOT EXISTS user_identities (
+    issuer TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (issuer, subject)
+);
+
+CREATE TABLE IF NOT EXISTS sessions (
+    -- SHA-256 токена из cookie, сам токен не хранится
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    csrf_token TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    -- администратор, вошедший от имени user_id; NULL - обычная сессия
+    impersonator_id TEXT REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS sessions_user_id_idx ON sessions (user_id);
+CREATE INDEX IF NOT EXISTS sessions_expires_at_idx ON sessions (expires_at);
+
+-- Ответы на POST-запросы с заголовком Idempotency-Key: повтор запроса с тем же
+-- ключом получает сохранённый ответ. status_code = 0 - запрос ещё выполняется,
+-- после locked_until ключ может занять повтор (процесс мог упасть).
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    key TEXT NOT NULL,
+    -- SHA-256 метода, пути, рабочего пространства и тела запроса
+    fingerprint TEXT NOT NULL,
+    status_code INTEGER NOT NULL DEFAULT 0,
+    -- повторяемые заголовки ответа: Content-Type, Location, ETag
+    headers JSONB NOT NULL DEFAULT '{}',
+    body BYTEA,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    locked_until TIMESTAMP,
+    PRIMARY KEY (user_id, key)
+);
+
+CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
+
+-- Журнал действий администраторов и запросов, выполненных от имени другого
+-- пользователя. Записи не удаляются вместе с пользователями.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id TEXT PRIMARY KEY,
+    actor_id TEXT NOT NULL,
+    impersonator_id TEXT,
+    action TEXT NOT NULL,
+    target_type TEXT NOT NULL DEFAULT '',
+    target_id TEXT NOT NULL DEFAULT '',
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_created_at_idx ON audit_log (created_at DESC);
+CREATE INDEX IF NOT EXISTS audit_log_actor_id_idx ON audit_log (actor_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS audit_log_target_idx ON audit_log (target_type, target_id, created_at DESC);
+
+-- Рабочие пространства команд. Пользователи, сессии и связки SSO общие для
+-- экземпляра; все данные задач принадлежат одному пространству (workspace_id).
+CREATE TABLE IF NOT EXISTS workspaces (
+    id TEXT PRIMARY KEY,
+    -- поддомен и значение заголовка X-Workspace
+    slug TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    settings JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS workspace_members (
+    workspace_id TEXT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL CHECK (role IN ('owner', 'member')),
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (workspace_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS workspace_members_user_id_idx ON workspace_members (user_id);
+
+-- Обновление базы прежней версии: задачи пользователя переносятся в его
+-- личное пространство - первое, которым он владеет, или новое. Функция
+-- временная и исчезает вместе с сеансом.
+CREATE OR REPLACE FUNCTION pg_temp.personal_workspace(owner TEXT) RETURNS TEXT
+LANGUAGE plpgsql AS $$
+DECLARE
+    ws TEXT;
+BEGIN
+    SELECT workspace_id INTO ws FROM workspace_members
+    WHERE user_id = owner AND role = 'owner'
+    ORDER BY created_at LIMIT 1;
+    IF ws IS NULL THEN
+        ws := gen_random_uuid()::text;
+        INSERT INTO workspaces (id, slug, name, settings, created_at, updated_at)
+        SELECT ws, 'ws-' || substr(md5(id), 1, 10), username, '{}', now(), now()
+        FROM users WHERE id = owner;
+        INSERT INTO workspace_members (workspace_id, user_id, role, created_at)
+        VALUES (ws, owner, 'owner', now());
+    END IF;
+    RETURN ws;
+END
+$$;
+
+-- Токен действует только в своём рабочем пространстве. Таблица читается при
+-- аутентификации, до выбора пространства, поэтому RLS на неё не включается.
+CREATE TABLE IF NOT EXISTS api_tokens (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    workspace_id TEXT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    prefix TEXT NOT NULL,
+    -- SHA-256 токена, сам токен не хранится
+    token_hash TEXT NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    expires_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS api_tokens_user_id_idx ON api_tokens (user_id);
+
+
 CREATE TABLE IF NOT EXISTS todo (
     id TEXT PRIMARY KEY,
+    workspace_id TEXT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    owner_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
     todo TEXT NOT NULL,
     message TEXT,
     created_at TIMESTAMP NOT NULL,
@@ -8,5 +150,175 @@ CREATE TABLE IF NOT EXISTS todo (
     deadline TIMESTAMP NOT NULL,
     priority TEXT,
     completed_at TIMESTAMP,
-    complete BOOLEAN NOT NULL
+    complete BOOLEAN NOT NULL,
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    -- ключ ручного порядка (см. пакет rank), сравнивается побайтово
+    position TEXT COLLATE "C" NOT NULL DEFAULT '',
+    assignee_id TEXT REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- Обновление баз, созданных более ранней версией: CREATE TABLE IF NOT EXISTS
+-- существующую таблицу не меняет, поэтому новые столбцы добавляются отдельно
+ALTER TABLE todo ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE todo ADD COLUMN IF NOT EXISTS position TEXT COLLATE "C" NOT NULL DEFAULT '';
+-- Задачи без позиции получают ключи в порядке создания: между двумя пустыми
+-- ключами переместить задачу нельзя. Ключи одной длины из цифр пакета rank
+-- и не оканчиваются на '0'.
+UPDATE todo SET position = numbered.position
+FROM (SELECT id, 'V' || lpad((row_number() OVER (ORDER BY created_at, id))::text, 9, '0') || '1' AS position
+      FROM todo WHERE position = '') numbered
+WHERE todo.id = numbered.id;
+-- Задачи, созданные до появления учётных записей, достаются пользователю legacy:
+-- войти им нельзя, пока администратор не задаст пароль
+ALTER TABLE todo ADD COLUMN IF NOT EXISTS owner_id TEXT REFERENCES users(id) ON DELETE CASCADE;
+INSERT INTO users (id, username, password_hash, created_at, updated_at)
+SELECT 'legacy', 'legacy', '', now(), now()
+WHERE EXISTS (SELECT 1 FROM todo WHERE owner_id IS NULL)
+ON CONFLICT DO NOTHING;
+UPDATE todo SET owner_id = 'legacy' WHERE owner_id IS NULL;
+ALTER TABLE todo ALTER COLUMN owner_id SET NOT NULL;
+ALTER TABLE todo ADD COLUMN IF NOT EXISTS assignee_id TEXT REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE todo ADD COLUMN IF NOT EXISTS workspace_id TEXT REFERENCES workspaces(id) ON DELETE CASCADE;
+UPDATE todo SET workspace_id = pg_temp.personal_workspace(owner_id) WHERE workspace_id IS NULL;
+ALTER TABLE todo ALTER COLUMN workspace_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS todo_owner_id_idx ON todo (workspace_id, owner_id);
+CREATE INDEX IF NOT EXISTS todo_tags_idx ON todo USING GIN (tags);
+CREATE INDEX IF NOT EXISTS todo_position_idx ON todo (position);
+CREATE INDEX IF NOT EXISTS todo_assignee_id_idx ON todo (assignee_id);
+
+CREATE TABLE IF NOT EXISTS todo_watchers (
+    workspace_id TEXT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    todo_id TEXT NOT NULL REFERENCES todo(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (todo_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS todo_watchers_user_id_idx ON todo_watchers (user_id);
+
+-- Комментарии к задачам; body - markdown
+CREATE TABLE IF NOT EXISTS comments (
+    id TEXT PRIMARY KEY,
+    workspace_id TEXT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    todo_id TEXT NOT NULL REFERENCES todo(id) ON DELETE CASCADE,
+    author_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS comments_todo_id_idx ON comments (todo_id, created_at);
+
+-- История правок: прежний текст комментария
+CREATE TABLE IF NOT EXISTS comment_revisions (
+    id TEXT PRIMARY KEY,
+    workspace_id TEXT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    comment_id TEXT NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    editor_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    edited_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS comment_revisions_comment_id_idx ON comment_revisions (comment_id);
+
+CREATE TABLE IF NOT EXISTS comment_mentions (
+    workspace_id TEXT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    comment_id TEXT NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    PRIMARY KEY (comment_id, user_id)
+);
+
+-- Содержимое вложений по SHA-256: одинаковые файлы хранятся один раз.
+-- Записи без ссылок удаляет фоновый сборщик вместе с объектами в хранилище.
+-- Таблица общая для всех пространств: по хешу нельзя узнать чужие данные,
+-- а доступ к вложению проверяется по attachments.
+CREATE TABLE IF NOT EXISTS blobs (
+    hash TEXT PRIMARY KEY,
+    size BIGINT NOT NULL,
+    content_type TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS attachments (
+    id TEXT PRIMARY KEY,
+    workspace_id TEXT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    todo_id TEXT NOT NULL REFERENCES todo(id) ON DELETE CASCADE,
+    uploader_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    filename TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    content_hash TEXT NOT NULL REFERENCES blobs(hash),
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS attachments_todo_id_idx ON attachments (todo_id);
+CREATE INDEX IF NOT EXISTS attachments_content_hash_idx ON attachments (content_hash);
+
+
+CREATE TABLE IF NOT EXISTS saved_view (
+    id TEXT PRIMARY KEY,
+    workspace_id TEXT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    owner_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    filter JSONB NOT NULL DEFAULT '{}',
+    pinned BOOLEAN NOT NULL DEFAULT false,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS saved_view_owner_id_idx ON saved_view (workspace_id, owner_id);
+
+
+-- Совместный доступ к списку владельца (resource_id = id владельца) или к задаче
+CREATE TABLE IF NOT EXISTS shares (
+    id TEXT PRIMARY KEY,
+    workspace_id TEXT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    resource_type TEXT NOT NULL CHECK (resource_type IN ('list', 'todo')),
+    resource_id TEXT NOT NULL,
+    owner_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL CHECK (role IN ('viewer', 'editor', 'owner')),
+    created_at TIMESTAMP NOT NULL,
+    UNIQUE (workspace_id, resource_type, resource_id, user_id)
 );
+
+CREATE INDEX IF NOT EXISTS shares_user_id_idx ON shares (user_id);
+
+-- Row-level security - вторая линия защиты после фильтров в репозиториях:
+-- строка видна, только если её workspace_id совпадает с app.workspace_id,
+-- который приложение задаёт в каждой транзакции. app.bypass_rls включают
+-- лишь служебные запросы, которым нужны все пространства (сборка мусора).
+-- Суперпользователь и владелец таблиц без FORCE обходят RLS, поэтому
+-- приложение подключается ролью todo_app.
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY['todo', 'todo_watchers', 'comments', 'comment_revisions',
+                             'comment_mentions', 'attachments', 'saved_view', 'shares']
+    LOOP
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', t);
+        EXECUTE format('DROP POLICY IF EXISTS workspace_isolation ON %I', t);
+        EXECUTE format($p$CREATE POLICY workspace_isolation ON %I
+            USING (workspace_id = current_setting('app.workspace_id', true)
+                   OR current_setting('app.bypass_rls', true) = 'on')
+            WITH CHECK (workspace_id = current_setting('app.workspace_id', true)
+                   OR current_setting('app.bypass_rls', true) = 'on')$p$, t);
+    END LOOP;
+END
+$$;
+
+-- Роль создаётся без пароля: его задаёт init-app-password.sh из
+-- TODO_APP_PASSWORD или вручную ALTER ROLE todo_app PASSWORD '...'
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'todo_app') THEN
+        CREATE ROLE todo_app LOGIN;
+    END IF;
+END
+$$;
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO todo_app;
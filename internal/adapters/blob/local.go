@@ -0,0 +1,105 @@
+// Package blob - хранилища содержимого вложений
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"ToDo-List/internal/core/ports"
+)
+
+// keyPattern не пускает в ключ разделители пути и ".."
+var keyPattern = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9._-]*$`)
+
+func checkKey(key string) error {
+	if !keyPattern.MatchString(key) || len(key) < 3 {
+		return fmt.Errorf("invalid blob key %q", key)
+	}
+	return nil
+}
+
+// LocalStore хранит объекты в каталоге на диске: root/ab/abcdef...
+type LocalStore struct {
+	root string
+}
+
+func NewLocalStore(root string) (ports.BlobStore, error) {
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, fmt.Errorf("create blob directory: %w", err)
+	}
+	return &LocalStore{root: root}, nil
+}
+
+func (s *LocalStore) path(key string) string {
+	return filepath.Join(s.root, key[:2], key)
+}
+
+// Put пишет во временный файл и переименовывает его, чтобы читатели
+// не увидели недописанный объект
+func (s *LocalStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, r)
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	if size >= 0 && written != size {
+		tmp.Close()
+		return fmt.Errorf("blob %s: wrote %d bytes, expected %d", key, written, size)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *LocalStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	f, err := os.Open(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ports.ErrBlobNotFound
+	}
+	return f, err
+}
+
+func (s *LocalStore) Exists(ctx context.Context, key string) (bool, error) {
+	if err := checkKey(key); err != nil {
+		return false, err
+	}
+	_, err := os.Stat(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	err := os.Remove(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
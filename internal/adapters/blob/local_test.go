@@ -0,0 +1,69 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"ToDo-List/internal/core/ports"
+)
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	const key = "abcdef0123"
+
+	if err := store.Put(ctx, key, strings.NewReader("hello"), 5, "text/plain"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if ok, err := store.Exists(ctx, key); !ok || err != nil {
+		t.Fatalf("Exists = %v, %v; want true", ok, err)
+	}
+	r, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	content, err := io.ReadAll(r)
+	r.Close()
+	if err != nil || string(content) != "hello" {
+		t.Fatalf("content = %q, %v; want hello", content, err)
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	// Повторное удаление - не ошибка
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete of a missing blob: %v", err)
+	}
+	if _, err := store.Get(ctx, key); !errors.Is(err, ports.ErrBlobNotFound) {
+		t.Errorf("Get after Delete = %v, want ErrBlobNotFound", err)
+	}
+}
+
+func TestLocalStoreRejects(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Недописанный объект не сохраняется
+	if err := store.Put(ctx, "abcdef", strings.NewReader("hel"), 5, "text/plain"); err == nil {
+		t.Error("Put of a short body succeeded")
+	}
+	if ok, _ := store.Exists(ctx, "abcdef"); ok {
+		t.Error("short body was stored")
+	}
+
+	for _, key := range []string{"../etc", "ab/cd", "..", "a", ".hidden"} {
+		if err := store.Put(ctx, key, strings.NewReader("x"), 1, "text/plain"); err == nil {
+			t.Errorf("Put accepted key %q", key)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ToDo-List/internal/adapters/http/handlers"
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/identity"
+	"ToDo-List/internal/core/ports"
+)
+
+// sessionAuth - сессии по токену cookie
+type sessionAuth struct {
+	ports.AuthService
+	sessions map[string]domain.Session
+	users    map[string]domain.User
+}
+
+func (a sessionAuth) Authenticate(ctx context.Context, token string) (domain.User, domain.Session, error) {
+	session, ok := a.sessions[token]
+	if !ok {
+		return domain.User{}, domain.Session{}, ports.ErrUnauthenticated
+	}
+	return a.users[session.UserId], session, nil
+}
+
+// discardAudit не записывает журнал аудита
+type discardAudit struct{}
+
+func (discardAudit) Record(context.Context, string, string, string, map[string]string) {}
+
+func newSessionAuth() sessionAuth {
+	return sessionAuth{
+		sessions: map[string]domain.Session{
+			"alice-token":  {UserId: "alice", CSRFToken: "alice-csrf"},
+			"reader-token": {UserId: "reader", CSRFToken: "reader-csrf"},
+		},
+		users: map[string]domain.User{
+			"alice":  {Id: "alice", Role: domain.UserRoleUser},
+			"reader": {Id: "reader", Role: domain.UserRoleReadOnly},
+		},
+	}
+}
+
+// authenticated отвечает именем пользователя из контекста
+func authenticated(t *testing.T, tokens ports.APITokenService) http.Handler {
+	return authMiddleware(newSessionAuth(), tokens, discardAudit{}, newTestLogger(t))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userId, _ := identity.UserId(r.Context())
+		w.Write([]byte(userId))
+	}))
+}
+
+func TestSessionAuth(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		cookie string
+		csrf   string
+		status int
+		user   string
+	}{
+		{name: "public path", method: http.MethodPost, path: "/api/auth/login", status: http.StatusOK},
+		{name: "no cookie", method: http.MethodGet, path: "/api/todos", status: http.StatusUnauthorized},
+		{name: "unknown session", method: http.MethodGet, path: "/api/todos", cookie: "stolen", status: http.StatusUnauthorized},
+		{name: "read", method: http.MethodGet, path: "/api/todos", cookie: "alice-token", status: http.StatusOK, user: "alice"},
+		{name: "write without CSRF", method: http.MethodPost, path: "/api/todo", cookie: "alice-token", status: http.StatusForbidden},
+		{name: "write with another CSRF", method: http.MethodPost, path: "/api/todo", cookie: "alice-token", csrf: "reader-csrf", status: http.StatusForbidden},
+		{name: "write", method: http.MethodPost, path: "/api/todo", cookie: "alice-token", csrf: "alice-csrf", status: http.StatusOK, user: "alice"},
+		{name: "readonly write", method: http.MethodDelete, path: "/api/todo/1", cookie: "reader-token", csrf: "reader-csrf", status: http.StatusForbidden},
+		// Выйти может и пользователь только для чтения
+		{name: "readonly logout", method: http.MethodPost, path: "/api/auth/logout", cookie: "reader-token", csrf: "reader-csrf", status: http.StatusOK, user: "reader"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: handlers.SessionCookie, Value: tt.cookie})
+			}
+			if tt.csrf != "" {
+				req.Header.Set(handlers.CSRFHeader, tt.csrf)
+			}
+			rec := httptest.NewRecorder()
+			authenticated(t, nil).ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if tt.status == http.StatusOK && rec.Body.String() != tt.user {
+				t.Errorf("user in context = %q, want %q", rec.Body, tt.user)
+			}
+		})
+	}
+}
+
+// staticTokens - персональные токены по открытому значению
+type staticTokens struct {
+	ports.APITokenService
+	tokens map[string]domain.APIToken
+}
+
+func (s staticTokens) Authenticate(ctx context.Context, plain string) (domain.User, domain.APIToken, error) {
+	token, ok := s.tokens[plain]
+	if !ok {
+		return domain.User{}, domain.APIToken{}, ports.ErrUnauthenticated
+	}
+	return domain.User{Id: token.UserId, Role: domain.UserRoleUser}, token, nil
+}
+
+func TestBearerAuth(t *testing.T) {
+	tokens := staticTokens{tokens: map[string]domain.APIToken{
+		"tdl_read":  {Id: "t1", UserId: "alice", Scopes: []string{domain.ScopeTodosRead}},
+		"tdl_write": {Id: "t2", UserId: "alice", Scopes: []string{domain.ScopeTodosWrite}},
+	}}
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		authorization string
+		status        int
+		challenge     string
+	}{
+		{name: "read", method: http.MethodGet, path: "/api/todos", authorization: "Bearer tdl_read", status: http.StatusOK},
+		{name: "write with read scope", method: http.MethodPost, path: "/api/todo", authorization: "Bearer tdl_read", status: http.StatusForbidden, challenge: `Bearer error="insufficient_scope", scope="todos:write"`},
+		// Заголовок Authorization браузер сам не подставляет, CSRF не нужен
+		{name: "write", method: http.MethodPost, path: "/api/todo", authorization: "Bearer tdl_write", status: http.StatusOK},
+		{name: "write scope reads", method: http.MethodGet, path: "/api/todos", authorization: "Bearer tdl_write", status: http.StatusOK},
+		{name: "unknown token", method: http.MethodGet, path: "/api/todos", authorization: "Bearer tdl_unknown", status: http.StatusUnauthorized, challenge: `Bearer error="invalid_token"`},
+		{name: "basic scheme", method: http.MethodGet, path: "/api/todos", authorization: "Basic YWxpY2U6cHc=", status: http.StatusUnauthorized, challenge: "Bearer"},
+		// Токеном нельзя выпустить новый токен или администрировать
+		{name: "tokens endpoint", method: http.MethodGet, path: "/api/tokens", authorization: "Bearer tdl_write", status: http.StatusForbidden},
+		{name: "admin endpoint", method: http.MethodGet, path: "/api/admin/users", authorization: "Bearer tdl_write", status: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Authorization", tt.authorization)
+			rec := httptest.NewRecorder()
+			authenticated(t, tokens).ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if got := rec.Header().Get("WWW-Authenticate"); got != tt.challenge {
+				t.Errorf("WWW-Authenticate = %q, want %q", got, tt.challenge)
+			}
+			if tt.status == http.StatusOK && rec.Body.String() != "alice" {
+				t.Errorf("user in context = %q, want alice", rec.Body)
+			}
+		})
+	}
+}
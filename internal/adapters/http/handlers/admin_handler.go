@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/application/service"
+	"ToDo-List/internal/core/ports"
+
+	"github.com/gorilla/mux"
+)
+
+type AdminHandler struct {
+	adminService ports.AdminService
+	secureCookie bool
+	logger       *logger.Logger
+}
+
+func NewAdminHandler(adminService ports.AdminService, secureCookie bool, logger *logger.Logger) *AdminHandler {
+	return &AdminHandler{
+		adminService: adminService,
+		secureCookie: secureCookie,
+		logger:       logger,
+	}
+}
+
+// GetUsersHandler - GET /api/admin/users?q=&role=&limit=&offset=
+func (h *AdminHandler) GetUsersHandler(w http.ResponseWriter, r *http.Request) {
+	h.log(r.Context()).Info("Received GET /api/admin/users request")
+
+	q := r.URL.Query()
+	filter := ports.UserFilter{Query: q.Get("q"), Role: q.Get("role")}
+	var err error
+	if filter.Limit, err = queryInt(q.Get("limit")); err != nil {
+		http.Error(w, "Invalid limit", http.StatusBadRequest)
+		return
+	}
+	if filter.Offset, err = queryInt(q.Get("offset")); err != nil {
+		http.Error(w, "Invalid offset", http.StatusBadRequest)
+		return
+	}
+
+	users, err := h.adminService.ListUsers(r.Context(), filter)
+	if err != nil {
+		h.writeAdminError(w, r, err, "Failed to get users")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(users)
+}
+
+// GetUserHandler - GET /api/admin/users/{id}
+func (h *AdminHandler) GetUserHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	h.log(r.Context()).Info("Received GET /api/admin/users/%s request", id)
+
+	user, err := h.adminService.GetUser(r.Context(), id)
+	if err != nil {
+		h.writeAdminError(w, r, err, "Failed to get user")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// SetRoleHandler - PUT /api/admin/users/{id}/role {"role": "admin" | "user" | "readonly"}
+func (h *AdminHandler) SetRoleHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	h.log(r.Context()).Info("Received PUT /api/admin/users/%s/role request", id)
+
+	var req struct {
+		Role string `json:"role"`
+	}
+	if err := decodeJSON(w, r, &req); err != nil {
+		h.log(r.Context()).Warn("Invalid request body: %v", err)
+		writeDecodeError(w, err)
+		return
+	}
+
+	user, err := h.adminService.SetRole(r.Context(), id, req.Role)
+	if err != nil {
+		h.writeAdminError(w, r, err, "Failed to change role")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// SetDisabledHandler - PUT /api/admin/users/{id}/disabled {"disabled": true}
+func (h *AdminHandler) SetDisabledHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	h.log(r.Context()).Info("Received PUT /api/admin/users/%s/disabled request", id)
+
+	var req struct {
+		Disabled *bool `json:"disabled"`
+	}
+	if err := decodeJSON(w, r, &req); err != nil {
+		h.log(r.Context()).Warn("Invalid request body: %v", err)
+		writeDecodeError(w, err)
+		return
+	}
+	if req.Disabled == nil {
+		http.Error(w, "disabled is required", http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.adminService.SetDisabled(r.Context(), id, *req.Disabled)
+	if err != nil {
+		h.writeAdminError(w, r, err, "Failed to update user")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// ResetPasswordHandler - POST /api/admin/users/{id}/password {"password"}
+func (h *AdminHandler) ResetPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	h.log(r.Context()).Info("Received POST /api/admin/users/%s/password request", id)
+
+	var req struct {
+		Password string `json:"password"`
+	}
+	if err := decodeJSON(w, r, &req); err != nil {
+		h.log(r.Context()).Warn("Invalid request body: %v", err)
+		writeDecodeError(w, err)
+		return
+	}
+
+	if err := h.adminService.ResetPassword(r.Context(), id, req.Password); err != nil {
+		h.writeAdminError(w, r, err, "Failed to reset password")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ImpersonateHandler - POST /api/admin/users/{id}/impersonate. Сессия
+// администратора сохраняется в отдельной cookie, браузер переключается на
+// сессию пользователя до выхода из неё.
+func (h *AdminHandler) ImpersonateHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	h.log(r.Context()).Info("Received POST /api/admin/users/%s/impersonate request", id)
+
+	adminCookie, err := r.Cookie(SessionCookie)
+	if err != nil {
+		http.Error(w, "Impersonation requires a browser session", http.StatusBadRequest)
+		return
+	}
+
+	user, session, token, err := h.adminService.Impersonate(r.Context(), id)
+	if err != nil {
+		h.writeAdminError(w, r, err, "Failed to impersonate user")
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     ImpersonatorCookie,
+		Value:    adminCookie.Value,
+		Path:     "/",
+		Expires:  session.ExpiresAt,
+		HttpOnly: true,
+		Secure:   h.secureCookie,
+		SameSite: http.SameSiteLaxMode,
+	})
+	setSessionCookies(w, token, session, h.secureCookie)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(loginResponse{User: user, CSRFToken: session.CSRFToken})
+}
+
+// GetAuditLogHandler - GET /api/admin/audit?actor=&target=&action=&before=&limit=
+func (h *AdminHandler) GetAuditLogHandler(w http.ResponseWriter, r *http.Request) {
+	h.log(r.Context()).Info("Received GET /api/admin/audit request")
+
+	q := r.URL.Query()
+	filter := ports.AuditFilter{
+		ActorId:  q.Get("actor"),
+		TargetId: q.Get("target"),
+		Action:   q.Get("action"),
+	}
+	if value := q.Get("before"); value != "" {
+		before, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			http.Error(w, "Invalid before: expected RFC 3339 time", http.StatusBadRequest)
+			return
+		}
+		filter.Before = before
+	}
+	var err error
+	if filter.Limit, err = queryInt(q.Get("limit")); err != nil {
+		http.Error(w, "Invalid limit", http.StatusBadRequest)
+		return
+	}
+
+	entries, err := h.adminService.GetAuditLog(r.Context(), filter)
+	if err != nil {
+		h.writeAdminError(w, r, err, "Failed to get audit log")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+// GetStatsHandler - GET /api/admin/stats
+func (h *AdminHandler) GetStatsHandler(w http.ResponseWriter, r *http.Request) {
+	h.log(r.Context()).Info("Received GET /api/admin/stats request")
+
+	stats, err := h.adminService.GetStats(r.Context())
+	if err != nil {
+		h.writeAdminError(w, r, err, "Failed to get stats")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+// queryInt разбирает необязательный неотрицательный параметр; пусто - 0
+func queryInt(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, errors.New("invalid number")
+	}
+	return n, nil
+}
+
+func (h *AdminHandler) writeAdminError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidUserRole),
+		errors.Is(err, service.ErrWeakPassword):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrSelfAdminChange),
+		errors.Is(err, service.ErrLastAdmin),
+		errors.Is(err, service.ErrCannotImpersonate):
+		h.log(r.Context()).Warn("Rejected admin action: %v", err)
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, ports.ErrUserNotFound):
+		http.Error(w, "User not found", http.StatusNotFound)
+	case errors.Is(err, ports.ErrForbidden):
+		http.Error(w, "Forbidden", http.StatusForbidden)
+	default:
+		h.log(r.Context()).Error("%s: %v", message, err)
+		http.Error(w, message, http.StatusInternalServerError)
+	}
+}
+
+// log - логгер запроса с его X-Request-ID
+func (h *AdminHandler) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, h.logger)
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/application/service"
+	"ToDo-List/internal/core/ports"
+
+	"github.com/gorilla/mux"
+)
+
+// multipartOverhead - запас на заголовки и границы multipart сверх размера файла
+const multipartOverhead = 1 << 20
+
+type AttachmentHandler struct {
+	attachmentService ports.AttachmentService
+	maxSize           int64
+	logger            *logger.Logger
+}
+
+func NewAttachmentHandler(attachmentService ports.AttachmentService, maxSize int64, logger *logger.Logger) *AttachmentHandler {
+	return &AttachmentHandler{
+		attachmentService: attachmentService,
+		maxSize:           maxSize,
+		logger:            logger,
+	}
+}
+
+// GetAttachmentsHandler - GET /api/todo/{id}/attachments
+func (h *AttachmentHandler) GetAttachmentsHandler(w http.ResponseWriter, r *http.Request) {
+	todoId := mux.Vars(r)["id"]
+	h.log(r.Context()).Info("Received GET /api/todo/%s/attachments request", todoId)
+
+	attachments, err := h.attachmentService.ListAttachments(r.Context(), todoId)
+	if err != nil {
+		h.writeAttachmentError(w, r, err, "Failed to get attachments")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(attachments)
+}
+
+// UploadAttachmentHandler - POST /api/todo/{id}/attachments, multipart/form-data с полем file
+func (h *AttachmentHandler) UploadAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	todoId := mux.Vars(r)["id"]
+	h.log(r.Context()).Info("Received POST /api/todo/%s/attachments request", todoId)
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxSize+multipartOverhead)
+	// Части больше 1 МБ multipart сохраняет во временные файлы
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "Attachment is too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		h.log(r.Context()).Warn("Invalid multipart request: %v", err)
+		http.Error(w, "Invalid multipart request", http.StatusBadRequest)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "Missing file field", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	attachment, err := h.attachmentService.Upload(r.Context(), todoId, ports.Upload{
+		Filename: header.Filename,
+		Size:     header.Size,
+		Content:  file,
+	})
+	if err != nil {
+		h.writeAttachmentError(w, r, err, "Failed to upload attachment")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(attachment)
+}
+
+// DownloadAttachmentHandler - GET /api/todo/{id}/attachments/{attachmentId}[?inline=true]
+func (h *AttachmentHandler) DownloadAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	h.log(r.Context()).Info("Received GET /api/todo/%s/attachments/%s request", vars["id"], vars["attachmentId"])
+
+	attachment, content, err := h.attachmentService.Open(r.Context(), vars["id"], vars["attachmentId"])
+	if err != nil {
+		h.writeAttachmentError(w, r, err, "Failed to download attachment")
+		return
+	}
+	defer content.Close()
+
+	disposition := "attachment"
+	if r.URL.Query().Get("inline") == "true" {
+		disposition = "inline"
+	}
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": attachment.Filename}))
+	// Браузер не должен угадывать тип и исполнять содержимое как страницу
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "sandbox")
+	w.Header().Set("ETag", `"`+attachment.ContentHash+`"`)
+
+	if _, err := io.Copy(w, content); err != nil {
+		h.log(r.Context()).Warn("Failed to stream attachment %s: %v", attachment.Id, err)
+	}
+}
+
+// DeleteAttachmentHandler - DELETE /api/todo/{id}/attachments/{attachmentId}
+func (h *AttachmentHandler) DeleteAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	h.log(r.Context()).Info("Received DELETE /api/todo/%s/attachments/%s request", vars["id"], vars["attachmentId"])
+
+	if err := h.attachmentService.DeleteAttachment(r.Context(), vars["id"], vars["attachmentId"]); err != nil {
+		h.writeAttachmentError(w, r, err, "Failed to delete attachment")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AttachmentHandler) writeAttachmentError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrAttachmentTooLarge):
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+	case errors.Is(err, service.ErrAttachmentType):
+		h.log(r.Context()).Warn("Rejected attachment: %v", err)
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+	case errors.Is(err, service.ErrAttachmentEmpty):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrResourceNotFound):
+		http.Error(w, "Todo not found", http.StatusNotFound)
+	case errors.Is(err, ports.ErrAttachmentNotFound):
+		http.Error(w, "Attachment not found", http.StatusNotFound)
+	case errors.Is(err, ports.ErrForbidden):
+		http.Error(w, "Forbidden", http.StatusForbidden)
+	default:
+		h.log(r.Context()).Error("%s: %v", message, err)
+		http.Error(w, message, http.StatusInternalServerError)
+	}
+}
+
+// log - логгер запроса с его X-Request-ID
+func (h *AttachmentHandler) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, h.logger)
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/application/service"
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/identity"
+	"ToDo-List/internal/core/ports"
+)
+
+const (
+	// SessionCookie - HttpOnly cookie с токеном сессии
+	SessionCookie = "session"
+	// CSRFCookie - cookie с CSRF-токеном, доступная из JS
+	CSRFCookie = "csrf_token"
+	// CSRFHeader - заголовок, в котором клиент возвращает CSRF-токен
+	CSRFHeader = "X-CSRF-Token"
+	// ImpersonatorCookie - HttpOnly cookie с сессией администратора на время
+	// входа от имени пользователя; выход восстанавливает её
+	ImpersonatorCookie = "impersonator_session"
+)
+
+type AuthHandler struct {
+	authService  ports.AuthService
+	oidc         ports.IdentityProvider // nil, если SSO не настроен
+	secureCookie bool
+	logger       *logger.Logger
+}
+
+func NewAuthHandler(authService ports.AuthService, oidc ports.IdentityProvider, secureCookie bool, logger *logger.Logger) *AuthHandler {
+	return &AuthHandler{
+		authService:  authService,
+		oidc:         oidc,
+		secureCookie: secureCookie,
+		logger:       logger,
+	}
+}
+
+type credentialsRequest struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type loginResponse struct {
+	User      domain.User `json:"user"`
+	CSRFToken string      `json:"csrfToken"`
+}
+
+// RegisterHandler - POST /api/auth/register
+func (h *AuthHandler) RegisterHandler(w http.ResponseWriter, r *http.Request) {
+	h.log(r.Context()).Info("Received POST /api/auth/register request")
+
+	var req credentialsRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		h.log(r.Context()).Warn("Invalid request body: %v", err)
+		writeDecodeError(w, err)
+		return
+	}
+
+	user, err := h.authService.Register(r.Context(), req.Username, req.Email, req.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidUsername),
+			errors.Is(err, service.ErrInvalidEmail),
+			errors.Is(err, service.ErrWeakPassword):
+			h.log(r.Context()).Warn("Invalid registration: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, ports.ErrUserExists):
+			h.log(r.Context()).Warn("Registration conflict: %v", err)
+			http.Error(w, "User already exists", http.StatusConflict)
+		default:
+			h.log(r.Context()).Error("Failed to register user: %v", err)
+			http.Error(w, "Failed to register user", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	h.log(r.Context()).Info("User registered successfully: %s", user.Id)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(user)
+}
+
+// LoginHandler - POST /api/auth/login
+func (h *AuthHandler) LoginHandler(w http.ResponseWriter, r *http.Request) {
+	h.log(r.Context()).Info("Received POST /api/auth/login request")
+
+	var req credentialsRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		h.log(r.Context()).Warn("Invalid request body: %v", err)
+		writeDecodeError(w, err)
+		return
+	}
+
+	user, session, token, err := h.authService.Login(r.Context(), req.Username, req.Password)
+	if err != nil {
+		if errors.Is(err, ports.ErrInvalidCredentials) {
+			http.Error(w, "Invalid username or password", http.StatusUnauthorized)
+			return
+		}
+		if errors.Is(err, ports.ErrUserDisabled) {
+			http.Error(w, "Account is disabled", http.StatusForbidden)
+			return
+		}
+		h.log(r.Context()).Error("Failed to log in: %v", err)
+		http.Error(w, "Failed to log in", http.StatusInternalServerError)
+		return
+	}
+
+	h.setSessionCookies(w, token, session)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(loginResponse{User: user, CSRFToken: session.CSRFToken})
+}
+
+func (h *AuthHandler) setSessionCookies(w http.ResponseWriter, token string, session domain.Session) {
+	setSessionCookies(w, token, session, h.secureCookie)
+}
+
+func setSessionCookies(w http.ResponseWriter, token string, session domain.Session, secure bool) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookie,
+		Value:    token,
+		Path:     "/",
+		Expires:  session.ExpiresAt,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+	// CSRF-токен читается фронтендом и отправляется в заголовке X-CSRF-Token
+	http.SetCookie(w, &http.Cookie{
+		Name:     CSRFCookie,
+		Value:    session.CSRFToken,
+		Path:     "/",
+		Expires:  session.ExpiresAt,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func clearCookie(w http.ResponseWriter, name string, secure bool) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    "",
+		Path:     "/",
+		Expires:  time.Unix(0, 0),
+		MaxAge:   -1,
+		HttpOnly: name != CSRFCookie,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// LogoutHandler - POST /api/auth/logout. Выход из сессии от имени пользователя
+// возвращает администратора в его собственную сессию.
+func (h *AuthHandler) LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	h.log(r.Context()).Info("Received POST /api/auth/logout request")
+
+	if cookie, err := r.Cookie(SessionCookie); err == nil {
+		if err := h.authService.Logout(r.Context(), cookie.Value); err != nil {
+			h.log(r.Context()).Error("Failed to delete session: %v", err)
+			http.Error(w, "Failed to log out", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if cookie, err := r.Cookie(ImpersonatorCookie); err == nil {
+		clearCookie(w, ImpersonatorCookie, h.secureCookie)
+		if admin, session, err := h.authService.Authenticate(r.Context(), cookie.Value); err == nil {
+			if impersonatorId, ok := identity.ImpersonatorId(r.Context()); ok {
+				h.log(r.Context()).Info("Admin %s stopped impersonation", impersonatorId)
+			}
+			h.setSessionCookies(w, cookie.Value, session)
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(loginResponse{User: admin, CSRFToken: session.CSRFToken})
+			return
+		}
+	}
+
+	clearCookie(w, SessionCookie, h.secureCookie)
+	clearCookie(w, CSRFCookie, h.secureCookie)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// MeHandler - GET /api/auth/me
+func (h *AuthHandler) MeHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := identity.UserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// log - логгер запроса с его X-Request-ID
+func (h *AuthHandler) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, h.logger)
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/application/service"
+	"ToDo-List/internal/core/ports"
+)
+
+type BulkHandler struct {
+	bulkService ports.BulkService
+	logger      *logger.Logger
+}
+
+func NewBulkHandler(bulkService ports.BulkService, logger *logger.Logger) *BulkHandler {
+	return &BulkHandler{
+		bulkService: bulkService,
+		logger:      logger,
+	}
+}
+
+// BulkTodosHandler - POST /api/todos/bulk
+func (h *BulkHandler) BulkTodosHandler(w http.ResponseWriter, r *http.Request) {
+	h.log(r.Context()).Info("Received POST /api/todos/bulk request")
+
+	var req ports.BulkRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		h.log(r.Context()).Warn("Invalid request body: %v", err)
+		writeDecodeError(w, err)
+		return
+	}
+
+	if err := validateBulkRequest(&req); err != nil {
+		h.log(r.Context()).Warn("Invalid bulk request: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.bulkService.ApplyBulk(r.Context(), req)
+	switch {
+	case errors.Is(err, service.ErrTooManyItems):
+		h.log(r.Context()).Warn("Bulk request too large: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, ports.ErrForbidden):
+		h.log(r.Context()).Warn("Bulk %s forbidden", req.Action)
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	case errors.Is(err, ports.ErrBulkAborted):
+		// Всё или ничего: возвращаем результаты, чтобы было видно, на какой задаче остановились
+		h.log(r.Context()).Warn("Bulk %s aborted", req.Action)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(result)
+		return
+	case err != nil:
+		h.log(r.Context()).Error("Failed to apply bulk %s: %v", req.Action, err)
+		http.Error(w, "Failed to apply bulk operation", http.StatusInternalServerError)
+		return
+	}
+
+	h.log(r.Context()).Info("Bulk %s: matched %d, succeeded %d, failed %d", req.Action, result.Matched, result.Succeeded, result.Failed)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// validateBulkRequest проверяет выбор задач и параметры действия
+func validateBulkRequest(req *ports.BulkRequest) error {
+	if (req.Filter == nil) == (len(req.Ids) == 0) {
+		return errors.New("Exactly one of 'ids' or 'filter' is required")
+	}
+	if len(req.Ids) > service.MaxBulkItems {
+		return errors.New("Too many ids")
+	}
+	if req.Filter != nil {
+		if err := validateFilter(*req.Filter); err != nil {
+			return err
+		}
+	}
+	if req.Mode != "" && req.Mode != "atomic" && req.Mode != "per_item" {
+		return errors.New("Invalid mode, expected atomic or per_item")
+	}
+
+	switch req.Action {
+	case ports.BulkComplete, ports.BulkUncomplete, ports.BulkDelete, ports.BulkSetDeadline:
+	case ports.BulkSetPriority:
+		if req.Priority != "low" && req.Priority != "medium" && req.Priority != "high" {
+			return errors.New("Invalid 'priority' for set_priority")
+		}
+	case ports.BulkTag, ports.BulkUntag:
+		req.Tags = normalizeTags(req.Tags)
+		if len(req.Tags) == 0 {
+			return errors.New("Missing 'tags' for tag action")
+		}
+	case ports.BulkMove:
+		if req.Move != "top" && req.Move != "bottom" {
+			return errors.New("Invalid 'move', expected top or bottom")
+		}
+	default:
+		return errors.New("Invalid action")
+	}
+	return nil
+}
+
+// log - логгер запроса с его X-Request-ID
+func (h *BulkHandler) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, h.logger)
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/application/service"
+	"ToDo-List/internal/core/ports"
+
+	"github.com/gorilla/mux"
+)
+
+type CommentHandler struct {
+	commentService ports.CommentService
+	logger         *logger.Logger
+}
+
+func NewCommentHandler(commentService ports.CommentService, logger *logger.Logger) *CommentHandler {
+	return &CommentHandler{
+		commentService: commentService,
+		logger:         logger,
+	}
+}
+
+type commentRequest struct {
+	Body string `json:"body"` // markdown
+}
+
+// GetCommentsHandler - GET /api/todo/{id}/comments
+func (h *CommentHandler) GetCommentsHandler(w http.ResponseWriter, r *http.Request) {
+	todoId := mux.Vars(r)["id"]
+	h.log(r.Context()).Info("Received GET /api/todo/%s/comments request", todoId)
+
+	comments, err := h.commentService.ListComments(r.Context(), todoId)
+	if err != nil {
+		h.writeCommentError(w, r, err, "Failed to get comments")
+		return
+	}
+
+	h.log(r.Context()).Info("Returning %d comments", len(comments))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(comments)
+}
+
+// CreateCommentHandler - POST /api/todo/{id}/comments
+func (h *CommentHandler) CreateCommentHandler(w http.ResponseWriter, r *http.Request) {
+	todoId := mux.Vars(r)["id"]
+	h.log(r.Context()).Info("Received POST /api/todo/%s/comments request", todoId)
+
+	var req commentRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		h.log(r.Context()).Warn("Invalid request body: %v", err)
+		writeDecodeError(w, err)
+		return
+	}
+
+	comment, err := h.commentService.AddComment(r.Context(), todoId, req.Body)
+	if err != nil {
+		h.writeCommentError(w, r, err, "Failed to add comment")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(comment)
+}
+
+// GetCommentHandler - GET /api/todo/{id}/comments/{commentId}
+func (h *CommentHandler) GetCommentHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	h.log(r.Context()).Info("Received GET /api/todo/%s/comments/%s request", vars["id"], vars["commentId"])
+
+	comment, err := h.commentService.GetComment(r.Context(), vars["id"], vars["commentId"])
+	if err != nil {
+		h.writeCommentError(w, r, err, "Failed to get comment")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(comment)
+}
+
+// UpdateCommentHandler - PUT /api/todo/{id}/comments/{commentId}
+func (h *CommentHandler) UpdateCommentHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	h.log(r.Context()).Info("Received PUT /api/todo/%s/comments/%s request", vars["id"], vars["commentId"])
+
+	var req commentRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		h.log(r.Context()).Warn("Invalid request body: %v", err)
+		writeDecodeError(w, err)
+		return
+	}
+
+	comment, err := h.commentService.EditComment(r.Context(), vars["id"], vars["commentId"], req.Body)
+	if err != nil {
+		h.writeCommentError(w, r, err, "Failed to update comment")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(comment)
+}
+
+// DeleteCommentHandler - DELETE /api/todo/{id}/comments/{commentId}
+func (h *CommentHandler) DeleteCommentHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	h.log(r.Context()).Info("Received DELETE /api/todo/%s/comments/%s request", vars["id"], vars["commentId"])
+
+	if err := h.commentService.DeleteComment(r.Context(), vars["id"], vars["commentId"]); err != nil {
+		h.writeCommentError(w, r, err, "Failed to delete comment")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetCommentHistoryHandler - GET /api/todo/{id}/comments/{commentId}/history
+func (h *CommentHandler) GetCommentHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	h.log(r.Context()).Info("Received GET /api/todo/%s/comments/%s/history request", vars["id"], vars["commentId"])
+
+	revisions, err := h.commentService.GetCommentHistory(r.Context(), vars["id"], vars["commentId"])
+	if err != nil {
+		h.writeCommentError(w, r, err, "Failed to get comment history")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(revisions)
+}
+
+func (h *CommentHandler) writeCommentError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidComment):
+		h.log(r.Context()).Warn("Invalid comment: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrResourceNotFound):
+		http.Error(w, "Todo not found", http.StatusNotFound)
+	case errors.Is(err, ports.ErrCommentNotFound):
+		http.Error(w, "Comment not found", http.StatusNotFound)
+	case errors.Is(err, ports.ErrForbidden):
+		http.Error(w, "Forbidden", http.StatusForbidden)
+	default:
+		h.log(r.Context()).Error("%s: %v", message, err)
+		http.Error(w, message, http.StatusInternalServerError)
+	}
+}
+
+// log - логгер запроса с его X-Request-ID
+func (h *CommentHandler) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, h.logger)
+}
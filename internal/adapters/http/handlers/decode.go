@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// MaxJSONBodySize - предельный размер JSON-тела запроса
+const MaxJSONBodySize = 1 << 20
+
+// errTrailingData - после JSON-значения в теле есть что-то ещё
+var errTrailingData = errors.New("request body must contain a single JSON value")
+
+// decodeJSON читает тело не больше MaxJSONBodySize и строго разбирает его:
+// неизвестные поля и данные после JSON-значения - ошибка
+func decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxJSONBodySize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if err := dec.Decode(&struct{}{}); err != io.EOF {
+		return errTrailingData
+	}
+	return nil
+}
+
+// writeDecodeError отвечает 413 на слишком большое тело и 400 на остальные ошибки разбора
+func writeDecodeError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+}
@@ -1,16 +1,20 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
 	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/application/service"
 	"ToDo-List/internal/core/domain"
 	"ToDo-List/internal/core/ports"
+	"ToDo-List/internal/core/query"
 
-	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 )
 
@@ -28,44 +32,47 @@ func NewTodoHandler(todoService ports.ToDoService, logger *logger.Logger) *TodoH
 
 // CreateTodoHandler - POST /api/todo
 func (h *TodoHandler) CreateTodoHandler(w http.ResponseWriter, r *http.Request) {
-	h.logger.Info("Received POST /api/todo request")
+	h.log(r.Context()).Info("Received POST /api/todo request")
 	
 	var todo domain.ToDo
-	err := json.NewDecoder(r.Body).Decode(&todo)
+	err := decodeJSON(w, r, &todo)
 	if err != nil {
-		h.logger.Warn("Invalid request body: %v", err)
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		h.log(r.Context()).Warn("Invalid request body: %v", err)
+		writeDecodeError(w, err)
 		return
 	}
 	
-	todo.Id = uuid.NewString()
+	// id задаёт клиент (UUID) или сервис
 	todo.CreatedAt = time.Now()
 	todo.UpdatedAt = time.Now()
 
 	if strings.TrimSpace(todo.Todo) == "" {
-		h.logger.Warn("Missing 'todo' field in request")
+		h.log(r.Context()).Warn("Missing 'todo' field in request")
 		http.Error(w, "Missing 'todo' field", http.StatusBadRequest)
 		return
 	}
 
-	// Message и Deadline делаем опциональными
-	if strings.TrimSpace(todo.Priority) == "" {
-		todo.Priority = "medium"
-	}
-	if todo.Priority != "low" && todo.Priority != "medium" && todo.Priority != "high" {
+	// Message и Deadline делаем опциональными; пустой приоритет заполнит
+	// сервис из настроек рабочего пространства
+	todo.Priority = strings.TrimSpace(todo.Priority)
+	if todo.Priority != "" && todo.Priority != "low" && todo.Priority != "medium" && todo.Priority != "high" {
 		todo.Priority = "medium"
 	}
 	todo.Complete = false
+	todo.Tags = normalizeTags(todo.Tags)
+	// Позицию назначает сервис: ключ от клиента мог бы сломать ручной порядок,
+	// переместить задачу можно через POST /api/todo/{id}/move
+	todo.Position = ""
 
-	h.logger.Debug("Creating todo: %+v", todo)
+	h.log(r.Context()).Debug("Creating todo: %+v", todo)
 	createdTodo, err := h.todoService.CreateTodo(r.Context(), todo)
 	if err != nil {
-		h.logger.Error("Failed to create todo: %v", err)
-		http.Error(w, "Failed to create todo", http.StatusInternalServerError)
+		h.log(r.Context()).Error("Failed to create todo: %v", err)
+		writeTodoError(w, err, "Failed to create todo")
 		return
 	}
 
-	h.logger.Info("Todo created successfully: %s", createdTodo.Id)
+	h.log(r.Context()).Info("Todo created successfully: %s", createdTodo.Id)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(createdTodo)
@@ -73,55 +80,36 @@ func (h *TodoHandler) CreateTodoHandler(w http.ResponseWriter, r *http.Request)
 
 // GetTodosHandler - GET /api/todos
 func (h *TodoHandler) GetTodosHandler(w http.ResponseWriter, r *http.Request) {
-	h.logger.Info("Received GET /api/todos request")
+	h.log(r.Context()).Info("Received GET /api/todos request")
 	
 	q := r.URL.Query()
 	
-	filter := ports.TodoFilter{
+	filter := domain.TodoFilter{
 		Status:   q.Get("status"),
 		OrderBy:  q.Get("orderBy"),
 		OrderDir: q.Get("orderDir"),
 		Period:   q.Get("period"),
+		Query:    q.Get("q"),
+		Sort:     q.Get("sort"),
+		Assignee: q.Get("assignee"),
 	}
 	
 	// Валидация параметров
-	if filter.Status != "" && filter.Status != "all" && filter.Status != "active" && 
-	   filter.Status != "completed" && filter.Status != "overdue" {
-		h.logger.Warn("Invalid status parameter: %s", filter.Status)
-		http.Error(w, "Invalid status parameter", http.StatusBadRequest)
-		return
-	}
-	
-	if filter.OrderDir != "" && filter.OrderDir != "asc" && filter.OrderDir != "desc" {
-		h.logger.Warn("Invalid orderDir parameter: %s", filter.OrderDir)
-		http.Error(w, "Invalid orderDir parameter", http.StatusBadRequest)
+	if err := validateFilter(filter); err != nil {
+		h.log(r.Context()).Warn("Invalid filter: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	
-	// Валидация OrderBy
-	validOrderBy := map[string]bool{
-		"created_at":   true,
-		"deadline":     true,
-		"priority":     true,
-		"completed_at": true,
-		"":             true, // пустое значение тоже валидно
-	}
-	
-	if !validOrderBy[filter.OrderBy] {
-		h.logger.Warn("Invalid orderBy parameter: %s", filter.OrderBy)
-		http.Error(w, "Invalid orderBy parameter", http.StatusBadRequest)
-		return
-	}
-	
-	h.logger.Debug("Fetching todos with filter: %+v", filter)
+	h.log(r.Context()).Debug("Fetching todos with filter: %+v", filter)
 	todos, err := h.todoService.GetAllTodosWithFilters(r.Context(), filter)
 	if err != nil {
-		h.logger.Error("Failed to get todos: %v", err)
-		http.Error(w, "Failed to get todos", http.StatusInternalServerError)
+		h.log(r.Context()).Error("Failed to get todos: %v", err)
+		writeTodoError(w, err, "Failed to get todos")
 		return
 	}
 	
-	h.logger.Info("Returning %d todos", len(todos))
+	h.log(r.Context()).Info("Returning %d todos", len(todos))
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(todos)
 }
@@ -129,88 +117,120 @@ func (h *TodoHandler) GetTodosHandler(w http.ResponseWriter, r *http.Request) {
 func (h *TodoHandler) GetTodoByIdHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
-	h.logger.Info("Received GET /api/todo/%s request", id)
+	h.log(r.Context()).Info("Received GET /api/todo/%s request", id)
 
 	if id == "" {
-		h.logger.Warn("Missing todo ID in request")
+		h.log(r.Context()).Warn("Missing todo ID in request")
 		http.Error(w, "Missing todo ID", http.StatusBadRequest)
 		return
 	}
 
-	h.logger.Debug("Fetching todo with ID: %s", id)
+	h.log(r.Context()).Debug("Fetching todo with ID: %s", id)
 	todo, err := h.todoService.GetTodoById(r.Context(), id)
 	if err != nil {
-		h.logger.Error("Todo not found: %s, error: %v", id, err)
+		h.log(r.Context()).Error("Todo not found: %s, error: %v", id, err)
 		http.Error(w, "Todo not found", http.StatusNotFound)
 		return
 	}
 
-	h.logger.Info("Todo found: %s", id)
+	h.log(r.Context()).Info("Todo found: %s", id)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(todo)
 }
 
-// UpdateTodoByIdHandler - PUT /api/todo/{id}
+// todoReplacement - тело PUT /api/todo/{id}: задача передаётся целиком,
+// пропущенные необязательные поля очищаются. Поля, без которых замена стёрла
+// бы состояние задачи, обязательны.
+type todoReplacement struct {
+	domain.ToDo
+	Priority *string `json:"priority"`
+	Complete *bool   `json:"complete"`
+}
+
+// UpdateTodoByIdHandler - PUT /api/todo/{id}. Если задачи с таким id нет, а id -
+// UUID, задача создаётся (201), иначе заменяется (200).
 func (h *TodoHandler) UpdateTodoByIdHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
-	h.logger.Info("Received PUT /api/todo/%s request", id)
+	h.log(r.Context()).Info("Received PUT /api/todo/%s request", id)
 
 	if id == "" {
-		h.logger.Warn("Missing todo ID in request")
+		h.log(r.Context()).Warn("Missing todo ID in request")
 		http.Error(w, "Missing todo ID", http.StatusBadRequest)
 		return
 	}
 
-	var todo domain.ToDo
-	err := json.NewDecoder(r.Body).Decode(&todo)
+	var req todoReplacement
+	err := decodeJSON(w, r, &req)
 	if err != nil {
-		h.logger.Warn("Invalid request body: %v", err)
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		h.log(r.Context()).Warn("Invalid request body: %v", err)
+		writeDecodeError(w, err)
 		return
 	}
 
-	if strings.TrimSpace(todo.Todo) == "" {
-		h.logger.Warn("Missing 'todo' field in update request")
+	if strings.TrimSpace(req.Todo) == "" {
+		h.log(r.Context()).Warn("Missing 'todo' field in update request")
 		http.Error(w, "Missing 'todo' field", http.StatusBadRequest)
 		return
 	}
+	if req.Priority == nil || req.Complete == nil {
+		h.log(r.Context()).Warn("Missing 'priority' or 'complete' field in update request")
+		http.Error(w, "PUT replaces the whole todo: 'priority' and 'complete' are required", http.StatusBadRequest)
+		return
+	}
 
+	todo := req.ToDo
 	todo.Id = id
-	h.logger.Debug("Updating todo: %+v", todo)
+	// Позиция не заменяется, новая задача встаёт в конец списка
+	todo.Position = ""
+	todo.Complete = *req.Complete
+	todo.Tags = normalizeTags(todo.Tags)
+	todo.Priority = strings.TrimSpace(*req.Priority)
+	if todo.Priority != "low" && todo.Priority != "medium" && todo.Priority != "high" {
+		todo.Priority = "medium"
+	}
+	h.log(r.Context()).Debug("Upserting todo: %+v", todo)
 	
-	err = h.todoService.UpdateTodo(r.Context(), todo)
+	result, created, err := h.todoService.UpsertTodo(r.Context(), todo)
 	if err != nil {
-		h.logger.Error("Failed to update todo %s: %v", id, err)
-		http.Error(w, "Failed to update todo", http.StatusInternalServerError)
+		h.log(r.Context()).Error("Failed to update todo %s: %v", id, err)
+		writeTodoError(w, err, "Failed to update todo")
 		return
 	}
 
-	h.logger.Info("Todo updated successfully: %s", id)
-	w.WriteHeader(http.StatusNoContent)
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+		h.log(r.Context()).Info("Todo created successfully: %s", id)
+	} else {
+		h.log(r.Context()).Info("Todo updated successfully: %s", id)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(result)
 }
 
 // DeleteTodoHandler - DELETE /api/todo/{id}
 func (h *TodoHandler) DeleteTodoHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
-	h.logger.Info("Received DELETE /api/todo/%s request", id)
+	h.log(r.Context()).Info("Received DELETE /api/todo/%s request", id)
 
 	if id == "" {
-		h.logger.Warn("Missing todo ID in request")
+		h.log(r.Context()).Warn("Missing todo ID in request")
 		http.Error(w, "Missing todo ID", http.StatusBadRequest)
 		return
 	}
 
-	h.logger.Debug("Deleting todo with ID: %s", id)
+	h.log(r.Context()).Debug("Deleting todo with ID: %s", id)
 	err := h.todoService.DeleteTodo(r.Context(), id)
 	if err != nil {
-		h.logger.Error("Failed to delete todo %s: %v", id, err)
-		http.Error(w, "Failed to delete todo", http.StatusInternalServerError)
+		h.log(r.Context()).Error("Failed to delete todo %s: %v", id, err)
+		writeTodoError(w, err, "Failed to delete todo")
 		return
 	}
 
-	h.logger.Info("Todo deleted successfully: %s", id)
+	h.log(r.Context()).Info("Todo deleted successfully: %s", id)
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -218,22 +238,209 @@ func (h *TodoHandler) DeleteTodoHandler(w http.ResponseWriter, r *http.Request)
 func (h *TodoHandler) CompleteTodoByIdHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
-	h.logger.Info("Received POST /api/todo/complete/%s request", id)
+	h.log(r.Context()).Info("Received POST /api/todo/complete/%s request", id)
 
 	if id == "" {
-		h.logger.Warn("Missing todo ID in complete request")
+		h.log(r.Context()).Warn("Missing todo ID in complete request")
 		http.Error(w, "Missing todo ID", http.StatusBadRequest)
 		return
 	}
 
-	h.logger.Debug("Completing todo with ID: %s", id)
+	h.log(r.Context()).Debug("Completing todo with ID: %s", id)
 	err := h.todoService.CompleteTodoById(r.Context(), id)
 	if err != nil {
-		h.logger.Error("Failed to complete todo %s: %v", id, err)
-		http.Error(w, "Failed to complete todo", http.StatusInternalServerError)
+		h.log(r.Context()).Error("Failed to complete todo %s: %v", id, err)
+		writeTodoError(w, err, "Failed to complete todo")
 		return
 	}
 
-	h.logger.Info("Todo completed successfully: %s", id)
+	h.log(r.Context()).Info("Todo completed successfully: %s", id)
 	w.WriteHeader(http.StatusNoContent)
-}
\ No newline at end of file
+}
+
+// MoveTodoHandler - POST /api/todo/{id}/move
+func (h *TodoHandler) MoveTodoHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	h.log(r.Context()).Info("Received POST /api/todo/%s/move request", id)
+
+	var req struct {
+		Before string `json:"before"`
+		After  string `json:"after"`
+	}
+	if err := decodeJSON(w, r, &req); err != nil {
+		h.log(r.Context()).Warn("Invalid request body: %v", err)
+		writeDecodeError(w, err)
+		return
+	}
+	if (req.Before == "") == (req.After == "") {
+		h.log(r.Context()).Warn("Move request must set exactly one of before/after")
+		http.Error(w, "Exactly one of 'before' or 'after' is required", http.StatusBadRequest)
+		return
+	}
+	if req.Before == id || req.After == id {
+		h.log(r.Context()).Warn("Todo %s cannot be moved relative to itself", id)
+		http.Error(w, "Todo cannot be moved relative to itself", http.StatusBadRequest)
+		return
+	}
+
+	todo, err := h.todoService.MoveTodo(r.Context(), id, req.Before, req.After)
+	if err != nil {
+		h.log(r.Context()).Error("Failed to move todo %s: %v", id, err)
+		writeTodoError(w, err, "Failed to move todo")
+		return
+	}
+
+	h.log(r.Context()).Info("Todo moved successfully: %s", id)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(todo)
+}
+
+// writeTodoError отвечает 403, если у пользователя нет прав на действие с задачей,
+// 404 - если задачи нет, 409 - если id новой задачи уже занят
+func writeTodoError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, ports.ErrForbidden):
+		http.Error(w, "Forbidden", http.StatusForbidden)
+	case errors.Is(err, service.ErrUserNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrNoAccess),
+		errors.Is(err, service.ErrInvalidTodoId),
+		errors.Is(err, service.ErrMoveAcrossLists):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ports.ErrTodoNotFound):
+		http.Error(w, "Todo not found", http.StatusNotFound)
+	case errors.Is(err, ports.ErrConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, message, http.StatusInternalServerError)
+	}
+}
+
+// AssignTodoHandler - PUT /api/todo/{id}/assignee
+func (h *TodoHandler) AssignTodoHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	h.log(r.Context()).Info("Received PUT /api/todo/%s/assignee request", id)
+
+	var req struct {
+		Assignee string `json:"assignee"` // "me", имя или id; пусто - снять назначение
+	}
+	if err := decodeJSON(w, r, &req); err != nil {
+		h.log(r.Context()).Warn("Invalid request body: %v", err)
+		writeDecodeError(w, err)
+		return
+	}
+
+	todo, err := h.todoService.AssignTodo(r.Context(), id, strings.TrimSpace(req.Assignee))
+	if err != nil {
+		h.log(r.Context()).Error("Failed to assign todo %s: %v", id, err)
+		writeTodoError(w, err, "Failed to assign todo")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(todo)
+}
+
+// WatchTodoHandler - POST /api/todo/{id}/watchers, {"user": "..."}; без тела - подписаться самому
+func (h *TodoHandler) WatchTodoHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	h.log(r.Context()).Info("Received POST /api/todo/%s/watchers request", id)
+
+	var req struct {
+		User string `json:"user"`
+	}
+	if r.ContentLength != 0 {
+		if err := decodeJSON(w, r, &req); err != nil {
+			h.log(r.Context()).Warn("Invalid request body: %v", err)
+			writeDecodeError(w, err)
+			return
+		}
+	}
+
+	todo, err := h.todoService.WatchTodo(r.Context(), id, strings.TrimSpace(req.User))
+	if err != nil {
+		h.log(r.Context()).Error("Failed to add watcher to todo %s: %v", id, err)
+		writeTodoError(w, err, "Failed to add watcher")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(todo)
+}
+
+// UnwatchTodoHandler - DELETE /api/todo/{id}/watchers/{user}
+func (h *TodoHandler) UnwatchTodoHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	h.log(r.Context()).Info("Received DELETE /api/todo/%s/watchers/%s request", id, vars["user"])
+
+	todo, err := h.todoService.UnwatchTodo(r.Context(), id, vars["user"])
+	if err != nil {
+		h.log(r.Context()).Error("Failed to remove watcher from todo %s: %v", id, err)
+		writeTodoError(w, err, "Failed to remove watcher")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(todo)
+}
+
+// validateFilter проверяет параметры фильтра списка задач
+func validateFilter(filter domain.TodoFilter) error {
+	if filter.Status != "" && filter.Status != "all" && filter.Status != "active" &&
+		filter.Status != "completed" && filter.Status != "overdue" {
+		return fmt.Errorf("Invalid status parameter")
+	}
+
+	if filter.OrderDir != "" && filter.OrderDir != "asc" && filter.OrderDir != "desc" {
+		return fmt.Errorf("Invalid orderDir parameter")
+	}
+
+	// Валидация OrderBy
+	validOrderBy := map[string]bool{
+		"created_at":   true,
+		"deadline":     true,
+		"priority":     true,
+		"completed_at": true,
+		"position":     true,
+		"":             true, // пустое значение тоже валидно
+	}
+	if !validOrderBy[filter.OrderBy] {
+		return fmt.Errorf("Invalid orderBy parameter")
+	}
+
+	// Валидация списка ключей сортировки
+	if filter.Sort != "" {
+		if _, err := query.ParseSort(filter.Sort); err != nil {
+			return fmt.Errorf("Invalid sort parameter: %w", err)
+		}
+	}
+
+	// Валидация выражения фильтра
+	if filter.Query != "" {
+		if _, err := query.Parse(filter.Query); err != nil {
+			return fmt.Errorf("Invalid q parameter: %w", err)
+		}
+	}
+	return nil
+}
+
+// normalizeTags приводит теги к нижнему регистру и убирает пустые и повторяющиеся
+func normalizeTags(tags []string) []string {
+	result := []string{}
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return result
+}
+
+// log - логгер запроса с его X-Request-ID
+func (h *TodoHandler) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, h.logger)
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ToDo-List/internal/application/service"
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/ports"
+
+	"github.com/gorilla/mux"
+)
+
+// recordingTodos запоминает задачи, переданные сервису
+type recordingTodos struct {
+	ports.ToDoService
+	created  []domain.ToDo
+	upserted []domain.ToDo
+	moveErr  error
+	// createErr - ответ CreateTodo, например занятый id
+	createErr error
+	// existing - id задач, которые UpsertTodo заменяет, остальные создаются
+	existing map[string]bool
+}
+
+func (s *recordingTodos) CreateTodo(_ context.Context, todo domain.ToDo) (domain.ToDo, error) {
+	if s.createErr != nil {
+		return domain.ToDo{}, s.createErr
+	}
+	s.created = append(s.created, todo)
+	return todo, nil
+}
+
+func (s *recordingTodos) UpsertTodo(_ context.Context, todo domain.ToDo) (domain.ToDo, bool, error) {
+	s.upserted = append(s.upserted, todo)
+	return todo, !s.existing[todo.Id], nil
+}
+
+func (s *recordingTodos) MoveTodo(context.Context, string, string, string) (domain.ToDo, error) {
+	return domain.ToDo{}, s.moveErr
+}
+
+func TestTodoPositionFromClientIgnored(t *testing.T) {
+	todos := &recordingTodos{existing: map[string]bool{"6f1c1d1e-7a43-4a53-9a43-2f5a0e6a9b10": true}}
+	h := NewTodoHandler(todos, newTestLogger(t))
+
+	rec := httptest.NewRecorder()
+	h.CreateTodoHandler(rec, httptest.NewRequest(http.MethodPost, "/api/todo", strings.NewReader(`{"todo":"a","position":"zz0"}`)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST status = %d, want 201", rec.Code)
+	}
+
+	const id = "6f1c1d1e-7a43-4a53-9a43-2f5a0e6a9b10"
+	req := httptest.NewRequest(http.MethodPut, "/api/todo/"+id, strings.NewReader(`{"todo":"a","priority":"low","complete":false,"position":"!"}`))
+	rec = httptest.NewRecorder()
+	h.UpdateTodoByIdHandler(rec, mux.SetURLVars(req, map[string]string{"id": id}))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PUT status = %d, want 200", rec.Code)
+	}
+
+	if len(todos.created) != 1 || todos.created[0].Position != "" {
+		t.Errorf("created todos = %+v, want one without position", todos.created)
+	}
+	if len(todos.upserted) != 1 || todos.upserted[0].Position != "" {
+		t.Errorf("upserted todos = %+v, want one without position", todos.upserted)
+	}
+}
+
+func TestMoveTodoAcrossListsIsBadRequest(t *testing.T) {
+	h := NewTodoHandler(&recordingTodos{moveErr: service.ErrMoveAcrossLists}, newTestLogger(t))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/todo/a/move", strings.NewReader(`{"before":"b"}`))
+	rec := httptest.NewRecorder()
+	h.MoveTodoHandler(rec, mux.SetURLVars(req, map[string]string{"id": "a"}))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+}
+
+func TestUpdateTodoByIdStatus(t *testing.T) {
+	const existing, unknown = "6f1c1d1e-7a43-4a53-9a43-2f5a0e6a9b10", "0b7e6a1c-5d3f-4c2a-8e9b-1a2b3c4d5e6f"
+	h := NewTodoHandler(&recordingTodos{existing: map[string]bool{existing: true}}, newTestLogger(t))
+
+	tests := []struct {
+		name string
+		id   string
+		body string
+		want int
+	}{
+		{name: "replace", id: existing, body: `{"todo":"a","priority":"low","complete":true}`, want: http.StatusOK},
+		{name: "create", id: unknown, body: `{"todo":"a","priority":"low","complete":true}`, want: http.StatusCreated},
+		// замена целиком: без priority или complete задача потеряла бы состояние
+		{name: "missing complete", id: existing, body: `{"todo":"a","priority":"low"}`, want: http.StatusBadRequest},
+		{name: "missing priority", id: existing, body: `{"todo":"a","complete":false}`, want: http.StatusBadRequest},
+		{name: "missing todo", id: existing, body: `{"priority":"low","complete":false}`, want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/api/todo/"+tt.id, strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			h.UpdateTodoByIdHandler(rec, mux.SetURLVars(req, map[string]string{"id": tt.id}))
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateTodoWithClientIdErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "id taken", err: &ports.ConflictError{Resource: "todo", Id: "6f1c1d1e-7a43-4a53-9a43-2f5a0e6a9b10"}, want: http.StatusConflict},
+		{name: "invalid id", err: service.ErrInvalidTodoId, want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewTodoHandler(&recordingTodos{createErr: tt.err}, newTestLogger(t))
+			rec := httptest.NewRecorder()
+			h.CreateTodoHandler(rec, httptest.NewRequest(http.MethodPost, "/api/todo", strings.NewReader(`{"id":"6f1c1d1e-7a43-4a53-9a43-2f5a0e6a9b10","todo":"a"}`)))
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
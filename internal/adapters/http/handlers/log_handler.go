@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/ports"
+)
+
+// LogHandler меняет уровни логирования переданного логгера и всех его
+// дочерних логгеров без перезапуска
+type LogHandler struct {
+	auditService ports.AuditService
+	logger       *logger.Logger
+}
+
+func NewLogHandler(auditService ports.AuditService, logger *logger.Logger) *LogHandler {
+	return &LogHandler{
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// logLevels - общий уровень и уровни отдельных пакетов
+type logLevels struct {
+	Level    string            `json:"level"`
+	Packages map[string]string `json:"packages"`
+}
+
+// GetLevelHandler - GET /api/admin/log-level
+func (h *LogHandler) GetLevelHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.current())
+}
+
+// SetLevelHandler - PUT /api/admin/log-level. Пустой level оставляет общий
+// уровень; packages заменяет переопределения целиком, {} убирает их, отсутствие
+// поля оставляет как есть.
+func (h *LogHandler) SetLevelHandler(w http.ResponseWriter, r *http.Request) {
+	var req logLevels
+	if err := decodeJSON(w, r, &req); err != nil {
+		h.logger.Warn("Invalid request body: %v", err)
+		writeDecodeError(w, err)
+		return
+	}
+
+	level := h.logger.Level()
+	if req.Level != "" {
+		var ok bool
+		if level, ok = logger.LookupLevel(req.Level); !ok {
+			http.Error(w, fmt.Sprintf("Unknown level %q", req.Level), http.StatusBadRequest)
+			return
+		}
+	}
+	var packages map[string]logger.Level
+	if req.Packages != nil {
+		packages = make(map[string]logger.Level, len(req.Packages))
+		for pkg, levelStr := range req.Packages {
+			packageLevel, ok := logger.LookupLevel(levelStr)
+			if pkg == "" || !ok {
+				http.Error(w, fmt.Sprintf("Invalid level %q for package %q", levelStr, pkg), http.StatusBadRequest)
+				return
+			}
+			packages[pkg] = packageLevel
+		}
+	}
+
+	h.logger.SetLevel(level)
+	if packages != nil {
+		h.logger.SetPackageLevels(packages)
+	}
+	current := h.current()
+	h.logger.Info("Log level changed to %s, packages: %v", current.Level, current.Packages)
+	h.auditService.Record(r.Context(), domain.AuditLogLevelChanged, "system", "log_level", map[string]string{
+		"level":    current.Level,
+		"packages": formatPackageLevels(current.Packages),
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(current)
+}
+
+func (h *LogHandler) current() logLevels {
+	packages := make(map[string]string)
+	for pkg, level := range h.logger.PackageLevels() {
+		packages[pkg] = strings.ToLower(level.String())
+	}
+	return logLevels{
+		Level:    strings.ToLower(h.logger.Level().String()),
+		Packages: packages,
+	}
+}
+
+// formatPackageLevels - repo=debug,service=warn для журнала аудита
+func formatPackageLevels(packages map[string]string) string {
+	items := make([]string, 0, len(packages))
+	for pkg, level := range packages {
+		items = append(items, pkg+"="+level)
+	}
+	sort.Strings(items)
+	return strings.Join(items, ",")
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ToDo-List/internal/adapters/logger"
+)
+
+// recordingAudit запоминает действия журнала аудита
+type recordingAudit struct {
+	actions []string
+	details []map[string]string
+}
+
+func (a *recordingAudit) Record(_ context.Context, action, _, _ string, details map[string]string) {
+	a.actions = append(a.actions, action)
+	a.details = append(a.details, details)
+}
+
+func TestSetLogLevel(t *testing.T) {
+	appLogger := newTestLogger(t)
+	audit := &recordingAudit{}
+	h := NewLogHandler(audit, appLogger)
+
+	rec := httptest.NewRecorder()
+	h.SetLevelHandler(rec, httptest.NewRequest(http.MethodPut, "/api/admin/log-level", strings.NewReader(`{"level":"debug","packages":{"repo":"warn"}}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var got logLevels
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Level != "debug" || got.Packages["repo"] != "warn" {
+		t.Errorf("response = %+v", got)
+	}
+	if appLogger.Level() != logger.LevelDebug || appLogger.PackageLevels()["repo"] != logger.LevelWarn {
+		t.Errorf("logger level = %v, packages = %v", appLogger.Level(), appLogger.PackageLevels())
+	}
+	if len(audit.details) != 1 || audit.details[0]["level"] != "debug" || audit.details[0]["packages"] != "repo=warn" {
+		t.Errorf("audit = %v %v", audit.actions, audit.details)
+	}
+
+	// Без packages переопределения остаются, {} их убирает
+	rec = httptest.NewRecorder()
+	h.SetLevelHandler(rec, httptest.NewRequest(http.MethodPut, "/api/admin/log-level", strings.NewReader(`{"level":"warn"}`)))
+	if rec.Code != http.StatusOK || appLogger.Level() != logger.LevelWarn || len(appLogger.PackageLevels()) != 1 {
+		t.Errorf("level only: status %d, level %v, packages %v", rec.Code, appLogger.Level(), appLogger.PackageLevels())
+	}
+	rec = httptest.NewRecorder()
+	h.SetLevelHandler(rec, httptest.NewRequest(http.MethodPut, "/api/admin/log-level", strings.NewReader(`{"packages":{}}`)))
+	if rec.Code != http.StatusOK || appLogger.Level() != logger.LevelWarn || len(appLogger.PackageLevels()) != 0 {
+		t.Errorf("clear packages: status %d, level %v, packages %v", rec.Code, appLogger.Level(), appLogger.PackageLevels())
+	}
+}
+
+func TestSetLogLevelInvalid(t *testing.T) {
+	tests := map[string]string{
+		"unknown level":         `{"level":"verbose"}`,
+		"unknown package level": `{"packages":{"repo":"loud"}}`,
+		"empty package":         `{"packages":{"":"debug"}}`,
+		"malformed":             `{"level":`,
+	}
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			appLogger := newTestLogger(t)
+			audit := &recordingAudit{}
+			rec := httptest.NewRecorder()
+			NewLogHandler(audit, appLogger).SetLevelHandler(rec, httptest.NewRequest(http.MethodPut, "/api/admin/log-level", strings.NewReader(body)))
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", rec.Code)
+			}
+			// Ошибка в запросе не меняет ни один уровень
+			if appLogger.Level() != logger.LevelError || len(appLogger.PackageLevels()) != 0 || len(audit.actions) != 0 {
+				t.Errorf("level %v, packages %v, audit %v", appLogger.Level(), appLogger.PackageLevels(), audit.actions)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"ToDo-List/internal/core/ports"
+
+	"golang.org/x/oauth2"
+)
+
+// oidcFlowCookie хранит state, nonce и PKCE verifier между редиректом
+// к провайдеру и возвратом на callback
+const oidcFlowCookie = "oidc_flow"
+
+// oidcFlowTTL - сколько ждём возврата пользователя от провайдера
+const oidcFlowTTL = 10 * time.Minute
+
+type authMethodsResponse struct {
+	Password bool `json:"password"`
+	OIDC     bool `json:"oidc"`
+}
+
+// AuthMethodsHandler - GET /api/auth/methods
+func (h *AuthHandler) AuthMethodsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(authMethodsResponse{Password: true, OIDC: h.oidc != nil})
+}
+
+// OIDCLoginHandler - GET /api/auth/oidc/login, редирект на страницу входа провайдера
+func (h *AuthHandler) OIDCLoginHandler(w http.ResponseWriter, r *http.Request) {
+	h.log(r.Context()).Info("Received GET /api/auth/oidc/login request")
+
+	if h.oidc == nil {
+		http.Error(w, "SSO is not configured", http.StatusNotFound)
+		return
+	}
+
+	state := oauth2.GenerateVerifier()
+	nonce := oauth2.GenerateVerifier()
+	verifier := oauth2.GenerateVerifier()
+
+	url, err := h.oidc.AuthCodeURL(r.Context(), state, nonce, verifier)
+	if err != nil {
+		h.log(r.Context()).Error("Failed to start OIDC login: %v", err)
+		http.Error(w, "Identity provider is unavailable", http.StatusBadGateway)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcFlowCookie,
+		Value:    state + "." + nonce + "." + verifier,
+		Path:     "/api/auth/oidc",
+		MaxAge:   int(oidcFlowTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   h.secureCookie,
+		// Lax: cookie должна прийти вместе с редиректом от провайдера
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, url, http.StatusFound)
+}
+
+// OIDCCallbackHandler - GET /api/auth/oidc/callback
+func (h *AuthHandler) OIDCCallbackHandler(w http.ResponseWriter, r *http.Request) {
+	h.log(r.Context()).Info("Received GET /api/auth/oidc/callback request")
+
+	if h.oidc == nil {
+		http.Error(w, "SSO is not configured", http.StatusNotFound)
+		return
+	}
+
+	// Flow-cookie одноразовая
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcFlowCookie,
+		Value:    "",
+		Path:     "/api/auth/oidc",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   h.secureCookie,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	query := r.URL.Query()
+	if errCode := query.Get("error"); errCode != "" {
+		h.log(r.Context()).Warn("OIDC provider returned error: %s: %s", errCode, query.Get("error_description"))
+		http.Error(w, "Login was cancelled or denied", http.StatusUnauthorized)
+		return
+	}
+
+	cookie, err := r.Cookie(oidcFlowCookie)
+	if err != nil {
+		h.log(r.Context()).Warn("Missing OIDC flow cookie")
+		http.Error(w, "Login session expired, please try again", http.StatusBadRequest)
+		return
+	}
+	parts := strings.Split(cookie.Value, ".")
+	if len(parts) != 3 || query.Get("state") == "" || query.Get("state") != parts[0] {
+		h.log(r.Context()).Warn("OIDC state mismatch")
+		http.Error(w, "Invalid login state", http.StatusBadRequest)
+		return
+	}
+	nonce, verifier := parts[1], parts[2]
+
+	ident, err := h.oidc.Exchange(r.Context(), query.Get("code"), verifier, nonce)
+	if err != nil {
+		h.log(r.Context()).Warn("OIDC login failed: %v", err)
+		http.Error(w, "Login failed", http.StatusUnauthorized)
+		return
+	}
+
+	_, session, token, err := h.authService.LoginExternal(r.Context(), ident)
+	if err != nil {
+		if errors.Is(err, ports.ErrUserDisabled) {
+			http.Error(w, "Account is disabled", http.StatusForbidden)
+			return
+		}
+		h.log(r.Context()).Error("Failed to log in external user: %v", err)
+		http.Error(w, "Failed to log in", http.StatusInternalServerError)
+		return
+	}
+
+	h.setSessionCookies(w, token, session)
+	http.Redirect(w, r, "/", http.StatusFound)
+}
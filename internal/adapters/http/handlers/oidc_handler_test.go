@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/ports"
+)
+
+// stubIdP запоминает state, nonce и verifier входа и проверяет их при обмене кода
+type stubIdP struct {
+	state, nonce, verifier string
+	exchangeErr            error
+}
+
+func (p *stubIdP) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	p.state, p.nonce, p.verifier = state, nonce, codeVerifier
+	return "https://idp.example.com/authorize?state=" + url.QueryEscape(state), nil
+}
+
+func (p *stubIdP) Exchange(ctx context.Context, code, codeVerifier, nonce string) (ports.ExternalIdentity, error) {
+	if p.exchangeErr != nil {
+		return ports.ExternalIdentity{}, p.exchangeErr
+	}
+	if code != "code-1" || codeVerifier != p.verifier || nonce != p.nonce {
+		return ports.ExternalIdentity{}, errors.New("invalid_grant")
+	}
+	return ports.ExternalIdentity{Issuer: "https://idp.example.com", Subject: "subject-1", Username: "alice"}, nil
+}
+
+// externalAuth открывает сессию внешнему пользователю или возвращает err
+type externalAuth struct {
+	ports.AuthService
+	err    error
+	logins []ports.ExternalIdentity
+}
+
+func (a *externalAuth) LoginExternal(ctx context.Context, ident ports.ExternalIdentity) (domain.User, domain.Session, string, error) {
+	if a.err != nil {
+		return domain.User{}, domain.Session{}, "", a.err
+	}
+	a.logins = append(a.logins, ident)
+	return domain.User{Id: "u1"}, domain.Session{CSRFToken: "csrf-1", ExpiresAt: time.Now().Add(time.Hour)}, "session-1", nil
+}
+
+func responseCookie(rec *httptest.ResponseRecorder, name string) *http.Cookie {
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+	return nil
+}
+
+// startOIDCLogin проходит /oidc/login и возвращает flow-cookie
+func startOIDCLogin(t *testing.T, h *AuthHandler) *http.Cookie {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.OIDCLoginHandler(rec, httptest.NewRequest(http.MethodGet, "/api/auth/oidc/login", nil))
+	if rec.Code != http.StatusFound || !strings.HasPrefix(rec.Header().Get("Location"), "https://idp.example.com/authorize") {
+		t.Fatalf("login = %d, Location %q", rec.Code, rec.Header().Get("Location"))
+	}
+	flow := responseCookie(rec, oidcFlowCookie)
+	if flow == nil || !flow.HttpOnly || flow.SameSite != http.SameSiteLaxMode {
+		t.Fatalf("flow cookie = %+v", flow)
+	}
+	return flow
+}
+
+func callback(h *AuthHandler, query string, flow *http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/auth/oidc/callback?"+query, nil)
+	if flow != nil {
+		req.AddCookie(flow)
+	}
+	rec := httptest.NewRecorder()
+	h.OIDCCallbackHandler(rec, req)
+	return rec
+}
+
+func TestOIDCLogin(t *testing.T) {
+	idp := &stubIdP{}
+	auth := &externalAuth{}
+	h := NewAuthHandler(auth, idp, true, newTestLogger(t))
+	flow := startOIDCLogin(t, h)
+
+	// state, nonce и verifier разные и остаются только в HttpOnly cookie
+	if idp.state == "" || idp.state == idp.nonce || idp.nonce == idp.verifier {
+		t.Errorf("state %q, nonce %q, verifier %q", idp.state, idp.nonce, idp.verifier)
+	}
+	rec := callback(h, "code=code-1&state="+url.QueryEscape(idp.state), flow)
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "/" {
+		t.Fatalf("callback = %d, Location %q: %s", rec.Code, rec.Header().Get("Location"), rec.Body)
+	}
+	if session := responseCookie(rec, SessionCookie); session == nil || session.Value != "session-1" || !session.Secure {
+		t.Errorf("session cookie = %+v", session)
+	}
+	// Flow-cookie одноразовая
+	if cleared := responseCookie(rec, oidcFlowCookie); cleared == nil || cleared.MaxAge >= 0 {
+		t.Errorf("flow cookie not cleared: %+v", cleared)
+	}
+	if len(auth.logins) != 1 || auth.logins[0].Subject != "subject-1" {
+		t.Errorf("LoginExternal calls = %v", auth.logins)
+	}
+}
+
+func TestOIDCCallbackRejected(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       func(state string) string
+		noCookie    bool
+		exchangeErr error
+		loginErr    error
+		status      int
+	}{
+		{name: "state mismatch", query: func(string) string { return "code=code-1&state=forged" }, status: http.StatusBadRequest},
+		{name: "no state", query: func(string) string { return "code=code-1" }, status: http.StatusBadRequest},
+		{name: "no flow cookie", query: func(state string) string { return "code=code-1&state=" + state }, noCookie: true, status: http.StatusBadRequest},
+		{name: "provider error", query: func(string) string { return "error=access_denied" }, status: http.StatusUnauthorized},
+		{name: "exchange failed", query: func(state string) string { return "code=code-1&state=" + state }, exchangeErr: errors.New("invalid id token"), status: http.StatusUnauthorized},
+		{name: "disabled user", query: func(state string) string { return "code=code-1&state=" + state }, loginErr: ports.ErrUserDisabled, status: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := &stubIdP{exchangeErr: tt.exchangeErr}
+			auth := &externalAuth{err: tt.loginErr}
+			h := NewAuthHandler(auth, idp, false, newTestLogger(t))
+			flow := startOIDCLogin(t, h)
+			if tt.noCookie {
+				flow = nil
+			}
+
+			rec := callback(h, tt.query(url.QueryEscape(idp.state)), flow)
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if responseCookie(rec, SessionCookie) != nil {
+				t.Error("session cookie set")
+			}
+		})
+	}
+}
+
+func TestOIDCNotConfigured(t *testing.T) {
+	h := NewAuthHandler(&externalAuth{}, nil, false, newTestLogger(t))
+	for _, handler := range []http.HandlerFunc{h.OIDCLoginHandler, h.OIDCCallbackHandler} {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, "/api/auth/oidc/login", nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", rec.Code)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/application/service"
+	"ToDo-List/internal/core/ports"
+
+	"github.com/gorilla/mux"
+)
+
+type ShareHandler struct {
+	shareService ports.ShareService
+	logger       *logger.Logger
+}
+
+func NewShareHandler(shareService ports.ShareService, logger *logger.Logger) *ShareHandler {
+	return &ShareHandler{
+		shareService: shareService,
+		logger:       logger,
+	}
+}
+
+// CreateShareHandler - POST /api/shares
+func (h *ShareHandler) CreateShareHandler(w http.ResponseWriter, r *http.Request) {
+	h.log(r.Context()).Info("Received POST /api/shares request")
+
+	var req ports.ShareRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		h.log(r.Context()).Warn("Invalid request body: %v", err)
+		writeDecodeError(w, err)
+		return
+	}
+
+	share, err := h.shareService.Share(r.Context(), req)
+	if err != nil {
+		h.writeShareError(w, r, err, "Failed to share")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(share)
+}
+
+// GetSharesHandler - GET /api/shares?resourceType=todo&resourceId=...
+func (h *ShareHandler) GetSharesHandler(w http.ResponseWriter, r *http.Request) {
+	h.log(r.Context()).Info("Received GET /api/shares request")
+
+	q := r.URL.Query()
+	shares, err := h.shareService.GetShares(r.Context(), q.Get("resourceType"), q.Get("resourceId"))
+	if err != nil {
+		h.writeShareError(w, r, err, "Failed to get shares")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(shares)
+}
+
+// DeleteShareHandler - DELETE /api/shares/{id}
+func (h *ShareHandler) DeleteShareHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	h.log(r.Context()).Info("Received DELETE /api/shares/%s request", id)
+
+	if err := h.shareService.RevokeShare(r.Context(), id); err != nil {
+		h.writeShareError(w, r, err, "Failed to revoke share")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetSharedHandler - GET /api/shared, задачи других пользователей, открытые мне
+func (h *ShareHandler) GetSharedHandler(w http.ResponseWriter, r *http.Request) {
+	h.log(r.Context()).Info("Received GET /api/shared request")
+
+	items, err := h.shareService.GetSharedWithMe(r.Context())
+	if err != nil {
+		h.log(r.Context()).Error("Failed to get shared todos: %v", err)
+		http.Error(w, "Failed to get shared todos", http.StatusInternalServerError)
+		return
+	}
+
+	h.log(r.Context()).Info("Returning %d shared todos", len(items))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(items)
+}
+
+func (h *ShareHandler) writeShareError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidRole),
+		errors.Is(err, service.ErrInvalidResourceType),
+		errors.Is(err, service.ErrShareeRequired),
+		errors.Is(err, service.ErrShareWithSelf):
+		h.log(r.Context()).Warn("Invalid share request: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrShareeNotFound), errors.Is(err, service.ErrResourceNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ports.ErrForbidden):
+		http.Error(w, "Forbidden", http.StatusForbidden)
+	default:
+		h.log(r.Context()).Error("%s: %v", message, err)
+		http.Error(w, message, http.StatusInternalServerError)
+	}
+}
+
+// log - логгер запроса с его X-Request-ID
+func (h *ShareHandler) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, h.logger)
+}
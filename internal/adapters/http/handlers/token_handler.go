@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/application/service"
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/ports"
+
+	"github.com/gorilla/mux"
+)
+
+type TokenHandler struct {
+	tokenService ports.APITokenService
+	logger       *logger.Logger
+}
+
+func NewTokenHandler(tokenService ports.APITokenService, logger *logger.Logger) *TokenHandler {
+	return &TokenHandler{
+		tokenService: tokenService,
+		logger:       logger,
+	}
+}
+
+type createTokenRequest struct {
+	Name      string    `json:"name"`
+	Scopes    []string  `json:"scopes"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// createTokenResponse - открытое значение токена отдаётся только в ответе на создание
+type createTokenResponse struct {
+	domain.APIToken
+	Token string `json:"token"`
+}
+
+// CreateTokenHandler - POST /api/tokens
+func (h *TokenHandler) CreateTokenHandler(w http.ResponseWriter, r *http.Request) {
+	h.log(r.Context()).Info("Received POST /api/tokens request")
+
+	var req createTokenRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		h.log(r.Context()).Warn("Invalid request body: %v", err)
+		writeDecodeError(w, err)
+		return
+	}
+
+	token, plain, err := h.tokenService.CreateToken(r.Context(), req.Name, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidTokenName) ||
+			errors.Is(err, service.ErrInvalidScope) ||
+			errors.Is(err, service.ErrInvalidExpiry) {
+			h.log(r.Context()).Warn("Invalid token request: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.log(r.Context()).Error("Failed to create token: %v", err)
+		http.Error(w, "Failed to create token", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(createTokenResponse{APIToken: token, Token: plain})
+}
+
+// GetTokensHandler - GET /api/tokens
+func (h *TokenHandler) GetTokensHandler(w http.ResponseWriter, r *http.Request) {
+	h.log(r.Context()).Info("Received GET /api/tokens request")
+
+	tokens, err := h.tokenService.ListTokens(r.Context())
+	if err != nil {
+		h.log(r.Context()).Error("Failed to list tokens: %v", err)
+		http.Error(w, "Failed to list tokens", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tokens)
+}
+
+// RevokeTokenHandler - DELETE /api/tokens/{id}
+func (h *TokenHandler) RevokeTokenHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	h.log(r.Context()).Info("Received DELETE /api/tokens/%s request", id)
+
+	if err := h.tokenService.RevokeToken(r.Context(), id); err != nil {
+		h.log(r.Context()).Error("Failed to revoke token %s: %v", id, err)
+		http.Error(w, "Token not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// log - логгер запроса с его X-Request-ID
+func (h *TokenHandler) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, h.logger)
+}
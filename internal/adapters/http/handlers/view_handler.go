@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/ports"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type ViewHandler struct {
+	viewService ports.ViewService
+	logger      *logger.Logger
+}
+
+func NewViewHandler(viewService ports.ViewService, logger *logger.Logger) *ViewHandler {
+	return &ViewHandler{
+		viewService: viewService,
+		logger:      logger,
+	}
+}
+
+// GetViewsHandler - GET /api/views
+func (h *ViewHandler) GetViewsHandler(w http.ResponseWriter, r *http.Request) {
+	h.log(r.Context()).Info("Received GET /api/views request")
+
+	views, err := h.viewService.GetAllViews(r.Context())
+	if err != nil {
+		h.log(r.Context()).Error("Failed to get views: %v", err)
+		http.Error(w, "Failed to get views", http.StatusInternalServerError)
+		return
+	}
+
+	h.log(r.Context()).Info("Returning %d views", len(views))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(views)
+}
+
+// CreateViewHandler - POST /api/views
+func (h *ViewHandler) CreateViewHandler(w http.ResponseWriter, r *http.Request) {
+	h.log(r.Context()).Info("Received POST /api/views request")
+
+	var view domain.View
+	if err := decodeJSON(w, r, &view); err != nil {
+		h.log(r.Context()).Warn("Invalid request body: %v", err)
+		writeDecodeError(w, err)
+		return
+	}
+
+	view.Name = strings.TrimSpace(view.Name)
+	if view.Name == "" {
+		h.log(r.Context()).Warn("Missing 'name' field in request")
+		http.Error(w, "Missing 'name' field", http.StatusBadRequest)
+		return
+	}
+	if err := validateFilter(view.Filter); err != nil {
+		h.log(r.Context()).Warn("Invalid view filter: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	view.Id = uuid.NewString()
+	view.CreatedAt = time.Now()
+	view.UpdatedAt = time.Now()
+
+	createdView, err := h.viewService.CreateView(r.Context(), view)
+	if err != nil {
+		h.log(r.Context()).Error("Failed to create view: %v", err)
+		http.Error(w, "Failed to create view", http.StatusInternalServerError)
+		return
+	}
+
+	h.log(r.Context()).Info("View created successfully: %s", createdView.Id)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(createdView)
+}
+
+// GetViewByIdHandler - GET /api/views/{id}
+func (h *ViewHandler) GetViewByIdHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	h.log(r.Context()).Info("Received GET /api/views/%s request", id)
+
+	view, err := h.viewService.GetViewById(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, ports.ErrViewNotFound) {
+			h.log(r.Context()).Warn("View not found: %s", id)
+			http.Error(w, "View not found", http.StatusNotFound)
+			return
+		}
+		h.log(r.Context()).Error("Failed to get view %s: %v", id, err)
+		http.Error(w, "Failed to get view", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(view)
+}
+
+// UpdateViewHandler - PUT /api/views/{id}
+func (h *ViewHandler) UpdateViewHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	h.log(r.Context()).Info("Received PUT /api/views/%s request", id)
+
+	var view domain.View
+	if err := decodeJSON(w, r, &view); err != nil {
+		h.log(r.Context()).Warn("Invalid request body: %v", err)
+		writeDecodeError(w, err)
+		return
+	}
+
+	view.Name = strings.TrimSpace(view.Name)
+	if view.Name == "" {
+		h.log(r.Context()).Warn("Missing 'name' field in update request")
+		http.Error(w, "Missing 'name' field", http.StatusBadRequest)
+		return
+	}
+	if err := validateFilter(view.Filter); err != nil {
+		h.log(r.Context()).Warn("Invalid view filter: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	view.Id = id
+	if err := h.viewService.UpdateView(r.Context(), view); err != nil {
+		if errors.Is(err, ports.ErrViewNotFound) {
+			h.log(r.Context()).Warn("View not found for update: %s", id)
+			http.Error(w, "View not found", http.StatusNotFound)
+			return
+		}
+		h.log(r.Context()).Error("Failed to update view %s: %v", id, err)
+		http.Error(w, "Failed to update view", http.StatusInternalServerError)
+		return
+	}
+
+	h.log(r.Context()).Info("View updated successfully: %s", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteViewHandler - DELETE /api/views/{id}
+func (h *ViewHandler) DeleteViewHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	h.log(r.Context()).Info("Received DELETE /api/views/%s request", id)
+
+	if err := h.viewService.DeleteView(r.Context(), id); err != nil {
+		if errors.Is(err, ports.ErrViewNotFound) {
+			h.log(r.Context()).Warn("View not found for deletion: %s", id)
+			http.Error(w, "View not found", http.StatusNotFound)
+			return
+		}
+		h.log(r.Context()).Error("Failed to delete view %s: %v", id, err)
+		http.Error(w, "Failed to delete view", http.StatusInternalServerError)
+		return
+	}
+
+	h.log(r.Context()).Info("View deleted successfully: %s", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetViewTodosHandler - GET /api/views/{id}/todos
+func (h *ViewHandler) GetViewTodosHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	h.log(r.Context()).Info("Received GET /api/views/%s/todos request", id)
+
+	todos, err := h.viewService.GetViewTodos(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, ports.ErrViewNotFound) {
+			h.log(r.Context()).Warn("View not found: %s", id)
+			http.Error(w, "View not found", http.StatusNotFound)
+			return
+		}
+		h.log(r.Context()).Error("Failed to get todos of view %s: %v", id, err)
+		http.Error(w, "Failed to get todos", http.StatusInternalServerError)
+		return
+	}
+
+	h.log(r.Context()).Info("Returning %d todos for view %s", len(todos), id)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(todos)
+}
+
+// GetViewCountsHandler - GET /api/views/counts
+func (h *ViewHandler) GetViewCountsHandler(w http.ResponseWriter, r *http.Request) {
+	h.log(r.Context()).Info("Received GET /api/views/counts request")
+
+	counts, err := h.viewService.GetViewCounts(r.Context())
+	if err != nil {
+		h.log(r.Context()).Error("Failed to count view todos: %v", err)
+		http.Error(w, "Failed to count view todos", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(counts)
+}
+
+// log - логгер запроса с его X-Request-ID
+func (h *ViewHandler) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, h.logger)
+}
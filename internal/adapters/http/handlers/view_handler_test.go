@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/ports"
+
+	"github.com/gorilla/mux"
+)
+
+func newTestLogger(t *testing.T) *logger.Logger {
+	t.Helper()
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { devNull.Close() })
+	appLogger, err := logger.New(logger.Config{Level: logger.LevelError, Output: devNull})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return appLogger
+}
+
+// missingViews - сервис, в котором нет ни одного фильтра
+type missingViews struct {
+	ports.ViewService
+}
+
+func (missingViews) GetViewById(context.Context, string) (domain.View, error) {
+	return domain.View{}, ports.ErrViewNotFound
+}
+
+func (missingViews) UpdateView(context.Context, domain.View) error {
+	return ports.ErrViewNotFound
+}
+
+func (missingViews) DeleteView(context.Context, string) error {
+	return ports.ErrViewNotFound
+}
+
+func (missingViews) GetViewTodos(context.Context, string) ([]domain.ToDo, error) {
+	return nil, ports.ErrViewNotFound
+}
+
+func TestViewHandlersNotFound(t *testing.T) {
+	h := NewViewHandler(missingViews{}, newTestLogger(t))
+	tests := []struct {
+		name    string
+		method  string
+		body    string
+		handler http.HandlerFunc
+	}{
+		{name: "get", method: http.MethodGet, handler: h.GetViewByIdHandler},
+		{name: "update", method: http.MethodPut, body: `{"name":"Today","filter":{"period":"today"}}`, handler: h.UpdateViewHandler},
+		{name: "delete", method: http.MethodDelete, handler: h.DeleteViewHandler},
+		{name: "todos", method: http.MethodGet, handler: h.GetViewTodosHandler},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := mux.SetURLVars(httptest.NewRequest(tt.method, "/api/views/missing", strings.NewReader(tt.body)), map[string]string{"id": "missing"})
+			rec := httptest.NewRecorder()
+			tt.handler(rec, req)
+			if rec.Code != http.StatusNotFound {
+				t.Errorf("status = %d, want 404", rec.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/application/service"
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/identity"
+	"ToDo-List/internal/core/ports"
+
+	"github.com/gorilla/mux"
+)
+
+type WorkspaceHandler struct {
+	workspaceService ports.WorkspaceService
+	logger           *logger.Logger
+}
+
+func NewWorkspaceHandler(workspaceService ports.WorkspaceService, logger *logger.Logger) *WorkspaceHandler {
+	return &WorkspaceHandler{
+		workspaceService: workspaceService,
+		logger:           logger,
+	}
+}
+
+// GetWorkspacesHandler - GET /api/workspaces, пространства пользователя
+func (h *WorkspaceHandler) GetWorkspacesHandler(w http.ResponseWriter, r *http.Request) {
+	h.log(r.Context()).Info("Received GET /api/workspaces request")
+
+	workspaces, err := h.workspaceService.ListWorkspaces(r.Context())
+	if err != nil {
+		h.writeWorkspaceError(w, r, err, "Failed to get workspaces")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(workspaces)
+}
+
+// CreateWorkspaceHandler - POST /api/workspaces {"slug", "name"}
+func (h *WorkspaceHandler) CreateWorkspaceHandler(w http.ResponseWriter, r *http.Request) {
+	h.log(r.Context()).Info("Received POST /api/workspaces request")
+
+	var req struct {
+		Slug string `json:"slug"`
+		Name string `json:"name"`
+	}
+	if err := decodeJSON(w, r, &req); err != nil {
+		h.log(r.Context()).Warn("Invalid request body: %v", err)
+		writeDecodeError(w, err)
+		return
+	}
+
+	workspace, err := h.workspaceService.CreateWorkspace(r.Context(), req.Slug, req.Name)
+	if err != nil {
+		h.writeWorkspaceError(w, r, err, "Failed to create workspace")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(workspace)
+}
+
+// GetCurrentWorkspaceHandler - GET /api/workspace, пространство запроса и роль в нём
+func (h *WorkspaceHandler) GetCurrentWorkspaceHandler(w http.ResponseWriter, r *http.Request) {
+	workspace, role, ok := identity.WorkspaceFromContext(r.Context())
+	if !ok {
+		http.Error(w, "No workspace selected", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ports.UserWorkspace{Workspace: workspace, Role: role})
+}
+
+// UpdateSettingsHandler - PUT /api/workspace/settings
+func (h *WorkspaceHandler) UpdateSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	h.log(r.Context()).Info("Received PUT /api/workspace/settings request")
+
+	var settings domain.WorkspaceSettings
+	if err := decodeJSON(w, r, &settings); err != nil {
+		h.log(r.Context()).Warn("Invalid request body: %v", err)
+		writeDecodeError(w, err)
+		return
+	}
+
+	workspace, err := h.workspaceService.UpdateSettings(r.Context(), settings)
+	if err != nil {
+		h.writeWorkspaceError(w, r, err, "Failed to update settings")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(workspace)
+}
+
+// GetMembersHandler - GET /api/workspace/members
+func (h *WorkspaceHandler) GetMembersHandler(w http.ResponseWriter, r *http.Request) {
+	h.log(r.Context()).Info("Received GET /api/workspace/members request")
+
+	members, err := h.workspaceService.ListMembers(r.Context())
+	if err != nil {
+		h.writeWorkspaceError(w, r, err, "Failed to get members")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(members)
+}
+
+// AddMemberHandler - POST /api/workspace/members {"username" | "email", "role"}
+func (h *WorkspaceHandler) AddMemberHandler(w http.ResponseWriter, r *http.Request) {
+	h.log(r.Context()).Info("Received POST /api/workspace/members request")
+
+	var req struct {
+		Username string `json:"username"`
+		Email    string `json:"email"`
+		Role     string `json:"role"`
+	}
+	if err := decodeJSON(w, r, &req); err != nil {
+		h.log(r.Context()).Warn("Invalid request body: %v", err)
+		writeDecodeError(w, err)
+		return
+	}
+
+	member, err := h.workspaceService.AddMember(r.Context(), req.Username, req.Email, req.Role)
+	if err != nil {
+		h.writeWorkspaceError(w, r, err, "Failed to add member")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(member)
+}
+
+// RemoveMemberHandler - DELETE /api/workspace/members/{userId}
+func (h *WorkspaceHandler) RemoveMemberHandler(w http.ResponseWriter, r *http.Request) {
+	userId := mux.Vars(r)["userId"]
+	h.log(r.Context()).Info("Received DELETE /api/workspace/members/%s request", userId)
+
+	if err := h.workspaceService.RemoveMember(r.Context(), userId); err != nil {
+		h.writeWorkspaceError(w, r, err, "Failed to remove member")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *WorkspaceHandler) writeWorkspaceError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidSlug),
+		errors.Is(err, service.ErrInvalidWorkspaceName),
+		errors.Is(err, service.ErrInvalidMemberRole),
+		errors.Is(err, service.ErrInvalidSettings),
+		errors.Is(err, service.ErrShareeRequired),
+		errors.Is(err, service.ErrLastOwner):
+		h.log(r.Context()).Warn("Invalid workspace request: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ports.ErrWorkspaceExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, service.ErrMemberNotFound), errors.Is(err, ports.ErrNotMember):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ports.ErrForbidden):
+		http.Error(w, "Forbidden", http.StatusForbidden)
+	default:
+		h.log(r.Context()).Error("%s: %v", message, err)
+		http.Error(w, message, http.StatusInternalServerError)
+	}
+}
+
+// log - логгер запроса с его X-Request-ID
+func (h *WorkspaceHandler) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, h.logger)
+}
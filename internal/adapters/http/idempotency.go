@@ -0,0 +1,158 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+
+	"ToDo-List/internal/adapters/http/handlers"
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/identity"
+	"ToDo-List/internal/core/ports"
+)
+
+const (
+	// IdempotencyKeyHeader - ключ, по которому повтор запроса получает прежний ответ
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader отмечает ответ, взятый из сохранённых
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+	// maxStoredResponseSize - ответы больше этого не сохраняются, ключ освобождается
+	maxStoredResponseSize = 1 << 20
+)
+
+// replayedHeaders - заголовки ответа, которые сохраняются вместе с телом
+var replayedHeaders = []string{"Content-Type", "Location", "ETag"}
+
+// idempotent выполняет запрос с заголовком Idempotency-Key не больше одного раза:
+// повтор с тем же ключом и телом получает сохранённый ответ, с другим телом - 422,
+// во время выполнения первого запроса - 409. Ответы 5xx не сохраняются, такой
+// запрос можно повторить. Если процесс упал, не сохранив ответ, ключ
+// освобождается после истечения блокировки. Без заголовка запрос выполняется
+// как обычно.
+func idempotent(idempotencyService ports.IdempotencyService, appLogger *logger.Logger, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" {
+			next(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength || !printableASCII(key) {
+			http.Error(w, "Invalid Idempotency-Key: expected up to 255 printable ASCII characters", http.StatusBadRequest)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, handlers.MaxJSONBodySize))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, "Failed to read request body", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := r.Context()
+		record, replay, err := idempotencyService.Begin(ctx, key, requestFingerprint(r, body))
+		if err != nil {
+			switch {
+			case errors.Is(err, ports.ErrIdempotencyKeyReused):
+				http.Error(w, "Idempotency-Key was already used with a different request", http.StatusUnprocessableEntity)
+			case errors.Is(err, ports.ErrIdempotencyInProgress):
+				w.Header().Set("Retry-After", "1")
+				http.Error(w, "A request with this Idempotency-Key is in progress", http.StatusConflict)
+			default:
+				appLogger.Error("Failed to reserve idempotency key: %v", err)
+				http.Error(w, "Failed to process request", http.StatusInternalServerError)
+			}
+			return
+		}
+		if replay {
+			for name, value := range record.Headers {
+				w.Header().Set(name, value)
+			}
+			w.Header().Set(IdempotentReplayedHeader, "true")
+			w.WriteHeader(record.StatusCode)
+			w.Write(record.Body)
+			return
+		}
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		completed := false
+		defer func() {
+			// Паника или ошибка сервера: ключ освобождается для повтора
+			if !completed {
+				if err := idempotencyService.Abort(context.WithoutCancel(ctx), record); err != nil {
+					appLogger.Error("Failed to release idempotency key: %v", err)
+				}
+			}
+		}()
+		next(rec, r)
+
+		if rec.status >= http.StatusInternalServerError || rec.overflow {
+			return
+		}
+		record.StatusCode = rec.status
+		record.Headers = map[string]string{}
+		for _, name := range replayedHeaders {
+			if value := rec.Header().Get(name); value != "" {
+				record.Headers[name] = value
+			}
+		}
+		record.Body = rec.body.Bytes()
+		if err := idempotencyService.Complete(context.WithoutCancel(ctx), record); err != nil {
+			appLogger.Error("Failed to store idempotent response: %v", err)
+			return
+		}
+		completed = true
+	}
+}
+
+// requestFingerprint - отпечаток запроса: метод, путь, рабочее пространство и тело
+func requestFingerprint(r *http.Request, body []byte) string {
+	workspaceId, _ := identity.WorkspaceId(r.Context())
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.Path+"\n"+workspaceId+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func printableASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// responseRecorder передаёт ответ клиенту и запоминает его для повторов
+type responseRecorder struct {
+	http.ResponseWriter
+	status   int
+	body     bytes.Buffer
+	overflow bool
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if !r.overflow {
+		if r.body.Len()+len(b) > maxStoredResponseSize {
+			r.overflow = true
+			r.body.Reset()
+		} else {
+			r.body.Write(b)
+		}
+	}
+	return r.ResponseWriter.Write(b)
+}
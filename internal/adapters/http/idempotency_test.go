@@ -0,0 +1,157 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ToDo-List/internal/application/service"
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/identity"
+	"ToDo-List/internal/core/ports"
+)
+
+// memIdempotency хранит ключи в памяти по правилам PostgreIdempotencyRepo
+type memIdempotency struct {
+	records map[string]domain.IdempotencyRecord
+}
+
+func (r *memIdempotency) ReserveIdempotencyKey(ctx context.Context, record domain.IdempotencyRecord) (domain.IdempotencyRecord, bool, error) {
+	id := record.UserId + "/" + record.Key
+	if current, ok := r.records[id]; ok && current.ExpiresAt.After(record.CreatedAt) &&
+		(current.StatusCode != 0 || current.LockedUntil.After(record.CreatedAt)) {
+		return current, false, nil
+	}
+	r.records[id] = record
+	return record, true, nil
+}
+
+func (r *memIdempotency) SaveIdempotentResponse(ctx context.Context, record domain.IdempotencyRecord) error {
+	id := record.UserId + "/" + record.Key
+	if current, ok := r.records[id]; !ok || !current.LockedUntil.Equal(record.LockedUntil) {
+		return ports.ErrIdempotencyLeaseLost
+	}
+	r.records[id] = record
+	return nil
+}
+
+func (r *memIdempotency) DeleteIdempotencyKey(ctx context.Context, record domain.IdempotencyRecord) error {
+	id := record.UserId + "/" + record.Key
+	if current, ok := r.records[id]; ok && current.LockedUntil.Equal(record.LockedUntil) {
+		delete(r.records, id)
+	}
+	return nil
+}
+
+func (r *memIdempotency) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error) {
+	return 0, nil
+}
+
+// idempotencyFixture - обработчик создания задачи за middleware idempotent
+type idempotencyFixture struct {
+	repo    *memIdempotency
+	handler http.HandlerFunc
+	calls   int
+	status  int
+}
+
+func newIdempotencyFixture(t *testing.T) *idempotencyFixture {
+	f := &idempotencyFixture{repo: &memIdempotency{records: map[string]domain.IdempotencyRecord{}}, status: http.StatusCreated}
+	idempotencyService := service.NewIdempotencyService(f.repo, time.Hour, newTestLogger(t))
+	f.handler = idempotent(idempotencyService, newTestLogger(t), func(w http.ResponseWriter, r *http.Request) {
+		f.calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", fmt.Sprintf("/api/todo/%d", f.calls))
+		w.WriteHeader(f.status)
+		fmt.Fprintf(w, `{"call":%d}`, f.calls)
+	})
+	return f
+}
+
+func (f *idempotencyFixture) post(user, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/todo", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	ctx := identity.WithUser(req.Context(), domain.User{Id: user})
+	ctx = identity.WithWorkspace(ctx, domain.Workspace{Id: "w1"}, domain.WorkspaceRoleMember)
+	rec := httptest.NewRecorder()
+	f.handler(rec, req.WithContext(ctx))
+	return rec
+}
+
+func TestIdempotentReplay(t *testing.T) {
+	f := newIdempotencyFixture(t)
+
+	first := f.post("alice", "k1", `{"todo":"a"}`)
+	second := f.post("alice", "k1", `{"todo":"a"}`)
+	if f.calls != 1 {
+		t.Fatalf("handler called %d times, want 1", f.calls)
+	}
+	if second.Code != first.Code || second.Body.String() != first.Body.String() {
+		t.Errorf("replay = %d %s, want %d %s", second.Code, second.Body, first.Code, first.Body)
+	}
+	if second.Header().Get("Location") != "/api/todo/1" || second.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Errorf("replay headers = %v", second.Header())
+	}
+	if first.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Error("first response marked as replayed")
+	}
+
+	// Тот же ключ с другим телом - ошибка клиента, а не новый запрос
+	if rec := f.post("alice", "k1", `{"todo":"b"}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("reused key status = %d, want 422", rec.Code)
+	}
+	// Ключи разных пользователей не пересекаются
+	if rec := f.post("bob", "k1", `{"todo":"a"}`); rec.Code != http.StatusCreated || f.calls != 2 {
+		t.Errorf("other user's key: status %d, %d calls; want a new request", rec.Code, f.calls)
+	}
+	// Без ключа запрос выполняется каждый раз
+	f.post("alice", "", `{"todo":"a"}`)
+	f.post("alice", "", `{"todo":"a"}`)
+	if f.calls != 4 {
+		t.Errorf("handler called %d times, want 4", f.calls)
+	}
+}
+
+func TestIdempotentServerErrorReleasesKey(t *testing.T) {
+	f := newIdempotencyFixture(t)
+
+	f.status = http.StatusInternalServerError
+	if rec := f.post("alice", "k1", `{}`); rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", rec.Code)
+	}
+	f.status = http.StatusCreated
+	if rec := f.post("alice", "k1", `{}`); rec.Code != http.StatusCreated || f.calls != 2 {
+		t.Errorf("retry after 500: status %d, %d calls; want the request run again", rec.Code, f.calls)
+	}
+}
+
+func TestIdempotentInProgressAndInvalidKey(t *testing.T) {
+	f := newIdempotencyFixture(t)
+
+	// Первый запрос с тем же телом ещё выполняется: ключ занят без ответа
+	req := httptest.NewRequest(http.MethodPost, "/api/todo", nil)
+	req = req.WithContext(identity.WithWorkspace(req.Context(), domain.Workspace{Id: "w1"}, domain.WorkspaceRoleMember))
+	now := time.Now()
+	f.repo.records["alice/k1"] = domain.IdempotencyRecord{
+		Key: "k1", UserId: "alice", Fingerprint: requestFingerprint(req, []byte(`{}`)),
+		CreatedAt: now, ExpiresAt: now.Add(time.Hour), LockedUntil: now.Add(time.Minute),
+	}
+	if rec := f.post("alice", "k1", `{}`); rec.Code != http.StatusConflict || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("in progress: status %d, Retry-After %q; want 409 with Retry-After", rec.Code, rec.Header().Get("Retry-After"))
+	}
+
+	for _, key := range []string{strings.Repeat("k", 256), "key\nwith newline"} {
+		if rec := f.post("alice", key, `{}`); rec.Code != http.StatusBadRequest {
+			t.Errorf("key %q: status = %d, want 400", key, rec.Code)
+		}
+	}
+	if f.calls != 0 {
+		t.Errorf("handler called %d times, want 0", f.calls)
+	}
+}
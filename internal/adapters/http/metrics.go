@@ -0,0 +1,27 @@
+package http
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/adapters/metrics"
+)
+
+// metricsHandler отдаёт метрики Prometheus. Если задан token, сборщик должен
+// передать его в Authorization: Bearer (bearer_token в scrape_config).
+func metricsHandler(m *metrics.Metrics, token string, appLogger *logger.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if token != "" {
+			plain, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !found || subtle.ConstantTimeCompare([]byte(plain), []byte(token)) != 1 {
+				appLogger.Warn("Rejected metrics request from %s", r.RemoteAddr)
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		m.ServeHTTP(w, r)
+	}
+}
@@ -0,0 +1,265 @@
+package http
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"ToDo-List/internal/adapters/http/handlers"
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/identity"
+	"ToDo-List/internal/core/policy"
+	"ToDo-List/internal/core/ports"
+)
+
+// publicPaths - маршруты /api, доступные без сессии
+var publicPaths = map[string]bool{
+	"/api/auth/register":      true,
+	"/api/auth/login":         true,
+	"/api/auth/methods":       true,
+	"/api/auth/oidc/login":    true,
+	"/api/auth/oidc/callback": true,
+}
+
+// sessionOnlyPrefixes - управлять токенами и пользователями можно только из
+// браузерной сессии, чтобы утёкший токен не мог выпустить себе замену
+var sessionOnlyPrefixes = []string{"/api/tokens", "/api/admin/"}
+
+// adminPrefix - маршруты администрирования, работают вне рабочих пространств
+const adminPrefix = "/api/admin/"
+
+func sessionOnly(path string) bool {
+	for _, prefix := range sessionOnlyPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// authMiddleware пропускает запрос с токеном Authorization: Bearer (проверяя его
+// области доступа) или с cookie сессии (проверяя CSRF-токен для изменяющих
+// запросов) и кладёт пользователя в контекст запроса. Изменяющие запросы в
+// сессии администратора от имени пользователя пишутся в журнал аудита.
+func authMiddleware(authService ports.AuthService, tokenService ports.APITokenService, auditService ports.AuditService, appLogger *logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if publicPaths[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if header := r.Header.Get("Authorization"); header != "" {
+				user, token, ok := authenticateBearer(w, r, header, tokenService, appLogger)
+				if !ok {
+					return
+				}
+				if !checkWritePermission(w, r, user, appLogger) {
+					return
+				}
+				ctx := identity.WithUser(withRequestUser(r.Context(), user.Id, appLogger), user)
+				ctx = context.WithValue(ctx, tokenWorkspaceKey{}, token.WorkspaceId)
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
+			cookie, err := r.Cookie(handlers.SessionCookie)
+			if err != nil {
+				appLogger.Debug("No session cookie for %s %s", r.Method, r.URL.Path)
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			user, session, err := authService.Authenticate(r.Context(), cookie.Value)
+			if err != nil {
+				appLogger.Debug("Authentication failed for %s %s: %v", r.Method, r.URL.Path, err)
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			if !isSafeMethod(r.Method) {
+				token := r.Header.Get(handlers.CSRFHeader)
+				if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(session.CSRFToken)) != 1 {
+					appLogger.Warn("CSRF check failed for user %s: %s %s", user.Id, r.Method, r.URL.Path)
+					http.Error(w, "Invalid CSRF token", http.StatusForbidden)
+					return
+				}
+			}
+			if !checkWritePermission(w, r, user, appLogger) {
+				return
+			}
+
+			ctx := identity.WithUser(withRequestUser(r.Context(), user.Id, appLogger), user)
+			if session.ImpersonatorId != "" {
+				// От чужого имени нельзя выпускать токены и администрировать
+				if sessionOnly(r.URL.Path) {
+					http.Error(w, "Not available while impersonating", http.StatusForbidden)
+					return
+				}
+				ctx = identity.WithImpersonator(ctx, session.ImpersonatorId)
+				if !isSafeMethod(r.Method) {
+					auditService.Record(ctx, domain.AuditImpersonatedRequest, "user", user.Id, map[string]string{
+						"method": r.Method,
+						"path":   r.URL.Path,
+					})
+				}
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// authenticateBearer проверяет персональный токен. CSRF для него не нужен:
+// браузер не подставляет заголовок Authorization сам.
+func authenticateBearer(w http.ResponseWriter, r *http.Request, header string, tokenService ports.APITokenService, appLogger *logger.Logger) (domain.User, domain.APIToken, bool) {
+	plain, found := strings.CutPrefix(header, "Bearer ")
+	if !found {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "Unsupported authorization scheme", http.StatusUnauthorized)
+		return domain.User{}, domain.APIToken{}, false
+	}
+
+	user, token, err := tokenService.Authenticate(r.Context(), strings.TrimSpace(plain))
+	if err != nil {
+		appLogger.Debug("Token authentication failed for %s %s: %v", r.Method, r.URL.Path, err)
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return domain.User{}, domain.APIToken{}, false
+	}
+
+	if sessionOnly(r.URL.Path) {
+		http.Error(w, "This endpoint requires a browser session", http.StatusForbidden)
+		return domain.User{}, domain.APIToken{}, false
+	}
+
+	scope := domain.ScopeTodosWrite
+	if isSafeMethod(r.Method) {
+		scope = domain.ScopeTodosRead
+	}
+	if !token.HasScope(scope) {
+		appLogger.Warn("Token %s lacks scope %s for %s %s", token.Id, scope, r.Method, r.URL.Path)
+		w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope", scope="`+scope+`"`)
+		http.Error(w, "Insufficient token scope: "+scope, http.StatusForbidden)
+		return domain.User{}, domain.APIToken{}, false
+	}
+	return user, token, true
+}
+
+// checkWritePermission отклоняет изменяющие запросы пользователя без права
+// todos:write (роль readonly). Выйти из сессии можно всегда.
+func checkWritePermission(w http.ResponseWriter, r *http.Request, user domain.User, appLogger *logger.Logger) bool {
+	if isSafeMethod(r.Method) || strings.HasPrefix(r.URL.Path, "/api/auth/") {
+		return true
+	}
+	if !policy.Allows(user.Role, policy.TodosWrite) {
+		appLogger.Warn("User %s with role %s denied %s %s", user.Id, user.Role, r.Method, r.URL.Path)
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// requirePermission пропускает к обработчику только пользователей с правом perm
+func requirePermission(perm policy.Permission, appLogger *logger.Logger, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := policy.Check(r.Context(), perm); err != nil {
+			userId, _ := identity.UserId(r.Context())
+			appLogger.Warn("User %s lacks permission %s for %s %s", userId, perm, r.Method, r.URL.Path)
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// WorkspaceHeader - заголовок с slug или id рабочего пространства запроса
+const WorkspaceHeader = "X-Workspace"
+
+// tokenWorkspaceKey - рабочее пространство, к которому привязан токен запроса
+type tokenWorkspaceKey struct{}
+
+// workspaceExempt - маршруты, которым не нужно рабочее пространство: вход,
+// профиль, список пространств пользователя и администрирование
+func workspaceExempt(path string) bool {
+	return publicPaths[path] || strings.HasPrefix(path, "/api/auth/") || path == "/api/workspaces" ||
+		strings.HasPrefix(path, adminPrefix)
+}
+
+// workspaceMiddleware выбирает рабочее пространство запроса и кладёт его в
+// контекст вместе с ролью пользователя. Порядок: пространство токена, заголовок
+// X-Workspace, поддомен <slug>.<baseDomain>, пространство по умолчанию.
+// Запрос в пространство, где пользователь не участник, отклоняется.
+func workspaceMiddleware(workspaceService ports.WorkspaceService, baseDomain string, appLogger *logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if workspaceExempt(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			userId, err := identity.UserId(r.Context())
+			if err != nil {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			requested := strings.TrimSpace(r.Header.Get(WorkspaceHeader))
+			if requested == "" {
+				requested = subdomainWorkspace(r.Host, baseDomain)
+			}
+			ref := requested
+			if bound, _ := r.Context().Value(tokenWorkspaceKey{}).(string); bound != "" {
+				ref = bound
+			}
+
+			workspace, role, err := workspaceService.Resolve(r.Context(), userId, ref)
+			if err != nil {
+				switch {
+				case errors.Is(err, ports.ErrWorkspaceNotFound):
+					http.Error(w, "Workspace not found", http.StatusNotFound)
+				case errors.Is(err, ports.ErrNotMember):
+					appLogger.Warn("User %s is not a member of workspace %q", userId, ref)
+					http.Error(w, "Not a member of this workspace", http.StatusForbidden)
+				default:
+					appLogger.Error("Failed to resolve workspace %q for user %s: %v", ref, userId, err)
+					http.Error(w, "Failed to resolve workspace", http.StatusInternalServerError)
+				}
+				return
+			}
+			// Токен одного пространства не открывает другое
+			if requested != "" && ref != requested && !strings.EqualFold(requested, workspace.Slug) && requested != workspace.Id {
+				appLogger.Warn("Token of workspace %s used for workspace %q", workspace.Id, requested)
+				http.Error(w, "Token is not valid for this workspace", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(identity.WithWorkspace(r.Context(), workspace, role)))
+		})
+	}
+}
+
+// subdomainWorkspace возвращает slug из Host вида <slug>.<baseDomain>
+func subdomainWorkspace(host, baseDomain string) string {
+	if baseDomain == "" {
+		return ""
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	slug, found := strings.CutSuffix(strings.ToLower(host), "."+strings.ToLower(baseDomain))
+	if !found || strings.Contains(slug, ".") {
+		return ""
+	}
+	return slug
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
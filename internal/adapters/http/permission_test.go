@@ -0,0 +1,39 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/identity"
+	"ToDo-List/internal/core/policy"
+)
+
+func TestRequirePermission(t *testing.T) {
+	tests := []struct {
+		role string
+		want int
+	}{
+		{role: domain.UserRoleAdmin, want: http.StatusNoContent},
+		{role: domain.UserRoleUser, want: http.StatusForbidden},
+		{role: domain.UserRoleReadOnly, want: http.StatusForbidden},
+		// без пользователя в контексте
+		{role: "", want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		handler := requirePermission(policy.LogsManage, newTestLogger(t), func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})
+		ctx := context.Background()
+		if tt.role != "" {
+			ctx = identity.WithUser(ctx, domain.User{Id: "u1", Role: tt.role})
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodPut, "/api/admin/log-level", nil).WithContext(ctx))
+		if rec.Code != tt.want {
+			t.Errorf("role %q: status = %d, want %d", tt.role, rec.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ToDo-List/internal/adapters/logger"
+)
+
+// RateLimit - Requests запросов за Per; столько же допускается всплеском.
+// Нулевое значение отключает ограничение.
+type RateLimit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// RateLimits - ограничения для классов маршрутов: чтение (GET, HEAD, OPTIONS)
+// и изменение (остальные методы)
+type RateLimits struct {
+	Read  RateLimit
+	Write RateLimit
+	// TrustProxy - брать адрес клиента из X-Forwarded-For (последний адрес,
+	// добавленный прокси); включать только за своим обратным прокси
+	TrustProxy bool
+}
+
+// DefaultRateLimits - ограничения, если не заданы в конфигурации
+var DefaultRateLimits = RateLimits{
+	Read:  RateLimit{Requests: 600, Per: time.Minute},
+	Write: RateLimit{Requests: 120, Per: time.Minute},
+}
+
+// ParseRateLimit разбирает ограничение вида "120/1m"; "off" или "0" отключает его
+func ParseRateLimit(value string) (RateLimit, error) {
+	value = strings.TrimSpace(value)
+	if value == "off" || value == "0" {
+		return RateLimit{}, nil
+	}
+	count, period, found := strings.Cut(value, "/")
+	if !found {
+		return RateLimit{}, fmt.Errorf("invalid rate limit %q: expected <requests>/<duration>", value)
+	}
+	requests, err := strconv.Atoi(count)
+	if err != nil || requests <= 0 {
+		return RateLimit{}, fmt.Errorf("invalid request count in rate limit %q", value)
+	}
+	per, err := time.ParseDuration(period)
+	if err != nil || per <= 0 {
+		return RateLimit{}, fmt.Errorf("invalid period in rate limit %q", value)
+	}
+	return RateLimit{Requests: requests, Per: per}, nil
+}
+
+func (l RateLimit) enabled() bool {
+	return l.Requests > 0 && l.Per > 0
+}
+
+// bucket - корзина токенов одного клиента
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// limiter хранит корзины клиентов одного класса маршрутов
+type limiter struct {
+	limit RateLimit
+	rate  float64 // токенов в секунду
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// limitResult - состояние корзины после запроса, для заголовков RateLimit-*
+type limitResult struct {
+	allowed    bool
+	limit      int
+	remaining  int
+	reset      time.Duration // до полного восстановления корзины
+	retryAfter time.Duration // до появления следующего токена, если отказано
+}
+
+func newLimiter(limit RateLimit) *limiter {
+	return &limiter{
+		limit:   limit,
+		rate:    float64(limit.Requests) / limit.Per.Seconds(),
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// allow проверяет корзины всех ключей запроса (адрес, токен) и списывает
+// по токену из каждой, только если все они пропускают запрос: отказ по одной
+// корзине не расходует остальные. Результат - по самой строгой корзине.
+func (l *limiter) allow(keys []string, now time.Time) limitResult {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	capacity := float64(l.limit.Requests)
+	buckets := make([]*bucket, len(keys))
+	allowed := true
+	for i, key := range keys {
+		b, ok := l.buckets[key]
+		if !ok {
+			b = &bucket{tokens: capacity, updated: now}
+			l.buckets[key] = b
+		}
+		b.tokens = math.Min(capacity, b.tokens+now.Sub(b.updated).Seconds()*l.rate)
+		b.updated = now
+		buckets[i] = b
+		if b.tokens < 1 {
+			allowed = false
+		}
+	}
+
+	var result limitResult
+	for i, b := range buckets {
+		r := limitResult{limit: l.limit.Requests, allowed: b.tokens >= 1}
+		if allowed {
+			b.tokens--
+		} else if !r.allowed {
+			r.retryAfter = l.duration(1 - b.tokens)
+		}
+		r.remaining = int(b.tokens)
+		r.reset = l.duration(capacity - b.tokens)
+		if i == 0 {
+			result = r
+		} else {
+			result = stricter(result, r)
+		}
+	}
+	return result
+}
+
+func (l *limiter) duration(tokens float64) time.Duration {
+	return time.Duration(tokens / l.rate * float64(time.Second))
+}
+
+// sweep раз в период удаляет корзины, которые успели наполниться: они ничем
+// не отличаются от новых
+func (l *limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.limit.Per {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.updated) >= l.limit.Per {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// rateLimitMiddleware ограничивает частоту запросов по корзине токенов: для
+// каждого адреса клиента и, отдельно, для каждого токена Authorization: Bearer.
+// Ответ содержит заголовки RateLimit-Limit/Remaining/Reset, превышение - 429
+// с Retry-After.
+func rateLimitMiddleware(limits RateLimits, appLogger *logger.Logger) func(http.Handler) http.Handler {
+	var read, write *limiter
+	if limits.Read.enabled() {
+		read = newLimiter(limits.Read)
+	}
+	if limits.Write.enabled() {
+		write = newLimiter(limits.Write)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			l := write
+			if isSafeMethod(r.Method) {
+				l = read
+			}
+			if l == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			now := time.Now()
+			ip := clientIP(r, limits.TrustProxy)
+			keys := []string{"ip:" + ip}
+			if key := bearerKey(r); key != "" {
+				keys = append(keys, "token:"+key)
+			}
+			result := l.allow(keys, now)
+
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(result.limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.remaining))
+			w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.reset)))
+			if !result.allowed {
+				appLogger.Warn("Rate limit exceeded for %s: %s %s", ip, r.Method, r.URL.Path)
+				w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(result.retryAfter)))
+				http.Error(w, "Too many requests", http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// stricter выбирает из двух корзин ту, что ближе к исчерпанию
+func stricter(a, b limitResult) limitResult {
+	switch {
+	case a.allowed != b.allowed:
+		if !a.allowed {
+			return a
+		}
+		return b
+	case !a.allowed:
+		if a.retryAfter >= b.retryAfter {
+			return a
+		}
+		return b
+	case a.remaining <= b.remaining:
+		return a
+	default:
+		return b
+	}
+}
+
+// bearerKey - корзина токена определяется хешем, сам токен в памяти не хранится
+func bearerKey(r *http.Request) string {
+	plain, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found || strings.TrimSpace(plain) == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(strings.TrimSpace(plain)))
+	return hex.EncodeToString(sum[:16])
+}
+
+// clientIP возвращает адрес клиента. За прокси берётся последний адрес из
+// X-Forwarded-For: его добавил наш прокси, предыдущие клиент мог подделать.
+func clientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			parts := strings.Split(forwarded, ",")
+			if ip := strings.TrimSpace(parts[len(parts)-1]); ip != "" {
+				return ip
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
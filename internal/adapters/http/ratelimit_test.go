@@ -0,0 +1,187 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"ToDo-List/internal/adapters/logger"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	tests := []struct {
+		input string
+		want  RateLimit
+	}{
+		{input: "120/1m", want: RateLimit{Requests: 120, Per: time.Minute}},
+		{input: " 5/30s ", want: RateLimit{Requests: 5, Per: 30 * time.Second}},
+		{input: "1/1h30m", want: RateLimit{Requests: 1, Per: 90 * time.Minute}},
+		{input: "off", want: RateLimit{}},
+		{input: "0", want: RateLimit{}},
+	}
+	for _, tt := range tests {
+		got, err := ParseRateLimit(tt.input)
+		if err != nil {
+			t.Errorf("ParseRateLimit(%q): %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseRateLimit(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseRateLimitErrors(t *testing.T) {
+	for _, input := range []string{"", "120", "120/", "/1m", "x/1m", "-1/1m", "0/1m", "10/0s", "10/-1m", "10/minute"} {
+		if got, err := ParseRateLimit(input); err == nil {
+			t.Errorf("ParseRateLimit(%q) = %+v, want error", input, got)
+		}
+	}
+}
+
+func TestLimiterBucket(t *testing.T) {
+	l := newLimiter(RateLimit{Requests: 3, Per: 3 * time.Second}) // токен в секунду
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	keys := []string{"ip:1"}
+
+	// полная корзина пропускает всплеск из Requests запросов
+	for i := 2; i >= 0; i-- {
+		result := l.allow(keys, start)
+		if !result.allowed || result.remaining != i || result.limit != 3 {
+			t.Fatalf("request %d: %+v, want allowed with %d remaining", 3-i, result, i)
+		}
+	}
+	result := l.allow(keys, start)
+	if result.allowed || result.retryAfter != time.Second || result.reset != 3*time.Second {
+		t.Fatalf("request over limit: %+v, want rejected, retry after 1s, reset 3s", result)
+	}
+
+	// токены восстанавливаются со скоростью Requests/Per
+	result = l.allow(keys, start.Add(1500*time.Millisecond))
+	if !result.allowed || result.remaining != 0 {
+		t.Fatalf("after 1.5s: %+v, want allowed with 0 remaining", result)
+	}
+	result = l.allow(keys, start.Add(1600*time.Millisecond))
+	if result.allowed || result.retryAfter != 400*time.Millisecond {
+		t.Fatalf("after 1.6s: %+v, want rejected, retry after 400ms", result)
+	}
+
+	// корзина не наполняется сверх Requests
+	result = l.allow(keys, start.Add(time.Hour))
+	if !result.allowed || result.remaining != 2 {
+		t.Fatalf("after an hour: %+v, want allowed with 2 remaining", result)
+	}
+
+	// у другого клиента своя корзина
+	if result := l.allow([]string{"ip:2"}, start.Add(time.Hour)); !result.allowed || result.remaining != 2 {
+		t.Fatalf("other client: %+v, want allowed with 2 remaining", result)
+	}
+}
+
+// Отказ по корзине токена не расходует корзину адреса, и наоборот
+func TestLimiterChargesOnlyWhenAllAllow(t *testing.T) {
+	l := newLimiter(RateLimit{Requests: 2, Per: time.Hour})
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// токен исчерпан запросами с другого адреса
+	for i := 0; i < 2; i++ {
+		if !l.allow([]string{"ip:other", "token:t"}, now).allowed {
+			t.Fatalf("request %d with token rejected", i+1)
+		}
+	}
+	for i := 0; i < 5; i++ {
+		if l.allow([]string{"ip:1", "token:t"}, now).allowed {
+			t.Fatal("request with exhausted token allowed")
+		}
+	}
+	result := l.allow([]string{"ip:1"}, now)
+	if !result.allowed || result.remaining != 1 {
+		t.Fatalf("address after token rejections: %+v, want allowed with 1 remaining", result)
+	}
+
+	// адрес исчерпан: другой токен с этого адреса не расходуется
+	l.allow([]string{"ip:1"}, now)
+	if l.allow([]string{"ip:1", "token:u"}, now).allowed {
+		t.Fatal("request from exhausted address allowed")
+	}
+	if result := l.allow([]string{"ip:2", "token:u"}, now); !result.allowed || result.remaining != 1 {
+		t.Fatalf("token after address rejection: %+v, want allowed with 1 remaining", result)
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+	appLogger, err := logger.New(logger.Config{Level: logger.LevelError, Output: devNull})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	limits := RateLimits{Read: RateLimit{Requests: 2, Per: time.Minute}}
+	handler := rateLimitMiddleware(limits, appLogger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	do := func(method string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/todos", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i, wantRemaining := range []string{"1", "0"} {
+		rec := do(http.MethodGet)
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("request %d: status %d", i+1, rec.Code)
+		}
+		if got := rec.Header().Get("RateLimit-Remaining"); got != wantRemaining {
+			t.Errorf("request %d: RateLimit-Remaining = %s, want %s", i+1, got, wantRemaining)
+		}
+		if got := rec.Header().Get("RateLimit-Limit"); got != "2" {
+			t.Errorf("request %d: RateLimit-Limit = %s, want 2", i+1, got)
+		}
+	}
+	rec := do(http.MethodGet)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("request over limit: status %d, want 429", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "30" {
+		t.Errorf("Retry-After = %s, want 30", got)
+	}
+
+	// запись ограничена отдельно, здесь ограничение выключено
+	if rec := do(http.MethodPost); rec.Code != http.StatusNoContent {
+		t.Errorf("POST with write limit off: status %d", rec.Code)
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		remoteAddr string
+		forwarded  string
+		trustProxy bool
+		want       string
+	}{
+		{remoteAddr: "192.0.2.1:1234", want: "192.0.2.1"},
+		{remoteAddr: "[2001:db8::1]:443", want: "2001:db8::1"},
+		{remoteAddr: "192.0.2.1:1234", forwarded: "198.51.100.7", want: "192.0.2.1"},
+		{remoteAddr: "10.0.0.2:1234", forwarded: "203.0.113.9, 198.51.100.7", trustProxy: true, want: "198.51.100.7"},
+		{remoteAddr: "10.0.0.2:1234", trustProxy: true, want: "10.0.0.2"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = tt.remoteAddr
+		if tt.forwarded != "" {
+			req.Header.Set("X-Forwarded-For", tt.forwarded)
+		}
+		if got := clientIP(req, tt.trustProxy); got != tt.want {
+			t.Errorf("clientIP(%s, X-Forwarded-For %q, trust %v) = %s, want %s",
+				tt.remoteAddr, tt.forwarded, tt.trustProxy, got, tt.want)
+		}
+	}
+}
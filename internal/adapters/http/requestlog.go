@@ -0,0 +1,131 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/adapters/metrics"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader - идентификатор запроса: принимается от клиента или прокси,
+// иначе генерируется, и возвращается в ответе
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIdLength - более длинный X-Request-ID клиента заменяется своим
+const maxRequestIdLength = 128
+
+// requestInfo заполняется по ходу обработки запроса и попадает в журнал доступа
+type requestInfo struct {
+	route  string
+	userId string
+}
+
+type requestInfoKey struct{}
+
+// requestLogging присваивает запросу X-Request-ID, кладёт в контекст логгер
+// с этим идентификатором (им пишут обработчики, сервисы и репозитории) и
+// после ответа пишет одну строку журнала доступа и, если m не nil, учитывает
+// запрос в метриках
+func requestLogging(next http.Handler, m *metrics.Metrics, appLogger *logger.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		requestId := r.Header.Get(RequestIDHeader)
+		if requestId == "" || len(requestId) > maxRequestIdLength || !printableASCII(requestId) {
+			requestId = uuid.NewString()
+		}
+		w.Header().Set(RequestIDHeader, requestId)
+
+		fields := map[string]interface{}{"request_id": requestId}
+		// trace_id связывает строки лога с трассировкой запроса
+		if spanContext := trace.SpanContextFromContext(r.Context()); spanContext.IsValid() {
+			fields["trace_id"] = spanContext.TraceID().String()
+		}
+		requestLogger := appLogger.WithFields(fields)
+		info := &requestInfo{}
+		ctx := logger.WithContext(r.Context(), requestLogger)
+		ctx = context.WithValue(ctx, requestInfoKey{}, info)
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		duration := time.Since(start)
+		route := info.route
+		if route == "" {
+			route = "unmatched"
+		}
+		if m != nil {
+			m.ObserveRequest(r.Method, route, rec.status, duration)
+		}
+		requestLogger.WithFields(map[string]interface{}{
+			"method":      r.Method,
+			"route":       route,
+			"status":      rec.status,
+			"bytes":       rec.bytes,
+			"duration_ms": duration.Milliseconds(),
+			"user":        info.userId,
+		}).Info("%s %s %d", r.Method, route, rec.status)
+	})
+}
+
+// routeTemplate запоминает шаблон маршрута (/api/todo/{id}), а не путь,
+// чтобы строки журнала доступа, метрики и span группировались по маршрутам
+func routeTemplate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if route := mux.CurrentRoute(r); route != nil {
+			if template, err := route.GetPathTemplate(); err == nil {
+				if info, ok := r.Context().Value(requestInfoKey{}).(*requestInfo); ok {
+					info.route = template
+				}
+				span := trace.SpanFromContext(r.Context())
+				span.SetName(template)
+				span.SetAttributes(semconv.HTTPRoute(template))
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// withRequestUser отмечает пользователя в журнале доступа и в логгере запроса
+func withRequestUser(ctx context.Context, userId string, appLogger *logger.Logger) context.Context {
+	if info, ok := ctx.Value(requestInfoKey{}).(*requestInfo); ok {
+		info.userId = userId
+	}
+	requestLogger := logger.FromContext(ctx, appLogger).WithFields(map[string]interface{}{"user_id": userId})
+	return logger.WithContext(ctx, requestLogger)
+}
+
+// statusRecorder запоминает код и размер ответа
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap даёт http.ResponseController доступ к Flush и таймаутам исходного ответа
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -4,37 +4,178 @@ import (
 	"net/http"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"ToDo-List/internal/adapters/http/handlers"
 	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/adapters/metrics"
 	"ToDo-List/internal/application/service"
+	"ToDo-List/internal/core/policy"
 	"ToDo-List/internal/core/ports"
 
 	"github.com/gorilla/mux"
 )
 
-func NewRouter(repo ports.PostgreRepo, appLogger *logger.Logger) *mux.Router {
+// Dependencies - порты, из которых роутер собирает сервисы
+type Dependencies struct {
+	Todos    ports.PostgreRepo
+	Views    ports.ViewRepo
+	Bulk     ports.BulkRepo
+	Users    ports.UserRepo
+	Sessions ports.SessionRepo
+	Tokens   ports.APITokenRepo
+	Shares   ports.ShareRepo
+	Comments ports.CommentRepo
+	// Workspaces - рабочие пространства и их участники
+	Workspaces ports.WorkspaceRepo
+	// Audit и Stats - журнал аудита и сводка для администраторов
+	Audit ports.AuditRepo
+	Stats ports.StatsRepo
+	// Idempotency - сохранённые ответы на запросы с Idempotency-Key
+	Idempotency ports.IdempotencyRepo
+	// Attachments и Blobs - записи о вложениях и хранилище их содержимого
+	Attachments ports.AttachmentRepo
+	Blobs       ports.BlobStore
+	// Notifier доставляет уведомления исполнителям и наблюдателям
+	Notifier ports.Notifier
+	UoW      ports.UnitOfWork
+	Hasher   ports.PasswordHasher
+	// OIDC - провайдер SSO; nil, если вход через SSO не настроен
+	OIDC ports.IdentityProvider
+
+	SessionTTL       time.Duration
+	IdempotencyTTL   time.Duration
+	SecureCookie     bool
+	AttachmentLimits service.AttachmentLimits
+	// WorkspaceDomain - базовый домен для выбора пространства по поддомену;
+	// пусто - поддомены не используются
+	WorkspaceDomain string
+	// RateLimits - ограничения частоты запросов к /api
+	RateLimits RateLimits
+	// Metrics - метрики для /metrics; nil, если метрики выключены
+	Metrics *metrics.Metrics
+	// MetricsToken - токен Bearer для /metrics; пусто - без проверки
+	MetricsToken string
+}
+
+func NewRouter(deps Dependencies, appLogger *logger.Logger) http.Handler {
 	router := mux.NewRouter()
 
 	appLogger.Info("Initializing HTTP router...")
 
-	todoService := service.NewToDoService(repo, appLogger) // передаем логгер в сервис
+	repo := deps.Todos
+	todoService := service.NewToDoService(repo, deps.Shares, deps.Users, deps.UoW, deps.Notifier, appLogger) // передаем логгер в сервис
 	todoHandler := handlers.NewTodoHandler(todoService, appLogger)
+	viewService := service.NewViewService(deps.Views, repo, appLogger)
+	viewHandler := handlers.NewViewHandler(viewService, appLogger)
+	bulkService := service.NewBulkService(deps.Bulk, repo, todoService, deps.UoW, appLogger)
+	bulkHandler := handlers.NewBulkHandler(bulkService, appLogger)
+	authService := service.NewAuthService(deps.Users, deps.Sessions, deps.UoW, deps.Hasher, deps.SessionTTL, appLogger)
+	authHandler := handlers.NewAuthHandler(authService, deps.OIDC, deps.SecureCookie, appLogger)
+	tokenService := service.NewAPITokenService(deps.Tokens, deps.Users, appLogger)
+	tokenHandler := handlers.NewTokenHandler(tokenService, appLogger)
+	shareService := service.NewShareService(deps.Shares, repo, deps.Users, deps.Workspaces, appLogger)
+	shareHandler := handlers.NewShareHandler(shareService, appLogger)
+	commentService := service.NewCommentService(deps.Comments, repo, deps.Shares, deps.Users, deps.UoW, deps.Notifier, appLogger)
+	commentHandler := handlers.NewCommentHandler(commentService, appLogger)
+	attachmentService := service.NewAttachmentService(deps.Attachments, repo, deps.Shares, deps.Blobs, deps.UoW, deps.AttachmentLimits, appLogger)
+	attachmentHandler := handlers.NewAttachmentHandler(attachmentService, deps.AttachmentLimits.MaxSize, appLogger)
+	workspaceService := service.NewWorkspaceService(deps.Workspaces, deps.Users, deps.UoW, appLogger)
+	workspaceHandler := handlers.NewWorkspaceHandler(workspaceService, appLogger)
+	auditService := service.NewAuditService(deps.Audit, appLogger)
+	adminService := service.NewAdminService(deps.Users, deps.Sessions, deps.Audit, auditService, deps.Stats, deps.Hasher, deps.UoW, appLogger)
+	adminHandler := handlers.NewAdminHandler(adminService, deps.SecureCookie, appLogger)
+	logHandler := handlers.NewLogHandler(auditService, appLogger)
+	idempotencyService := service.NewIdempotencyService(deps.Idempotency, deps.IdempotencyTTL, appLogger)
+
+	// Шаблон найденного маршрута нужен журналу доступа, метрикам и трассировке
+	router.Use(routeTemplate)
 
 	// Создаем подроутер для API с префиксом /api
 	apiRouter := router.PathPrefix("/api").Subrouter()
-
-	// POST /api/todo
-	apiRouter.HandleFunc("/todo", todoHandler.CreateTodoHandler).Methods(http.MethodPost)
+	// Частота запросов ограничивается до аутентификации, в том числе для входа
+	apiRouter.Use(rateLimitMiddleware(deps.RateLimits, appLogger))
+	// Все маршруты /api, кроме регистрации и входа, требуют сессию
+	apiRouter.Use(authMiddleware(authService, tokenService, auditService, appLogger))
+	// Остальные маршруты работают в рабочем пространстве запроса
+	apiRouter.Use(workspaceMiddleware(workspaceService, deps.WorkspaceDomain, appLogger))
+
+	// Аутентификация: /api/auth
+	apiRouter.HandleFunc("/auth/register", authHandler.RegisterHandler).Methods(http.MethodPost)
+	apiRouter.HandleFunc("/auth/login", authHandler.LoginHandler).Methods(http.MethodPost)
+	apiRouter.HandleFunc("/auth/logout", authHandler.LogoutHandler).Methods(http.MethodPost)
+	apiRouter.HandleFunc("/auth/me", authHandler.MeHandler).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/auth/methods", authHandler.AuthMethodsHandler).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/auth/oidc/login", authHandler.OIDCLoginHandler).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/auth/oidc/callback", authHandler.OIDCCallbackHandler).Methods(http.MethodGet)
+
+	// Рабочие пространства: /api/workspaces, /api/workspace
+	apiRouter.HandleFunc("/workspaces", workspaceHandler.GetWorkspacesHandler).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/workspaces", workspaceHandler.CreateWorkspaceHandler).Methods(http.MethodPost)
+	apiRouter.HandleFunc("/workspace", workspaceHandler.GetCurrentWorkspaceHandler).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/workspace/settings", workspaceHandler.UpdateSettingsHandler).Methods(http.MethodPut)
+	apiRouter.HandleFunc("/workspace/members", workspaceHandler.GetMembersHandler).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/workspace/members", workspaceHandler.AddMemberHandler).Methods(http.MethodPost)
+	apiRouter.HandleFunc("/workspace/members/{userId}", workspaceHandler.RemoveMemberHandler).Methods(http.MethodDelete)
+
+	// Администрирование: /api/admin, только для пользователей с нужным правом
+	apiRouter.HandleFunc("/admin/users", requirePermission(policy.UsersManage, appLogger, adminHandler.GetUsersHandler)).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/admin/users/{id}", requirePermission(policy.UsersManage, appLogger, adminHandler.GetUserHandler)).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/admin/users/{id}/role", requirePermission(policy.UsersManage, appLogger, adminHandler.SetRoleHandler)).Methods(http.MethodPut)
+	apiRouter.HandleFunc("/admin/users/{id}/disabled", requirePermission(policy.UsersManage, appLogger, adminHandler.SetDisabledHandler)).Methods(http.MethodPut)
+	apiRouter.HandleFunc("/admin/users/{id}/password", requirePermission(policy.UsersManage, appLogger, adminHandler.ResetPasswordHandler)).Methods(http.MethodPost)
+	apiRouter.HandleFunc("/admin/users/{id}/impersonate", requirePermission(policy.UsersImpersonate, appLogger, adminHandler.ImpersonateHandler)).Methods(http.MethodPost)
+	apiRouter.HandleFunc("/admin/audit", requirePermission(policy.AuditRead, appLogger, adminHandler.GetAuditLogHandler)).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/admin/stats", requirePermission(policy.StatsRead, appLogger, adminHandler.GetStatsHandler)).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/admin/log-level", requirePermission(policy.LogsManage, appLogger, logHandler.GetLevelHandler)).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/admin/log-level", requirePermission(policy.LogsManage, appLogger, logHandler.SetLevelHandler)).Methods(http.MethodPut)
+
+	// Персональные токены: /api/tokens
+	apiRouter.HandleFunc("/tokens", tokenHandler.GetTokensHandler).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/tokens", tokenHandler.CreateTokenHandler).Methods(http.MethodPost)
+	apiRouter.HandleFunc("/tokens/{id}", tokenHandler.RevokeTokenHandler).Methods(http.MethodDelete)
+
+	// POST /api/todo, повтор с тем же Idempotency-Key не создаёт дубликат
+	apiRouter.HandleFunc("/todo", idempotent(idempotencyService, appLogger, todoHandler.CreateTodoHandler)).Methods(http.MethodPost)
 
 	// GET /api/todos
 	apiRouter.HandleFunc("/todos", todoHandler.GetTodosHandler).Methods(http.MethodGet)
 
+	// POST /api/todos/bulk
+	apiRouter.HandleFunc("/todos/bulk", idempotent(idempotencyService, appLogger, bulkHandler.BulkTodosHandler)).Methods(http.MethodPost)
+
 	// Health check
 	router.HandleFunc("/health", healthHandler(repo, appLogger)).Methods(http.MethodGet)
 
+	// Метрики Prometheus
+	if deps.Metrics != nil {
+		router.HandleFunc("/metrics", metricsHandler(deps.Metrics, deps.MetricsToken, appLogger)).Methods(http.MethodGet)
+	}
+
 	// POST /api/todo/complete/{id}
-	apiRouter.HandleFunc("/todo/complete/{id}", todoHandler.CompleteTodoByIdHandler).Methods(http.MethodPost)
+	apiRouter.HandleFunc("/todo/complete/{id}", idempotent(idempotencyService, appLogger, todoHandler.CompleteTodoByIdHandler)).Methods(http.MethodPost)
+
+	// POST /api/todo/{id}/move
+	apiRouter.HandleFunc("/todo/{id}/move", todoHandler.MoveTodoHandler).Methods(http.MethodPost)
+
+	// Исполнитель и наблюдатели
+	apiRouter.HandleFunc("/todo/{id}/assignee", todoHandler.AssignTodoHandler).Methods(http.MethodPut)
+	apiRouter.HandleFunc("/todo/{id}/watchers", todoHandler.WatchTodoHandler).Methods(http.MethodPost)
+	apiRouter.HandleFunc("/todo/{id}/watchers/{user}", todoHandler.UnwatchTodoHandler).Methods(http.MethodDelete)
+
+	// Комментарии: /api/todo/{id}/comments
+	apiRouter.HandleFunc("/todo/{id}/comments", commentHandler.GetCommentsHandler).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/todo/{id}/comments", commentHandler.CreateCommentHandler).Methods(http.MethodPost)
+	apiRouter.HandleFunc("/todo/{id}/comments/{commentId}", commentHandler.GetCommentHandler).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/todo/{id}/comments/{commentId}", commentHandler.UpdateCommentHandler).Methods(http.MethodPut)
+	apiRouter.HandleFunc("/todo/{id}/comments/{commentId}", commentHandler.DeleteCommentHandler).Methods(http.MethodDelete)
+	apiRouter.HandleFunc("/todo/{id}/comments/{commentId}/history", commentHandler.GetCommentHistoryHandler).Methods(http.MethodGet)
+
+	// Вложения: /api/todo/{id}/attachments
+	apiRouter.HandleFunc("/todo/{id}/attachments", attachmentHandler.GetAttachmentsHandler).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/todo/{id}/attachments", attachmentHandler.UploadAttachmentHandler).Methods(http.MethodPost)
+	apiRouter.HandleFunc("/todo/{id}/attachments/{attachmentId}", attachmentHandler.DownloadAttachmentHandler).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/todo/{id}/attachments/{attachmentId}", attachmentHandler.DeleteAttachmentHandler).Methods(http.MethodDelete)
 
 	// GET /api/todo/{id}
 	apiRouter.HandleFunc("/todo/{id}", todoHandler.GetTodoByIdHandler).Methods(http.MethodGet)
@@ -43,11 +184,27 @@ func NewRouter(repo ports.PostgreRepo, appLogger *logger.Logger) *mux.Router {
 	// DELETE /api/todo/{id}
 	apiRouter.HandleFunc("/todo/{id}", todoHandler.DeleteTodoHandler).Methods(http.MethodDelete)
 
+	// Совместный доступ: /api/shares, /api/shared
+	apiRouter.HandleFunc("/shares", shareHandler.GetSharesHandler).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/shares", shareHandler.CreateShareHandler).Methods(http.MethodPost)
+	apiRouter.HandleFunc("/shares/{id}", shareHandler.DeleteShareHandler).Methods(http.MethodDelete)
+	apiRouter.HandleFunc("/shared", shareHandler.GetSharedHandler).Methods(http.MethodGet)
+
+	// Сохранённые фильтры: /api/views
+	apiRouter.HandleFunc("/views", viewHandler.GetViewsHandler).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/views", viewHandler.CreateViewHandler).Methods(http.MethodPost)
+	// GET /api/views/counts - регистрируется до /views/{id}
+	apiRouter.HandleFunc("/views/counts", viewHandler.GetViewCountsHandler).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/views/{id}", viewHandler.GetViewByIdHandler).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/views/{id}", viewHandler.UpdateViewHandler).Methods(http.MethodPut)
+	apiRouter.HandleFunc("/views/{id}", viewHandler.DeleteViewHandler).Methods(http.MethodDelete)
+	apiRouter.HandleFunc("/views/{id}/todos", viewHandler.GetViewTodosHandler).Methods(http.MethodGet)
+
 	// Обслуживание статических файлов фронтенда
 	router.PathPrefix("/").Handler(customFileServer("./web", appLogger))
 
 	appLogger.Info("HTTP router initialized successfully")
-	return router
+	return traceRequests(requestLogging(router, deps.Metrics, appLogger))
 }
 
 func customFileServer(root string, appLogger *logger.Logger) http.Handler {
@@ -0,0 +1,138 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	spanExporter = tracetest.NewInMemoryExporter()
+	setupTracing sync.Once
+)
+
+// recordSpans включает запись span в память. Глобальный tracer пакета
+// привязывается к первому TracerProvider, поэтому он задаётся один раз.
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	setupTracing.Do(func() {
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(spanExporter)))
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+	})
+	spanExporter.Reset()
+	return spanExporter
+}
+
+func spanAttr(span tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, attr := range span.Attributes {
+		if attr.Key == key {
+			return attr.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func tracedRouter(status int) http.Handler {
+	router := mux.NewRouter()
+	router.Use(routeTemplate)
+	router.HandleFunc("/api/todo/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	})
+	return traceRequests(router)
+}
+
+func TestTraceRequestsContinuesIncomingTrace(t *testing.T) {
+	exporter := recordSpans(t)
+	req := httptest.NewRequest(http.MethodGet, "/api/todo/42", nil)
+	req.Header.Set("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	tracedRouter(http.StatusOK).ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Name != "/api/todo/{id}" {
+		t.Errorf("span name = %q, want the route template", span.Name)
+	}
+	if span.SpanKind != trace.SpanKindServer {
+		t.Errorf("span kind = %v, want server", span.SpanKind)
+	}
+	if got := span.SpanContext.TraceID().String(); got != "0af7651916cd43dd8448eb211c80319c" {
+		t.Errorf("trace id = %s, want the one from traceparent", got)
+	}
+	if got := span.Parent.SpanID().String(); got != "b7ad6b7169203331" {
+		t.Errorf("parent span id = %s, want the one from traceparent", got)
+	}
+	if got := spanAttr(span, "http.route").AsString(); got != "/api/todo/{id}" {
+		t.Errorf("http.route = %q", got)
+	}
+	if got := spanAttr(span, "http.response.status_code").AsInt64(); got != http.StatusOK {
+		t.Errorf("http.response.status_code = %d", got)
+	}
+}
+
+func TestTraceRequestsStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		want   codes.Code
+	}{
+		{status: http.StatusOK, want: codes.Unset},
+		// Ошибка клиента не помечает span ошибкой
+		{status: http.StatusNotFound, want: codes.Unset},
+		{status: http.StatusInternalServerError, want: codes.Error},
+	}
+	for _, tt := range tests {
+		exporter := recordSpans(t)
+		tracedRouter(tt.status).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/todo/42", nil))
+
+		spans := exporter.GetSpans()
+		if len(spans) != 1 {
+			t.Fatalf("status %d: got %d spans, want 1", tt.status, len(spans))
+		}
+		if spans[0].Status.Code != tt.want {
+			t.Errorf("status %d: span status = %v, want %v", tt.status, spans[0].Status.Code, tt.want)
+		}
+		// Новая трассировка, если traceparent не пришёл
+		if spans[0].Parent.IsValid() {
+			t.Errorf("status %d: span has a parent without traceparent", tt.status)
+		}
+	}
+}
+
+func TestTraceRequestsUnmatchedRoute(t *testing.T) {
+	exporter := recordSpans(t)
+	tracedRouter(http.StatusOK).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/no/such/route", nil))
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || spans[0].Name != http.MethodPost {
+		t.Errorf("spans = %v, want one named by the method", spans)
+	}
+}
+
+func TestAccessLogHasTraceId(t *testing.T) {
+	exporter := recordSpans(t)
+	appLogger, entries := newJSONLogger(t)
+	traceRequests(loggedRouter(appLogger)).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/todo/42", nil))
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	traceId := spans[0].SpanContext.TraceID().String()
+	for _, entry := range entries() {
+		if entry["trace_id"] != traceId {
+			t.Errorf("entry trace_id = %v, want %s", entry["trace_id"], traceId)
+		}
+	}
+}
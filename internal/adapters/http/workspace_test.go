@@ -0,0 +1,122 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/identity"
+	"ToDo-List/internal/core/ports"
+)
+
+func newTestLogger(t *testing.T) *logger.Logger {
+	t.Helper()
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { devNull.Close() })
+	appLogger, err := logger.New(logger.Config{Level: logger.LevelError, Output: devNull})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return appLogger
+}
+
+// teamWorkspaces: alice участник пространств team (id w-team) и alice (по
+// умолчанию), пространство other существует, но alice в нём не состоит
+type teamWorkspaces struct {
+	ports.WorkspaceService
+}
+
+func (teamWorkspaces) Resolve(ctx context.Context, userId, ref string) (domain.Workspace, string, error) {
+	switch ref {
+	case "", "alice":
+		return domain.Workspace{Id: "w-alice", Slug: "alice"}, domain.WorkspaceRoleOwner, nil
+	case "team", "w-team":
+		return domain.Workspace{Id: "w-team", Slug: "team"}, domain.WorkspaceRoleMember, nil
+	case "other":
+		return domain.Workspace{}, "", ports.ErrNotMember
+	default:
+		return domain.Workspace{}, "", ports.ErrWorkspaceNotFound
+	}
+}
+
+func TestWorkspaceMiddleware(t *testing.T) {
+	var resolved string
+	handler := workspaceMiddleware(teamWorkspaces{}, "todo.example.com", newTestLogger(t))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resolved, _ = identity.WorkspaceId(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		name          string
+		host          string
+		header        string
+		token         string // пространство, к которому привязан API-токен
+		wantStatus    int
+		wantWorkspace string
+	}{
+		{name: "default", host: "todo.example.com", wantStatus: http.StatusNoContent, wantWorkspace: "w-alice"},
+		{name: "header slug", header: "team", wantStatus: http.StatusNoContent, wantWorkspace: "w-team"},
+		{name: "subdomain", host: "team.todo.example.com", wantStatus: http.StatusNoContent, wantWorkspace: "w-team"},
+		{name: "header wins over subdomain", host: "alice.todo.example.com", header: "team", wantStatus: http.StatusNoContent, wantWorkspace: "w-team"},
+		{name: "not a member", header: "other", wantStatus: http.StatusForbidden},
+		{name: "unknown", header: "missing", wantStatus: http.StatusNotFound},
+		{name: "token workspace", token: "w-team", wantStatus: http.StatusNoContent, wantWorkspace: "w-team"},
+		{name: "token workspace by slug", token: "w-team", header: "team", wantStatus: http.StatusNoContent, wantWorkspace: "w-team"},
+		// Токен одного пространства не открывает другое
+		{name: "token of another workspace", token: "w-team", header: "alice", wantStatus: http.StatusForbidden},
+		{name: "token of another subdomain", token: "w-team", host: "alice.todo.example.com", wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolved = ""
+			req := httptest.NewRequest(http.MethodGet, "/api/todos", nil)
+			if tt.host != "" {
+				req.Host = tt.host
+			}
+			if tt.header != "" {
+				req.Header.Set(WorkspaceHeader, tt.header)
+			}
+			ctx := identity.WithUser(req.Context(), domain.User{Id: "alice"})
+			if tt.token != "" {
+				ctx = context.WithValue(ctx, tokenWorkspaceKey{}, tt.token)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req.WithContext(ctx))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if resolved != tt.wantWorkspace {
+				t.Errorf("workspace = %q, want %q", resolved, tt.wantWorkspace)
+			}
+		})
+	}
+}
+
+func TestSubdomainWorkspace(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+	}{
+		{host: "team.todo.example.com", want: "team"},
+		{host: "Team.Todo.Example.com:8080", want: "team"},
+		{host: "todo.example.com", want: ""},
+		{host: "a.team.todo.example.com", want: ""},
+		{host: "team.example.org", want: ""},
+	}
+	for _, tt := range tests {
+		if got := subdomainWorkspace(tt.host, "todo.example.com"); got != tt.want {
+			t.Errorf("subdomainWorkspace(%q) = %q, want %q", tt.host, got, tt.want)
+		}
+	}
+	if got := subdomainWorkspace("team.todo.example.com", ""); got != "" {
+		t.Errorf("subdomainWorkspace without base domain = %q, want empty", got)
+	}
+}
@@ -1,6 +1,8 @@
 package logger
 
 import (
+	"fmt"
+	"log/slog"
 	"os"
 	"strings"
 )
@@ -49,7 +51,98 @@ func ParseLevel(levelStr string) Level {
 	}
 }
 
+// slogLevelFatal - в slog нет уровня FATAL, он располагается выше ERROR
+const slogLevelFatal = slog.LevelError + 4
+
+func (l Level) slogLevel() slog.Level {
+	switch l {
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelWarn:
+		return slog.LevelWarn
+	case LevelError:
+		return slog.LevelError
+	case LevelFatal:
+		return slogLevelFatal
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// levelFromSlog переводит уровень slog (в том числе промежуточный,
+// например WARN+1 от сторонних библиотек) в ближайший уровень снизу
+func levelFromSlog(l slog.Level) Level {
+	switch {
+	case l >= slogLevelFatal:
+		return LevelFatal
+	case l >= slog.LevelError:
+		return LevelError
+	case l >= slog.LevelWarn:
+		return LevelWarn
+	case l >= slog.LevelInfo:
+		return LevelInfo
+	default:
+		return LevelDebug
+	}
+}
+
+// Format - формат строк лога
+type Format int
+
+const (
+	// FormatText - читаемая строка; цвет только при выводе в терминал
+	FormatText Format = iota
+	FormatJSON
+	// FormatLogfmt - строки key=value
+	FormatLogfmt
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatJSON:
+		return "json"
+	case FormatLogfmt:
+		return "logfmt"
+	default:
+		return "text"
+	}
+}
+
+func ParseFormat(formatStr string) (Format, error) {
+	switch strings.ToLower(formatStr) {
+	case "", "text":
+		return FormatText, nil
+	case "json":
+		return FormatJSON, nil
+	case "logfmt":
+		return FormatLogfmt, nil
+	default:
+		return FormatText, fmt.Errorf("unknown log format %q: expected text, json or logfmt", formatStr)
+	}
+}
+
+// LookupLevel в отличие от ParseLevel сообщает о неизвестном уровне
+func LookupLevel(levelStr string) (Level, bool) {
+	switch strings.ToUpper(levelStr) {
+	case "DEBUG", "INFO", "WARN", "WARNING", "ERROR", "FATAL":
+		return ParseLevel(levelStr), true
+	default:
+		return LevelInfo, false
+	}
+}
+
 type Config struct {
 	Level  Level
+	Format Format
+	// Output - консольный вывод; nil - os.Stdout
 	Output *os.File
+	// File - вывод в файл с ротацией, дополнительно к консоли
+	File FileConfig
+	// FileOnly - писать только в файл
+	FileOnly bool
+	// PackageLevels - уровни отдельных пакетов по последнему элементу пути:
+	// {"repo": LevelDebug}
+	PackageLevels map[string]Level
+	// Redaction - что скрывается в записях
+	Redaction RedactionConfig
 }
\ No newline at end of file
@@ -0,0 +1,18 @@
+package logger
+
+import "context"
+
+type contextKey struct{}
+
+// WithContext кладёт в контекст логгер запроса, например с его request_id
+func WithContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext возвращает логгер запроса или fallback, если его нет
+func FromContext(ctx context.Context, fallback *Logger) *Logger {
+	if l, ok := ctx.Value(contextKey{}).(*Logger); ok {
+		return l
+	}
+	return fallback
+}
@@ -0,0 +1,139 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// levels - уровни логгера, общие для него и всех дочерних логгеров;
+// меняются на ходу через SetLevel и SetPackageLevels
+type levels struct {
+	global   slog.LevelVar
+	packages atomic.Pointer[map[string]slog.Level]
+	// pcPackages кеширует пакет по адресу вызова
+	pcPackages sync.Map
+}
+
+func newLevels(global Level, packages map[string]Level) *levels {
+	lv := &levels{}
+	lv.global.Set(global.slogLevel())
+	lv.setPackages(packages)
+	return lv
+}
+
+func (lv *levels) setPackages(packages map[string]Level) {
+	converted := make(map[string]slog.Level, len(packages))
+	for pkg, level := range packages {
+		converted[pkg] = level.slogLevel()
+	}
+	lv.packages.Store(&converted)
+}
+
+// min - самый подробный из уровней; записи ниже него отбрасываются сразу
+func (lv *levels) min() slog.Level {
+	min := lv.global.Level()
+	for _, level := range *lv.packages.Load() {
+		if level < min {
+			min = level
+		}
+	}
+	return min
+}
+
+// forPC - уровень для пакета, из которого сделана запись
+func (lv *levels) forPC(pc uintptr) slog.Level {
+	packages := *lv.packages.Load()
+	if len(packages) == 0 || pc == 0 {
+		return lv.global.Level()
+	}
+	pkg, ok := lv.pcPackages.Load(pc)
+	if !ok {
+		frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		pkg = packageName(frame.Function)
+		lv.pcPackages.Store(pc, pkg)
+	}
+	if level, ok := packages[pkg.(string)]; ok {
+		return level
+	}
+	return lv.global.Level()
+}
+
+// packageName - последний элемент пути пакета функции:
+// ToDo-List/internal/repo.(*PostgreRepo).CreateTodo -> repo
+func packageName(function string) string {
+	if i := strings.LastIndex(function, "/"); i >= 0 {
+		function = function[i+1:]
+	}
+	name, _, _ := strings.Cut(function, ".")
+	return name
+}
+
+// levelHandler отбирает записи по уровню пакета и передаёт их выводам
+type levelHandler struct {
+	next   slog.Handler
+	levels *levels
+}
+
+func (h *levelHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.levels.min()
+}
+
+func (h *levelHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < h.levels.forPC(r.PC) {
+		return nil
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &levelHandler{next: h.next.WithAttrs(attrs), levels: h.levels}
+}
+
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{next: h.next.WithGroup(name), levels: h.levels}
+}
+
+// multiHandler пишет каждую запись во все выводы, например в stdout и файл
+type multiHandler []slog.Handler
+
+func (m multiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range m {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m multiHandler) Handle(ctx context.Context, r slog.Record) error {
+	var firstErr error
+	for _, h := range m {
+		if !h.Enabled(ctx, r.Level) {
+			continue
+		}
+		if err := h.Handle(ctx, r.Clone()); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (m multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(multiHandler, len(m))
+	for i, h := range m {
+		handlers[i] = h.WithAttrs(attrs)
+	}
+	return handlers
+}
+
+func (m multiHandler) WithGroup(name string) slog.Handler {
+	handlers := make(multiHandler, len(m))
+	for i, h := range m {
+		handlers[i] = h.WithGroup(name)
+	}
+	return handlers
+}
@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetLevelAppliesToChildren(t *testing.T) {
+	l, lines := newFileLogger(t, Config{Level: LevelError})
+	child := l.WithFields(map[string]interface{}{"request_id": "r1"})
+	child.Info("hidden")
+
+	l.SetLevel(LevelInfo)
+	child.Info("visible")
+
+	got := lines()
+	if len(got) != 1 || !strings.Contains(got[0], "visible") {
+		t.Errorf("lines = %v, want only the line after SetLevel", got)
+	}
+	if l.Level() != LevelInfo {
+		t.Errorf("Level() = %v, want INFO", l.Level())
+	}
+}
+
+func TestPackageLevels(t *testing.T) {
+	// Запись из этого пакета (logger) подробнее общего уровня
+	l, lines := newFileLogger(t, Config{Level: LevelError, PackageLevels: map[string]Level{"logger": LevelDebug}})
+	l.Debug("package debug")
+
+	l.SetPackageLevels(map[string]Level{"repo": LevelDebug})
+	l.Debug("other package")
+
+	got := lines()
+	if len(got) != 1 || !strings.Contains(got[0], "package debug") {
+		t.Errorf("lines = %v, want only the line while the override was set", got)
+	}
+	if levels := l.PackageLevels(); len(levels) != 1 || levels["repo"] != LevelDebug {
+		t.Errorf("PackageLevels() = %v", levels)
+	}
+}
+
+func TestPackageName(t *testing.T) {
+	tests := map[string]string{
+		"ToDo-List/internal/repo.(*PostgreRepo).CreateTodo": "repo",
+		"main.main": "main",
+		"ToDo-List/internal/application/service.(*TodoService).CreateTodo.func1": "service",
+	}
+	for function, want := range tests {
+		if got := packageName(function); got != want {
+			t.Errorf("packageName(%q) = %q, want %q", function, got, want)
+		}
+	}
+}
@@ -1,57 +1,106 @@
 package logger
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"time"
 )
 
+// Logger - printf-обёртка над slog.Handler: записи проходят через тот же
+// обработчик, что и у slog.Logger из Slog(), поэтому логи приложения и
+// сторонних библиотек выходят в одном формате. Дочерние логгеры WithFields
+// разделяют с родителем уровни и выводы.
 type Logger struct {
-	*log.Logger
-	level Level
+	handler  slog.Handler
+	levels   *levels
+	redactor *redactor
+	file     *rotatingFile
 }
 
-func New(config Config) *Logger {
-	output := config.Output
-	if output == nil {
-		output = os.Stdout
+// New создаёт логгер с выводом в консоль и, если задан File.Path, в файл
+func New(config Config) (*Logger, error) {
+	redactor, err := newRedactor(config.Redaction)
+	if err != nil {
+		return nil, err
 	}
 
+	var sinks multiHandler
+	if !config.FileOnly {
+		output := config.Output
+		if output == nil {
+			output = os.Stdout
+		}
+		sinks = append(sinks, newSink(output, config.Format, isTerminal(output)))
+	}
+
+	var file *rotatingFile
+	if config.File.Path != "" {
+		if file, err = openRotatingFile(config.File); err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, newSink(file, config.Format, false))
+	}
+	if len(sinks) == 0 {
+		return nil, fmt.Errorf("no log output: FileOnly requires File.Path")
+	}
+
+	lv := newLevels(config.Level, config.PackageLevels)
+	var handler slog.Handler = sinks
+	if len(sinks) == 1 {
+		handler = sinks[0]
+	}
+	// Секреты скрываются до записи в любой вывод
+	handler = &redactHandler{next: handler, redactor: redactor}
 	return &Logger{
-		Logger: log.New(output, "", 0), 
-		level:  config.Level,
+		handler:  &levelHandler{next: handler, levels: lv},
+		levels:   lv,
+		redactor: redactor,
+		file:     file,
+	}, nil
+}
+
+// newSink - обработчик одного вывода. Уровень отбирает levelHandler, поэтому
+// вывод принимает всё начиная с DEBUG.
+func newSink(output io.Writer, format Format, color bool) slog.Handler {
+	options := &slog.HandlerOptions{
+		AddSource:   true,
+		Level:       slog.LevelDebug,
+		ReplaceAttr: replaceAttr,
+	}
+	switch format {
+	case FormatJSON:
+		return slog.NewJSONHandler(output, options)
+	case FormatLogfmt:
+		return slog.NewTextHandler(output, options)
+	default:
+		return newTextHandler(output, slog.LevelDebug, color)
 	}
 }
 
 // Debug логирует сообщение на уровне DEBUG
 func (l *Logger) Debug(format string, v ...interface{}) {
-	if l.level <= LevelDebug {
-		l.log(LevelDebug, format, v...)
-	}
+	l.log(LevelDebug, format, v...)
 }
 
 // Info логирует сообщение на уровне INFO
 func (l *Logger) Info(format string, v ...interface{}) {
-	if l.level <= LevelInfo {
-		l.log(LevelInfo, format, v...)
-	}
+	l.log(LevelInfo, format, v...)
 }
 
 // Warn логирует сообщение на уровне WARN
 func (l *Logger) Warn(format string, v ...interface{}) {
-	if l.level <= LevelWarn {
-		l.log(LevelWarn, format, v...)
-	}
+	l.log(LevelWarn, format, v...)
 }
 
 // Error логирует сообщение на уровне ERROR
 func (l *Logger) Error(format string, v ...interface{}) {
-	if l.level <= LevelError {
-		l.log(LevelError, format, v...)
-	}
+	l.log(LevelError, format, v...)
 }
 
 // Fatal логирует сообщение на уровне FATAL и завершает программу
@@ -60,48 +109,111 @@ func (l *Logger) Fatal(format string, v ...interface{}) {
 	os.Exit(1)
 }
 
-// log внутренний метод для форматирования и вывода логов
+// log внутренний метод для форматирования и передачи записи обработчику
 func (l *Logger) log(level Level, format string, v ...interface{}) {
-	// Получаем информацию о caller'е
-	_, file, line, ok := runtime.Caller(2)
-	if !ok {
-		file = "unknown"
-		line = 0
-	} else {
-		// Оставляем только имя файла, а не полный путь
-		file = filepath.Base(file)
-	}
-
-	// Форматируем сообщение
-	message := fmt.Sprintf(format, v...)
-	timestamp := time.Now().Format("2006-01-02 15:04:05.000")
-	
-	// Цвета для терминала (опционально)
-	var colorCode string
-	switch level {
-	case LevelDebug:
-		colorCode = "\033[36m" // Cyan
-	case LevelInfo:
-		colorCode = "\033[32m" // Green
-	case LevelWarn:
-		colorCode = "\033[33m" // Yellow
-	case LevelError:
-		colorCode = "\033[31m" // Red
-	case LevelFatal:
-		colorCode = "\033[35m" // Magenta
-	}
-	resetCode := "\033[0m"
-
-	// Форматированная строка лога
-	logEntry := fmt.Sprintf("%s%s [%s] %s:%d - %s%s", 
-		colorCode, timestamp, level, file, line, message, resetCode)
-
-	l.Logger.Println(logEntry)
-}
-
-// WithFields создает логгер с дополнительными полями (для будущего расширения)
+	ctx := context.Background()
+	if !l.handler.Enabled(ctx, level.slogLevel()) {
+		return
+	}
+
+	// Пропускаем runtime.Callers, log и публичный метод - остаётся caller
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+
+	message := fmt.Sprintf(format, l.redactor.args(v)...)
+	record := slog.NewRecord(time.Now(), level.slogLevel(), message, pcs[0])
+	_ = l.handler.Handle(ctx, record)
+}
+
+// WithFields создает дочерний логгер, добавляющий поля к каждой записи
 func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
-	// Для простоты возвращаем тот же логгер
-	// В реальном проекте можно реализовать контекстное логирование
-	return l
-}
\ No newline at end of file
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	attrs := make([]slog.Attr, 0, len(keys))
+	for _, key := range keys {
+		attrs = append(attrs, slog.Any(key, fields[key]))
+	}
+	return &Logger{handler: l.handler.WithAttrs(attrs), levels: l.levels, redactor: l.redactor, file: l.file}
+}
+
+// Slog возвращает slog.Logger, пишущий через этот логгер; подходит для
+// slog.SetDefault и библиотек, принимающих *slog.Logger
+func (l *Logger) Slog() *slog.Logger {
+	return slog.New(l.handler)
+}
+
+// Level - текущий общий уровень
+func (l *Logger) Level() Level {
+	return levelFromSlog(l.levels.global.Level())
+}
+
+// SetLevel меняет общий уровень логгера и всех дочерних логгеров
+func (l *Logger) SetLevel(level Level) {
+	l.levels.global.Set(level.slogLevel())
+}
+
+// PackageLevels - уровни, переопределённые для пакетов
+func (l *Logger) PackageLevels() map[string]Level {
+	packages := *l.levels.packages.Load()
+	result := make(map[string]Level, len(packages))
+	for pkg, level := range packages {
+		result[pkg] = levelFromSlog(level)
+	}
+	return result
+}
+
+// SetPackageLevels заменяет уровни пакетов; пустая карта убирает переопределения
+func (l *Logger) SetPackageLevels(packages map[string]Level) {
+	l.levels.setPackages(packages)
+}
+
+// Reopen заново открывает файл лога, например после внешнего logrotate
+func (l *Logger) Reopen() error {
+	if l.file == nil {
+		return nil
+	}
+	return l.file.Reopen()
+}
+
+// Close закрывает файл лога; вызывается при остановке приложения
+func (l *Logger) Close() error {
+	if l.file == nil {
+		return nil
+	}
+	return l.file.Close()
+}
+
+// replaceAttr приводит уровень и источник в JSON и logfmt к виду текстового формата
+func replaceAttr(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) > 0 {
+		return a
+	}
+	switch a.Key {
+	case slog.LevelKey:
+		if level, ok := a.Value.Any().(slog.Level); ok {
+			a.Value = slog.StringValue(levelFromSlog(level).String())
+		}
+	case slog.SourceKey:
+		if source, ok := a.Value.Any().(*slog.Source); ok {
+			// Записи из пакета log приходят без источника
+			if source.File == "" {
+				return slog.Attr{}
+			}
+			a.Value = slog.StringValue(fmt.Sprintf("%s:%d", filepath.Base(source.File), source.Line))
+		}
+	}
+	return a
+}
+
+// isTerminal - вывод идёт в терминал, а не в файл или в логи Docker
+func isTerminal(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,144 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newFileLogger пишет в файл во временном каталоге; lines читает записанное
+func newFileLogger(t *testing.T, config Config) (*Logger, func() []string) {
+	t.Helper()
+	output, err := os.Create(filepath.Join(t.TempDir(), "out.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { output.Close() })
+	config.Output = output
+	l, err := New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l, func() []string {
+		data, err := os.ReadFile(output.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var lines []string
+		scanner := bufio.NewScanner(strings.NewReader(string(data)))
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		return lines
+	}
+}
+
+func TestJSONFormat(t *testing.T) {
+	l, lines := newFileLogger(t, Config{Level: LevelInfo, Format: FormatJSON})
+	l.WithFields(map[string]interface{}{"request_id": "r1", "user": "u1"}).Warn("todo %d not found", 42)
+
+	got := lines()
+	if len(got) != 1 {
+		t.Fatalf("got %d lines, want 1: %v", len(got), got)
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(got[0]), &entry); err != nil {
+		t.Fatalf("line is not JSON: %v: %s", err, got[0])
+	}
+	want := map[string]string{"level": "WARN", "msg": "todo 42 not found", "request_id": "r1", "user": "u1"}
+	for key, value := range want {
+		if entry[key] != value {
+			t.Errorf("%s = %v, want %q", key, entry[key], value)
+		}
+	}
+	// Источник - место вызова, а не обёртка логгера
+	if source, _ := entry["source"].(string); !strings.HasPrefix(source, "logger_test.go:") {
+		t.Errorf("source = %v, want logger_test.go:<line>", entry["source"])
+	}
+	if strings.Contains(got[0], "\x1b[") {
+		t.Error("JSON line contains colour codes")
+	}
+}
+
+func TestTextFormatHasNoColourInFile(t *testing.T) {
+	l, lines := newFileLogger(t, Config{Level: LevelInfo})
+	l.WithFields(map[string]interface{}{"todo_id": 7}).Info("created")
+
+	got := lines()
+	if len(got) != 1 {
+		t.Fatalf("got %d lines, want 1: %v", len(got), got)
+	}
+	if strings.Contains(got[0], "\x1b[") {
+		t.Errorf("colour codes written to a file: %q", got[0])
+	}
+	if !strings.Contains(got[0], "[INFO]") || !strings.Contains(got[0], "created") || !strings.Contains(got[0], "todo_id=7") {
+		t.Errorf("unexpected line %q", got[0])
+	}
+}
+
+func TestWithFieldsDoesNotChangeParent(t *testing.T) {
+	l, lines := newFileLogger(t, Config{Level: LevelInfo, Format: FormatLogfmt})
+	child := l.WithFields(map[string]interface{}{"request_id": "r1"})
+	child.WithFields(map[string]interface{}{"todo_id": 7}).Info("grandchild")
+	child.Info("child")
+	l.Info("parent")
+
+	got := lines()
+	if len(got) != 3 {
+		t.Fatalf("got %d lines, want 3: %v", len(got), got)
+	}
+	if !strings.Contains(got[0], "request_id=r1") || !strings.Contains(got[0], "todo_id=7") {
+		t.Errorf("grandchild lost fields: %q", got[0])
+	}
+	if !strings.Contains(got[1], "request_id=r1") || strings.Contains(got[1], "todo_id") {
+		t.Errorf("child fields wrong: %q", got[1])
+	}
+	if strings.Contains(got[2], "request_id") {
+		t.Errorf("parent got child fields: %q", got[2])
+	}
+}
+
+func TestLevelFilter(t *testing.T) {
+	l, lines := newFileLogger(t, Config{Level: LevelWarn})
+	l.Debug("debug")
+	l.Info("info")
+	l.Warn("warn")
+	l.Error("error")
+
+	got := lines()
+	if len(got) != 2 || !strings.Contains(got[0], "warn") || !strings.Contains(got[1], "error") {
+		t.Errorf("lines = %v, want warn and error only", got)
+	}
+}
+
+func TestSlogSharesOutput(t *testing.T) {
+	l, lines := newFileLogger(t, Config{Level: LevelInfo, Format: FormatJSON})
+	l.Slog().Info("from library", "component", "pgx")
+
+	got := lines()
+	if len(got) != 1 {
+		t.Fatalf("got %d lines, want 1: %v", len(got), got)
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(got[0]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry["level"] != "INFO" || entry["component"] != "pgx" {
+		t.Errorf("entry = %v", entry)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for input, want := range map[string]Format{"": FormatText, "text": FormatText, "JSON": FormatJSON, "logfmt": FormatLogfmt} {
+		got, err := ParseFormat(input)
+		if err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %v, %v; want %v", input, got, err, want)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("unknown format accepted")
+	}
+}
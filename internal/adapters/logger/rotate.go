@@ -0,0 +1,195 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileConfig - вывод логов в файл с ротацией
+type FileConfig struct {
+	Path string
+	// MaxSize - размер файла в байтах, после которого он ротируется; 0 - без ограничения
+	MaxSize int64
+	// MaxAge - сколько пишется в один файл до ротации; 0 - без ограничения
+	MaxAge time.Duration
+	// MaxBackups - сколько ротированных файлов хранить; 0 - все
+	MaxBackups int
+	// Compress - сжимать ротированные файлы gzip
+	Compress bool
+}
+
+// backupTimeFormat - метка времени в имени ротированного файла: todo-20240102T150405.000.log
+const backupTimeFormat = "20060102T150405.000"
+
+// rotatingFile - io.Writer, переименовывающий файл при превышении размера
+// или возраста и открывающий новый
+type rotatingFile struct {
+	cfg FileConfig
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	opened time.Time
+
+	// background - сжатие и удаление старых файлов после ротации
+	background sync.WaitGroup
+}
+
+func openRotatingFile(cfg FileConfig) (*rotatingFile, error) {
+	f := &rotatingFile{cfg: cfg}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *rotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.cfg.Path), 0o755); err != nil {
+		return fmt.Errorf("create log directory: %w", err)
+	}
+	file, err := os.OpenFile(f.cfg.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("stat log file: %w", err)
+	}
+	f.file = file
+	f.size = info.Size()
+	f.opened = time.Now()
+	return nil
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	if f.needsRotation(len(p)) {
+		if err := f.rotate(); err != nil {
+			// Лучше продолжить писать в старый файл, чем потерять запись
+			fmt.Fprintf(os.Stderr, "log rotation failed: %v\n", err)
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *rotatingFile) needsRotation(next int) bool {
+	if f.size == 0 {
+		return false
+	}
+	if f.cfg.MaxSize > 0 && f.size+int64(next) > f.cfg.MaxSize {
+		return true
+	}
+	return f.cfg.MaxAge > 0 && time.Since(f.opened) >= f.cfg.MaxAge
+}
+
+func (f *rotatingFile) rotate() error {
+	ext := filepath.Ext(f.cfg.Path)
+	backup := strings.TrimSuffix(f.cfg.Path, ext) + "-" + time.Now().Format(backupTimeFormat) + ext
+
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	renameErr := os.Rename(f.cfg.Path, backup)
+	if err := f.open(); err != nil {
+		return err
+	}
+	if renameErr != nil {
+		return renameErr
+	}
+
+	f.background.Add(1)
+	go func() {
+		defer f.background.Done()
+		if f.cfg.Compress {
+			if err := compressFile(backup); err != nil {
+				fmt.Fprintf(os.Stderr, "log compression failed: %v\n", err)
+			}
+		}
+		f.removeOldBackups()
+	}()
+	return nil
+}
+
+// removeOldBackups оставляет MaxBackups самых новых ротированных файлов
+func (f *rotatingFile) removeOldBackups() {
+	if f.cfg.MaxBackups <= 0 {
+		return
+	}
+	ext := filepath.Ext(f.cfg.Path)
+	backups, err := filepath.Glob(strings.TrimSuffix(f.cfg.Path, ext) + "-*" + ext + "*")
+	if err != nil || len(backups) <= f.cfg.MaxBackups {
+		return
+	}
+	// Метка времени в имени сортируется так же, как время
+	sort.Strings(backups)
+	for _, old := range backups[:len(backups)-f.cfg.MaxBackups] {
+		os.Remove(old)
+	}
+}
+
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// Reopen закрывает и заново открывает файл по тому же пути - после ротации
+// внешним logrotate
+func (f *rotatingFile) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file != nil {
+		f.file.Close()
+	}
+	return f.open()
+}
+
+// Close закрывает файл и дожидается сжатия ротированных файлов
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	var err error
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+	}
+	f.mu.Unlock()
+	f.background.Wait()
+	return err
+}
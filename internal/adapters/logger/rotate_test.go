@@ -0,0 +1,156 @@
+package logger
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func backups(t *testing.T, path, suffix string) []string {
+	t.Helper()
+	ext := filepath.Ext(path)
+	matches, err := filepath.Glob(strings.TrimSuffix(path, ext) + "-*" + ext + suffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return matches
+}
+
+// writeLines пишет строки с паузой, чтобы метки времени в именах
+// ротированных файлов не совпадали
+func writeLines(t *testing.T, f *rotatingFile, n int, line string) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+}
+
+func TestRotateBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "todo.log")
+	f, err := openRotatingFile(FileConfig{Path: path, MaxSize: 25})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeLines(t, f, 3, "0123456789\n")
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 11 + 11 помещаются в 25 байт, третья строка уходит в новый файл
+	if got := backups(t, path, ""); len(got) != 1 {
+		t.Errorf("backups = %v, want 1", got)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "0123456789\n" {
+		t.Errorf("current file = %q, want the last line only", data)
+	}
+}
+
+func TestRotateByAge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todo.log")
+	f, err := openRotatingFile(FileConfig{Path: path, MaxAge: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeLines(t, f, 1, "first\n")
+	time.Sleep(20 * time.Millisecond)
+	writeLines(t, f, 1, "second\n")
+	f.Close()
+
+	if got := backups(t, path, ""); len(got) != 1 {
+		t.Errorf("backups = %v, want 1", got)
+	}
+}
+
+func TestRotateCompressesAndKeepsMaxBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todo.log")
+	f, err := openRotatingFile(FileConfig{Path: path, MaxSize: 1, MaxBackups: 2, Compress: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeLines(t, f, 5, "line\n")
+	// Close дожидается фонового сжатия и удаления
+	f.Close()
+
+	compressed := backups(t, path, ".gz")
+	if len(compressed) != 2 {
+		t.Fatalf("compressed backups = %v, want 2", compressed)
+	}
+	if plain := backups(t, path, ""); len(plain) != 0 {
+		t.Errorf("uncompressed backups left: %v", plain)
+	}
+	src, err := os.Open(compressed[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+	gz, err := gzip.NewReader(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := io.ReadAll(gz); string(data) != "line\n" {
+		t.Errorf("backup content = %q", data)
+	}
+}
+
+func TestReopenAfterExternalRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "todo.log")
+	l, err := New(Config{Level: LevelInfo, FileOnly: true, File: FileConfig{Path: path}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	l.Info("before")
+	// logrotate переименовывает файл, запись продолжается в старый дескриптор
+	if err := os.Rename(path, filepath.Join(dir, "todo.log.1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	l.Info("after")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "before") || !strings.Contains(string(data), "after") {
+		t.Errorf("reopened file = %q, want only the line after reopen", data)
+	}
+}
+
+func TestConsoleAndFileSinks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todo.log")
+	l, lines := newFileLogger(t, Config{Level: LevelInfo, Format: FormatJSON, File: FileConfig{Path: path}})
+	l.Info("both")
+	l.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := lines(); len(got) != 1 || !strings.Contains(got[0], `"msg":"both"`) {
+		t.Errorf("console = %v", got)
+	}
+	if !strings.Contains(string(data), `"msg":"both"`) {
+		t.Errorf("file = %q", data)
+	}
+}
+
+func TestFileOnlyRequiresPath(t *testing.T) {
+	if _, err := New(Config{FileOnly: true}); err == nil {
+		t.Error("FileOnly without File.Path accepted")
+	}
+}
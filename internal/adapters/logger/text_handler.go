@@ -0,0 +1,130 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// textHandler пишет строки вида
+// 2024-01-02 15:04:05.000 [INFO] main.go:42 - message key=value
+type textHandler struct {
+	mu    *sync.Mutex
+	out   io.Writer
+	level slog.Leveler
+	color bool
+
+	attrs  string // уже отформатированные поля WithAttrs
+	prefix string // группы WithGroup: "group."
+}
+
+func newTextHandler(out io.Writer, level slog.Leveler, color bool) *textHandler {
+	return &textHandler{mu: &sync.Mutex{}, out: out, level: level, color: color}
+}
+
+func (h *textHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *textHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	var b strings.Builder
+	for _, a := range attrs {
+		appendAttr(&b, h.prefix, a)
+	}
+	child := *h
+	child.attrs += b.String()
+	return &child
+}
+
+func (h *textHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	child := *h
+	child.prefix += name + "."
+	return &child
+}
+
+func (h *textHandler) Handle(_ context.Context, r slog.Record) error {
+	file, line := "unknown", 0
+	if r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		// Оставляем только имя файла, а не полный путь
+		file, line = filepath.Base(frame.File), frame.Line
+	}
+	level := levelFromSlog(r.Level)
+
+	var b strings.Builder
+	if h.color {
+		b.WriteString(colorCode(level))
+	}
+	fmt.Fprintf(&b, "%s [%s] %s:%d - %s", r.Time.Format("2006-01-02 15:04:05.000"), level, file, line, r.Message)
+	b.WriteString(h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		appendAttr(&b, h.prefix, a)
+		return true
+	})
+	if h.color {
+		b.WriteString("\033[0m")
+	}
+	b.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.out, b.String())
+	return err
+}
+
+// appendAttr дописывает " key=value"; группы раскрываются в ключи через точку
+func appendAttr(b *strings.Builder, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, attr := range a.Value.Group() {
+			appendAttr(b, prefix, attr)
+		}
+		return
+	}
+
+	var value string
+	if a.Value.Kind() == slog.KindTime {
+		value = a.Value.Time().Format(time.RFC3339Nano)
+	} else {
+		value = a.Value.String()
+	}
+	if value == "" || strings.ContainsAny(value, " =\"\t\n") {
+		value = strconv.Quote(value)
+	}
+	fmt.Fprintf(b, " %s%s=%s", prefix, a.Key, value)
+}
+
+// colorCode - цвет уровня для терминала
+func colorCode(level Level) string {
+	switch level {
+	case LevelDebug:
+		return "\033[36m" // Cyan
+	case LevelInfo:
+		return "\033[32m" // Green
+	case LevelWarn:
+		return "\033[33m" // Yellow
+	case LevelError:
+		return "\033[31m" // Red
+	default:
+		return "\033[35m" // Magenta
+	}
+}
@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ToDo-List/internal/core/domain"
+)
+
+// namespace - префикс имён всех метрик приложения
+const namespace = "todo"
+
+// httpBuckets - границы гистограммы длительности HTTP-запросов, секунды
+var httpBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// dbBuckets - границы гистограммы длительности запросов к БД, секунды
+var dbBuckets = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5}
+
+// Metrics - метрики приложения: HTTP, запросы к БД, пул соединений и задачи
+type Metrics struct {
+	registry *Registry
+
+	httpRequests *CounterVec
+	httpDuration *HistogramVec
+	dbDuration   *HistogramVec
+	dbErrors     *CounterVec
+	todos        *GaugeVec
+}
+
+func New() *Metrics {
+	r := NewRegistry()
+	m := &Metrics{
+		registry: r,
+		httpRequests: r.NewCounterVec(namespace+"_http_requests_total",
+			"HTTP requests by method, route template and status code.", "method", "route", "status"),
+		httpDuration: r.NewHistogramVec(namespace+"_http_request_duration_seconds",
+			"HTTP request latency by method, route template and status code.", httpBuckets, "method", "route", "status"),
+		dbDuration: r.NewHistogramVec(namespace+"_db_query_duration_seconds",
+			"Database query latency by repository method.", dbBuckets, "repo", "method"),
+		dbErrors: r.NewCounterVec(namespace+"_db_query_errors_total",
+			"Failed database queries by repository method.", "repo", "method"),
+		todos: r.NewGaugeVec(namespace+"_todos",
+			"Todos across all workspaces by state (open, overdue, completed); overdue todos are also open.", "state"),
+	}
+	return m
+}
+
+// ServeHTTP отдаёт метрики в текстовом формате Prometheus
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.registry.ServeHTTP(w, r)
+}
+
+// ObserveRequest учитывает обработанный HTTP-запрос; route - шаблон маршрута,
+// а не путь, чтобы число рядов не зависело от id в URL
+func (m *Metrics) ObserveRequest(method, route string, status int, duration time.Duration) {
+	method = methodLabel(method)
+	statusStr := strconv.Itoa(status)
+	m.httpRequests.Inc(method, route, statusStr)
+	m.httpDuration.Observe(duration.Seconds(), method, route, statusStr)
+}
+
+// methodLabel - нестандартные методы учитываются как OTHER: иначе клиент
+// создавал бы новые ряды произвольными методами
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	}
+	return "OTHER"
+}
+
+// ObserveQuery учитывает запрос к БД (repo.QueryObserver)
+func (m *Metrics) ObserveQuery(repo, method string, duration time.Duration, err error) {
+	m.dbDuration.Observe(duration.Seconds(), repo, method)
+	if err != nil {
+		m.dbErrors.Inc(repo, method)
+	}
+}
+
+// SetTodoCounts обновляет число задач по состояниям (ports.TodoGauges)
+func (m *Metrics) SetTodoCounts(counts domain.TodoCounts) {
+	m.todos.Set(float64(counts.Open), "open")
+	m.todos.Set(float64(counts.Overdue), "overdue")
+	m.todos.Set(float64(counts.Completed), "completed")
+}
+
+// RegisterDBStats добавляет метрики пула соединений из sql.DBStats; значения
+// читаются при каждом опросе
+func (m *Metrics) RegisterDBStats(db *sql.DB) {
+	stat := func(fn func(s sql.DBStats) float64) func() float64 {
+		return func() float64 { return fn(db.Stats()) }
+	}
+	r := m.registry
+	r.NewGaugeFunc(namespace+"_db_max_open_connections", "Maximum number of open connections to the database.",
+		stat(func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) }))
+	r.NewGaugeFunc(namespace+"_db_open_connections", "Established connections, both in use and idle.",
+		stat(func(s sql.DBStats) float64 { return float64(s.OpenConnections) }))
+	r.NewGaugeFunc(namespace+"_db_in_use_connections", "Connections currently in use.",
+		stat(func(s sql.DBStats) float64 { return float64(s.InUse) }))
+	r.NewGaugeFunc(namespace+"_db_idle_connections", "Idle connections.",
+		stat(func(s sql.DBStats) float64 { return float64(s.Idle) }))
+	r.NewCounterFunc(namespace+"_db_wait_count_total", "Connections waited for.",
+		stat(func(s sql.DBStats) float64 { return float64(s.WaitCount) }))
+	r.NewCounterFunc(namespace+"_db_wait_duration_seconds_total", "Time blocked waiting for a new connection.",
+		stat(func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() }))
+	r.NewCounterFunc(namespace+"_db_max_idle_closed_total", "Connections closed due to SetMaxIdleConns.",
+		stat(func(s sql.DBStats) float64 { return float64(s.MaxIdleClosed) }))
+	r.NewCounterFunc(namespace+"_db_max_idle_time_closed_total", "Connections closed due to SetConnMaxIdleTime.",
+		stat(func(s sql.DBStats) float64 { return float64(s.MaxIdleTimeClosed) }))
+	r.NewCounterFunc(namespace+"_db_max_lifetime_closed_total", "Connections closed due to SetConnMaxLifetime.",
+		stat(func(s sql.DBStats) float64 { return float64(s.MaxLifetimeClosed) }))
+}
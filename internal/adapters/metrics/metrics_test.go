@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"ToDo-List/internal/core/domain"
+)
+
+func TestMetrics(t *testing.T) {
+	m := New()
+	m.ObserveRequest(http.MethodGet, "/api/todos/{id}", http.StatusOK, 20*time.Millisecond)
+	m.ObserveRequest("PROPFIND", "/api/todos", http.StatusMethodNotAllowed, time.Millisecond)
+	m.ObserveRequest("get", "/api/todos", http.StatusMethodNotAllowed, time.Millisecond)
+	m.ObserveQuery("PostgreRepo", "CreateTodo", 3*time.Millisecond, nil)
+	m.ObserveQuery("PostgreRepo", "CreateTodo", time.Millisecond, errors.New("connection reset"))
+	m.SetTodoCounts(domain.TodoCounts{Open: 5, Overdue: 2, Completed: 7})
+
+	out := scrape(t, m)
+	for _, want := range []string{
+		`todo_http_requests_total{method="GET",route="/api/todos/{id}",status="200"} 1`,
+		// нестандартные методы не создают новых рядов
+		`todo_http_requests_total{method="OTHER",route="/api/todos",status="405"} 2`,
+		`todo_http_request_duration_seconds_bucket{method="GET",route="/api/todos/{id}",status="200",le="0.025"} 1`,
+		`todo_http_request_duration_seconds_bucket{method="GET",route="/api/todos/{id}",status="200",le="0.01"} 0`,
+		`todo_db_query_duration_seconds_count{repo="PostgreRepo",method="CreateTodo"} 2`,
+		`todo_db_query_errors_total{repo="PostgreRepo",method="CreateTodo"} 1`,
+		`todo_todos{state="open"} 5`,
+		`todo_todos{state="overdue"} 2`,
+		`todo_todos{state="completed"} 7`,
+	} {
+		if !strings.Contains(out, want+"\n") {
+			t.Errorf("exposition lacks %s:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "PROPFIND") || strings.Contains(out, `method="get"`) {
+		t.Errorf("exposition contains a non-standard method:\n%s", out)
+	}
+}
@@ -0,0 +1,289 @@
+// Package metrics - метрики в текстовом формате Prometheus (0.0.4) без
+// внешних зависимостей: счётчики, гистограммы и датчики с метками.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Registry хранит метрики и отдаёт их по HTTP
+type Registry struct {
+	mu         sync.Mutex
+	collectors []collector
+}
+
+type collector interface {
+	write(w *bufio.Writer)
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.collectors = append(r.collectors, c)
+}
+
+// ServeHTTP отдаёт все метрики в текстовом формате Prometheus
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	r.mu.Lock()
+	collectors := append([]collector(nil), r.collectors...)
+	r.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	bw := bufio.NewWriter(w)
+	for _, c := range collectors {
+		c.write(bw)
+	}
+	bw.Flush()
+}
+
+// desc - имя, описание и метки метрики
+type desc struct {
+	name   string
+	help   string
+	kind   string // counter, gauge, histogram
+	labels []string
+}
+
+func (d desc) writeHeader(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", d.name, escapeHelp(d.help), d.name, d.kind)
+}
+
+// series - значения метки одного ряда; ключ - значения через \xff
+type series struct {
+	labelValues []string
+}
+
+func seriesKey(d desc, labelValues []string) string {
+	if len(labelValues) != len(d.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", d.name, len(d.labels), len(labelValues)))
+	}
+	return strings.Join(labelValues, "\xff")
+}
+
+// sortedKeys - ряды выводятся в стабильном порядке
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatLabels - {a="1",b="2"}; extra добавляется последней парой (le для гистограмм)
+func formatLabels(names, values []string, extraName, extraValue string) string {
+	if len(names) == 0 && extraName == "" {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=\"%s\"", name, escapeLabel(values[i]))
+	}
+	if extraName != "" {
+		if len(names) > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=\"%s\"", extraName, extraValue)
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
+
+// CounterVec - монотонно растущие счётчики с метками
+type CounterVec struct {
+	desc
+	mu     sync.Mutex
+	values map[string]*counterSeries
+}
+
+type counterSeries struct {
+	series
+	value float64
+}
+
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{
+		desc:   desc{name: name, help: help, kind: "counter", labels: labels},
+		values: make(map[string]*counterSeries),
+	}
+	r.register(c)
+	return c
+}
+
+// Inc увеличивает счётчик ряда с указанными значениями меток на 1
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *CounterVec) Add(v float64, labelValues ...string) {
+	key := seriesKey(c.desc, labelValues)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.values[key]
+	if !ok {
+		s = &counterSeries{series: series{labelValues: labelValues}}
+		c.values[key] = s
+	}
+	s.value += v
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.writeHeader(w)
+	for _, key := range sortedKeys(c.values) {
+		s := c.values[key]
+		fmt.Fprintf(w, "%s%s %s\n", c.name, formatLabels(c.labels, s.labelValues, "", ""), formatFloat(s.value))
+	}
+}
+
+// GaugeVec - значения с метками, которые задаются целиком
+type GaugeVec struct {
+	desc
+	mu     sync.Mutex
+	values map[string]*counterSeries
+}
+
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{
+		desc:   desc{name: name, help: help, kind: "gauge", labels: labels},
+		values: make(map[string]*counterSeries),
+	}
+	r.register(g)
+	return g
+}
+
+func (g *GaugeVec) Set(v float64, labelValues ...string) {
+	key := seriesKey(g.desc, labelValues)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	s, ok := g.values[key]
+	if !ok {
+		s = &counterSeries{series: series{labelValues: labelValues}}
+		g.values[key] = s
+	}
+	s.value = v
+}
+
+func (g *GaugeVec) write(w *bufio.Writer) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.writeHeader(w)
+	for _, key := range sortedKeys(g.values) {
+		s := g.values[key]
+		fmt.Fprintf(w, "%s%s %s\n", g.name, formatLabels(g.labels, s.labelValues, "", ""), formatFloat(s.value))
+	}
+}
+
+// funcMetric - значение без меток, которое читается при каждом опросе
+type funcMetric struct {
+	desc
+	fn func() float64
+}
+
+// NewGaugeFunc регистрирует датчик, значение которого возвращает fn
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) {
+	r.register(&funcMetric{desc: desc{name: name, help: help, kind: "gauge"}, fn: fn})
+}
+
+// NewCounterFunc регистрирует счётчик, который ведётся вне реестра (например, sql.DBStats)
+func (r *Registry) NewCounterFunc(name, help string, fn func() float64) {
+	r.register(&funcMetric{desc: desc{name: name, help: help, kind: "counter"}, fn: fn})
+}
+
+func (f *funcMetric) write(w *bufio.Writer) {
+	f.writeHeader(w)
+	fmt.Fprintf(w, "%s %s\n", f.name, formatFloat(f.fn()))
+}
+
+// HistogramVec - распределение значений (длительностей) по корзинам с метками
+type HistogramVec struct {
+	desc
+	buckets []float64
+	mu      sync.Mutex
+	values  map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	series
+	counts []uint64 // по корзинам, не накопленные
+	sum    float64
+	count  uint64
+}
+
+// NewHistogramVec - buckets - верхние границы корзин по возрастанию; +Inf добавляется сам
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{
+		desc:    desc{name: name, help: help, kind: "histogram", labels: labels},
+		buckets: buckets,
+		values:  make(map[string]*histogramSeries),
+	}
+	r.register(h)
+	return h
+}
+
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	key := seriesKey(h.desc, labelValues)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.values[key]
+	if !ok {
+		s = &histogramSeries{series: series{labelValues: labelValues}, counts: make([]uint64, len(h.buckets))}
+		h.values[key] = s
+	}
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		s.counts[i]++
+	}
+	s.sum += v
+	s.count++
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.writeHeader(w)
+	for _, key := range sortedKeys(h.values) {
+		s := h.values[key]
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += s.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(h.labels, s.labelValues, "le", formatFloat(bound)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(h.labels, s.labelValues, "le", "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, formatLabels(h.labels, s.labelValues, "", ""), formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, formatLabels(h.labels, s.labelValues, "", ""), s.count)
+	}
+}
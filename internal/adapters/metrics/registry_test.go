@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func scrape(t *testing.T, h http.Handler) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if got := rec.Header().Get("Content-Type"); got != "text/plain; version=0.0.4; charset=utf-8" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	return rec.Body.String()
+}
+
+func TestRegistryExposition(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounterVec("app_requests_total", "Requests by path.\nSecond line with \\.", "path", "code")
+	requests.Inc("/b", "200")
+	requests.Add(2, "/a", "500")
+	requests.Inc("/a", "500")
+	requests.Inc(`/q"x\y`+"\n", "200")
+
+	temperature := r.NewGaugeVec("app_temperature", "Current temperature.", "room")
+	temperature.Set(21.5, "kitchen")
+	temperature.Set(-3, "cellar")
+	temperature.Set(19, "kitchen")
+
+	r.NewGaugeFunc("app_up", "Always up.", func() float64 { return 1 })
+	r.NewCounterFunc("app_ticks_total", "Ticks.", func() float64 { return 1e21 })
+
+	latency := r.NewHistogramVec("app_latency_seconds", "Latency.", []float64{0.1, 0.5, 1}, "op")
+	for _, v := range []float64{0.05, 0.1, 0.3, 0.7, 2} {
+		latency.Observe(v, "read")
+	}
+
+	want := `# HELP app_requests_total Requests by path.\nSecond line with \\.
+# TYPE app_requests_total counter
+app_requests_total{path="/a",code="500"} 3
+app_requests_total{path="/b",code="200"} 1
+app_requests_total{path="/q\"x\\y\n",code="200"} 1
+# HELP app_temperature Current temperature.
+# TYPE app_temperature gauge
+app_temperature{room="cellar"} -3
+app_temperature{room="kitchen"} 19
+# HELP app_up Always up.
+# TYPE app_up gauge
+app_up 1
+# HELP app_ticks_total Ticks.
+# TYPE app_ticks_total counter
+app_ticks_total 1e+21
+# HELP app_latency_seconds Latency.
+# TYPE app_latency_seconds histogram
+app_latency_seconds_bucket{op="read",le="0.1"} 2
+app_latency_seconds_bucket{op="read",le="0.5"} 3
+app_latency_seconds_bucket{op="read",le="1"} 4
+app_latency_seconds_bucket{op="read",le="+Inf"} 5
+app_latency_seconds_sum{op="read"} 3.15
+app_latency_seconds_count{op="read"} 5
+`
+	if got := scrape(t, r); got != want {
+		t.Errorf("exposition:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRegistryEmptyAndUnlabeled(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounterVec("empty_total", "No series yet.", "label")
+	h := r.NewHistogramVec("unlabeled_seconds", "Without labels.", []float64{1})
+	h.Observe(0.5)
+
+	want := `# HELP empty_total No series yet.
+# TYPE empty_total counter
+# HELP unlabeled_seconds Without labels.
+# TYPE unlabeled_seconds histogram
+unlabeled_seconds_bucket{le="1"} 1
+unlabeled_seconds_bucket{le="+Inf"} 1
+unlabeled_seconds_sum 0.5
+unlabeled_seconds_count 1
+`
+	if got := scrape(t, r); got != want {
+		t.Errorf("exposition:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestLabelCountMismatchPanics(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("x_total", "X.", "a", "b")
+	defer func() {
+		if recover() == nil {
+			t.Error("Inc with a wrong number of label values did not panic")
+		}
+	}()
+	c.Inc("only-one")
+}
+
+func TestFormatFloat(t *testing.T) {
+	tests := []struct {
+		v    float64
+		want string
+	}{
+		{v: 0, want: "0"},
+		{v: 0.25, want: "0.25"},
+		{v: 1e-7, want: "1e-07"},
+		{v: math.Inf(1), want: "+Inf"},
+		{v: math.Inf(-1), want: "-Inf"},
+		{v: math.NaN(), want: "NaN"},
+	}
+	for _, tt := range tests {
+		if got := formatFloat(tt.v); got != tt.want {
+			t.Errorf("formatFloat(%v) = %s, want %s", tt.v, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+// Package notify - реализации ports.Notifier
+package notify
+
+import (
+	"context"
+	"errors"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/ports"
+)
+
+// LogNotifier пишет уведомления в лог приложения
+type LogNotifier struct {
+	logger *logger.Logger
+}
+
+func NewLogNotifier(logger *logger.Logger) ports.Notifier {
+	return &LogNotifier{logger: logger}
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, notification ports.Notification) error {
+	n.logger.Info("Notification %s for todo %s by %s to %v",
+		notification.Event, notification.TodoId, notification.ActorId, notification.Recipients)
+	return nil
+}
+
+// Multi рассылает уведомление всем notifier'ам
+type Multi []ports.Notifier
+
+func (m Multi) Notify(ctx context.Context, notification ports.Notification) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.Notify(ctx, notification); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close закрывает notifier'ы с фоновой отправкой, дожидаясь её завершения
+func (m Multi) Close(ctx context.Context) error {
+	var errs []error
+	for _, n := range m {
+		if closer, ok := n.(interface{ Close(context.Context) error }); ok {
+			if err := closer.Close(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
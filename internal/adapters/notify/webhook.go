@@ -0,0 +1,141 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/ports"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer - span отправки webhook
+var tracer = otel.Tracer("ToDo-List/internal/adapters/notify")
+
+// webhookQueueSize - сколько уведомлений может ждать отправки
+const webhookQueueSize = 256
+
+// WebhookNotifier отправляет уведомления POST-запросом с JSON в фоне,
+// чтобы медленный получатель не задерживал ответы API
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+	queue  chan webhookItem
+	done   chan struct{} // закрывается, когда очередь разобрана после Close
+	logger *logger.Logger
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// webhookItem - уведомление в очереди и контекст трассировки запроса, который
+// его вызвал: отправка продолжает ту же трассировку
+type webhookItem struct {
+	notification ports.Notification
+	spanContext  trace.SpanContext
+}
+
+func NewWebhookNotifier(url string, logger *logger.Logger) ports.Notifier {
+	n := &WebhookNotifier{
+		url:    url,
+		client: &http.Client{Timeout: 5 * time.Second},
+		queue:  make(chan webhookItem, webhookQueueSize),
+		done:   make(chan struct{}),
+		logger: logger,
+	}
+	go n.run()
+	return n
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, notification ports.Notification) error {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if n.closed {
+		return fmt.Errorf("webhook notifier is closed, notification %s for todo %s dropped", notification.Event, notification.TodoId)
+	}
+
+	select {
+	case n.queue <- webhookItem{notification: notification, spanContext: trace.SpanContextFromContext(ctx)}:
+		return nil
+	default:
+		return fmt.Errorf("webhook queue is full, notification %s for todo %s dropped", notification.Event, notification.TodoId)
+	}
+}
+
+// Close перестаёт принимать уведомления и ждёт отправки уже поставленных в
+// очередь, но не дольше, чем живёт ctx
+func (n *WebhookNotifier) Close(ctx context.Context) error {
+	n.mu.Lock()
+	if !n.closed {
+		n.closed = true
+		close(n.queue)
+	}
+	n.mu.Unlock()
+
+	select {
+	case <-n.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("webhook queue not drained: %w", ctx.Err())
+	}
+}
+
+func (n *WebhookNotifier) run() {
+	defer close(n.done)
+	for item := range n.queue {
+		if err := n.send(item); err != nil {
+			n.logger.Warn("Webhook delivery failed: %v", err)
+		}
+	}
+}
+
+// send отправляет уведомление с заголовком traceparent, чтобы получатель мог
+// продолжить трассировку
+func (n *WebhookNotifier) send(item webhookItem) (err error) {
+	ctx := trace.ContextWithSpanContext(context.Background(), item.spanContext)
+	ctx, span := tracer.Start(ctx, "webhook "+item.notification.Event,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.HTTPRequestMethodKey.String(http.MethodPost)),
+	)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	body, err := json.Marshal(item.notification)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/ports"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTestLogger(t *testing.T) *logger.Logger {
+	t.Helper()
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { devNull.Close() })
+	appLogger, err := logger.New(logger.Config{Level: logger.LevelError, Output: devNull})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return appLogger
+}
+
+var (
+	spanExporter = tracetest.NewInMemoryExporter()
+	setupTracing sync.Once
+)
+
+// recordSpans включает запись span в память; TracerProvider задаётся один раз,
+// потому что глобальный tracer пакета привязывается к первому
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	setupTracing.Do(func() {
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(spanExporter)))
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+	})
+	spanExporter.Reset()
+	return spanExporter
+}
+
+// Отправка webhook продолжает трассировку запроса и передаёт её получателю
+func TestWebhookPropagatesTrace(t *testing.T) {
+	exporter := recordSpans(t)
+
+	traceparent := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent <- r.Header.Get("traceparent")
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(server.URL, newTestLogger(t)).(*WebhookNotifier)
+	ctx, request := otel.Tracer("test").Start(context.Background(), "/api/todo/{id}")
+	if err := notifier.Notify(ctx, ports.Notification{Event: "todo.completed", TodoId: "t1"}); err != nil {
+		t.Fatal(err)
+	}
+	request.End()
+
+	closeCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := notifier.Close(closeCtx); err != nil {
+		t.Fatal(err)
+	}
+
+	var webhook tracetest.SpanStub
+	for _, span := range exporter.GetSpans() {
+		if span.Name == "webhook todo.completed" {
+			webhook = span
+		}
+	}
+	if webhook.SpanContext.TraceID() != request.SpanContext().TraceID() || webhook.Parent.SpanID() != request.SpanContext().SpanID() {
+		t.Fatalf("webhook span is not a child of the request span: %+v", webhook)
+	}
+	want := "00-" + webhook.SpanContext.TraceID().String() + "-" + webhook.SpanContext.SpanID().String() + "-01"
+	if got := <-traceparent; got != want {
+		t.Errorf("traceparent = %q, want %q", got, want)
+	}
+}
+
+func TestWebhookClosed(t *testing.T) {
+	notifier := NewWebhookNotifier("http://127.0.0.1:1", newTestLogger(t)).(*WebhookNotifier)
+	if err := notifier.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := notifier.Notify(context.Background(), ports.Notification{Event: "todo.created", TodoId: "t1"}); err == nil {
+		t.Error("Notify after Close accepted the notification")
+	}
+}
@@ -0,0 +1,142 @@
+// Package oidc - вход через внешний провайдер OpenID Connect
+// (authorization code + PKCE).
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/ports"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// DefaultUsernameClaim - claim, из которого берётся имя нового пользователя
+const DefaultUsernameClaim = "preferred_username"
+
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	// Scopes - дополнительные области; openid добавляется всегда
+	Scopes        []string
+	UsernameClaim string
+}
+
+// Provider выполняет discovery лениво, при первом входе, чтобы приложение
+// запускалось и без доступного провайдера. Ключи JWKS кешируются go-oidc
+// и перечитываются, когда приходит токен с незнакомым kid.
+type Provider struct {
+	cfg    Config
+	client *http.Client
+	logger *logger.Logger
+
+	mu       sync.Mutex
+	provider *gooidc.Provider
+	verifier *gooidc.IDTokenVerifier
+	oauth    *oauth2.Config
+}
+
+func NewProvider(cfg Config, logger *logger.Logger) ports.IdentityProvider {
+	if cfg.UsernameClaim == "" {
+		cfg.UsernameClaim = DefaultUsernameClaim
+	}
+	return &Provider{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+		logger: logger,
+	}
+}
+
+// discover загружает /.well-known/openid-configuration один раз
+func (p *Provider) discover(ctx context.Context) (*oauth2.Config, *gooidc.IDTokenVerifier, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.provider != nil {
+		return p.oauth, p.verifier, nil
+	}
+
+	p.logger.Info("Discovering OIDC provider %s", p.cfg.Issuer)
+	provider, err := gooidc.NewProvider(gooidc.ClientContext(ctx, p.client), p.cfg.Issuer)
+	if err != nil {
+		p.logger.Error("OIDC discovery failed: %v", err)
+		return nil, nil, fmt.Errorf("oidc discovery: %w", err)
+	}
+
+	scopes := append([]string{gooidc.ScopeOpenID}, p.cfg.Scopes...)
+	p.provider = provider
+	p.verifier = provider.Verifier(&gooidc.Config{ClientID: p.cfg.ClientID})
+	p.oauth = &oauth2.Config{
+		ClientID:     p.cfg.ClientID,
+		ClientSecret: p.cfg.ClientSecret,
+		RedirectURL:  p.cfg.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       scopes,
+	}
+	return p.oauth, p.verifier, nil
+}
+
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	oauth, _, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	return oauth.AuthCodeURL(state, gooidc.Nonce(nonce), oauth2.S256ChallengeOption(codeVerifier)), nil
+}
+
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (ports.ExternalIdentity, error) {
+	oauth, verifier, err := p.discover(ctx)
+	if err != nil {
+		return ports.ExternalIdentity{}, err
+	}
+
+	ctx = gooidc.ClientContext(ctx, p.client)
+	token, err := oauth.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		p.logger.Warn("OIDC code exchange failed: %v", err)
+		return ports.ExternalIdentity{}, fmt.Errorf("oidc code exchange: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return ports.ExternalIdentity{}, errors.New("oidc: token response has no id_token")
+	}
+
+	// Verify проверяет подпись по JWKS, iss, aud и срок действия
+	idToken, err := verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		p.logger.Warn("ID token verification failed: %v", err)
+		return ports.ExternalIdentity{}, fmt.Errorf("oidc: invalid id token: %w", err)
+	}
+	if idToken.Nonce != nonce {
+		return ports.ExternalIdentity{}, errors.New("oidc: id token nonce mismatch")
+	}
+
+	var claims map[string]interface{}
+	if err := idToken.Claims(&claims); err != nil {
+		return ports.ExternalIdentity{}, fmt.Errorf("oidc: decode claims: %w", err)
+	}
+
+	ident := ports.ExternalIdentity{
+		Issuer:  idToken.Issuer,
+		Subject: idToken.Subject,
+	}
+	ident.Email, _ = claims["email"].(string)
+	ident.EmailVerified, _ = claims["email_verified"].(bool)
+	ident.Username, _ = claims[p.cfg.UsernameClaim].(string)
+	if ident.Username == "" {
+		ident.Username = ident.Email
+	}
+	if ident.Username == "" {
+		ident.Username = idToken.Subject
+	}
+	return ident, nil
+}
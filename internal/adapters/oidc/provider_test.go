@@ -0,0 +1,243 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"ToDo-List/internal/adapters/logger"
+)
+
+func newTestLogger(t *testing.T) *logger.Logger {
+	t.Helper()
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { devNull.Close() })
+	appLogger, err := logger.New(logger.Config{Level: logger.LevelError, Output: devNull})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return appLogger
+}
+
+// fakeIdP - провайдер с discovery, JWKS и token endpoint. Код обменивается
+// на ID token, только если code_verifier соответствует code_challenge из
+// запроса авторизации.
+type fakeIdP struct {
+	*httptest.Server
+	key *rsa.PrivateKey
+
+	challenge string // code_challenge из адреса авторизации
+	nonce     string // nonce, который попадёт в ID token
+	claims    map[string]interface{}
+}
+
+func newFakeIdP(t *testing.T) *fakeIdP {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &fakeIdP{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                                idp.URL,
+			"authorization_endpoint":                idp.URL + "/authorize",
+			"token_endpoint":                        idp.URL + "/token",
+			"jwks_uri":                              idp.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"alg": "RS256",
+			"use": "sig",
+			"kid": "k1",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		sum := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+		if r.FormValue("code") != "good-code" || base64.RawURLEncoding.EncodeToString(sum[:]) != idp.challenge {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"expires_in":   300,
+			"id_token":     idp.idToken(t),
+		})
+	})
+	idp.Server = httptest.NewServer(mux)
+	t.Cleanup(idp.Close)
+	return idp
+}
+
+// idToken подписывает RS256 ID token для клиента todo
+func (idp *fakeIdP) idToken(t *testing.T) string {
+	claims := map[string]interface{}{
+		"iss":   idp.URL,
+		"sub":   "subject-1",
+		"aud":   "todo",
+		"exp":   time.Now().Add(time.Minute).Unix(),
+		"iat":   time.Now().Unix(),
+		"nonce": idp.nonce,
+	}
+	for key, value := range idp.claims {
+		claims[key] = value
+	}
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "k1", "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, idp.key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// authorize начинает вход и запоминает code_challenge, как это сделал бы провайдер
+func (idp *fakeIdP) authorize(t *testing.T, p *Provider, state, nonce, verifier string) url.Values {
+	t.Helper()
+	authURL, err := p.AuthCodeURL(context.Background(), state, nonce, verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := parsed.Query()
+	idp.challenge = query.Get("code_challenge")
+	idp.nonce = query.Get("nonce")
+	return query
+}
+
+func newTestProvider(t *testing.T, idp *fakeIdP) *Provider {
+	t.Helper()
+	return NewProvider(Config{Issuer: idp.URL, ClientID: "todo", RedirectURL: "http://todo.test/api/auth/oidc/callback"}, newTestLogger(t)).(*Provider)
+}
+
+func TestAuthCodeURLUsesPKCE(t *testing.T) {
+	idp := newFakeIdP(t)
+	query := idp.authorize(t, newTestProvider(t, idp), "state-1", "nonce-1", "verifier-1")
+
+	sum := sha256.Sum256([]byte("verifier-1"))
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             "todo",
+		"state":                 "state-1",
+		"nonce":                 "nonce-1",
+		"code_challenge_method": "S256",
+		"code_challenge":        base64.RawURLEncoding.EncodeToString(sum[:]),
+	}
+	for key, value := range want {
+		if query.Get(key) != value {
+			t.Errorf("%s = %q, want %q", key, query.Get(key), value)
+		}
+	}
+	if !strings.Contains(query.Get("scope"), "openid") {
+		t.Errorf("scope = %q, want openid", query.Get("scope"))
+	}
+	// Verifier не уходит в браузер
+	if strings.Contains(query.Encode(), "verifier-1") {
+		t.Error("code verifier leaked into the authorization URL")
+	}
+}
+
+func TestExchange(t *testing.T) {
+	idp := newFakeIdP(t)
+	idp.claims = map[string]interface{}{"preferred_username": "Alice", "email": "alice@example.com", "email_verified": true}
+	p := newTestProvider(t, idp)
+	idp.authorize(t, p, "state-1", "nonce-1", "verifier-1")
+
+	ident, err := p.Exchange(context.Background(), "good-code", "verifier-1", "nonce-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ident.Issuer != idp.URL || ident.Subject != "subject-1" || ident.Username != "Alice" || ident.Email != "alice@example.com" || !ident.EmailVerified {
+		t.Errorf("identity = %+v", ident)
+	}
+}
+
+func TestExchangeUsernameFallback(t *testing.T) {
+	idp := newFakeIdP(t)
+	p := newTestProvider(t, idp)
+
+	idp.claims = map[string]interface{}{"email": "bob@example.com"}
+	idp.authorize(t, p, "s", "n", "v")
+	if ident, err := p.Exchange(context.Background(), "good-code", "v", "n"); err != nil || ident.Username != "bob@example.com" || ident.EmailVerified {
+		t.Errorf("without username claim = %+v, %v; want the email", ident, err)
+	}
+
+	idp.claims = nil
+	idp.authorize(t, p, "s", "n", "v")
+	if ident, err := p.Exchange(context.Background(), "good-code", "v", "n"); err != nil || ident.Username != "subject-1" {
+		t.Errorf("without username and email = %+v, %v; want the subject", ident, err)
+	}
+}
+
+func TestExchangeRejected(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     string
+		verifier string
+		nonce    string
+	}{
+		{name: "wrong verifier", code: "good-code", verifier: "other", nonce: "nonce-1"},
+		{name: "unknown code", code: "bad-code", verifier: "verifier-1", nonce: "nonce-1"},
+		{name: "nonce mismatch", code: "good-code", verifier: "verifier-1", nonce: "replayed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := newFakeIdP(t)
+			p := newTestProvider(t, idp)
+			idp.authorize(t, p, "state-1", "nonce-1", "verifier-1")
+
+			if ident, err := p.Exchange(context.Background(), tt.code, tt.verifier, tt.nonce); err == nil {
+				t.Errorf("Exchange accepted: %+v", ident)
+			}
+		})
+	}
+}
+
+func TestExchangeRejectsForeignSignature(t *testing.T) {
+	idp := newFakeIdP(t)
+	p := newTestProvider(t, idp)
+	idp.authorize(t, p, "state-1", "nonce-1", "verifier-1")
+	// Discovery и JWKS прочитаны, затем токены подписываются чужим ключом
+	if _, err := p.Exchange(context.Background(), "good-code", "verifier-1", "nonce-1"); err != nil {
+		t.Fatal(err)
+	}
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp.key = other
+
+	if ident, err := p.Exchange(context.Background(), "good-code", "verifier-1", "nonce-1"); err == nil {
+		t.Errorf("token signed by an unknown key accepted: %+v", ident)
+	}
+}
@@ -0,0 +1,93 @@
+// Package password хеширует пароли пользователей алгоритмом argon2id.
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"ToDo-List/internal/core/ports"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// ErrInvalidHash - строка хеша не в формате $argon2id$v=19$m=...,t=...,p=...$salt$key
+var ErrInvalidHash = errors.New("invalid argon2id hash")
+
+// Params - параметры argon2id (значения по умолчанию по рекомендациям OWASP)
+type Params struct {
+	Memory      uint32 // КиБ
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+var DefaultParams = Params{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+type Argon2Hasher struct {
+	params Params
+}
+
+func NewArgon2Hasher(params Params) ports.PasswordHasher {
+	return &Argon2Hasher{params: params}
+}
+
+// Hash возвращает хеш в формате PHC, параметры хранятся вместе с хешем,
+// поэтому их можно менять без перехеширования старых паролей
+func (h *Argon2Hasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.params.Iterations, h.params.Memory, h.params.Parallelism, h.params.KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		h.params.Memory,
+		h.params.Iterations,
+		h.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify сравнивает пароль с хешем за постоянное время
+func (h *Argon2Hasher) Verify(password, encoded string) (bool, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return false, ErrInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, ErrInvalidHash
+	}
+
+	var p Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism); err != nil {
+		return false, ErrInvalidHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, ErrInvalidHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false, ErrInvalidHash
+	}
+
+	candidate := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, candidate) == 1, nil
+}
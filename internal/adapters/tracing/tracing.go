@@ -0,0 +1,109 @@
+// Package tracing настраивает OpenTelemetry: экспорт span в OTLP/HTTP или
+// в виде JSON в поток вывода и распространение контекста трассировки W3C (traceparent, tracestate).
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+
+	"ToDo-List/internal/adapters/logger"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+const (
+	// ExporterNone - span не записываются, но traceparent передаётся дальше
+	ExporterNone = "none"
+	// ExporterStdout - span в виде JSON, по одному на строку, в Config.Writer
+	// (по умолчанию stderr, чтобы не смешиваться с логами в stdout)
+	ExporterStdout = "stdout"
+	// ExporterOTLP - span отправляются коллектору по OTLP/HTTP
+	ExporterOTLP = "otlp"
+)
+
+// otlpTracesPath добавляется к адресу коллектора без пути
+const otlpTracesPath = "/v1/traces"
+
+type Config struct {
+	// Exporter - none, stdout или otlp
+	Exporter string
+	// Endpoint - адрес коллектора OTLP/HTTP: http://localhost:4318; пусто -
+	// из стандартных OTEL_EXPORTER_OTLP_* (по умолчанию localhost:4318)
+	Endpoint    string
+	ServiceName string
+	// SampleRatio - доля записываемых трассировок, начатых здесь; для
+	// продолжаемых трассировок действует решение из traceparent
+	SampleRatio float64
+	// Writer - куда пишет экспортёр stdout; nil - os.Stderr
+	Writer io.Writer
+}
+
+// Setup регистрирует глобальные propagator и TracerProvider. Возвращаемая
+// функция отправляет накопленные span и останавливает экспорт.
+func Setup(ctx context.Context, cfg Config, appLogger *logger.Logger) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		appLogger.Warn("Tracing error: %v", err)
+	}))
+
+	if cfg.Exporter == "" || cfg.Exporter == ExporterNone {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := newExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("tracing resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+func newExporter(ctx context.Context, cfg Config) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case ExporterStdout:
+		writer := cfg.Writer
+		if writer == nil {
+			writer = os.Stderr
+		}
+		return stdouttrace.New(stdouttrace.WithWriter(writer))
+	case ExporterOTLP:
+		var options []otlptracehttp.Option
+		if cfg.Endpoint != "" {
+			endpoint, err := url.Parse(cfg.Endpoint)
+			if err != nil {
+				return nil, fmt.Errorf("invalid OTLP endpoint %q: %w", cfg.Endpoint, err)
+			}
+			if endpoint.Path == "" || endpoint.Path == "/" {
+				endpoint.Path = otlpTracesPath
+			}
+			options = append(options, otlptracehttp.WithEndpointURL(endpoint.String()))
+		}
+		exporter, err := otlptracehttp.New(ctx, options...)
+		if err != nil {
+			return nil, fmt.Errorf("OTLP exporter: %w", err)
+		}
+		return exporter, nil
+	}
+	return nil, fmt.Errorf("unknown trace exporter %q", cfg.Exporter)
+}
@@ -0,0 +1,95 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"ToDo-List/internal/adapters/logger"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func newTestLogger(t *testing.T) *logger.Logger {
+	t.Helper()
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { devNull.Close() })
+	appLogger, err := logger.New(logger.Config{Level: logger.LevelError, Output: devNull})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return appLogger
+}
+
+func TestStdoutExporter(t *testing.T) {
+	var out bytes.Buffer
+	shutdown, err := Setup(context.Background(), Config{Exporter: ExporterStdout, ServiceName: "todo-test", SampleRatio: 1, Writer: &out}, newTestLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, span := otel.Tracer("test").Start(context.Background(), "TodoService.CreateTodo")
+	span.End()
+	// Shutdown отправляет накопленные span
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(out.String(), `"Name":"TodoService.CreateTodo"`) || !strings.Contains(out.String(), "todo-test") {
+		t.Errorf("exported = %s", out.String())
+	}
+}
+
+func TestSampleRatioRespectsParent(t *testing.T) {
+	var out bytes.Buffer
+	shutdown, err := Setup(context.Background(), Config{Exporter: ExporterStdout, SampleRatio: 0, Writer: &out}, newTestLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Трассировка, начатая здесь, не записывается
+	_, local := otel.Tracer("test").Start(context.Background(), "local")
+	local.End()
+
+	// Решение вызывающей стороны из traceparent сильнее доли
+	header := http.Header{"Traceparent": {"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"}}
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), propagation.HeaderCarrier(header))
+	_, remote := otel.Tracer("test").Start(ctx, "remote")
+	remote.End()
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), `"Name":"local"`) || !strings.Contains(out.String(), `"Name":"remote"`) {
+		t.Errorf("exported = %s", out.String())
+	}
+}
+
+func TestNoneExporterPropagates(t *testing.T) {
+	shutdown, err := Setup(context.Background(), Config{Exporter: ExporterNone}, newTestLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(context.Background())
+
+	// Без экспорта traceparent всё равно передаётся дальше
+	in := http.Header{"Traceparent": {"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"}}
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), propagation.HeaderCarrier(in))
+	out := http.Header{}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(out))
+	if got := out.Get("Traceparent"); got != in.Get("Traceparent") {
+		t.Errorf("traceparent = %q, want %q", got, in.Get("Traceparent"))
+	}
+}
+
+func TestUnknownExporter(t *testing.T) {
+	if _, err := Setup(context.Background(), Config{Exporter: "zipkin"}, newTestLogger(t)); err == nil {
+		t.Error("unknown exporter accepted")
+	}
+}
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/identity"
+	"ToDo-List/internal/core/policy"
+	"ToDo-List/internal/core/ports"
+)
+
+// ImpersonationTTL - время жизни сессии администратора от имени пользователя
+const ImpersonationTTL = time.Hour
+
+// maxUsersPage - ограничение размера страницы списка пользователей
+const maxUsersPage = 200
+
+var (
+	ErrInvalidUserRole   = errors.New("role must be admin, user or readonly")
+	ErrSelfAdminChange   = errors.New("admins cannot change their own role or disable themselves")
+	ErrLastAdmin         = errors.New("at least one active admin must remain")
+	ErrCannotImpersonate = errors.New("cannot impersonate this user")
+)
+
+type AdminService struct {
+	users    ports.UserRepo
+	sessions ports.SessionRepo
+	auditLog ports.AuditRepo
+	audit    ports.AuditService
+	stats    ports.StatsRepo
+	hasher   ports.PasswordHasher
+	uow      ports.UnitOfWork
+	logger   *logger.Logger
+}
+
+func NewAdminService(users ports.UserRepo, sessions ports.SessionRepo, auditLog ports.AuditRepo, audit ports.AuditService, stats ports.StatsRepo, hasher ports.PasswordHasher, uow ports.UnitOfWork, logger *logger.Logger) ports.AdminService {
+	return &AdminService{
+		users:    users,
+		sessions: sessions,
+		auditLog: auditLog,
+		audit:    audit,
+		stats:    stats,
+		hasher:   hasher,
+		uow:      uow,
+		logger:   logger,
+	}
+}
+
+func (s *AdminService) ListUsers(ctx context.Context, filter ports.UserFilter) ([]domain.User, error) {
+	if err := policy.Check(ctx, policy.UsersManage); err != nil {
+		return nil, err
+	}
+	if filter.Limit <= 0 || filter.Limit > maxUsersPage {
+		filter.Limit = maxUsersPage
+	}
+	return s.users.GetUsers(ctx, filter)
+}
+
+func (s *AdminService) GetUser(ctx context.Context, id string) (domain.User, error) {
+	if err := policy.Check(ctx, policy.UsersManage); err != nil {
+		return domain.User{}, err
+	}
+	return s.users.GetUserById(ctx, id)
+}
+
+func (s *AdminService) SetRole(ctx context.Context, id, role string) (domain.User, error) {
+	if err := policy.Check(ctx, policy.UsersManage); err != nil {
+		return domain.User{}, err
+	}
+	if !policy.ValidRole(role) {
+		return domain.User{}, ErrInvalidUserRole
+	}
+	if err := s.checkNotSelf(ctx, id); err != nil {
+		return domain.User{}, err
+	}
+
+	var user domain.User
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = s.users.GetUserById(ctx, id)
+		if err != nil {
+			return err
+		}
+		if user.Role == role {
+			return nil
+		}
+		if user.Role == domain.UserRoleAdmin && !user.Disabled {
+			if err := s.ensureOtherAdmin(ctx); err != nil {
+				return err
+			}
+		}
+		previous := user.Role
+		user.Role = role
+		user.UpdatedAt = time.Now()
+		if err := s.users.UpdateUserRole(ctx, id, role, user.UpdatedAt); err != nil {
+			return err
+		}
+		if !policy.Allows(role, policy.UsersImpersonate) {
+			// Вход от имени других пользователей заканчивается вместе с правом на него
+			if err := s.sessions.DeleteSessionsByImpersonator(ctx, id); err != nil {
+				return err
+			}
+		}
+		s.log(ctx).Info("Role of user %s changed from %s to %s", id, previous, role)
+		return nil
+	})
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	s.audit.Record(ctx, domain.AuditUserRoleChanged, "user", id, map[string]string{"role": role})
+	return user, nil
+}
+
+func (s *AdminService) SetDisabled(ctx context.Context, id string, disabled bool) (domain.User, error) {
+	if err := policy.Check(ctx, policy.UsersManage); err != nil {
+		return domain.User{}, err
+	}
+	if err := s.checkNotSelf(ctx, id); err != nil {
+		return domain.User{}, err
+	}
+
+	var user domain.User
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = s.users.GetUserById(ctx, id)
+		if err != nil {
+			return err
+		}
+		if disabled && user.Role == domain.UserRoleAdmin && !user.Disabled {
+			if err := s.ensureOtherAdmin(ctx); err != nil {
+				return err
+			}
+		}
+		user.Disabled = disabled
+		user.UpdatedAt = time.Now()
+		if err := s.users.SetUserDisabled(ctx, id, disabled, user.UpdatedAt); err != nil {
+			return err
+		}
+		if disabled {
+			// Открытые сессии заблокированного пользователя больше не действуют,
+			// как и сессии, открытые им от имени других
+			if err := s.sessions.DeleteSessionsByUser(ctx, id); err != nil {
+				return err
+			}
+			return s.sessions.DeleteSessionsByImpersonator(ctx, id)
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	action := domain.AuditUserEnabled
+	if disabled {
+		action = domain.AuditUserDisabled
+	}
+	s.log(ctx).Info("User %s: %s", id, action)
+	s.audit.Record(ctx, action, "user", id, nil)
+	return user, nil
+}
+
+func (s *AdminService) ResetPassword(ctx context.Context, id, password string) error {
+	if err := policy.Check(ctx, policy.UsersManage); err != nil {
+		return err
+	}
+	if len(password) < MinPasswordLength {
+		return ErrWeakPassword
+	}
+
+	hash, err := s.hasher.Hash(password)
+	if err != nil {
+		s.log(ctx).Error("Failed to hash password: %v", err)
+		return err
+	}
+
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.users.UpdatePassword(ctx, id, hash, time.Now()); err != nil {
+			return err
+		}
+		return s.sessions.DeleteSessionsByUser(ctx, id)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.log(ctx).Info("Password of user %s reset", id)
+	s.audit.Record(ctx, domain.AuditUserPasswordReset, "user", id, nil)
+	return nil
+}
+
+// Impersonate открывает короткую сессию от имени пользователя. Администраторов
+// и заблокированных пользователей подменять нельзя.
+func (s *AdminService) Impersonate(ctx context.Context, id string) (domain.User, domain.Session, string, error) {
+	if err := policy.Check(ctx, policy.UsersImpersonate); err != nil {
+		return domain.User{}, domain.Session{}, "", err
+	}
+	adminId, err := identity.UserId(ctx)
+	if err != nil {
+		return domain.User{}, domain.Session{}, "", err
+	}
+	if adminId == id {
+		return domain.User{}, domain.Session{}, "", ErrCannotImpersonate
+	}
+
+	user, err := s.users.GetUserById(ctx, id)
+	if err != nil {
+		return domain.User{}, domain.Session{}, "", err
+	}
+	if user.Disabled || user.Role == domain.UserRoleAdmin {
+		return domain.User{}, domain.Session{}, "", ErrCannotImpersonate
+	}
+
+	now := time.Now()
+	session, token, err := openSession(ctx, s.sessions, domain.Session{
+		UserId:         user.Id,
+		ImpersonatorId: adminId,
+		CreatedAt:      now,
+		ExpiresAt:      now.Add(ImpersonationTTL),
+	})
+	if err != nil {
+		return domain.User{}, domain.Session{}, "", err
+	}
+
+	s.log(ctx).Info("Admin %s started impersonating user %s", adminId, user.Id)
+	s.audit.Record(ctx, domain.AuditImpersonationStarted, "user", user.Id, map[string]string{
+		"expiresAt": session.ExpiresAt.UTC().Format(time.RFC3339),
+	})
+	return user, session, token, nil
+}
+
+func (s *AdminService) GetAuditLog(ctx context.Context, filter ports.AuditFilter) ([]domain.AuditEntry, error) {
+	if err := policy.Check(ctx, policy.AuditRead); err != nil {
+		return nil, err
+	}
+	return s.auditLog.GetAuditEntries(ctx, filter)
+}
+
+func (s *AdminService) GetStats(ctx context.Context) (domain.SystemStats, error) {
+	if err := policy.Check(ctx, policy.StatsRead); err != nil {
+		return domain.SystemStats{}, err
+	}
+	return s.stats.GetSystemStats(ctx)
+}
+
+// checkNotSelf не даёт администратору лишить прав самого себя
+func (s *AdminService) checkNotSelf(ctx context.Context, id string) error {
+	adminId, err := identity.UserId(ctx)
+	if err != nil {
+		return err
+	}
+	if adminId == id {
+		return ErrSelfAdminChange
+	}
+	return nil
+}
+
+// ensureOtherAdmin проверяет, что после снятия прав останется активный администратор
+func (s *AdminService) ensureOtherAdmin(ctx context.Context) error {
+	n, err := s.users.CountActiveAdmins(ctx)
+	if err != nil {
+		return err
+	}
+	if n <= 1 {
+		return ErrLastAdmin
+	}
+	return nil
+}
+
+// log - логгер запроса с его X-Request-ID
+func (s *AdminService) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, s.logger)
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/ports"
+)
+
+// impersonationFixture: root и alice - администраторы, alice вошла от имени bob
+type impersonationFixture struct {
+	users    *memUsers
+	sessions *memSessions
+	admin    ports.AdminService
+	auth     ports.AuthService
+	token    string
+}
+
+func newImpersonationFixture(t *testing.T) *impersonationFixture {
+	t.Helper()
+	f := &impersonationFixture{
+		users: newMemUsers(
+			domain.User{Id: "root", Role: domain.UserRoleAdmin},
+			domain.User{Id: "alice", Role: domain.UserRoleAdmin},
+			domain.User{Id: "bob", Role: domain.UserRoleUser},
+		),
+		sessions: newMemSessions(),
+	}
+	appLogger := newTestLogger(t)
+	f.admin = NewAdminService(f.users, f.sessions, nil, discardAudit{}, nil, plainHasher{}, inlineUnitOfWork{}, appLogger)
+	f.auth = NewAuthService(f.users, f.sessions, inlineUnitOfWork{}, plainHasher{}, 0, appLogger)
+
+	_, _, token, err := f.admin.Impersonate(asUser("alice", domain.UserRoleAdmin), "bob")
+	if err != nil {
+		t.Fatalf("Impersonate: %v", err)
+	}
+	if user, _, err := f.auth.Authenticate(context.Background(), token); err != nil || user.Id != "bob" {
+		t.Fatalf("Authenticate before revocation = %q, %v; want bob", user.Id, err)
+	}
+	f.token = token
+	return f
+}
+
+// assertRevoked проверяет, что сессия от имени bob больше не действует и удалена
+func (f *impersonationFixture) assertRevoked(t *testing.T) {
+	t.Helper()
+	if _, _, err := f.auth.Authenticate(context.Background(), f.token); !errors.Is(err, ports.ErrUnauthenticated) {
+		t.Errorf("Authenticate = %v, want ErrUnauthenticated", err)
+	}
+	if len(f.sessions.sessions) != 0 {
+		t.Errorf("sessions left = %+v, want none", f.sessions.sessions)
+	}
+}
+
+func TestImpersonationRevokedByAdminService(t *testing.T) {
+	root := asUser("root", domain.UserRoleAdmin)
+
+	t.Run("disabled", func(t *testing.T) {
+		f := newImpersonationFixture(t)
+		if _, err := f.admin.SetDisabled(root, "alice", true); err != nil {
+			t.Fatalf("SetDisabled: %v", err)
+		}
+		f.assertRevoked(t)
+	})
+	t.Run("demoted", func(t *testing.T) {
+		f := newImpersonationFixture(t)
+		if _, err := f.admin.SetRole(root, "alice", domain.UserRoleUser); err != nil {
+			t.Fatalf("SetRole: %v", err)
+		}
+		f.assertRevoked(t)
+	})
+}
+
+func TestImpersonationRevokedOnAuthenticate(t *testing.T) {
+	// Права администратора изменились в обход AdminService: проверяет Authenticate
+	tests := []struct {
+		name   string
+		change func(users *memUsers)
+	}{
+		{name: "disabled", change: func(users *memUsers) {
+			alice := users.users["alice"]
+			alice.Disabled = true
+			users.users["alice"] = alice
+		}},
+		{name: "demoted", change: func(users *memUsers) {
+			alice := users.users["alice"]
+			alice.Role = domain.UserRoleReadOnly
+			users.users["alice"] = alice
+		}},
+		{name: "deleted", change: func(users *memUsers) { delete(users.users, "alice") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newImpersonationFixture(t)
+			tt.change(f.users)
+			f.assertRevoked(t)
+		})
+	}
+}
+
+func TestSetRoleKeepsSessionsOfAdmins(t *testing.T) {
+	f := newImpersonationFixture(t)
+	if _, err := f.admin.SetRole(asUser("root", domain.UserRoleAdmin), "bob", domain.UserRoleReadOnly); err != nil {
+		t.Fatalf("SetRole: %v", err)
+	}
+	if _, _, err := f.auth.Authenticate(context.Background(), f.token); err != nil {
+		t.Errorf("Authenticate after an unrelated role change: %v", err)
+	}
+}
+
+func TestAdminPolicy(t *testing.T) {
+	f := newImpersonationFixture(t)
+	for _, role := range []string{domain.UserRoleUser, domain.UserRoleReadOnly} {
+		ctx := asUser("bob", role)
+		if _, err := f.admin.SetRole(ctx, "alice", domain.UserRoleUser); !errors.Is(err, ports.ErrForbidden) {
+			t.Errorf("%s: SetRole = %v, want ErrForbidden", role, err)
+		}
+		if _, _, _, err := f.admin.Impersonate(ctx, "root"); !errors.Is(err, ports.ErrForbidden) {
+			t.Errorf("%s: Impersonate = %v, want ErrForbidden", role, err)
+		}
+	}
+
+	root := asUser("root", domain.UserRoleAdmin)
+	if _, err := f.admin.SetDisabled(root, "root", true); !errors.Is(err, ErrSelfAdminChange) {
+		t.Errorf("SetDisabled of self = %v, want ErrSelfAdminChange", err)
+	}
+	if _, _, _, err := f.admin.Impersonate(root, "alice"); !errors.Is(err, ErrCannotImpersonate) {
+		t.Errorf("Impersonate of an admin = %v, want ErrCannotImpersonate", err)
+	}
+	// После понижения alice root остаётся единственным администратором;
+	// запрос alice, начатый до понижения, его права не снимет
+	if _, err := f.admin.SetRole(root, "alice", domain.UserRoleUser); err != nil {
+		t.Fatalf("SetRole: %v", err)
+	}
+	if _, err := f.admin.SetRole(asUser("alice", domain.UserRoleAdmin), "root", domain.UserRoleUser); !errors.Is(err, ErrLastAdmin) {
+		t.Errorf("demoting the last admin = %v, want ErrLastAdmin", err)
+	}
+}
@@ -0,0 +1,361 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/identity"
+	"ToDo-List/internal/core/ports"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrAttachmentTooLarge = errors.New("attachment is too large")
+	ErrAttachmentEmpty    = errors.New("attachment is empty")
+	ErrAttachmentType     = errors.New("attachment type is not allowed")
+)
+
+// AttachmentLimits - ограничения на загружаемые файлы
+type AttachmentLimits struct {
+	MaxSize int64
+	// AllowedTypes - MIME-типы, определённые по содержимому файла
+	AllowedTypes []string
+}
+
+var DefaultAttachmentLimits = AttachmentLimits{
+	MaxSize: 10 << 20,
+	AllowedTypes: []string{
+		"image/png",
+		"image/jpeg",
+		"image/gif",
+		"image/webp",
+		"application/pdf",
+		"text/plain",
+	},
+}
+
+// orphanBatchSize - сколько неиспользуемых объектов удаляется за один проход
+const orphanBatchSize = 100
+
+type AttachmentService struct {
+	attachments ports.AttachmentRepo
+	todos       ports.PostgreRepo
+	shares      ports.ShareRepo
+	blobs       ports.BlobStore
+	uow         ports.UnitOfWork
+	limits      AttachmentLimits
+	logger      *logger.Logger
+}
+
+func NewAttachmentService(attachments ports.AttachmentRepo, todos ports.PostgreRepo, shares ports.ShareRepo, blobs ports.BlobStore, uow ports.UnitOfWork, limits AttachmentLimits, logger *logger.Logger) ports.AttachmentService {
+	return &AttachmentService{
+		attachments: attachments,
+		todos:       todos,
+		shares:      shares,
+		blobs:       blobs,
+		uow:         uow,
+		limits:      limits,
+		logger:      logger,
+	}
+}
+
+// getTodo возвращает доступную пользователю задачу и проверяет роль
+func (s *AttachmentService) getTodo(ctx context.Context, todoId string, required string) (domain.ToDo, error) {
+	todo, err := s.todos.GetTodoById(ctx, todoId)
+	if err != nil {
+		s.log(ctx).Warn("Todo %s is not accessible for attachments: %v", todoId, err)
+		return domain.ToDo{}, ErrResourceNotFound
+	}
+	role, err := todoRole(ctx, s.shares, todo)
+	if err != nil {
+		return domain.ToDo{}, err
+	}
+	if domain.RoleRank(role) < domain.RoleRank(required) {
+		return domain.ToDo{}, ports.ErrForbidden
+	}
+	return todo, nil
+}
+
+func (s *AttachmentService) ListAttachments(ctx context.Context, todoId string) ([]domain.Attachment, error) {
+	if _, err := s.getTodo(ctx, todoId, domain.RoleViewer); err != nil {
+		return nil, err
+	}
+	return s.attachments.GetAttachmentsByTodo(ctx, todoId)
+}
+
+func (s *AttachmentService) Upload(ctx context.Context, todoId string, upload ports.Upload) (domain.Attachment, error) {
+	s.log(ctx).Debug("Uploading %q (%d bytes) to todo %s", upload.Filename, upload.Size, todoId)
+
+	if maxSize := s.maxSize(ctx); upload.Size > maxSize {
+		return domain.Attachment{}, fmt.Errorf("%w: %d bytes, limit %d", ErrAttachmentTooLarge, upload.Size, maxSize)
+	}
+	if upload.Size == 0 {
+		return domain.Attachment{}, ErrAttachmentEmpty
+	}
+	userId, err := identity.UserId(ctx)
+	if err != nil {
+		return domain.Attachment{}, err
+	}
+	if _, err := s.getTodo(ctx, todoId, domain.RoleEditor); err != nil {
+		return domain.Attachment{}, err
+	}
+
+	// Тип определяется по содержимому: заявленному клиентом Content-Type не доверяем
+	head := make([]byte, 512)
+	n, err := io.ReadFull(upload.Content, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return domain.Attachment{}, err
+	}
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(head[:n]))
+	if !s.allowedType(contentType) {
+		return domain.Attachment{}, fmt.Errorf("%w: %s", ErrAttachmentType, contentType)
+	}
+
+	hash, size, err := hashContent(upload.Content)
+	if err != nil {
+		return domain.Attachment{}, err
+	}
+	if size != upload.Size {
+		return domain.Attachment{}, fmt.Errorf("attachment size mismatch: read %d bytes, expected %d", size, upload.Size)
+	}
+
+	attachment := domain.Attachment{
+		Id:          uuid.NewString(),
+		TodoId:      todoId,
+		UploaderId:  userId,
+		Filename:    sanitizeFilename(upload.Filename),
+		ContentType: contentType,
+		Size:        size,
+		ContentHash: hash,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.uow.Do(ctx, func(ctx context.Context) error {
+		return s.attachments.CreateAttachment(ctx, attachment)
+	}); err != nil {
+		return domain.Attachment{}, err
+	}
+
+	// Содержимое пишется после фиксации записи: пока на объект есть ссылка,
+	// сборщик мусора его не тронет. Одинаковые файлы загружаются один раз.
+	if err := s.storeContent(ctx, hash, upload.Content, size, contentType); err != nil {
+		s.log(ctx).Error("Failed to store attachment %s content: %v", attachment.Id, err)
+		if delErr := s.attachments.DeleteAttachment(ctx, attachment.Id); delErr != nil {
+			s.log(ctx).Error("Failed to roll back attachment %s: %v", attachment.Id, delErr)
+		}
+		return domain.Attachment{}, err
+	}
+
+	s.log(ctx).Info("Attachment %s (%s, %d bytes) added to todo %s", attachment.Id, contentType, size, todoId)
+	return attachment, nil
+}
+
+func (s *AttachmentService) storeContent(ctx context.Context, hash string, content io.ReadSeeker, size int64, contentType string) error {
+	exists, err := s.blobs.Exists(ctx, hash)
+	if err != nil {
+		return err
+	}
+	if exists {
+		s.log(ctx).Debug("Blob %s already stored, skipping upload", hash)
+		return nil
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return s.blobs.Put(ctx, hash, content, size, contentType)
+}
+
+func (s *AttachmentService) Open(ctx context.Context, todoId, id string) (domain.Attachment, io.ReadCloser, error) {
+	if _, err := s.getTodo(ctx, todoId, domain.RoleViewer); err != nil {
+		return domain.Attachment{}, nil, err
+	}
+	attachment, err := s.attachments.GetAttachmentById(ctx, todoId, id)
+	if err != nil {
+		return domain.Attachment{}, nil, err
+	}
+	content, err := s.blobs.Get(ctx, attachment.ContentHash)
+	if err != nil {
+		s.log(ctx).Error("Failed to open blob %s of attachment %s: %v", attachment.ContentHash, id, err)
+		return domain.Attachment{}, nil, err
+	}
+	return attachment, content, nil
+}
+
+func (s *AttachmentService) DeleteAttachment(ctx context.Context, todoId, id string) error {
+	s.log(ctx).Debug("Deleting attachment %s of todo %s", id, todoId)
+
+	var attachment domain.Attachment
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		if _, err := s.getTodo(ctx, todoId, domain.RoleEditor); err != nil {
+			return err
+		}
+		var err error
+		attachment, err = s.attachments.GetAttachmentById(ctx, todoId, id)
+		if err != nil {
+			return err
+		}
+		return s.attachments.DeleteAttachment(ctx, id)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.log(ctx).Info("Attachment %s deleted from todo %s", id, todoId)
+	// Содержимое удаляется сразу, если на него больше никто не ссылается
+	if _, err := deleteOrphanBlob(ctx, s.attachments, s.blobs, s.uow, attachment.ContentHash); err != nil {
+		s.log(ctx).Warn("Failed to delete blob %s: %v", attachment.ContentHash, err)
+	}
+	return nil
+}
+
+// maxSize - лимит экземпляра или меньший лимит из настроек рабочего пространства
+func (s *AttachmentService) maxSize(ctx context.Context) int64 {
+	workspace, _, ok := identity.WorkspaceFromContext(ctx)
+	if ok && workspace.Settings.AttachmentMaxSize > 0 && workspace.Settings.AttachmentMaxSize < s.limits.MaxSize {
+		return workspace.Settings.AttachmentMaxSize
+	}
+	return s.limits.MaxSize
+}
+
+func (s *AttachmentService) allowedType(contentType string) bool {
+	for _, allowed := range s.limits.AllowedTypes {
+		if contentType == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// BlobCollector удаляет из хранилища содержимое, на которое не ссылается ни одно
+// вложение: после удаления задачи её вложения удаляются каскадом в БД, а файлы -
+// здесь, при следующем проходе
+type BlobCollector struct {
+	attachments ports.AttachmentRepo
+	blobs       ports.BlobStore
+	uow         ports.UnitOfWork
+	logger      *logger.Logger
+}
+
+func NewBlobCollector(attachments ports.AttachmentRepo, blobs ports.BlobStore, uow ports.UnitOfWork, logger *logger.Logger) *BlobCollector {
+	return &BlobCollector{
+		attachments: attachments,
+		blobs:       blobs,
+		uow:         uow,
+		logger:      logger,
+	}
+}
+
+// Collect удаляет неиспользуемые объекты и возвращает их количество
+func (c *BlobCollector) Collect(ctx context.Context) (int, error) {
+	deleted := 0
+	for {
+		hashes, err := c.attachments.GetOrphanBlobs(ctx, orphanBatchSize)
+		if err != nil {
+			return deleted, err
+		}
+		removed := 0
+		for _, hash := range hashes {
+			ok, err := deleteOrphanBlob(ctx, c.attachments, c.blobs, c.uow, hash)
+			if err != nil {
+				return deleted, err
+			}
+			if ok {
+				removed++
+			}
+		}
+		deleted += removed
+		// Если все найденные объекты снова используются, на следующем шаге
+		// найдутся те же самые
+		if len(hashes) < orphanBatchSize || removed == 0 {
+			return deleted, nil
+		}
+	}
+}
+
+// Run вызывает Collect каждые interval, пока не отменён ctx
+func (c *BlobCollector) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := c.Collect(ctx)
+			if err != nil {
+				c.log(ctx).Error("Blob garbage collection failed: %v", err)
+			}
+			if deleted > 0 {
+				c.log(ctx).Info("Deleted %d unreferenced blobs", deleted)
+			}
+		}
+	}
+}
+
+// deleteOrphanBlob удаляет объект из хранилища в той же транзакции, что и запись
+// о нём: пока строка заблокирована, новое вложение с тем же содержимым ждёт,
+// а затем загрузит содержимое заново
+func deleteOrphanBlob(ctx context.Context, attachments ports.AttachmentRepo, blobs ports.BlobStore, uow ports.UnitOfWork, hash string) (bool, error) {
+	deleted := false
+	err := uow.Do(ctx, func(ctx context.Context) error {
+		ok, err := attachments.DeleteOrphanBlob(ctx, hash)
+		if err != nil || !ok {
+			return err
+		}
+		deleted = true
+		return blobs.Delete(ctx, hash)
+	})
+	return deleted && err == nil, err
+}
+
+func hashContent(r io.ReadSeeker) (string, int64, error) {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return "", 0, err
+	}
+	h := sha256.New()
+	size, err := io.Copy(h, r)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
+
+// sanitizeFilename оставляет только имя файла без пути и управляющих символов
+func sanitizeFilename(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == "/" {
+		name = "file"
+	}
+	if len([]rune(name)) > 255 {
+		name = string([]rune(name)[:255])
+	}
+	return name
+}
+
+// log - логгер запроса с его X-Request-ID
+func (s *AttachmentService) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, s.logger)
+}
+
+// log - логгер запроса с его X-Request-ID
+func (c *BlobCollector) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, c.logger)
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/identity"
+	"ToDo-List/internal/core/ports"
+)
+
+// memAttachments - вложения и записи о содержимом в памяти
+type memAttachments struct {
+	ports.AttachmentRepo
+	attachments map[string]domain.Attachment
+	blobs       map[string]bool
+	// reused - содержимое, на которое сослалось новое вложение, пока
+	// сборщик собирался его удалить
+	reused map[string]bool
+}
+
+func newMemAttachments() *memAttachments {
+	return &memAttachments{attachments: map[string]domain.Attachment{}, blobs: map[string]bool{}, reused: map[string]bool{}}
+}
+
+func (r *memAttachments) CreateAttachment(ctx context.Context, attachment domain.Attachment) error {
+	r.attachments[attachment.Id] = attachment
+	r.blobs[attachment.ContentHash] = true
+	return nil
+}
+
+func (r *memAttachments) GetAttachmentById(ctx context.Context, todoId, id string) (domain.Attachment, error) {
+	attachment, ok := r.attachments[id]
+	if !ok || attachment.TodoId != todoId {
+		return domain.Attachment{}, ports.ErrAttachmentNotFound
+	}
+	return attachment, nil
+}
+
+func (r *memAttachments) DeleteAttachment(ctx context.Context, id string) error {
+	delete(r.attachments, id)
+	return nil
+}
+
+func (r *memAttachments) referenced(hash string) bool {
+	for _, attachment := range r.attachments {
+		if attachment.ContentHash == hash {
+			return true
+		}
+	}
+	return r.reused[hash]
+}
+
+func (r *memAttachments) GetOrphanBlobs(ctx context.Context, limit int) ([]string, error) {
+	hashes := []string{}
+	for hash := range r.blobs {
+		if !r.referenced(hash) || r.reused[hash] {
+			hashes = append(hashes, hash)
+		}
+	}
+	sort.Strings(hashes)
+	if len(hashes) > limit {
+		hashes = hashes[:limit]
+	}
+	return hashes, nil
+}
+
+func (r *memAttachments) DeleteOrphanBlob(ctx context.Context, hash string) (bool, error) {
+	if !r.blobs[hash] || r.referenced(hash) {
+		return false, nil
+	}
+	delete(r.blobs, hash)
+	return true, nil
+}
+
+// memBlobs - хранилище содержимого в памяти
+type memBlobs struct {
+	objects   map[string][]byte
+	puts      int
+	deleteErr error
+}
+
+func newMemBlobs() *memBlobs {
+	return &memBlobs{objects: map[string][]byte{}}
+}
+
+func (s *memBlobs) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	s.objects[key] = content
+	s.puts++
+	return nil
+}
+
+func (s *memBlobs) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	content, ok := s.objects[key]
+	if !ok {
+		return nil, ports.ErrBlobNotFound
+	}
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+func (s *memBlobs) Exists(ctx context.Context, key string) (bool, error) {
+	_, ok := s.objects[key]
+	return ok, nil
+}
+
+func (s *memBlobs) Delete(ctx context.Context, key string) error {
+	if s.deleteErr != nil {
+		return s.deleteErr
+	}
+	delete(s.objects, key)
+	return nil
+}
+
+func newTestAttachmentService(t *testing.T, attachments *memAttachments, blobs *memBlobs, limits AttachmentLimits) ports.AttachmentService {
+	t.Helper()
+	todos := newMemTodos(domain.ToDo{Id: "t1", OwnerId: "alice", Todo: "t1"})
+	shares := &memShares{roles: map[string]string{"viewer/t1": domain.RoleViewer}}
+	return NewAttachmentService(attachments, todos, shares, blobs, inlineUnitOfWork{}, limits, newTestLogger(t))
+}
+
+func upload(name, content string) ports.Upload {
+	return ports.Upload{Filename: name, Size: int64(len(content)), Content: strings.NewReader(content)}
+}
+
+func TestUploadLimits(t *testing.T) {
+	limits := AttachmentLimits{MaxSize: 64, AllowedTypes: []string{"text/plain"}}
+	png := "\x89PNG\r\n\x1a\n" + strings.Repeat("\x00", 16)
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		upload  ports.Upload
+		wantErr error
+	}{
+		{name: "allowed", ctx: asUser("alice", domain.UserRoleUser), upload: upload("notes.txt", "hello")},
+		{name: "too large", ctx: asUser("alice", domain.UserRoleUser), upload: upload("big.txt", strings.Repeat("a", 65)), wantErr: ErrAttachmentTooLarge},
+		{name: "empty", ctx: asUser("alice", domain.UserRoleUser), upload: upload("empty.txt", ""), wantErr: ErrAttachmentEmpty},
+		// Тип определяется по содержимому, а не по имени файла
+		{name: "type by content", ctx: asUser("alice", domain.UserRoleUser), upload: upload("image.txt", png), wantErr: ErrAttachmentType},
+		{name: "viewer", ctx: asUser("viewer", domain.UserRoleUser), upload: upload("notes.txt", "hello"), wantErr: ports.ErrForbidden},
+		{
+			// Лимит пространства меньше лимита экземпляра
+			name: "workspace limit",
+			ctx: identity.WithWorkspace(asUser("alice", domain.UserRoleUser),
+				domain.Workspace{Id: "w1", Settings: domain.WorkspaceSettings{AttachmentMaxSize: 4}}, domain.WorkspaceRoleMember),
+			upload:  upload("notes.txt", "hello"),
+			wantErr: ErrAttachmentTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attachments, blobs := newMemAttachments(), newMemBlobs()
+			s := newTestAttachmentService(t, attachments, blobs, limits)
+
+			attachment, err := s.Upload(tt.ctx, "t1", tt.upload)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Upload = %v, want %v", err, tt.wantErr)
+				}
+				if len(attachments.attachments) != 0 || len(blobs.objects) != 0 {
+					t.Errorf("rejected upload was stored: %d attachments, %d blobs", len(attachments.attachments), len(blobs.objects))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Upload: %v", err)
+			}
+			if attachment.ContentType != "text/plain" || string(blobs.objects[attachment.ContentHash]) != "hello" {
+				t.Errorf("attachment = %+v, stored %q", attachment, blobs.objects[attachment.ContentHash])
+			}
+		})
+	}
+}
+
+func TestUploadStoresSameContentOnce(t *testing.T) {
+	attachments, blobs := newMemAttachments(), newMemBlobs()
+	s := newTestAttachmentService(t, attachments, blobs, DefaultAttachmentLimits)
+	ctx := asUser("alice", domain.UserRoleUser)
+
+	first, err := s.Upload(ctx, "t1", upload("a.txt", "same"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := s.Upload(ctx, "t1", upload("b.txt", "same"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.ContentHash != second.ContentHash || blobs.puts != 1 {
+		t.Errorf("hashes %s/%s, %d puts; want one shared object", first.ContentHash, second.ContentHash, blobs.puts)
+	}
+
+	// Содержимое удаляется вместе с последним вложением, которое на него ссылается
+	if err := s.DeleteAttachment(ctx, "t1", first.Id); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := blobs.objects[first.ContentHash]; !ok {
+		t.Fatal("blob deleted while another attachment uses it")
+	}
+	if err := s.DeleteAttachment(ctx, "t1", second.Id); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := blobs.objects[first.ContentHash]; ok {
+		t.Error("blob kept after its last attachment was deleted")
+	}
+}
+
+func TestBlobCollector(t *testing.T) {
+	attachments, blobs := newMemAttachments(), newMemBlobs()
+	for _, hash := range []string{"aaa", "bbb", "ccc"} {
+		attachments.blobs[hash] = true
+		blobs.objects[hash] = []byte(hash)
+	}
+	// Вложение задачи ещё ссылается на bbb, на ccc сослалось новое вложение
+	attachments.attachments["x"] = domain.Attachment{Id: "x", TodoId: "t1", ContentHash: "bbb"}
+	attachments.reused["ccc"] = true
+
+	deleted, err := NewBlobCollector(attachments, blobs, inlineUnitOfWork{}, newTestLogger(t)).Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("deleted %d blobs, want 1", deleted)
+	}
+	for hash, want := range map[string]bool{"aaa": false, "bbb": true, "ccc": true} {
+		if _, ok := blobs.objects[hash]; ok != want {
+			t.Errorf("blob %s kept = %v, want %v", hash, ok, want)
+		}
+	}
+}
+
+func TestDeleteOrphanBlobReportsStoreFailure(t *testing.T) {
+	attachments, blobs := newMemAttachments(), newMemBlobs()
+	attachments.blobs["aaa"] = true
+	blobs.objects["aaa"] = []byte("a")
+	blobs.deleteErr = errors.New("storage unavailable")
+
+	// Ошибка хранилища откатывает удаление записи в транзакции и не считается удалением
+	deleted, err := deleteOrphanBlob(context.Background(), attachments, blobs, inlineUnitOfWork{}, "aaa")
+	if deleted || !errors.Is(err, blobs.deleteErr) {
+		t.Errorf("deleteOrphanBlob = %v, %v; want false and the store error", deleted, err)
+	}
+
+	blobs.deleteErr = nil
+	attachments.blobs["bbb"] = true
+	attachments.attachments["x"] = domain.Attachment{Id: "x", ContentHash: "bbb"}
+	blobs.objects["bbb"] = []byte("b")
+	if deleted, err := deleteOrphanBlob(context.Background(), attachments, blobs, inlineUnitOfWork{}, "bbb"); deleted || err != nil {
+		t.Errorf("deleteOrphanBlob of a used blob = %v, %v; want false", deleted, err)
+	}
+	if _, ok := blobs.objects["bbb"]; !ok {
+		t.Error("used blob was deleted from the store")
+	}
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/identity"
+	"ToDo-List/internal/core/ports"
+
+	"github.com/google/uuid"
+)
+
+type AuditService struct {
+	audit  ports.AuditRepo
+	logger *logger.Logger
+}
+
+func NewAuditService(audit ports.AuditRepo, logger *logger.Logger) ports.AuditService {
+	return &AuditService{
+		audit:  audit,
+		logger: logger,
+	}
+}
+
+// Record пишет действие пользователя из контекста. Ошибка записи не прерывает
+// действие, только попадает в лог.
+func (s *AuditService) Record(ctx context.Context, action, targetType, targetId string, details map[string]string) {
+	actorId, _ := identity.UserId(ctx)
+	impersonatorId, _ := identity.ImpersonatorId(ctx)
+
+	entry := domain.AuditEntry{
+		Id:             uuid.NewString(),
+		ActorId:        actorId,
+		ImpersonatorId: impersonatorId,
+		Action:         action,
+		TargetType:     targetType,
+		TargetId:       targetId,
+		Details:        details,
+		CreatedAt:      time.Now(),
+	}
+	if err := s.audit.CreateAuditEntry(ctx, entry); err != nil {
+		s.log(ctx).Error("Failed to write audit entry %s by %s: %v", action, actorId, err)
+	}
+}
+
+// log - логгер запроса с его X-Request-ID
+func (s *AuditService) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, s.logger)
+}
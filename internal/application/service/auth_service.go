@@ -0,0 +1,350 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/policy"
+	"ToDo-List/internal/core/ports"
+
+	"github.com/google/uuid"
+)
+
+// DefaultSessionTTL - время жизни сессии, если не задано в конфигурации
+const DefaultSessionTTL = 7 * 24 * time.Hour
+
+// MinPasswordLength - минимальная длина пароля при регистрации
+const MinPasswordLength = 8
+
+var (
+	ErrInvalidUsername = errors.New("username must be 3-32 characters: letters, digits, '.', '_' or '-'")
+	ErrInvalidEmail    = errors.New("invalid email")
+	ErrWeakPassword    = errors.New("password must be at least 8 characters")
+)
+
+var (
+	usernamePattern = regexp.MustCompile(`^[a-z0-9._-]{3,32}$`)
+	emailPattern    = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+)
+
+type AuthService struct {
+	users      ports.UserRepo
+	sessions   ports.SessionRepo
+	uow        ports.UnitOfWork
+	hasher     ports.PasswordHasher
+	sessionTTL time.Duration
+	logger     *logger.Logger
+
+	// dummyHash сравнивается с паролем, когда пользователь не найден,
+	// чтобы время ответа не выдавало существование имени
+	dummyHash string
+}
+
+func NewAuthService(users ports.UserRepo, sessions ports.SessionRepo, uow ports.UnitOfWork, hasher ports.PasswordHasher, sessionTTL time.Duration, logger *logger.Logger) ports.AuthService {
+	if sessionTTL <= 0 {
+		sessionTTL = DefaultSessionTTL
+	}
+	dummyHash, err := hasher.Hash(uuid.NewString())
+	if err != nil {
+		logger.Error("Failed to prepare dummy password hash: %v", err)
+	}
+	return &AuthService{
+		users:      users,
+		sessions:   sessions,
+		uow:        uow,
+		hasher:     hasher,
+		sessionTTL: sessionTTL,
+		logger:     logger,
+		dummyHash:  dummyHash,
+	}
+}
+
+func (s *AuthService) Register(ctx context.Context, username, email, password string) (domain.User, error) {
+	username = strings.ToLower(strings.TrimSpace(username))
+	email = strings.ToLower(strings.TrimSpace(email))
+	s.log(ctx).Debug("Registering user: %s", username)
+
+	if !usernamePattern.MatchString(username) {
+		return domain.User{}, ErrInvalidUsername
+	}
+	if email != "" && !emailPattern.MatchString(email) {
+		return domain.User{}, ErrInvalidEmail
+	}
+	if len(password) < MinPasswordLength {
+		return domain.User{}, ErrWeakPassword
+	}
+
+	hash, err := s.hasher.Hash(password)
+	if err != nil {
+		s.log(ctx).Error("Failed to hash password: %v", err)
+		return domain.User{}, err
+	}
+
+	now := time.Now()
+	user := domain.User{
+		Id:           uuid.NewString(),
+		Username:     username,
+		Email:        email,
+		PasswordHash: hash,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	return s.users.CreateUser(ctx, user)
+}
+
+func (s *AuthService) Login(ctx context.Context, username, password string) (domain.User, domain.Session, string, error) {
+	username = strings.ToLower(strings.TrimSpace(username))
+	s.log(ctx).Debug("Login attempt: %s", username)
+
+	user, err := s.users.GetUserByUsername(ctx, username)
+	if err != nil {
+		s.hasher.Verify(password, s.dummyHash)
+		return domain.User{}, domain.Session{}, "", ports.ErrInvalidCredentials
+	}
+	// У пользователей SSO нет локального пароля
+	if user.PasswordHash == "" {
+		s.hasher.Verify(password, s.dummyHash)
+		return domain.User{}, domain.Session{}, "", ports.ErrInvalidCredentials
+	}
+
+	ok, err := s.hasher.Verify(password, user.PasswordHash)
+	if err != nil {
+		s.log(ctx).Error("Failed to verify password for %s: %v", user.Id, err)
+		return domain.User{}, domain.Session{}, "", ports.ErrInvalidCredentials
+	}
+	if !ok {
+		s.log(ctx).Warn("Invalid password for user %s", user.Id)
+		return domain.User{}, domain.Session{}, "", ports.ErrInvalidCredentials
+	}
+	// Блокировку сообщаем только после проверки пароля
+	if user.Disabled {
+		s.log(ctx).Warn("Login of disabled user %s", user.Id)
+		return domain.User{}, domain.Session{}, "", ports.ErrUserDisabled
+	}
+
+	session, token, err := s.createSession(ctx, user.Id)
+	if err != nil {
+		return domain.User{}, domain.Session{}, "", err
+	}
+
+	// Попутно чистим истёкшие сессии, отдельный планировщик не нужен
+	if n, err := s.sessions.DeleteExpiredSessions(ctx); err != nil {
+		s.log(ctx).Warn("Failed to delete expired sessions: %v", err)
+	} else if n > 0 {
+		s.log(ctx).Debug("Deleted %d expired sessions", n)
+	}
+
+	s.log(ctx).Info("User logged in: %s", user.Id)
+	return user, session, token, nil
+}
+
+func (s *AuthService) LoginExternal(ctx context.Context, ident ports.ExternalIdentity) (domain.User, domain.Session, string, error) {
+	s.log(ctx).Debug("External login: issuer=%s, subject=%s", ident.Issuer, ident.Subject)
+
+	if ident.Issuer == "" || ident.Subject == "" {
+		return domain.User{}, domain.Session{}, "", ports.ErrInvalidCredentials
+	}
+
+	user, err := s.users.GetUserByExternalIdentity(ctx, ident.Issuer, ident.Subject)
+	if err != nil {
+		// Первый вход: создаём пользователя. К существующей локальной учётной
+		// записи по email не привязываем - иначе провайдер мог бы захватить её.
+		user, err = s.createExternalUser(ctx, ident)
+		if err != nil {
+			return domain.User{}, domain.Session{}, "", err
+		}
+	}
+	if user.Disabled {
+		s.log(ctx).Warn("External login of disabled user %s", user.Id)
+		return domain.User{}, domain.Session{}, "", ports.ErrUserDisabled
+	}
+
+	session, token, err := s.createSession(ctx, user.Id)
+	if err != nil {
+		return domain.User{}, domain.Session{}, "", err
+	}
+
+	s.log(ctx).Info("User logged in via %s: %s", ident.Issuer, user.Id)
+	return user, session, token, nil
+}
+
+// createExternalUser создаёт пользователя по утверждениям провайдера. Имя берётся
+// из claim, при занятости к нему добавляется случайный суффикс.
+func (s *AuthService) createExternalUser(ctx context.Context, ident ports.ExternalIdentity) (domain.User, error) {
+	base := sanitizeUsername(ident.Username)
+	email := ""
+	if ident.EmailVerified {
+		email = strings.ToLower(strings.TrimSpace(ident.Email))
+	}
+
+	now := time.Now()
+	user := domain.User{
+		Username:  base,
+		Email:     email,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	const attempts = 5
+	for i := 0; i < attempts; i++ {
+		user.Id = uuid.NewString()
+		err := s.uow.Do(ctx, func(ctx context.Context) error {
+			created, err := s.users.CreateUser(ctx, user)
+			if err != nil {
+				return err
+			}
+			user = created
+			return s.users.LinkExternalIdentity(ctx, user.Id, ident.Issuer, ident.Subject)
+		})
+		if err == nil {
+			s.log(ctx).Info("Created user %s (%s) for %s at %s", user.Id, user.Username, ident.Subject, ident.Issuer)
+			return user, nil
+		}
+		if !errors.Is(err, ports.ErrUserExists) {
+			return domain.User{}, err
+		}
+
+		// Конфликт: параллельный первый вход уже создал пользователя, занято имя или email
+		if linked, lookupErr := s.users.GetUserByExternalIdentity(ctx, ident.Issuer, ident.Subject); lookupErr == nil {
+			return linked, nil
+		}
+		if _, lookupErr := s.users.GetUserByUsername(ctx, user.Username); lookupErr == nil {
+			suffix, tokenErr := randomToken()
+			if tokenErr != nil {
+				return domain.User{}, tokenErr
+			}
+			user.Username = truncate(base, 32-5) + "-" + strings.ToLower(suffix[:4])
+		} else {
+			s.log(ctx).Warn("Email of %s at %s is already used, creating user without email", ident.Subject, ident.Issuer)
+			user.Email = ""
+		}
+	}
+	return domain.User{}, ports.ErrUserExists
+}
+
+// sanitizeUsername приводит claim к допустимому имени пользователя
+func sanitizeUsername(claim string) string {
+	claim = strings.ToLower(strings.TrimSpace(claim))
+	if at := strings.IndexByte(claim, '@'); at > 0 {
+		claim = claim[:at]
+	}
+
+	var b strings.Builder
+	for _, r := range claim {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('-')
+		}
+	}
+	name := truncate(b.String(), 32)
+	for len(name) < 3 {
+		name += "_"
+	}
+	return name
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
+
+func (s *AuthService) createSession(ctx context.Context, userId string) (domain.Session, string, error) {
+	now := time.Now()
+	return openSession(ctx, s.sessions, domain.Session{
+		UserId:    userId,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.sessionTTL),
+	})
+}
+
+// openSession сохраняет сессию с новыми токенами: клиенту отдаётся токен,
+// в БД - только его хеш
+func openSession(ctx context.Context, sessions ports.SessionRepo, session domain.Session) (domain.Session, string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return domain.Session{}, "", err
+	}
+	csrf, err := randomToken()
+	if err != nil {
+		return domain.Session{}, "", err
+	}
+
+	session.Id = hashToken(token)
+	session.CSRFToken = csrf
+	if err := sessions.CreateSession(ctx, session); err != nil {
+		return domain.Session{}, "", err
+	}
+	return session, token, nil
+}
+
+func (s *AuthService) Logout(ctx context.Context, token string) error {
+	s.log(ctx).Debug("Logging out session")
+	return s.sessions.DeleteSession(ctx, hashToken(token))
+}
+
+func (s *AuthService) Authenticate(ctx context.Context, token string) (domain.User, domain.Session, error) {
+	if token == "" {
+		return domain.User{}, domain.Session{}, ports.ErrUnauthenticated
+	}
+
+	session, err := s.sessions.GetSession(ctx, hashToken(token))
+	if err != nil {
+		return domain.User{}, domain.Session{}, err
+	}
+	if time.Now().After(session.ExpiresAt) {
+		s.log(ctx).Debug("Session expired for user %s", session.UserId)
+		s.sessions.DeleteSession(ctx, session.Id)
+		return domain.User{}, domain.Session{}, ports.ErrUnauthenticated
+	}
+
+	user, err := s.users.GetUserById(ctx, session.UserId)
+	if err != nil || user.Disabled {
+		return domain.User{}, domain.Session{}, ports.ErrUnauthenticated
+	}
+	if session.ImpersonatorId != "" {
+		// Сессия от чужого имени живёт, пока администратор сохраняет это право
+		admin, err := s.users.GetUserById(ctx, session.ImpersonatorId)
+		if err != nil && !errors.Is(err, ports.ErrUserNotFound) {
+			return domain.User{}, domain.Session{}, err
+		}
+		if err != nil || admin.Disabled || !policy.Allows(admin.Role, policy.UsersImpersonate) {
+			s.log(ctx).Info("Impersonation session of %s revoked: admin %s lost access", session.UserId, session.ImpersonatorId)
+			s.sessions.DeleteSession(ctx, session.Id)
+			return domain.User{}, domain.Session{}, ports.ErrUnauthenticated
+		}
+	}
+	return user, session, nil
+}
+
+// randomToken возвращает 256 случайных бит в base64url
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken - токены хранятся в БД только в виде SHA-256
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// log - логгер запроса с его X-Request-ID
+func (s *AuthService) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, s.logger)
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/ports"
+)
+
+func newTestAuthService(t *testing.T, users *memUsers, sessions *memSessions) ports.AuthService {
+	t.Helper()
+	return NewAuthService(users, sessions, inlineUnitOfWork{}, plainHasher{}, time.Hour, newTestLogger(t))
+}
+
+func TestRegister(t *testing.T) {
+	users := newMemUsers()
+	auth := newTestAuthService(t, users, newMemSessions())
+
+	user, err := auth.Register(context.Background(), "  Alice ", "Alice@Example.com", "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Имя и email хранятся в нижнем регистре, пароль - только хешем
+	if user.Username != "alice" || user.Email != "alice@example.com" || user.Role != domain.UserRoleUser || user.Id == "" {
+		t.Errorf("registered user = %+v", user)
+	}
+	if _, err := auth.Register(context.Background(), "ALICE", "", "another password"); !errors.Is(err, ports.ErrUserExists) {
+		t.Errorf("duplicate username = %v, want ErrUserExists", err)
+	}
+	if _, err := auth.Register(context.Background(), "alice2", "alice@example.com", "another password"); !errors.Is(err, ports.ErrUserExists) {
+		t.Errorf("duplicate email = %v, want ErrUserExists", err)
+	}
+}
+
+func TestRegisterValidation(t *testing.T) {
+	tests := []struct {
+		username, email, password string
+		want                      error
+	}{
+		{username: "al", password: "long enough", want: ErrInvalidUsername},
+		{username: "alice smith", password: "long enough", want: ErrInvalidUsername},
+		{username: "alice", email: "not-an-email", password: "long enough", want: ErrInvalidEmail},
+		{username: "alice", password: "short", want: ErrWeakPassword},
+	}
+	for _, tt := range tests {
+		users := newMemUsers()
+		_, err := newTestAuthService(t, users, newMemSessions()).Register(context.Background(), tt.username, tt.email, tt.password)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("Register(%q, %q, %q) = %v, want %v", tt.username, tt.email, tt.password, err, tt.want)
+		}
+		if len(users.users) != 0 {
+			t.Errorf("Register(%q) stored an invalid user", tt.username)
+		}
+	}
+}
+
+func TestLoginAndLogout(t *testing.T) {
+	sessions := newMemSessions()
+	auth := newTestAuthService(t, newMemUsers(domain.User{Id: "u1", Username: "alice", PasswordHash: "correct horse"}), sessions)
+
+	user, session, token, err := auth.Login(context.Background(), "Alice", "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Id != "u1" || token == "" || session.CSRFToken == "" {
+		t.Fatalf("Login = %+v, %+v, %q", user, session, token)
+	}
+	// В хранилище только хеш токена
+	if _, ok := sessions.sessions[token]; ok || session.Id != hashToken(token) {
+		t.Error("session stored under the plain token")
+	}
+
+	authenticated, _, err := auth.Authenticate(context.Background(), token)
+	if err != nil || authenticated.Id != "u1" {
+		t.Fatalf("Authenticate = %q, %v; want u1", authenticated.Id, err)
+	}
+	if err := auth.Logout(context.Background(), token); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := auth.Authenticate(context.Background(), token); !errors.Is(err, ports.ErrUnauthenticated) {
+		t.Errorf("Authenticate after logout = %v, want ErrUnauthenticated", err)
+	}
+}
+
+func TestLoginRejected(t *testing.T) {
+	users := newMemUsers(
+		domain.User{Id: "u1", Username: "alice", PasswordHash: "correct horse"},
+		domain.User{Id: "u2", Username: "bob", PasswordHash: "bob password", Disabled: true},
+		// пользователь SSO без локального пароля
+		domain.User{Id: "u3", Username: "carol"},
+	)
+	tests := []struct {
+		username, password string
+		want               error
+	}{
+		{username: "alice", password: "wrong", want: ports.ErrInvalidCredentials},
+		{username: "nobody", password: "correct horse", want: ports.ErrInvalidCredentials},
+		{username: "carol", password: "", want: ports.ErrInvalidCredentials},
+		// О блокировке узнаёт только тот, кто знает пароль
+		{username: "bob", password: "wrong", want: ports.ErrInvalidCredentials},
+		{username: "bob", password: "bob password", want: ports.ErrUserDisabled},
+	}
+	for _, tt := range tests {
+		sessions := newMemSessions()
+		_, _, _, err := newTestAuthService(t, users, sessions).Login(context.Background(), tt.username, tt.password)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("Login(%q, %q) = %v, want %v", tt.username, tt.password, err, tt.want)
+		}
+		if len(sessions.sessions) != 0 {
+			t.Errorf("Login(%q, %q) created a session", tt.username, tt.password)
+		}
+	}
+}
+
+func TestAuthenticateRejected(t *testing.T) {
+	users := newMemUsers(
+		domain.User{Id: "u1", Username: "alice"},
+		domain.User{Id: "u2", Username: "bob", Disabled: true},
+	)
+	sessions := newMemSessions()
+	auth := newTestAuthService(t, users, sessions)
+
+	_, expiredToken, _ := openSession(context.Background(), sessions, domain.Session{UserId: "u1", ExpiresAt: time.Now().Add(-time.Minute)})
+	_, disabledToken, _ := openSession(context.Background(), sessions, domain.Session{UserId: "u2", ExpiresAt: time.Now().Add(time.Hour)})
+
+	for name, token := range map[string]string{"empty": "", "unknown": "no-such-token", "expired": expiredToken, "disabled user": disabledToken} {
+		if _, _, err := auth.Authenticate(context.Background(), token); !errors.Is(err, ports.ErrUnauthenticated) {
+			t.Errorf("%s: Authenticate = %v, want ErrUnauthenticated", name, err)
+		}
+	}
+	// Истёкшая сессия удаляется при первой проверке
+	if _, ok := sessions.sessions[hashToken(expiredToken)]; ok {
+		t.Error("expired session kept")
+	}
+}
+
+func TestLoginExternal(t *testing.T) {
+	users := newMemUsers()
+	auth := newTestAuthService(t, users, newMemSessions())
+	ident := ports.ExternalIdentity{Issuer: "https://idp", Subject: "s1", Username: "Alice.Smith@corp.example", Email: "Alice@Corp.example", EmailVerified: true}
+
+	user, _, token, err := auth.LoginExternal(context.Background(), ident)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Имя из claim приводится к допустимому, локального пароля нет
+	if user.Username != "alice.smith" || user.Email != "alice@corp.example" || user.PasswordHash != "" || token == "" {
+		t.Errorf("created user = %+v", user)
+	}
+
+	again, _, _, err := auth.LoginExternal(context.Background(), ident)
+	if err != nil || again.Id != user.Id || len(users.users) != 1 {
+		t.Errorf("second login = %q, %v; want the same user %q", again.Id, err, user.Id)
+	}
+	if _, _, _, err := auth.Login(context.Background(), "alice.smith", ""); !errors.Is(err, ports.ErrInvalidCredentials) {
+		t.Errorf("password login of an SSO user = %v, want ErrInvalidCredentials", err)
+	}
+}
+
+func TestLoginExternalConflicts(t *testing.T) {
+	users := newMemUsers(domain.User{Id: "local", Username: "alice", Email: "alice@corp.example", PasswordHash: "secret password"})
+	auth := newTestAuthService(t, users, newMemSessions())
+
+	// Занятые имя и email не привязывают провайдера к локальной учётной записи
+	user, _, _, err := auth.LoginExternal(context.Background(), ports.ExternalIdentity{Issuer: "https://idp", Subject: "s1", Username: "alice", Email: "alice@corp.example", EmailVerified: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Id == "local" || !strings.HasPrefix(user.Username, "alice-") || user.Email != "" {
+		t.Errorf("user = %+v, want a new user with a suffixed name and no email", user)
+	}
+
+	// Неподтверждённый email не сохраняется
+	user, _, _, err = auth.LoginExternal(context.Background(), ports.ExternalIdentity{Issuer: "https://idp", Subject: "s2", Username: "bob", Email: "bob@corp.example"})
+	if err != nil || user.Username != "bob" || user.Email != "" {
+		t.Errorf("unverified email: user = %+v, %v", user, err)
+	}
+}
+
+func TestLoginExternalRejected(t *testing.T) {
+	users := newMemUsers(domain.User{Id: "u1", Username: "alice", Disabled: true})
+	users.identities["https://idp/s1"] = "u1"
+	sessions := newMemSessions()
+	auth := newTestAuthService(t, users, sessions)
+
+	if _, _, _, err := auth.LoginExternal(context.Background(), ports.ExternalIdentity{Issuer: "https://idp", Subject: "s1"}); !errors.Is(err, ports.ErrUserDisabled) {
+		t.Errorf("disabled user = %v, want ErrUserDisabled", err)
+	}
+	if _, _, _, err := auth.LoginExternal(context.Background(), ports.ExternalIdentity{Issuer: "https://idp"}); !errors.Is(err, ports.ErrInvalidCredentials) {
+		t.Errorf("no subject = %v, want ErrInvalidCredentials", err)
+	}
+	if len(sessions.sessions) != 0 {
+		t.Error("session created for a rejected login")
+	}
+}
+
+func TestSanitizeUsername(t *testing.T) {
+	tests := map[string]string{
+		"Alice":                    "alice",
+		"alice.smith@corp.example": "alice.smith",
+		"Иван Петров":              "-----------",
+		"a":                        "a__",
+		strings.Repeat("x", 40):    strings.Repeat("x", 32),
+	}
+	for claim, want := range tests {
+		if got := sanitizeUsername(claim); got != want {
+			t.Errorf("sanitizeUsername(%q) = %q, want %q", claim, got, want)
+		}
+	}
+}
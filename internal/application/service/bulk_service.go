@@ -0,0 +1,312 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/identity"
+	"ToDo-List/internal/core/policy"
+	"ToDo-List/internal/core/ports"
+	"ToDo-List/internal/core/rank"
+)
+
+// MaxBulkItems - ограничение на количество задач в одной массовой операции
+const MaxBulkItems = 1000
+
+// ErrTooManyItems - операция затрагивает больше MaxBulkItems задач
+var ErrTooManyItems = errors.New("too many todos for bulk operation")
+
+// errItemFailed откатывает точку сохранения одной задачи в режиме per_item
+var errItemFailed = errors.New("bulk item failed")
+
+// BulkService применяет действие к задачам из списка текущего пользователя.
+// Каждая задача обрабатывается через TodoService, как одиночными запросами:
+// с теми же проверками и уведомлениями.
+type BulkService struct {
+	bulk   ports.BulkRepo
+	repo   ports.PostgreRepo
+	todos  ports.ToDoService
+	uow    ports.UnitOfWork
+	logger *logger.Logger
+}
+
+func NewBulkService(bulk ports.BulkRepo, repo ports.PostgreRepo, todos ports.ToDoService, uow ports.UnitOfWork, logger *logger.Logger) ports.BulkService {
+	return &BulkService{
+		bulk:   bulk,
+		repo:   repo,
+		todos:  todos,
+		uow:    uow,
+		logger: logger,
+	}
+}
+
+func (s *BulkService) ApplyBulk(ctx context.Context, req ports.BulkRequest) (ports.BulkResult, error) {
+	s.log(ctx).Debug("Applying bulk %s (dryRun=%t, mode=%s)", req.Action, req.DryRun, req.Mode)
+
+	result := ports.BulkResult{Action: req.Action, DryRun: req.DryRun}
+
+	ids, err := s.resolveIds(ctx, req)
+	if err != nil {
+		return result, err
+	}
+	if len(ids) > MaxBulkItems {
+		return result, fmt.Errorf("%w: matched %d, limit is %d", ErrTooManyItems, len(ids), MaxBulkItems)
+	}
+
+	// Предпросмотр: только количество задач, которые будут затронуты
+	if req.DryRun {
+		if req.Filter != nil {
+			result.Matched = len(ids)
+		} else if len(ids) > 0 {
+			result.Matched, err = s.bulk.CountExistingTodos(ctx, ids)
+			if err != nil {
+				return result, err
+			}
+		}
+		s.log(ctx).Info("Bulk %s dry run matched %d todos", req.Action, result.Matched)
+		return result, nil
+	}
+
+	if err := policy.Check(ctx, policy.TodosWrite); err != nil {
+		return result, err
+	}
+	result.Matched = len(ids)
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	action := req.BulkAction
+	if action.Action == ports.BulkMove {
+		action.Positions, err = s.movePositions(ctx, ids, action.Move)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	// Уведомления уходят только после фиксации транзакции: откаченные
+	// изменения не должны до них дойти
+	ctx, notifications := deferNotifications(ctx)
+	items, err := s.apply(ctx, ids, action, req.Mode != "per_item")
+	result.Items = items
+	for _, item := range items {
+		switch item.Status {
+		case "ok":
+			result.Succeeded++
+		case "skipped":
+		default:
+			result.Failed++
+		}
+	}
+	if err == ports.ErrBulkAborted {
+		// Транзакция откачена, ни одно изменение не сохранено
+		result.Succeeded = 0
+	}
+	if err != nil {
+		s.log(ctx).Warn("Bulk %s failed: %v", req.Action, err)
+		return result, err
+	}
+	notifications.flush()
+
+	s.log(ctx).Info("Bulk %s: %d succeeded, %d failed", req.Action, result.Succeeded, result.Failed)
+	return result, nil
+}
+
+// apply выполняет действие над задачами в одной транзакции. При atomic
+// ошибка любой задачи откатывает всё, иначе каждая задача обрабатывается
+// во вложенном Do (точке сохранения) и её ошибка откатывает только её.
+func (s *BulkService) apply(ctx context.Context, ids []string, action ports.BulkAction, atomic bool) ([]ports.BulkItemResult, error) {
+	var items []ports.BulkItemResult
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		items = make([]ports.BulkItemResult, 0, len(ids))
+		for i, id := range ids {
+			if atomic {
+				item := s.applyItem(ctx, id, action)
+				items = append(items, item)
+				if item.Status != "ok" {
+					// Всё или ничего: остальные задачи не обрабатываются
+					s.log(ctx).Warn("Bulk %s aborted on todo %s: %s", action.Action, id, item.Error)
+					for _, rest := range ids[i+1:] {
+						items = append(items, ports.BulkItemResult{Id: rest, Status: "skipped"})
+					}
+					return ports.ErrBulkAborted
+				}
+				continue
+			}
+
+			var item ports.BulkItemResult
+			err := s.uow.Do(ctx, func(ctx context.Context) error {
+				item = s.applyItem(ctx, id, action)
+				if item.Status != "ok" {
+					return errItemFailed
+				}
+				return nil
+			})
+			if err != nil && err != errItemFailed {
+				return err
+			}
+			items = append(items, item)
+		}
+		return nil
+	})
+	return items, err
+}
+
+// applyItem выполняет действие над одной задачей пользователя
+func (s *BulkService) applyItem(ctx context.Context, id string, action ports.BulkAction) ports.BulkItemResult {
+	err := s.applyAction(ctx, id, action)
+	switch {
+	case err == nil:
+		return ports.BulkItemResult{Id: id, Status: "ok"}
+	case errors.Is(err, ports.ErrTodoNotFound):
+		return ports.BulkItemResult{Id: id, Status: "not_found", Error: "todo not found"}
+	default:
+		s.log(ctx).Warn("Bulk %s failed for todo %s: %v", action.Action, id, err)
+		return ports.BulkItemResult{Id: id, Status: "error", Error: err.Error()}
+	}
+}
+
+func (s *BulkService) applyAction(ctx context.Context, id string, action ports.BulkAction) error {
+	userId, err := identity.UserId(ctx)
+	if err != nil {
+		return err
+	}
+	todo, err := s.todos.GetTodoById(ctx, id)
+	if err != nil {
+		return err
+	}
+	// Как и выборка по фильтру, операция затрагивает только свои задачи
+	if todo.OwnerId != userId {
+		return ports.ErrTodoNotFound
+	}
+
+	switch action.Action {
+	case ports.BulkComplete:
+		return s.todos.CompleteTodoById(ctx, id)
+	case ports.BulkDelete:
+		return s.todos.DeleteTodo(ctx, id)
+	case ports.BulkMove:
+		position, ok := action.Positions[id]
+		if !ok {
+			return errors.New("no position computed")
+		}
+		return s.repo.UpdateTodoPosition(ctx, id, position)
+	case ports.BulkUncomplete:
+		todo.Complete = false
+		todo.CompletedAt = time.Time{}
+	case ports.BulkSetPriority:
+		todo.Priority = action.Priority
+	case ports.BulkSetDeadline:
+		todo.Deadline = action.Deadline
+	case ports.BulkTag:
+		todo.Tags = addTags(todo.Tags, action.Tags)
+	case ports.BulkUntag:
+		todo.Tags = removeTags(todo.Tags, action.Tags)
+	default:
+		return fmt.Errorf("unknown action %q", action.Action)
+	}
+	return s.todos.UpdateTodo(ctx, todo)
+}
+
+// addTags добавляет теги без повторов, сохраняя сортировку
+func addTags(tags, add []string) []string {
+	seen := map[string]bool{}
+	result := []string{}
+	for _, tag := range append(append([]string{}, tags...), add...) {
+		if !seen[tag] {
+			seen[tag] = true
+			result = append(result, tag)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// removeTags убирает из tags теги remove
+func removeTags(tags, remove []string) []string {
+	drop := map[string]bool{}
+	for _, tag := range remove {
+		drop[tag] = true
+	}
+	result := []string{}
+	for _, tag := range tags {
+		if !drop[tag] {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// resolveIds возвращает id задач из запроса или подходящих под фильтр.
+// По фильтру выбираются только свои задачи: чужие, открытые по доступу,
+// операция не затрагивает.
+func (s *BulkService) resolveIds(ctx context.Context, req ports.BulkRequest) ([]string, error) {
+	if req.Filter != nil {
+		userId, err := identity.UserId(ctx)
+		if err != nil {
+			return nil, err
+		}
+		todos, err := s.repo.GetAllTodosWithFilters(ctx, *req.Filter)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]string, 0, len(todos))
+		for _, todo := range todos {
+			if todo.OwnerId == userId {
+				ids = append(ids, todo.Id)
+			}
+		}
+		return ids, nil
+	}
+
+	ids := make([]string, 0, len(req.Ids))
+	seen := map[string]bool{}
+	for _, id := range req.Ids {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// movePositions выдаёт задачам новые позиции в начале или конце ручного
+// порядка, сохраняя порядок, в котором они перечислены
+func (s *BulkService) movePositions(ctx context.Context, ids []string, where string) (map[string]string, error) {
+	positions := make(map[string]string, len(ids))
+
+	if where == "top" {
+		key, err := s.repo.GetFirstPosition(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for i := len(ids) - 1; i >= 0; i-- {
+			if key, err = rank.Before(key); err != nil {
+				return nil, err
+			}
+			positions[ids[i]] = key
+		}
+		return positions, nil
+	}
+
+	key, err := s.repo.GetLastPosition(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		if key, err = rank.After(key); err != nil {
+			return nil, err
+		}
+		positions[id] = key
+	}
+	return positions, nil
+}
+
+// log - логгер запроса с его X-Request-ID
+func (s *BulkService) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, s.logger)
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/identity"
+	"ToDo-List/internal/core/ports"
+)
+
+// memBulk считает свои задачи пользователя, как PostgreBulkRepo
+type memBulk struct {
+	todos *memTodos
+}
+
+func (r memBulk) CountExistingTodos(ctx context.Context, ids []string) (int, error) {
+	userId, _ := identity.UserId(ctx)
+	n := 0
+	for _, id := range ids {
+		if todo, ok := r.todos.todos[id]; ok && todo.OwnerId == userId {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// newTestBulkService: у alice задачи a1 и a2 (исполнитель carol), bob открыл
+// alice свою задачу b1 на редактирование
+func newTestBulkService(t *testing.T) (ports.BulkService, *memTodos, *recordingNotifier) {
+	t.Helper()
+	todos := newMemTodos(
+		domain.ToDo{Id: "a1", OwnerId: "alice", Todo: "a1", Priority: "low", AssigneeId: "carol"},
+		domain.ToDo{Id: "a2", OwnerId: "alice", Todo: "a2", Priority: "low", AssigneeId: "carol"},
+		domain.ToDo{Id: "b1", OwnerId: "bob", Todo: "b1", Priority: "low"},
+	)
+	shares := &memShares{roles: map[string]string{"alice/b1": domain.RoleEditor}}
+	notifier := &recordingNotifier{}
+	todoService := newTestTodoService(t, todos, shares, notifier)
+	return NewBulkService(memBulk{todos}, todos, todoService, inlineUnitOfWork{}, newTestLogger(t)), todos, notifier
+}
+
+func TestBulkFilterMatchesOwnTodosOnly(t *testing.T) {
+	bulk, todos, notifier := newTestBulkService(t)
+	ctx := asUser("alice", domain.UserRoleUser)
+	req := ports.BulkRequest{Filter: &domain.TodoFilter{}, BulkAction: ports.BulkAction{Action: ports.BulkComplete}}
+
+	req.DryRun = true
+	preview, err := bulk.ApplyBulk(ctx, req)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if preview.Matched != 2 {
+		t.Errorf("dry run matched %d, want 2 own todos", preview.Matched)
+	}
+
+	req.DryRun = false
+	result, err := bulk.ApplyBulk(ctx, req)
+	if err != nil {
+		t.Fatalf("ApplyBulk: %v", err)
+	}
+	if result.Matched != preview.Matched || result.Succeeded != 2 || result.Failed != 0 {
+		t.Errorf("result = %+v, want the 2 previewed todos completed", result)
+	}
+	if todos.todos["b1"].Complete {
+		t.Error("shared todo b1 was completed by a filter")
+	}
+	// Как и POST /api/todo/complete/{id}, выполнение уведомляет исполнителя
+	if got := notifier.events(); len(got) != 2 || got[0] != "completed a1" || got[1] != "completed a2" {
+		t.Errorf("notifications = %v, want completed a1 and a2", got)
+	}
+}
+
+func TestBulkPerItemSkipsSharedTodos(t *testing.T) {
+	bulk, todos, notifier := newTestBulkService(t)
+	ctx := asUser("alice", domain.UserRoleUser)
+
+	result, err := bulk.ApplyBulk(ctx, ports.BulkRequest{
+		Ids:        []string{"a1", "b1", "missing"},
+		Mode:       "per_item",
+		BulkAction: ports.BulkAction{Action: ports.BulkDelete},
+	})
+	if err != nil {
+		t.Fatalf("ApplyBulk: %v", err)
+	}
+	want := []string{"ok", "not_found", "not_found"}
+	for i, item := range result.Items {
+		if item.Status != want[i] {
+			t.Errorf("item %s status = %q, want %q", item.Id, item.Status, want[i])
+		}
+	}
+	if _, ok := todos.todos["b1"]; !ok {
+		t.Error("shared todo b1 was deleted")
+	}
+	if got := notifier.events(); len(got) != 1 || got[0] != "deleted a1" {
+		t.Errorf("notifications = %v, want deleted a1", got)
+	}
+}
+
+func TestBulkAbortDropsNotifications(t *testing.T) {
+	bulk, _, notifier := newTestBulkService(t)
+
+	result, err := bulk.ApplyBulk(asUser("alice", domain.UserRoleUser), ports.BulkRequest{
+		Ids:        []string{"a1", "missing", "a2"},
+		BulkAction: ports.BulkAction{Action: ports.BulkSetPriority, Priority: "high"},
+	})
+	if !errors.Is(err, ports.ErrBulkAborted) {
+		t.Fatalf("ApplyBulk = %v, want ErrBulkAborted", err)
+	}
+	if result.Succeeded != 0 || len(result.Items) != 3 || result.Items[2].Status != "skipped" {
+		t.Errorf("result = %+v, want nothing succeeded and a2 skipped", result)
+	}
+	// Транзакция откачена: уведомление об изменении a1 не отправляется
+	if got := notifier.events(); len(got) != 0 {
+		t.Errorf("notifications = %v, want none", got)
+	}
+}
+
+func TestBulkTagsAndReadOnly(t *testing.T) {
+	bulk, todos, _ := newTestBulkService(t)
+
+	_, err := bulk.ApplyBulk(asUser("alice", domain.UserRoleUser), ports.BulkRequest{
+		Ids:        []string{"a1"},
+		BulkAction: ports.BulkAction{Action: ports.BulkTag, Tags: []string{"work", "home", "work"}},
+	})
+	if err != nil {
+		t.Fatalf("tag: %v", err)
+	}
+	if tags := todos.todos["a1"].Tags; len(tags) != 2 || tags[0] != "home" || tags[1] != "work" {
+		t.Errorf("tags = %v, want [home work]", tags)
+	}
+
+	_, err = bulk.ApplyBulk(asUser("alice", domain.UserRoleReadOnly), ports.BulkRequest{
+		Ids:        []string{"a1"},
+		BulkAction: ports.BulkAction{Action: ports.BulkDelete},
+	})
+	if !errors.Is(err, ports.ErrForbidden) {
+		t.Errorf("read-only bulk delete = %v, want ErrForbidden", err)
+	}
+}
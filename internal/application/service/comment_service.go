@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/identity"
+	"ToDo-List/internal/core/ports"
+
+	"github.com/google/uuid"
+)
+
+const maxCommentLength = 10000
+
+// ErrInvalidComment - пустой или слишком длинный текст комментария
+var ErrInvalidComment = errors.New("comment body must be 1-10000 characters")
+
+// mentionPattern находит @имя; перед @ не должно быть буквы или цифры,
+// чтобы не принимать за упоминание адрес почты
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w.@-])@([a-zA-Z0-9._-]{3,32})`)
+
+type CommentService struct {
+	comments ports.CommentRepo
+	todos    ports.PostgreRepo
+	shares   ports.ShareRepo
+	users    ports.UserRepo
+	uow      ports.UnitOfWork
+	notifier ports.Notifier
+	logger   *logger.Logger
+}
+
+func NewCommentService(comments ports.CommentRepo, todos ports.PostgreRepo, shares ports.ShareRepo, users ports.UserRepo, uow ports.UnitOfWork, notifier ports.Notifier, logger *logger.Logger) ports.CommentService {
+	return &CommentService{
+		comments: comments,
+		todos:    todos,
+		shares:   shares,
+		users:    users,
+		uow:      uow,
+		notifier: notifier,
+		logger:   logger,
+	}
+}
+
+// getTodo возвращает задачу, если она доступна пользователю. Комментировать
+// может любой, кому задача открыта, в том числе с ролью viewer.
+func (s *CommentService) getTodo(ctx context.Context, todoId string) (domain.ToDo, error) {
+	todo, err := s.todos.GetTodoById(ctx, todoId)
+	if err != nil {
+		s.log(ctx).Warn("Todo %s is not accessible for comments: %v", todoId, err)
+		return domain.ToDo{}, ErrResourceNotFound
+	}
+	return todo, nil
+}
+
+func (s *CommentService) ListComments(ctx context.Context, todoId string) ([]domain.Comment, error) {
+	if _, err := s.getTodo(ctx, todoId); err != nil {
+		return nil, err
+	}
+	return s.comments.GetCommentsByTodo(ctx, todoId)
+}
+
+func (s *CommentService) GetComment(ctx context.Context, todoId, id string) (domain.Comment, error) {
+	if _, err := s.getTodo(ctx, todoId); err != nil {
+		return domain.Comment{}, err
+	}
+	return s.comments.GetCommentById(ctx, todoId, id)
+}
+
+func (s *CommentService) AddComment(ctx context.Context, todoId, body string) (domain.Comment, error) {
+	s.log(ctx).Debug("Adding comment to todo %s", todoId)
+
+	body, err := validateCommentBody(body)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+	userId, err := identity.UserId(ctx)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+
+	var todo domain.ToDo
+	var comment domain.Comment
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		todo, err = s.getTodo(ctx, todoId)
+		if err != nil {
+			return err
+		}
+
+		mentions, err := s.resolveMentions(ctx, todo, body)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		comment = domain.Comment{
+			Id:        uuid.NewString(),
+			TodoId:    todoId,
+			AuthorId:  userId,
+			Body:      body,
+			Mentions:  mentions,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := s.comments.CreateComment(ctx, comment); err != nil {
+			return err
+		}
+		comment, err = s.comments.GetCommentById(ctx, todoId, comment.Id)
+		return err
+	})
+	if err != nil {
+		return domain.Comment{}, err
+	}
+
+	s.log(ctx).Info("Comment %s added to todo %s by %s", comment.Id, todoId, userId)
+	recipients := append(append([]string{todo.AssigneeId}, todo.Watchers...), mentionIds(comment.Mentions)...)
+	sendNotification(ctx, s.notifier, s.log(ctx), ports.Notification{Event: ports.EventCommented, CommentId: comment.Id}, todo, recipients)
+	return comment, nil
+}
+
+func (s *CommentService) EditComment(ctx context.Context, todoId, id, body string) (domain.Comment, error) {
+	s.log(ctx).Debug("Editing comment %s of todo %s", id, todoId)
+
+	body, err := validateCommentBody(body)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+	userId, err := identity.UserId(ctx)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+
+	var todo domain.ToDo
+	var comment domain.Comment
+	var previous []domain.Mention
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		todo, err = s.getTodo(ctx, todoId)
+		if err != nil {
+			return err
+		}
+		comment, err = s.comments.GetCommentById(ctx, todoId, id)
+		if err != nil {
+			return err
+		}
+		if comment.AuthorId != userId {
+			s.log(ctx).Warn("User %s cannot edit comment %s of %s", userId, id, comment.AuthorId)
+			return ports.ErrForbidden
+		}
+		previous = comment.Mentions
+		if comment.Body == body {
+			return nil
+		}
+
+		revision := domain.CommentRevision{
+			Id:        uuid.NewString(),
+			CommentId: id,
+			Body:      comment.Body,
+			EditorId:  userId,
+			EditedAt:  time.Now(),
+		}
+		comment.Body = body
+		comment.UpdatedAt = revision.EditedAt
+		comment.Mentions, err = s.resolveMentions(ctx, todo, body)
+		if err != nil {
+			return err
+		}
+		if err := s.comments.UpdateComment(ctx, comment, revision); err != nil {
+			return err
+		}
+		comment, err = s.comments.GetCommentById(ctx, todoId, id)
+		return err
+	})
+	if err != nil {
+		return domain.Comment{}, err
+	}
+
+	// Уведомляем только тех, кого упомянули в новой редакции впервые
+	seen := map[string]bool{}
+	for _, m := range previous {
+		seen[m.UserId] = true
+	}
+	added := []string{}
+	for _, m := range comment.Mentions {
+		if !seen[m.UserId] {
+			added = append(added, m.UserId)
+		}
+	}
+	sendNotification(ctx, s.notifier, s.log(ctx), ports.Notification{Event: ports.EventMentioned, CommentId: comment.Id}, todo, added)
+	return comment, nil
+}
+
+func (s *CommentService) DeleteComment(ctx context.Context, todoId, id string) error {
+	s.log(ctx).Debug("Deleting comment %s of todo %s", id, todoId)
+
+	userId, err := identity.UserId(ctx)
+	if err != nil {
+		return err
+	}
+
+	return s.uow.Do(ctx, func(ctx context.Context) error {
+		todo, err := s.getTodo(ctx, todoId)
+		if err != nil {
+			return err
+		}
+		comment, err := s.comments.GetCommentById(ctx, todoId, id)
+		if err != nil {
+			return err
+		}
+		// Чужой комментарий может удалить владелец задачи (модерация)
+		if comment.AuthorId != userId {
+			role, err := todoRole(ctx, s.shares, todo)
+			if err != nil {
+				return err
+			}
+			if domain.RoleRank(role) < domain.RoleRank(domain.RoleOwner) {
+				return ports.ErrForbidden
+			}
+		}
+		if err := s.comments.DeleteComment(ctx, id); err != nil {
+			return err
+		}
+		s.log(ctx).Info("Comment %s deleted by %s", id, userId)
+		return nil
+	})
+}
+
+func (s *CommentService) GetCommentHistory(ctx context.Context, todoId, id string) ([]domain.CommentRevision, error) {
+	if _, err := s.GetComment(ctx, todoId, id); err != nil {
+		return nil, err
+	}
+	return s.comments.GetCommentRevisions(ctx, id)
+}
+
+// resolveMentions находит упомянутых пользователей. Неизвестные имена и
+// пользователи без доступа к задаче пропускаются: текст остаётся как есть.
+func (s *CommentService) resolveMentions(ctx context.Context, todo domain.ToDo, body string) ([]domain.Mention, error) {
+	mentions := []domain.Mention{}
+	seen := map[string]bool{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		username := strings.ToLower(strings.TrimRight(match[1], "."))
+		if seen[username] {
+			continue
+		}
+		seen[username] = true
+
+		user, err := s.users.GetUserByUsername(ctx, username)
+		if err != nil {
+			s.log(ctx).Debug("Mentioned user @%s not found", username)
+			continue
+		}
+		ok, err := hasTodoAccess(ctx, s.shares, user.Id, todo)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			s.log(ctx).Debug("Mentioned user @%s has no access to todo %s", username, todo.Id)
+			continue
+		}
+		mentions = append(mentions, domain.Mention{UserId: user.Id, Username: user.Username})
+	}
+	return mentions, nil
+}
+
+func validateCommentBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" || utf8.RuneCountInString(body) > maxCommentLength {
+		return "", ErrInvalidComment
+	}
+	return body, nil
+}
+
+func mentionIds(mentions []domain.Mention) []string {
+	ids := make([]string, len(mentions))
+	for i, m := range mentions {
+		ids[i] = m.UserId
+	}
+	return ids
+}
+
+// log - логгер запроса с его X-Request-ID
+func (s *CommentService) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, s.logger)
+}
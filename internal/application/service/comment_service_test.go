@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/ports"
+)
+
+// memComments - комментарии и история правок в памяти
+type memComments struct {
+	comments  map[string]domain.Comment
+	revisions []domain.CommentRevision
+}
+
+func (r *memComments) CreateComment(ctx context.Context, comment domain.Comment) error {
+	r.comments[comment.Id] = comment
+	return nil
+}
+
+func (r *memComments) GetCommentsByTodo(ctx context.Context, todoId string) ([]domain.Comment, error) {
+	comments := []domain.Comment{}
+	for _, comment := range r.comments {
+		if comment.TodoId == todoId {
+			comments = append(comments, comment)
+		}
+	}
+	return comments, nil
+}
+
+func (r *memComments) GetCommentById(ctx context.Context, todoId, id string) (domain.Comment, error) {
+	comment, ok := r.comments[id]
+	if !ok || comment.TodoId != todoId {
+		return domain.Comment{}, fmt.Errorf("%w: %s", ports.ErrCommentNotFound, id)
+	}
+	return comment, nil
+}
+
+func (r *memComments) UpdateComment(ctx context.Context, comment domain.Comment, revision domain.CommentRevision) error {
+	comment.Edited = true
+	r.comments[comment.Id] = comment
+	r.revisions = append(r.revisions, revision)
+	return nil
+}
+
+func (r *memComments) DeleteComment(ctx context.Context, id string) error {
+	delete(r.comments, id)
+	return nil
+}
+
+func (r *memComments) GetCommentRevisions(ctx context.Context, commentId string) ([]domain.CommentRevision, error) {
+	revisions := []domain.CommentRevision{}
+	for _, revision := range r.revisions {
+		if revision.CommentId == commentId {
+			revisions = append(revisions, revision)
+		}
+	}
+	return revisions, nil
+}
+
+// commentFixture: задача t1 владельца alice, исполнитель bob; carol видит её
+// как viewer, у dave доступа нет
+type commentFixture struct {
+	service  ports.CommentService
+	comments *memComments
+	notifier *recordingNotifier
+}
+
+func newCommentFixture(t *testing.T) *commentFixture {
+	t.Helper()
+	f := &commentFixture{
+		comments: &memComments{comments: map[string]domain.Comment{}},
+		notifier: &recordingNotifier{},
+	}
+	todos := newMemTodos(domain.ToDo{Id: "t1", Todo: "Release", OwnerId: "alice", AssigneeId: "bob"})
+	shares := &memShares{roles: map[string]string{"bob/t1": domain.RoleEditor, "carol/t1": domain.RoleViewer}}
+	users := newMemUsers(
+		domain.User{Id: "alice", Username: "alice"},
+		domain.User{Id: "bob", Username: "bob"},
+		domain.User{Id: "carol", Username: "carol"},
+		domain.User{Id: "dave", Username: "dave"},
+	)
+	f.service = NewCommentService(f.comments, todos, shares, users, inlineUnitOfWork{}, f.notifier, newTestLogger(t))
+	return f
+}
+
+// recipients - получатели уведомлений по порядку
+func (f *commentFixture) recipients() []string {
+	recipients := []string{}
+	for _, sent := range f.notifier.sent {
+		recipients = append(recipients, sent.Event+":"+strings.Join(sent.Recipients, ","))
+	}
+	return recipients
+}
+
+func TestAddComment(t *testing.T) {
+	f := newCommentFixture(t)
+
+	// Комментировать может и viewer
+	comment, err := f.service.AddComment(asUser("carol", domain.UserRoleUser), "t1", "  @Alice @dave @nobody @alice mail carol@example.com  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if comment.AuthorId != "carol" || comment.Body != "@Alice @dave @nobody @alice mail carol@example.com" {
+		t.Errorf("comment = %+v", comment)
+	}
+	// dave без доступа и неизвестное имя не упоминаются, повтор учитывается один раз
+	if len(comment.Mentions) != 1 || comment.Mentions[0].UserId != "alice" {
+		t.Errorf("mentions = %+v, want alice only", comment.Mentions)
+	}
+	// Исполнитель и упомянутые, но не автор
+	if got := f.recipients(); len(got) != 1 || got[0] != "commented:bob,alice" {
+		t.Errorf("notifications = %v", got)
+	}
+}
+
+func TestAddCommentRejected(t *testing.T) {
+	f := newCommentFixture(t)
+	tests := []struct {
+		todoId string
+		body   string
+		want   error
+	}{
+		{todoId: "t1", body: "   ", want: ErrInvalidComment},
+		{todoId: "t1", body: strings.Repeat("я", maxCommentLength+1), want: ErrInvalidComment},
+		{todoId: "missing", body: "hello", want: ErrResourceNotFound},
+	}
+	for _, tt := range tests {
+		if _, err := f.service.AddComment(asUser("alice", domain.UserRoleUser), tt.todoId, tt.body); !errors.Is(err, tt.want) {
+			t.Errorf("AddComment(%q, %d chars) = %v, want %v", tt.todoId, len(tt.body), err, tt.want)
+		}
+	}
+	if len(f.comments.comments) != 0 || len(f.notifier.sent) != 0 {
+		t.Error("rejected comment stored or notified")
+	}
+	if _, err := f.service.AddComment(asUser("alice", domain.UserRoleUser), "t1", strings.Repeat("я", maxCommentLength)); err != nil {
+		t.Errorf("comment of the maximum length: %v", err)
+	}
+}
+
+func TestEditComment(t *testing.T) {
+	f := newCommentFixture(t)
+	comment, err := f.service.AddComment(asUser("alice", domain.UserRoleUser), "t1", "ping @carol")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.notifier.sent = nil
+
+	if _, err := f.service.EditComment(asUser("bob", domain.UserRoleUser), "t1", comment.Id, "hijacked"); !errors.Is(err, ports.ErrForbidden) {
+		t.Errorf("edit by another user = %v, want ErrForbidden", err)
+	}
+
+	edited, err := f.service.EditComment(asUser("alice", domain.UserRoleUser), "t1", comment.Id, "ping @carol and @bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edited.Body != "ping @carol and @bob" || !edited.Edited || len(edited.Mentions) != 2 {
+		t.Errorf("edited = %+v", edited)
+	}
+	// Уведомление только для впервые упомянутых
+	if got := f.recipients(); len(got) != 1 || got[0] != "mentioned:bob" {
+		t.Errorf("notifications = %v, want mentioned:bob", got)
+	}
+
+	history, err := f.service.GetCommentHistory(asUser("carol", domain.UserRoleUser), "t1", comment.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].Body != "ping @carol" || history[0].EditorId != "alice" {
+		t.Errorf("history = %+v", history)
+	}
+
+	// Тот же текст не создаёт правку
+	if _, err := f.service.EditComment(asUser("alice", domain.UserRoleUser), "t1", comment.Id, " ping @carol and @bob "); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.comments.revisions) != 1 {
+		t.Errorf("revisions = %d, want 1", len(f.comments.revisions))
+	}
+}
+
+func TestDeleteComment(t *testing.T) {
+	f := newCommentFixture(t)
+	byBob, _ := f.service.AddComment(asUser("bob", domain.UserRoleUser), "t1", "from bob")
+	byCarol, _ := f.service.AddComment(asUser("carol", domain.UserRoleUser), "t1", "from carol")
+
+	// Редактор задачи не удаляет чужие комментарии, владелец задачи - может
+	if err := f.service.DeleteComment(asUser("bob", domain.UserRoleUser), "t1", byCarol.Id); !errors.Is(err, ports.ErrForbidden) {
+		t.Errorf("delete by editor = %v, want ErrForbidden", err)
+	}
+	if err := f.service.DeleteComment(asUser("bob", domain.UserRoleUser), "t1", byBob.Id); err != nil {
+		t.Errorf("delete own comment: %v", err)
+	}
+	if err := f.service.DeleteComment(asUser("alice", domain.UserRoleUser), "t1", byCarol.Id); err != nil {
+		t.Errorf("delete by todo owner: %v", err)
+	}
+	if len(f.comments.comments) != 0 {
+		t.Errorf("comments left: %v", f.comments.comments)
+	}
+	if _, err := f.service.GetComment(asUser("alice", domain.UserRoleUser), "t1", byBob.Id); !errors.Is(err, ports.ErrCommentNotFound) {
+		t.Errorf("GetComment after delete = %v, want ErrCommentNotFound", err)
+	}
+}
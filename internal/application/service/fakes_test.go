@@ -0,0 +1,349 @@
+package service
+
+import (
+	"context"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/identity"
+	"ToDo-List/internal/core/ports"
+)
+
+func newTestLogger(t *testing.T) *logger.Logger {
+	t.Helper()
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { devNull.Close() })
+	appLogger, err := logger.New(logger.Config{Level: logger.LevelError, Output: devNull})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return appLogger
+}
+
+// asUser - контекст запроса пользователя в рабочем пространстве w1
+func asUser(id, role string) context.Context {
+	ctx := identity.WithUser(context.Background(), domain.User{Id: id, Username: id, Role: role})
+	return identity.WithWorkspace(ctx, domain.Workspace{Id: "w1"}, domain.WorkspaceRoleMember)
+}
+
+// memTodos - задачи в памяти. Как и PostgreRepo, читает задачи только
+// текущего рабочего пространства; права проверяет сервис.
+type memTodos struct {
+	ports.PostgreRepo
+	todos map[string]domain.ToDo
+}
+
+func newMemTodos(todos ...domain.ToDo) *memTodos {
+	r := &memTodos{todos: map[string]domain.ToDo{}}
+	for _, todo := range todos {
+		if todo.Position == "" {
+			todo.Position = "V"
+		}
+		r.todos[todo.Id] = todo
+	}
+	return r
+}
+
+func (r *memTodos) GetTodoById(ctx context.Context, id string) (domain.ToDo, error) {
+	todo, ok := r.todos[id]
+	if !ok {
+		return domain.ToDo{}, ports.ErrTodoNotFound
+	}
+	return todo, nil
+}
+
+func (r *memTodos) GetTodoByIdForUpdate(ctx context.Context, id string) (domain.ToDo, error) {
+	return r.GetTodoById(ctx, id)
+}
+
+func (r *memTodos) GetAllTodosWithFilters(ctx context.Context, filter domain.TodoFilter) ([]domain.ToDo, error) {
+	todos := []domain.ToDo{}
+	for _, todo := range r.todos {
+		todos = append(todos, todo)
+	}
+	sort.Slice(todos, func(i, j int) bool { return todos[i].Id < todos[j].Id })
+	return todos, nil
+}
+
+func (r *memTodos) CreateTodo(ctx context.Context, todo domain.ToDo) (domain.ToDo, error) {
+	if _, ok := r.todos[todo.Id]; ok {
+		return domain.ToDo{}, &ports.ConflictError{Resource: "todo", Id: todo.Id}
+	}
+	if todo.OwnerId == "" {
+		todo.OwnerId, _ = identity.UserId(ctx)
+	}
+	r.todos[todo.Id] = todo
+	return todo, nil
+}
+
+func (r *memTodos) UpdateTodo(ctx context.Context, todo domain.ToDo) error {
+	current, ok := r.todos[todo.Id]
+	if !ok {
+		return ports.ErrTodoNotFound
+	}
+	// Как UPDATE в PostgreRepo: владелец, позиция, исполнитель и наблюдатели не меняются
+	todo.OwnerId = current.OwnerId
+	todo.CreatedAt = current.CreatedAt
+	todo.Position = current.Position
+	todo.AssigneeId = current.AssigneeId
+	todo.Watchers = current.Watchers
+	r.todos[todo.Id] = todo
+	return nil
+}
+
+func (r *memTodos) DeleteTodoById(ctx context.Context, id string) error {
+	if _, ok := r.todos[id]; !ok {
+		return ports.ErrTodoNotFound
+	}
+	delete(r.todos, id)
+	return nil
+}
+
+func (r *memTodos) GetLastPosition(ctx context.Context) (string, error) {
+	last := ""
+	for _, todo := range r.todos {
+		if todo.Position > last {
+			last = todo.Position
+		}
+	}
+	return last, nil
+}
+
+// memShares - выданные доступы: роль пользователя для задачи или для
+// всего списка владельца
+type memShares struct {
+	ports.ShareRepo
+	roles   map[string]string // userId + "/" + todoId или userId + "/list:" + ownerId -> роль
+	created []domain.Share
+}
+
+func (r *memShares) GetTodoRole(ctx context.Context, userId string, todo domain.ToDo) (string, error) {
+	role := r.roles[userId+"/"+todo.Id]
+	if list := r.roles[userId+"/list:"+todo.OwnerId]; domain.RoleRank(list) > domain.RoleRank(role) {
+		role = list
+	}
+	return role, nil
+}
+
+func (r *memShares) CreateShare(ctx context.Context, share domain.Share) (domain.Share, error) {
+	r.created = append(r.created, share)
+	return share, nil
+}
+
+// inlineUnitOfWork выполняет fn без транзакции
+type inlineUnitOfWork struct{}
+
+func (inlineUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type recordingNotifier struct {
+	sent []ports.Notification
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, notification ports.Notification) error {
+	n.sent = append(n.sent, notification)
+	return nil
+}
+
+// events возвращает события уведомлений по порядку
+func (n *recordingNotifier) events() []string {
+	events := []string{}
+	for _, sent := range n.sent {
+		events = append(events, sent.Event+" "+sent.TodoId)
+	}
+	return events
+}
+
+// memUsers - пользователи в памяти
+type memUsers struct {
+	ports.UserRepo
+	users      map[string]domain.User
+	identities map[string]string // issuer + "/" + subject -> userId
+}
+
+func newMemUsers(users ...domain.User) *memUsers {
+	r := &memUsers{users: map[string]domain.User{}, identities: map[string]string{}}
+	for _, user := range users {
+		r.users[user.Id] = user
+	}
+	return r
+}
+
+func (r *memUsers) GetUserById(ctx context.Context, id string) (domain.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return domain.User{}, ports.ErrUserNotFound
+	}
+	return user, nil
+}
+
+func (r *memUsers) GetUserByUsername(ctx context.Context, username string) (domain.User, error) {
+	for _, user := range r.users {
+		if user.Username == username {
+			return user, nil
+		}
+	}
+	return domain.User{}, ports.ErrUserNotFound
+}
+
+// CreateUser отклоняет занятые имя и email, как уникальные индексы users
+func (r *memUsers) CreateUser(ctx context.Context, user domain.User) (domain.User, error) {
+	for _, existing := range r.users {
+		if existing.Username == user.Username || (user.Email != "" && existing.Email == user.Email) {
+			return domain.User{}, ports.ErrUserExists
+		}
+	}
+	if user.Role == "" {
+		user.Role = domain.UserRoleUser
+	}
+	r.users[user.Id] = user
+	return user, nil
+}
+
+func (r *memUsers) GetUserByExternalIdentity(ctx context.Context, issuer, subject string) (domain.User, error) {
+	userId, ok := r.identities[issuer+"/"+subject]
+	if !ok {
+		return domain.User{}, ports.ErrUserNotFound
+	}
+	return r.GetUserById(ctx, userId)
+}
+
+func (r *memUsers) LinkExternalIdentity(ctx context.Context, userId, issuer, subject string) error {
+	if _, ok := r.identities[issuer+"/"+subject]; ok {
+		return ports.ErrUserExists
+	}
+	r.identities[issuer+"/"+subject] = userId
+	return nil
+}
+
+func (r *memUsers) UpdateUserRole(ctx context.Context, id, role string, updatedAt time.Time) error {
+	user := r.users[id]
+	user.Role = role
+	r.users[id] = user
+	return nil
+}
+
+func (r *memUsers) SetUserDisabled(ctx context.Context, id string, disabled bool, at time.Time) error {
+	user := r.users[id]
+	user.Disabled = disabled
+	r.users[id] = user
+	return nil
+}
+
+func (r *memUsers) CountActiveAdmins(ctx context.Context) (int, error) {
+	n := 0
+	for _, user := range r.users {
+		if user.Role == domain.UserRoleAdmin && !user.Disabled {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// memSessions - сессии в памяти по хешу токена
+type memSessions struct {
+	sessions map[string]domain.Session
+}
+
+func newMemSessions() *memSessions {
+	return &memSessions{sessions: map[string]domain.Session{}}
+}
+
+func (r *memSessions) CreateSession(ctx context.Context, session domain.Session) error {
+	r.sessions[session.Id] = session
+	return nil
+}
+
+func (r *memSessions) GetSession(ctx context.Context, id string) (domain.Session, error) {
+	session, ok := r.sessions[id]
+	if !ok {
+		return domain.Session{}, ports.ErrUnauthenticated
+	}
+	return session, nil
+}
+
+func (r *memSessions) DeleteSession(ctx context.Context, id string) error {
+	delete(r.sessions, id)
+	return nil
+}
+
+func (r *memSessions) DeleteExpiredSessions(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (r *memSessions) DeleteSessionsByUser(ctx context.Context, userId string) error {
+	for id, session := range r.sessions {
+		if session.UserId == userId {
+			delete(r.sessions, id)
+		}
+	}
+	return nil
+}
+
+func (r *memSessions) DeleteSessionsByImpersonator(ctx context.Context, adminId string) error {
+	for id, session := range r.sessions {
+		if session.ImpersonatorId == adminId {
+			delete(r.sessions, id)
+		}
+	}
+	return nil
+}
+
+// discardAudit не записывает журнал аудита
+type discardAudit struct{}
+
+func (discardAudit) Record(ctx context.Context, action, targetType, targetId string, details map[string]string) {
+}
+
+// plainHasher хранит пароли как есть
+type plainHasher struct{}
+
+func (plainHasher) Hash(password string) (string, error) {
+	return password, nil
+}
+
+func (plainHasher) Verify(password, hash string) (bool, error) {
+	return password == hash, nil
+}
+
+func (r *memTodos) GetFirstPosition(ctx context.Context) (string, error) {
+	first := ""
+	for _, todo := range r.todos {
+		if first == "" || todo.Position < first {
+			first = todo.Position
+		}
+	}
+	return first, nil
+}
+
+func (r *memTodos) UpdateTodoPosition(ctx context.Context, id string, position string) error {
+	todo, ok := r.todos[id]
+	if !ok {
+		return ports.ErrTodoNotFound
+	}
+	todo.Position = position
+	r.todos[id] = todo
+	return nil
+}
+
+// memWorkspaces - участники рабочих пространств
+type memWorkspaces struct {
+	ports.WorkspaceRepo
+	members map[string]string // workspaceId + "/" + userId -> роль
+}
+
+func (r *memWorkspaces) GetMember(ctx context.Context, workspaceId, userId string) (domain.WorkspaceMember, error) {
+	role, ok := r.members[workspaceId+"/"+userId]
+	if !ok {
+		return domain.WorkspaceMember{}, ports.ErrNotMember
+	}
+	return domain.WorkspaceMember{WorkspaceId: workspaceId, UserId: userId, Role: role}, nil
+}
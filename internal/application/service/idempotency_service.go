@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/identity"
+	"ToDo-List/internal/core/ports"
+)
+
+// DefaultIdempotencyTTL - сколько хранится ответ для повторов, если не задано в конфигурации
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// idempotencyCleanupInterval - как часто попутно удаляются истёкшие ключи
+const idempotencyCleanupInterval = 10 * time.Minute
+
+// idempotencyLease - сколько ключ занят выполняющимся запросом. Больше
+// server.write_timeout по умолчанию: если процесс упал, не сохранив ответ,
+// повтор с тем же ключом выполнится после истечения блокировки.
+const idempotencyLease = 5 * time.Minute
+
+type IdempotencyService struct {
+	repo   ports.IdempotencyRepo
+	ttl    time.Duration
+	logger *logger.Logger
+
+	mu          sync.Mutex
+	lastCleanup time.Time
+}
+
+func NewIdempotencyService(repo ports.IdempotencyRepo, ttl time.Duration, logger *logger.Logger) ports.IdempotencyService {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+	return &IdempotencyService{
+		repo:   repo,
+		ttl:    ttl,
+		logger: logger,
+	}
+}
+
+func (s *IdempotencyService) Begin(ctx context.Context, key, fingerprint string) (domain.IdempotencyRecord, bool, error) {
+	userId, err := identity.UserId(ctx)
+	if err != nil {
+		return domain.IdempotencyRecord{}, false, err
+	}
+	s.cleanup(ctx)
+
+	// TIMESTAMP хранит микросекунды, а LockedUntil сравнивается с сохранённым
+	now := time.Now().Truncate(time.Microsecond)
+	record, reserved, err := s.repo.ReserveIdempotencyKey(ctx, domain.IdempotencyRecord{
+		Key:         key,
+		UserId:      userId,
+		Fingerprint: fingerprint,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.ttl),
+		LockedUntil: now.Add(idempotencyLease),
+	})
+	if err != nil {
+		return domain.IdempotencyRecord{}, false, err
+	}
+	if reserved {
+		return record, false, nil
+	}
+
+	if record.Fingerprint != fingerprint {
+		s.log(ctx).Warn("Idempotency key of user %s reused with a different request", userId)
+		return domain.IdempotencyRecord{}, false, ports.ErrIdempotencyKeyReused
+	}
+	if record.StatusCode == 0 {
+		return domain.IdempotencyRecord{}, false, ports.ErrIdempotencyInProgress
+	}
+	s.log(ctx).Debug("Replaying response for idempotency key of user %s", userId)
+	return record, true, nil
+}
+
+func (s *IdempotencyService) Complete(ctx context.Context, reservation domain.IdempotencyRecord) error {
+	if err := s.repo.SaveIdempotentResponse(ctx, reservation); err != nil {
+		if errors.Is(err, ports.ErrIdempotencyLeaseLost) {
+			s.log(ctx).Warn("Idempotency key of user %s was reclaimed before the response was stored", reservation.UserId)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *IdempotencyService) Abort(ctx context.Context, reservation domain.IdempotencyRecord) error {
+	return s.repo.DeleteIdempotencyKey(ctx, reservation)
+}
+
+// cleanup удаляет истёкшие ключи не чаще раза в idempotencyCleanupInterval,
+// отдельный планировщик не нужен
+func (s *IdempotencyService) cleanup(ctx context.Context) {
+	now := time.Now()
+	s.mu.Lock()
+	if now.Sub(s.lastCleanup) < idempotencyCleanupInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastCleanup = now
+	s.mu.Unlock()
+
+	if n, err := s.repo.DeleteExpiredIdempotencyKeys(ctx, now); err != nil {
+		s.log(ctx).Warn("Failed to delete expired idempotency keys: %v", err)
+	} else if n > 0 {
+		s.log(ctx).Debug("Deleted %d expired idempotency keys", n)
+	}
+}
+
+// log - логгер запроса с его X-Request-ID
+func (s *IdempotencyService) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, s.logger)
+}
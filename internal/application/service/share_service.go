@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/identity"
+	"ToDo-List/internal/core/ports"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidRole         = errors.New("role must be viewer, editor or owner")
+	ErrInvalidResourceType = errors.New("resourceType must be list or todo")
+	ErrShareeRequired      = errors.New("exactly one of username or email is required")
+	ErrShareeNotFound      = errors.New("user to share with not found")
+	ErrShareWithSelf       = errors.New("cannot share with yourself")
+	ErrResourceNotFound    = errors.New("resource not found")
+)
+
+type ShareService struct {
+	shares     ports.ShareRepo
+	todos      ports.PostgreRepo
+	users      ports.UserRepo
+	workspaces ports.WorkspaceRepo
+	logger     *logger.Logger
+}
+
+func NewShareService(shares ports.ShareRepo, todos ports.PostgreRepo, users ports.UserRepo, workspaces ports.WorkspaceRepo, logger *logger.Logger) ports.ShareService {
+	return &ShareService{
+		shares:     shares,
+		todos:      todos,
+		users:      users,
+		workspaces: workspaces,
+		logger:     logger,
+	}
+}
+
+func (s *ShareService) Share(ctx context.Context, req ports.ShareRequest) (domain.Share, error) {
+	s.log(ctx).Debug("Sharing %s %s as %s", req.ResourceType, req.ResourceId, req.Role)
+
+	if domain.RoleRank(req.Role) == 0 {
+		return domain.Share{}, ErrInvalidRole
+	}
+	ownerId, resourceId, err := s.authorizeResource(ctx, req.ResourceType, req.ResourceId)
+	if err != nil {
+		return domain.Share{}, err
+	}
+
+	sharee, err := s.findSharee(ctx, req.Username, req.Email)
+	if err != nil {
+		return domain.Share{}, err
+	}
+	userId, _ := identity.UserId(ctx)
+	if sharee.Id == userId || sharee.Id == ownerId {
+		return domain.Share{}, ErrShareWithSelf
+	}
+
+	share, err := s.shares.CreateShare(ctx, domain.Share{
+		Id:           uuid.NewString(),
+		ResourceType: req.ResourceType,
+		ResourceId:   resourceId,
+		OwnerId:      ownerId,
+		UserId:       sharee.Id,
+		Role:         req.Role,
+		CreatedAt:    time.Now(),
+	})
+	if err != nil {
+		return domain.Share{}, err
+	}
+	share.Username = sharee.Username
+
+	s.log(ctx).Info("User %s shared %s %s with %s as %s", userId, share.ResourceType, share.ResourceId, sharee.Id, share.Role)
+	return share, nil
+}
+
+func (s *ShareService) GetShares(ctx context.Context, resourceType, resourceId string) ([]domain.Share, error) {
+	_, resourceId, err := s.authorizeResource(ctx, resourceType, resourceId)
+	if err != nil {
+		return nil, err
+	}
+	return s.shares.GetSharesByResource(ctx, resourceType, resourceId)
+}
+
+func (s *ShareService) RevokeShare(ctx context.Context, id string) error {
+	userId, err := identity.UserId(ctx)
+	if err != nil {
+		return err
+	}
+
+	share, err := s.shares.GetShareById(ctx, id)
+	if err != nil {
+		s.log(ctx).Warn("Share %s not found: %v", id, err)
+		return ErrResourceNotFound
+	}
+	// Получатель может отказаться от доступа, остальные должны управлять ресурсом
+	if share.UserId != userId {
+		if _, _, err := s.authorizeResource(ctx, share.ResourceType, share.ResourceId); err != nil {
+			return err
+		}
+	}
+
+	if err := s.shares.DeleteShare(ctx, id); err != nil {
+		return err
+	}
+	s.log(ctx).Info("Share %s revoked by %s", id, userId)
+	return nil
+}
+
+func (s *ShareService) GetSharedWithMe(ctx context.Context) ([]ports.SharedTodo, error) {
+	userId, err := identity.UserId(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.shares.GetSharedTodos(ctx, userId)
+}
+
+// authorizeResource проверяет, что пользователь управляет ресурсом (роль owner),
+// и возвращает владельца и id ресурса. Список можно открыть только свой.
+func (s *ShareService) authorizeResource(ctx context.Context, resourceType, resourceId string) (string, string, error) {
+	userId, err := identity.UserId(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	switch resourceType {
+	case domain.ShareList:
+		if resourceId == "" {
+			resourceId = userId
+		}
+		if resourceId != userId {
+			return "", "", ports.ErrForbidden
+		}
+		return userId, resourceId, nil
+	case domain.ShareTodo:
+		todo, err := s.todos.GetTodoById(ctx, resourceId)
+		if err != nil {
+			s.log(ctx).Warn("Todo %s is not accessible for sharing: %v", resourceId, err)
+			return "", "", ErrResourceNotFound
+		}
+		role, err := todoRole(ctx, s.shares, todo)
+		if err != nil {
+			return "", "", err
+		}
+		if domain.RoleRank(role) < domain.RoleRank(domain.RoleOwner) {
+			return "", "", ports.ErrForbidden
+		}
+		return todo.OwnerId, todo.Id, nil
+	default:
+		return "", "", ErrInvalidResourceType
+	}
+}
+
+func (s *ShareService) findSharee(ctx context.Context, username, email string) (domain.User, error) {
+	username = strings.ToLower(strings.TrimSpace(username))
+	email = strings.ToLower(strings.TrimSpace(email))
+	if (username == "") == (email == "") {
+		return domain.User{}, ErrShareeRequired
+	}
+
+	var user domain.User
+	var err error
+	if username != "" {
+		user, err = s.users.GetUserByUsername(ctx, username)
+	} else {
+		user, err = s.users.GetUserByEmail(ctx, email)
+	}
+	if err != nil {
+		return domain.User{}, ErrShareeNotFound
+	}
+
+	// Открыть можно только участнику текущего рабочего пространства; чужим
+	// пространствам не сообщаем, что такой пользователь существует
+	workspaceId, err := identity.WorkspaceId(ctx)
+	if err != nil {
+		return domain.User{}, err
+	}
+	if _, err := s.workspaces.GetMember(ctx, workspaceId, user.Id); err != nil {
+		s.log(ctx).Debug("Sharee %s is not a member of workspace %s: %v", user.Id, workspaceId, err)
+		return domain.User{}, ErrShareeNotFound
+	}
+	return user, nil
+}
+
+// log - логгер запроса с его X-Request-ID
+func (s *ShareService) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, s.logger)
+}
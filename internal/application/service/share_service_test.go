@@ -0,0 +1,119 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/ports"
+)
+
+func TestTodoShareRoles(t *testing.T) {
+	todos := newMemTodos(
+		domain.ToDo{Id: "t1", OwnerId: "alice", Todo: "t1", Priority: "low"},
+		domain.ToDo{Id: "t2", OwnerId: "alice", Todo: "t2", Priority: "low"},
+	)
+	shares := &memShares{roles: map[string]string{
+		"viewer/t1":             domain.RoleViewer,
+		"editor/t1":             domain.RoleEditor,
+		"coowner/t1":            domain.RoleOwner,
+		"listeditor/list:alice": domain.RoleEditor,
+	}}
+	s := newTestTodoService(t, todos, shares, nil)
+
+	tests := []struct {
+		user      string
+		todo      string
+		canUpdate bool
+		canDelete bool
+	}{
+		{user: "alice", todo: "t1", canUpdate: true, canDelete: true},
+		{user: "viewer", todo: "t1"},
+		{user: "editor", todo: "t1", canUpdate: true},
+		// Доступ к одной задаче не открывает остальные задачи списка
+		{user: "editor", todo: "t2"},
+		{user: "coowner", todo: "t1", canUpdate: true, canDelete: true},
+		{user: "listeditor", todo: "t2", canUpdate: true},
+		{user: "stranger", todo: "t1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.user+"/"+tt.todo, func(t *testing.T) {
+			ctx := asUser(tt.user, domain.UserRoleUser)
+			todo := todos.todos[tt.todo]
+
+			err := s.UpdateTodo(ctx, domain.ToDo{Id: todo.Id, Todo: "changed", Priority: "high"})
+			if tt.canUpdate != (err == nil) || (err != nil && !errors.Is(err, ports.ErrForbidden)) {
+				t.Errorf("UpdateTodo = %v, want allowed=%v", err, tt.canUpdate)
+			}
+			err = s.CompleteTodoById(ctx, todo.Id)
+			if tt.canUpdate != (err == nil) || (err != nil && !errors.Is(err, ports.ErrForbidden)) {
+				t.Errorf("CompleteTodoById = %v, want allowed=%v", err, tt.canUpdate)
+			}
+			err = s.DeleteTodo(ctx, todo.Id)
+			if tt.canDelete != (err == nil) || (err != nil && !errors.Is(err, ports.ErrForbidden)) {
+				t.Errorf("DeleteTodo = %v, want allowed=%v", err, tt.canDelete)
+			}
+			// Удалённая задача возвращается для следующих случаев
+			todos.todos[todo.Id] = todo
+		})
+	}
+}
+
+func TestReadOnlyUserCannotEditOwnTodo(t *testing.T) {
+	todos := newMemTodos(domain.ToDo{Id: "t1", OwnerId: "alice", Todo: "t1", Priority: "low"})
+	s := newTestTodoService(t, todos, nil, nil)
+
+	ctx := asUser("alice", domain.UserRoleReadOnly)
+	if _, err := s.GetTodoById(ctx, "t1"); err != nil {
+		t.Errorf("GetTodoById: %v", err)
+	}
+	if err := s.CompleteTodoById(ctx, "t1"); !errors.Is(err, ports.ErrForbidden) {
+		t.Errorf("CompleteTodoById = %v, want ErrForbidden", err)
+	}
+	if _, err := s.CreateTodo(ctx, domain.ToDo{Todo: "new"}); !errors.Is(err, ports.ErrForbidden) {
+		t.Errorf("CreateTodo = %v, want ErrForbidden", err)
+	}
+}
+
+func TestShareRequiresOwnerRole(t *testing.T) {
+	todos := newMemTodos(domain.ToDo{Id: "t1", OwnerId: "alice", Todo: "t1"})
+	shares := &memShares{roles: map[string]string{"editor/t1": domain.RoleEditor}}
+	users := newMemUsers(
+		domain.User{Id: "alice", Username: "alice"},
+		domain.User{Id: "editor", Username: "editor"},
+		domain.User{Id: "bob", Username: "bob"},
+		domain.User{Id: "outsider", Username: "outsider"},
+	)
+	workspaces := &memWorkspaces{members: map[string]string{
+		"w1/alice":  domain.WorkspaceRoleMember,
+		"w1/editor": domain.WorkspaceRoleMember,
+		"w1/bob":    domain.WorkspaceRoleMember,
+	}}
+	s := NewShareService(shares, todos, users, workspaces, newTestLogger(t))
+
+	share := ports.ShareRequest{ResourceType: domain.ShareTodo, ResourceId: "t1", Username: "bob", Role: domain.RoleViewer}
+	if _, err := s.Share(asUser("editor", domain.UserRoleUser), share); !errors.Is(err, ports.ErrForbidden) {
+		t.Errorf("editor sharing = %v, want ErrForbidden", err)
+	}
+	created, err := s.Share(asUser("alice", domain.UserRoleUser), share)
+	if err != nil {
+		t.Fatalf("owner sharing: %v", err)
+	}
+	if created.OwnerId != "alice" || created.UserId != "bob" || created.Role != domain.RoleViewer {
+		t.Errorf("share = %+v, want viewer for bob on alice's todo", created)
+	}
+
+	// Пользователь из другого пространства выглядит как несуществующий
+	share.Username = "outsider"
+	if _, err := s.Share(asUser("alice", domain.UserRoleUser), share); !errors.Is(err, ErrShareeNotFound) {
+		t.Errorf("sharing outside the workspace = %v, want ErrShareeNotFound", err)
+	}
+	// Открыть можно только свой список
+	list := ports.ShareRequest{ResourceType: domain.ShareList, ResourceId: "alice", Username: "bob", Role: domain.RoleEditor}
+	if _, err := s.Share(asUser("editor", domain.UserRoleUser), list); !errors.Is(err, ports.ErrForbidden) {
+		t.Errorf("sharing someone else's list = %v, want ErrForbidden", err)
+	}
+	if len(shares.created) != 1 {
+		t.Errorf("created shares = %+v, want only the owner's", shares.created)
+	}
+}
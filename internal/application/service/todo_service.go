@@ -2,69 +2,601 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"ToDo-List/internal/adapters/logger"
 	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/identity"
+	"ToDo-List/internal/core/policy"
 	"ToDo-List/internal/core/ports"
+	"ToDo-List/internal/core/rank"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
 )
 
+// tracer - span методов сервисов
+var tracer = otel.Tracer("ToDo-List/internal/application/service")
+
+// ErrUserNotFound - пользователь для назначения не найден
+var ErrUserNotFound = errors.New("user not found")
+
+// ErrNoAccess - назначаемый пользователь не имеет доступа к задаче
+var ErrNoAccess = errors.New("user has no access to this todo")
+
+// ErrInvalidTodoId - id задачи от клиента должен быть UUID в каноническом виде
+var ErrInvalidTodoId = errors.New("todo id must be a UUID")
+
+// ErrMoveAcrossLists - задачу можно переместить только внутри списка её владельца
+var ErrMoveAcrossLists = errors.New("todo can only be moved within its owner's list")
+
 type TodoService struct {
-	repo   ports.PostgreRepo
-	logger *logger.Logger
+	repo     ports.PostgreRepo
+	shares   ports.ShareRepo
+	users    ports.UserRepo
+	uow      ports.UnitOfWork
+	notifier ports.Notifier
+	logger   *logger.Logger
 }
 
-func NewToDoService(repo ports.PostgreRepo, logger *logger.Logger) ports.ToDoService {
+func NewToDoService(repo ports.PostgreRepo, shares ports.ShareRepo, users ports.UserRepo, uow ports.UnitOfWork, notifier ports.Notifier, logger *logger.Logger) ports.ToDoService {
 	return &TodoService{
-		repo:   repo,
-		logger: logger,
+		repo:     repo,
+		shares:   shares,
+		users:    users,
+		uow:      uow,
+		notifier: notifier,
+		logger:   logger,
+	}
+}
+
+// todoRole возвращает роль текущего пользователя для задачи: владелец задачи -
+// owner, остальные - наивысшая роль из выданных доступов
+func todoRole(ctx context.Context, shares ports.ShareRepo, todo domain.ToDo) (string, error) {
+	userId, err := identity.UserId(ctx)
+	if err != nil {
+		return "", err
+	}
+	if todo.OwnerId == userId {
+		return domain.RoleOwner, nil
+	}
+	return shares.GetTodoRole(ctx, userId, todo)
+}
+
+// authorize проверяет, что роль пользователя для задачи не ниже required.
+// Для изменений нужно ещё и системное право todos:write.
+func (s *TodoService) authorize(ctx context.Context, todo domain.ToDo, required string) error {
+	if domain.RoleRank(required) > domain.RoleRank(domain.RoleViewer) {
+		if err := policy.Check(ctx, policy.TodosWrite); err != nil {
+			return err
+		}
+	}
+	role, err := todoRole(ctx, s.shares, todo)
+	if err != nil {
+		return err
 	}
+	if domain.RoleRank(role) < domain.RoleRank(required) {
+		s.log(ctx).Warn("Access denied to todo %s: role %q, required %q", todo.Id, role, required)
+		return ports.ErrForbidden
+	}
+	return nil
 }
 
 func (s *TodoService) CreateTodo(ctx context.Context, todo domain.ToDo) (domain.ToDo, error) {
-	s.logger.Debug("Creating todo in service")
-	return s.repo.CreateTodo(ctx, todo)
+	ctx, span := tracer.Start(ctx, "TodoService.CreateTodo")
+	defer span.End()
+
+	s.log(ctx).Debug("Creating todo in service")
+	if err := policy.Check(ctx, policy.TodosWrite); err != nil {
+		return domain.ToDo{}, err
+	}
+
+	// Клиент может сам выбрать id, например для задач, созданных офлайн
+	if todo.Id == "" {
+		todo.Id = uuid.NewString()
+	} else if !validTodoId(todo.Id) {
+		return domain.ToDo{}, ErrInvalidTodoId
+	}
+
+	if todo.Priority == "" {
+		todo.Priority = "medium"
+		if workspace, _, ok := identity.WorkspaceFromContext(ctx); ok && workspace.Settings.DefaultPriority != "" {
+			todo.Priority = workspace.Settings.DefaultPriority
+		}
+	}
+
+	var created domain.ToDo
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		// Новая задача встаёт в конец ручного порядка
+		if todo.Position == "" {
+			last, err := s.repo.GetLastPosition(ctx)
+			if err != nil {
+				return err
+			}
+			todo.Position, err = rank.After(last)
+			if err != nil {
+				return err
+			}
+		}
+
+		var err error
+		created, err = s.repo.CreateTodo(ctx, todo)
+		return err
+	})
+	return created, err
 }
-func (s *TodoService) GetAllTodosWithFilters(ctx context.Context, filter ports.TodoFilter) ([]domain.ToDo, error) {
-	s.logger.Debug("Getting all todos with filters: %+v", filter)
+func (s *TodoService) GetAllTodosWithFilters(ctx context.Context, filter domain.TodoFilter) ([]domain.ToDo, error) {
+	ctx, span := tracer.Start(ctx, "TodoService.GetAllTodosWithFilters")
+	defer span.End()
+
+	s.log(ctx).Debug("Getting all todos with filters: %+v", filter)
+	if err := policy.Check(ctx, policy.TodosRead); err != nil {
+		return nil, err
+	}
 	return s.repo.GetAllTodosWithFilters(ctx, filter)
 }
 
+// GetTodoById - репозиторий отдаёт только доступные пользователю задачи,
+// а любой доступ включает чтение
 func (s *TodoService) GetTodoById(ctx context.Context, id string) (domain.ToDo, error) {
-	s.logger.Debug("Getting todo by ID: %s", id)
+	ctx, span := tracer.Start(ctx, "TodoService.GetTodoById")
+	defer span.End()
+
+	s.log(ctx).Debug("Getting todo by ID: %s", id)
+	if err := policy.Check(ctx, policy.TodosRead); err != nil {
+		return domain.ToDo{}, err
+	}
 	return s.repo.GetTodoById(ctx, id)
 }
 
 func (s *TodoService) UpdateTodo(ctx context.Context, todo domain.ToDo) error {
-	s.logger.Debug("Updating todo: %s", todo.Id)
+	ctx, span := tracer.Start(ctx, "TodoService.UpdateTodo")
+	defer span.End()
+
+	s.log(ctx).Debug("Updating todo: %s", todo.Id)
 	todo.UpdatedAt = time.Now()
-	return s.repo.UpdateTodo(ctx, todo)
+
+	var current domain.ToDo
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		current, err = s.repo.GetTodoByIdForUpdate(ctx, todo.Id)
+		if err != nil {
+			return err
+		}
+		if err := s.authorize(ctx, current, domain.RoleEditor); err != nil {
+			return err
+		}
+		return s.repo.UpdateTodo(ctx, todo)
+	})
+	if err != nil {
+		return err
+	}
+
+	current.Todo = todo.Todo
+	s.notify(ctx, ports.EventUpdated, current)
+	return nil
+}
+
+func (s *TodoService) UpsertTodo(ctx context.Context, todo domain.ToDo) (domain.ToDo, bool, error) {
+	ctx, span := tracer.Start(ctx, "TodoService.UpsertTodo")
+	defer span.End()
+
+	s.log(ctx).Debug("Upserting todo: %s", todo.Id)
+
+	var result domain.ToDo
+	created := false
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		current, err := s.repo.GetTodoByIdForUpdate(ctx, todo.Id)
+		if errors.Is(err, ports.ErrTodoNotFound) && validTodoId(todo.Id) {
+			// Новая задача создаётся так же, как через POST /api/todo, но
+			// сохраняет состояние из тела: созданная офлайн задача могла
+			// быть уже выполнена
+			now := time.Now()
+			todo.CreatedAt = now
+			todo.UpdatedAt = now
+			syncCompletedAt(&todo, time.Time{}, now)
+			result, err = s.CreateTodo(ctx, todo)
+			created = err == nil
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.authorize(ctx, current, domain.RoleEditor); err != nil {
+			return err
+		}
+		// Тело - задача целиком: время выполнения согласуется с флагом
+		todo.UpdatedAt = time.Now()
+		syncCompletedAt(&todo, current.CompletedAt, todo.UpdatedAt)
+		if err := s.repo.UpdateTodo(ctx, todo); err != nil {
+			return err
+		}
+		result, err = s.repo.GetTodoById(ctx, todo.Id)
+		return err
+	})
+	if err != nil {
+		return domain.ToDo{}, false, err
+	}
+
+	if !created {
+		s.notify(ctx, ports.EventUpdated, result)
+	}
+	return result, created, nil
+}
+
+// syncCompletedAt согласует время выполнения с флагом Complete: у невыполненной
+// задачи его нет, выполненная без времени из тела сохраняет прежнее или
+// получает now
+func syncCompletedAt(todo *domain.ToDo, previous, now time.Time) {
+	switch {
+	case !todo.Complete:
+		todo.CompletedAt = time.Time{}
+	case !todo.CompletedAt.IsZero():
+	case !previous.IsZero():
+		todo.CompletedAt = previous
+	default:
+		todo.CompletedAt = now
+	}
+}
+
+// validTodoId проверяет, что id - UUID в каноническом виде (строчные буквы, с дефисами)
+func validTodoId(id string) bool {
+	parsed, err := uuid.Parse(id)
+	return err == nil && parsed.String() == id
 }
 
 func (s *TodoService) DeleteTodo(ctx context.Context, id string) error {
-	s.logger.Debug("Deleting todo: %s", id)
-	return s.repo.DeleteTodoById(ctx, id)
+	ctx, span := tracer.Start(ctx, "TodoService.DeleteTodo")
+	defer span.End()
+
+	s.log(ctx).Debug("Deleting todo: %s", id)
+
+	var todo domain.ToDo
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		todo, err = s.repo.GetTodoByIdForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		// Редактор может менять задачу, но удалить её может только владелец
+		if err := s.authorize(ctx, todo, domain.RoleOwner); err != nil {
+			return err
+		}
+		return s.repo.DeleteTodoById(ctx, id)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.notify(ctx, ports.EventDeleted, todo)
+	return nil
 }
 
 func (s *TodoService) CompleteTodoById(ctx context.Context, id string) error {
-	s.logger.Debug("Completing todo: %s", id)
-	
-	
-	
-	todo, err := s.repo.GetTodoById(ctx, id)
+	ctx, span := tracer.Start(ctx, "TodoService.CompleteTodoById")
+	defer span.End()
+
+	s.log(ctx).Debug("Completing todo: %s", id)
+
+	// Чтение и запись в одной транзакции с блокировкой строки, чтобы
+	// параллельные изменения не перезаписали друг друга
+	var todo domain.ToDo
+	completed := false
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		todo, err = s.repo.GetTodoByIdForUpdate(ctx, id)
+		if err != nil {
+			s.log(ctx).Error("Failed to get todo for completion: %s, error: %v", id, err)
+			return err
+		}
+		if err := s.authorize(ctx, todo, domain.RoleEditor); err != nil {
+			return err
+		}
+		if todo.Complete {
+			s.log(ctx).Warn("Todo %s is already completed", id)
+			return nil
+		}
+
+		todo.Complete = true
+		todo.CompletedAt = time.Now()
+		todo.UpdatedAt = time.Now()
+
+		s.log(ctx).Info("Marking todo as completed: %s", id)
+		if err := s.repo.UpdateTodo(ctx, todo); err != nil {
+			return err
+		}
+		completed = true
+		return nil
+	})
 	if err != nil {
-		s.logger.Error("Failed to get todo for completion: %s, error: %v", id, err)
 		return err
 	}
-	if todo.Complete {
-		s.logger.Warn("Todo %s is already completed", id)
-		return nil 
+
+	if completed {
+		s.notify(ctx, ports.EventCompleted, todo)
 	}
+	return nil
+}
 
-	todo.Complete = true
-	todo.CompletedAt = time.Now()
-	todo.UpdatedAt = time.Now()
+func (s *TodoService) MoveTodo(ctx context.Context, id string, beforeId string, afterId string) (domain.ToDo, error) {
+	ctx, span := tracer.Start(ctx, "TodoService.MoveTodo")
+	defer span.End()
+
+	s.log(ctx).Debug("Moving todo %s (before=%s, after=%s)", id, beforeId, afterId)
 
-	s.logger.Info("Marking todo as completed: %s", id)
-	return s.repo.UpdateTodo(ctx, todo)
-}
\ No newline at end of file
+	if (beforeId == "") == (afterId == "") {
+		return domain.ToDo{}, fmt.Errorf("exactly one of before or after must be set")
+	}
+	targetId := beforeId
+	if targetId == "" {
+		targetId = afterId
+	}
+	if targetId == id {
+		return domain.ToDo{}, fmt.Errorf("todo cannot be moved relative to itself")
+	}
+
+	var todo domain.ToDo
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		todo, err = s.repo.GetTodoByIdForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.authorize(ctx, todo, domain.RoleEditor); err != nil {
+			return err
+		}
+		target, err := s.repo.GetTodoByIdForUpdate(ctx, targetId)
+		if err != nil {
+			return err
+		}
+		// Порядок задаётся внутри списка одного владельца
+		if target.OwnerId != todo.OwnerId {
+			return ErrMoveAcrossLists
+		}
+
+		// Новая позиция - между целью и её соседом, остальные задачи не меняются
+		var position string
+		if beforeId != "" {
+			prev, err := s.repo.GetAdjacentPosition(ctx, target.Position, true, id)
+			if err != nil {
+				return err
+			}
+			position, err = rank.Between(prev, target.Position)
+			if err != nil {
+				return err
+			}
+		} else {
+			next, err := s.repo.GetAdjacentPosition(ctx, target.Position, false, id)
+			if err != nil {
+				return err
+			}
+			position, err = rank.Between(target.Position, next)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := s.repo.UpdateTodoPosition(ctx, id, position); err != nil {
+			return err
+		}
+		todo.Position = position
+		return nil
+	})
+	if err != nil {
+		return domain.ToDo{}, err
+	}
+
+	s.log(ctx).Info("Todo %s moved to position %s", id, todo.Position)
+	return todo, nil
+}
+
+func (s *TodoService) AssignTodo(ctx context.Context, id string, assignee string) (domain.ToDo, error) {
+	ctx, span := tracer.Start(ctx, "TodoService.AssignTodo")
+	defer span.End()
+
+	s.log(ctx).Debug("Assigning todo %s to %q", id, assignee)
+
+	var todo domain.ToDo
+	var previous string
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		todo, err = s.repo.GetTodoByIdForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.authorize(ctx, todo, domain.RoleEditor); err != nil {
+			return err
+		}
+
+		assigneeId := ""
+		if assignee != "" {
+			assigneeId, err = s.resolveUserWithAccess(ctx, todo, assignee)
+			if err != nil {
+				return err
+			}
+		}
+		if err := s.repo.SetAssignee(ctx, id, assigneeId); err != nil {
+			return err
+		}
+		previous = todo.AssigneeId
+		todo.AssigneeId = assigneeId
+		return nil
+	})
+	if err != nil {
+		return domain.ToDo{}, err
+	}
+
+	if previous != todo.AssigneeId {
+		s.log(ctx).Info("Todo %s assigned to %q (was %q)", id, todo.AssigneeId, previous)
+		// Прежний исполнитель тоже узнаёт о переназначении
+		s.notify(ctx, ports.EventAssigned, todo, previous)
+	}
+	return todo, nil
+}
+
+func (s *TodoService) WatchTodo(ctx context.Context, id string, user string) (domain.ToDo, error) {
+	ctx, span := tracer.Start(ctx, "TodoService.WatchTodo")
+	defer span.End()
+
+	return s.changeWatcher(ctx, id, user, true)
+}
+
+func (s *TodoService) UnwatchTodo(ctx context.Context, id string, user string) (domain.ToDo, error) {
+	ctx, span := tracer.Start(ctx, "TodoService.UnwatchTodo")
+	defer span.End()
+
+	return s.changeWatcher(ctx, id, user, false)
+}
+
+// changeWatcher: подписаться или отписаться сам может любой с доступом к задаче,
+// управлять чужой подпиской - только редактор
+func (s *TodoService) changeWatcher(ctx context.Context, id string, user string, watch bool) (domain.ToDo, error) {
+	s.log(ctx).Debug("Changing watcher of todo %s: user=%q, watch=%v", id, user, watch)
+
+	var todo domain.ToDo
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		todo, err = s.repo.GetTodoByIdForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		me, err := identity.UserId(ctx)
+		if err != nil {
+			return err
+		}
+		userId := me
+		if user != "" && user != "me" {
+			userId, err = s.resolveUserWithAccess(ctx, todo, user)
+			if err != nil && !(errors.Is(err, ErrNoAccess) && !watch) {
+				return err
+			}
+		}
+		if userId != me {
+			if err := s.authorize(ctx, todo, domain.RoleEditor); err != nil {
+				return err
+			}
+		}
+
+		if watch {
+			err = s.repo.AddWatcher(ctx, id, userId)
+		} else {
+			err = s.repo.RemoveWatcher(ctx, id, userId)
+		}
+		if err != nil {
+			return err
+		}
+		todo, err = s.repo.GetTodoById(ctx, id)
+		return err
+	})
+	if err != nil {
+		return domain.ToDo{}, err
+	}
+	return todo, nil
+}
+
+// resolveUserWithAccess находит пользователя по "me", имени или id и проверяет,
+// что задача ему доступна
+func (s *TodoService) resolveUserWithAccess(ctx context.Context, todo domain.ToDo, user string) (string, error) {
+	if user == "me" {
+		return identity.UserId(ctx)
+	}
+
+	found, err := s.users.GetUserByUsername(ctx, strings.ToLower(user))
+	if err != nil {
+		found, err = s.users.GetUserById(ctx, user)
+		if err != nil {
+			return "", fmt.Errorf("%w: %s", ErrUserNotFound, user)
+		}
+	}
+
+	ok, err := hasTodoAccess(ctx, s.shares, found.Id, todo)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return found.Id, ErrNoAccess
+	}
+	return found.Id, nil
+}
+
+// hasTodoAccess проверяет, доступна ли задача пользователю userId
+func hasTodoAccess(ctx context.Context, shares ports.ShareRepo, userId string, todo domain.ToDo) (bool, error) {
+	if userId == todo.OwnerId {
+		return true, nil
+	}
+	role, err := shares.GetTodoRole(ctx, userId, todo)
+	if err != nil {
+		return false, err
+	}
+	return role != "", nil
+}
+
+// notify уведомляет исполнителя, наблюдателей и extra, кроме автора изменения
+func (s *TodoService) notify(ctx context.Context, event string, todo domain.ToDo, extra ...string) {
+	recipients := append(append([]string{todo.AssigneeId}, todo.Watchers...), extra...)
+	sendNotification(ctx, s.notifier, s.log(ctx), ports.Notification{Event: event}, todo, recipients)
+}
+
+// sendNotification дополняет n данными задачи и отправляет её получателям,
+// исключая автора изменения и повторы. Ошибка доставки не отменяет уже
+// выполненное изменение.
+func sendNotification(ctx context.Context, notifier ports.Notifier, log *logger.Logger, n ports.Notification, todo domain.ToDo, recipients []string) {
+	actorId, _ := identity.UserId(ctx)
+
+	seen := map[string]bool{actorId: true, "": true}
+	n.Recipients = []string{}
+	for _, userId := range recipients {
+		if !seen[userId] {
+			seen[userId] = true
+			n.Recipients = append(n.Recipients, userId)
+		}
+	}
+	if len(n.Recipients) == 0 {
+		return
+	}
+
+	n.TodoId = todo.Id
+	n.Todo = todo.Todo
+	n.ActorId = actorId
+	n.At = time.Now()
+	send := func() {
+		if err := notifier.Notify(ctx, n); err != nil {
+			log.Warn("Failed to send %s notification for todo %s: %v", n.Event, todo.Id, err)
+		}
+	}
+	if pending, ok := ctx.Value(pendingKey{}).(*pendingNotifications); ok {
+		pending.send = append(pending.send, send)
+		return
+	}
+	send()
+}
+
+type pendingKey struct{}
+
+// pendingNotifications - уведомления, отложенные до фиксации внешней транзакции
+type pendingNotifications struct {
+	send []func()
+}
+
+// deferNotifications откладывает уведомления, отправленные с возвращённым
+// контекстом, до вызова flush. Если flush не вызван, они отбрасываются.
+func deferNotifications(ctx context.Context) (context.Context, *pendingNotifications) {
+	pending := &pendingNotifications{}
+	return context.WithValue(ctx, pendingKey{}, pending), pending
+}
+
+func (p *pendingNotifications) flush() {
+	for _, send := range p.send {
+		send()
+	}
+	p.send = nil
+}
+
+// log - логгер запроса с его X-Request-ID
+func (s *TodoService) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, s.logger)
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/ports"
+)
+
+const newTodoId = "6f1c1d1e-7a43-4a53-9a43-2f5a0e6a9b10"
+
+func newTestTodoService(t *testing.T, todos *memTodos, shares *memShares, notifier *recordingNotifier) *TodoService {
+	t.Helper()
+	if shares == nil {
+		shares = &memShares{}
+	}
+	if notifier == nil {
+		notifier = &recordingNotifier{}
+	}
+	return NewToDoService(todos, shares, nil, inlineUnitOfWork{}, notifier, newTestLogger(t)).(*TodoService)
+}
+
+func TestUpsertTodoCreatesUnknownId(t *testing.T) {
+	todos := newMemTodos()
+	s := newTestTodoService(t, todos, nil, nil)
+	ctx := asUser("alice", domain.UserRoleUser)
+
+	// Созданная офлайн и уже выполненная задача сохраняет своё состояние
+	result, created, err := s.UpsertTodo(ctx, domain.ToDo{Id: newTodoId, Todo: "done offline", Priority: "low", Complete: true})
+	if err != nil {
+		t.Fatalf("UpsertTodo: %v", err)
+	}
+	if !created {
+		t.Fatal("UpsertTodo of an unknown id did not create the todo")
+	}
+	if !result.Complete || result.CompletedAt.IsZero() {
+		t.Errorf("created todo: complete=%v completedAt=%v, want completed with a time", result.Complete, result.CompletedAt)
+	}
+	if result.OwnerId != "alice" || result.Position == "" {
+		t.Errorf("created todo: owner=%q position=%q, want alice with a position", result.OwnerId, result.Position)
+	}
+
+	// id не в виде UUID не создаёт задачу
+	if _, _, err := s.UpsertTodo(ctx, domain.ToDo{Id: "todo-1", Todo: "x", Priority: "low"}); err == nil {
+		t.Error("UpsertTodo created a todo with a non-UUID id")
+	}
+}
+
+func TestUpsertTodoReplacesExisting(t *testing.T) {
+	completedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	todos := newMemTodos(domain.ToDo{
+		Id: newTodoId, OwnerId: "alice", Todo: "old", Message: "notes", Priority: "high",
+		Complete: true, CompletedAt: completedAt, Tags: []string{"work"}, AssigneeId: "bob",
+	})
+	notifier := &recordingNotifier{}
+	s := newTestTodoService(t, todos, nil, notifier)
+	ctx := asUser("alice", domain.UserRoleUser)
+
+	// Время выполнения из базы сохраняется, если тело его не передаёт
+	result, created, err := s.UpsertTodo(ctx, domain.ToDo{Id: newTodoId, Todo: "new", Priority: "low", Complete: true})
+	if err != nil {
+		t.Fatalf("UpsertTodo: %v", err)
+	}
+	if created {
+		t.Fatal("UpsertTodo of an existing todo reported it as created")
+	}
+	if result.Todo != "new" || result.Priority != "low" || result.Message != "" || len(result.Tags) != 0 {
+		t.Errorf("replaced todo = %+v, want omitted fields cleared", result)
+	}
+	if !result.CompletedAt.Equal(completedAt) {
+		t.Errorf("completedAt = %v, want the stored %v", result.CompletedAt, completedAt)
+	}
+	if got := notifier.events(); len(got) != 1 || got[0] != "updated "+newTodoId {
+		t.Errorf("notifications = %v, want one updated", got)
+	}
+
+	// Снятый флаг выполнения убирает время выполнения
+	result, _, err = s.UpsertTodo(ctx, domain.ToDo{Id: newTodoId, Todo: "new", Priority: "low", Complete: false, CompletedAt: completedAt})
+	if err != nil {
+		t.Fatalf("UpsertTodo: %v", err)
+	}
+	if result.Complete || !result.CompletedAt.IsZero() {
+		t.Errorf("reopened todo: complete=%v completedAt=%v", result.Complete, result.CompletedAt)
+	}
+}
+
+func TestCreateTodoWithClientId(t *testing.T) {
+	todos := newMemTodos()
+	s := newTestTodoService(t, todos, nil, nil)
+	ctx := asUser("alice", domain.UserRoleUser)
+
+	created, err := s.CreateTodo(ctx, domain.ToDo{Id: newTodoId, Todo: "offline"})
+	if err != nil {
+		t.Fatalf("CreateTodo: %v", err)
+	}
+	if created.Id != newTodoId {
+		t.Errorf("created id = %q, want the client id %q", created.Id, newTodoId)
+	}
+
+	// Повторный id - конфликт, а не перезапись
+	if _, err := s.CreateTodo(ctx, domain.ToDo{Id: newTodoId, Todo: "again"}); !errors.Is(err, ports.ErrConflict) {
+		t.Errorf("CreateTodo with a taken id: err = %v, want ErrConflict", err)
+	}
+	if todos.todos[newTodoId].Todo != "offline" {
+		t.Errorf("todo text = %q, want the first version kept", todos.todos[newTodoId].Todo)
+	}
+
+	if _, err := s.CreateTodo(ctx, domain.ToDo{Id: "todo-1", Todo: "x"}); !errors.Is(err, ErrInvalidTodoId) {
+		t.Errorf("CreateTodo with a non-UUID id: err = %v, want ErrInvalidTodoId", err)
+	}
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/ports"
+)
+
+// TodoStatsCollector периодически пересчитывает число открытых, просроченных и
+// выполненных задач: считать их при каждом опросе метрик слишком дорого
+type TodoStatsCollector struct {
+	stats  ports.StatsRepo
+	gauges ports.TodoGauges
+	logger *logger.Logger
+}
+
+func NewTodoStatsCollector(stats ports.StatsRepo, gauges ports.TodoGauges, logger *logger.Logger) *TodoStatsCollector {
+	return &TodoStatsCollector{
+		stats:  stats,
+		gauges: gauges,
+		logger: logger,
+	}
+}
+
+// Refresh считает задачи и обновляет значения метрик
+func (c *TodoStatsCollector) Refresh(ctx context.Context) error {
+	counts, err := c.stats.GetTodoCounts(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	c.gauges.SetTodoCounts(counts)
+	return nil
+}
+
+// Run вызывает Refresh сразу и затем каждые interval, пока не отменён ctx
+func (c *TodoStatsCollector) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := c.Refresh(ctx); err != nil && ctx.Err() == nil {
+			c.logger.Error("Todo metrics refresh failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/identity"
+	"ToDo-List/internal/core/ports"
+
+	"github.com/google/uuid"
+)
+
+// apiTokenPrefix отличает персональные токены от прочих секретов (например, при сканировании утечек)
+const apiTokenPrefix = "tdl_"
+
+// lastUsedResolution - last_used_at обновляется не чаще, чтобы не писать в БД на каждый запрос
+const lastUsedResolution = time.Minute
+
+var (
+	ErrInvalidTokenName = errors.New("token name must be 1-100 characters")
+	ErrInvalidScope     = errors.New("unknown token scope")
+	ErrInvalidExpiry    = errors.New("token expiry must be in the future")
+)
+
+var knownScopes = map[string]bool{
+	domain.ScopeTodosRead:  true,
+	domain.ScopeTodosWrite: true,
+}
+
+type APITokenService struct {
+	tokens ports.APITokenRepo
+	users  ports.UserRepo
+	logger *logger.Logger
+}
+
+func NewAPITokenService(tokens ports.APITokenRepo, users ports.UserRepo, logger *logger.Logger) ports.APITokenService {
+	return &APITokenService{
+		tokens: tokens,
+		users:  users,
+		logger: logger,
+	}
+}
+
+func (s *APITokenService) CreateToken(ctx context.Context, name string, scopes []string, expiresAt time.Time) (domain.APIToken, string, error) {
+	userId, err := identity.UserId(ctx)
+	if err != nil {
+		return domain.APIToken{}, "", err
+	}
+	// Токен действует только в рабочем пространстве, где он выпущен
+	workspaceId, err := identity.WorkspaceId(ctx)
+	if err != nil {
+		return domain.APIToken{}, "", err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 100 {
+		return domain.APIToken{}, "", ErrInvalidTokenName
+	}
+	// Без явных областей токен получает только чтение
+	if len(scopes) == 0 {
+		scopes = []string{domain.ScopeTodosRead}
+	}
+	for _, scope := range scopes {
+		if !knownScopes[scope] {
+			return domain.APIToken{}, "", fmt.Errorf("%w: %q", ErrInvalidScope, scope)
+		}
+	}
+	if !expiresAt.IsZero() && !expiresAt.After(time.Now()) {
+		return domain.APIToken{}, "", ErrInvalidExpiry
+	}
+
+	secret, err := randomToken()
+	if err != nil {
+		return domain.APIToken{}, "", err
+	}
+	plain := apiTokenPrefix + secret
+
+	token := domain.APIToken{
+		Id:          uuid.NewString(),
+		UserId:      userId,
+		WorkspaceId: workspaceId,
+		Name:        name,
+		Prefix:      plain[:len(apiTokenPrefix)+8],
+		TokenHash:   hashToken(plain),
+		Scopes:      scopes,
+		ExpiresAt:   expiresAt,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.tokens.CreateAPIToken(ctx, token); err != nil {
+		return domain.APIToken{}, "", err
+	}
+
+	s.log(ctx).Info("API token %s created for user %s in workspace %s", token.Id, userId, workspaceId)
+	return token, plain, nil
+}
+
+func (s *APITokenService) ListTokens(ctx context.Context) ([]domain.APIToken, error) {
+	userId, err := identity.UserId(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.tokens.GetAPITokensByUser(ctx, userId)
+}
+
+func (s *APITokenService) RevokeToken(ctx context.Context, id string) error {
+	userId, err := identity.UserId(ctx)
+	if err != nil {
+		return err
+	}
+	return s.tokens.DeleteAPIToken(ctx, userId, id)
+}
+
+func (s *APITokenService) Authenticate(ctx context.Context, plain string) (domain.User, domain.APIToken, error) {
+	if !strings.HasPrefix(plain, apiTokenPrefix) {
+		return domain.User{}, domain.APIToken{}, ports.ErrUnauthenticated
+	}
+
+	token, err := s.tokens.GetAPITokenByHash(ctx, hashToken(plain))
+	if err != nil {
+		return domain.User{}, domain.APIToken{}, err
+	}
+	now := time.Now()
+	if !token.ExpiresAt.IsZero() && now.After(token.ExpiresAt) {
+		s.log(ctx).Debug("API token %s expired", token.Id)
+		return domain.User{}, domain.APIToken{}, ports.ErrUnauthenticated
+	}
+
+	user, err := s.users.GetUserById(ctx, token.UserId)
+	if err != nil || user.Disabled {
+		return domain.User{}, domain.APIToken{}, ports.ErrUnauthenticated
+	}
+
+	if now.Sub(token.LastUsedAt) >= lastUsedResolution {
+		if err := s.tokens.TouchAPIToken(ctx, token.Id, now); err != nil {
+			s.log(ctx).Warn("Failed to update last use of token %s: %v", token.Id, err)
+		} else {
+			token.LastUsedAt = now
+		}
+	}
+	return user, token, nil
+}
+
+// log - логгер запроса с его X-Request-ID
+func (s *APITokenService) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, s.logger)
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/ports"
+)
+
+// memTokens - токены в памяти по хешу
+type memTokens struct {
+	tokens  map[string]domain.APIToken
+	touches int
+}
+
+func (r *memTokens) CreateAPIToken(ctx context.Context, token domain.APIToken) error {
+	r.tokens[token.TokenHash] = token
+	return nil
+}
+
+func (r *memTokens) GetAPITokensByUser(ctx context.Context, userId string) ([]domain.APIToken, error) {
+	tokens := []domain.APIToken{}
+	for _, token := range r.tokens {
+		if token.UserId == userId {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens, nil
+}
+
+func (r *memTokens) GetAPITokenByHash(ctx context.Context, hash string) (domain.APIToken, error) {
+	token, ok := r.tokens[hash]
+	if !ok {
+		return domain.APIToken{}, ports.ErrUnauthenticated
+	}
+	return token, nil
+}
+
+func (r *memTokens) DeleteAPIToken(ctx context.Context, userId, id string) error {
+	for hash, token := range r.tokens {
+		if token.Id == id && token.UserId == userId {
+			delete(r.tokens, hash)
+			return nil
+		}
+	}
+	return fmt.Errorf("api token with id %s not found", id)
+}
+
+func (r *memTokens) TouchAPIToken(ctx context.Context, id string, usedAt time.Time) error {
+	r.touches++
+	for hash, token := range r.tokens {
+		if token.Id == id {
+			token.LastUsedAt = usedAt
+			r.tokens[hash] = token
+		}
+	}
+	return nil
+}
+
+func newTestTokenService(t *testing.T, users ...domain.User) (ports.APITokenService, *memTokens) {
+	t.Helper()
+	tokens := &memTokens{tokens: map[string]domain.APIToken{}}
+	return NewAPITokenService(tokens, newMemUsers(users...), newTestLogger(t)), tokens
+}
+
+func TestCreateToken(t *testing.T) {
+	s, tokens := newTestTokenService(t, domain.User{Id: "alice"})
+
+	token, plain, err := s.CreateToken(asUser("alice", domain.UserRoleUser), " ci ", nil, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(plain, apiTokenPrefix) || !strings.HasPrefix(plain, token.Prefix) {
+		t.Errorf("plain token %q, prefix %q", plain, token.Prefix)
+	}
+	// Без явных областей - только чтение; токен привязан к пространству
+	if token.Name != "ci" || token.UserId != "alice" || token.WorkspaceId != "w1" || len(token.Scopes) != 1 || token.Scopes[0] != domain.ScopeTodosRead {
+		t.Errorf("token = %+v", token)
+	}
+	stored, ok := tokens.tokens[hashToken(plain)]
+	if !ok || stored.TokenHash == plain {
+		t.Error("token not stored by its hash")
+	}
+}
+
+func TestCreateTokenValidation(t *testing.T) {
+	s, tokens := newTestTokenService(t, domain.User{Id: "alice"})
+	ctx := asUser("alice", domain.UserRoleUser)
+	tests := []struct {
+		name      string
+		scopes    []string
+		expiresAt time.Time
+		want      error
+	}{
+		{name: "", want: ErrInvalidTokenName},
+		{name: strings.Repeat("x", 101), want: ErrInvalidTokenName},
+		{name: "ci", scopes: []string{"admin"}, want: ErrInvalidScope},
+		{name: "ci", expiresAt: time.Now().Add(-time.Hour), want: ErrInvalidExpiry},
+	}
+	for _, tt := range tests {
+		if _, _, err := s.CreateToken(ctx, tt.name, tt.scopes, tt.expiresAt); !errors.Is(err, tt.want) {
+			t.Errorf("CreateToken(%q, %v, %v) = %v, want %v", tt.name, tt.scopes, tt.expiresAt, err, tt.want)
+		}
+	}
+	if len(tokens.tokens) != 0 {
+		t.Errorf("invalid tokens stored: %v", tokens.tokens)
+	}
+}
+
+func TestAuthenticateToken(t *testing.T) {
+	s, tokens := newTestTokenService(t, domain.User{Id: "alice"}, domain.User{Id: "bob", Disabled: true})
+	_, plain, err := s.CreateToken(asUser("alice", domain.UserRoleUser), "ci", []string{domain.ScopeTodosWrite}, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, token, err := s.Authenticate(context.Background(), plain)
+	if err != nil || user.Id != "alice" || !token.HasScope(domain.ScopeTodosRead) {
+		t.Fatalf("Authenticate = %q, %+v, %v", user.Id, token, err)
+	}
+	// last_used_at пишется не чаще раза в lastUsedResolution
+	s.Authenticate(context.Background(), plain)
+	if tokens.touches != 1 {
+		t.Errorf("touches = %d, want 1", tokens.touches)
+	}
+
+	_, expired, _ := s.CreateToken(asUser("alice", domain.UserRoleUser), "old", nil, time.Now().Add(time.Hour))
+	for hash, token := range tokens.tokens {
+		if token.Name == "old" {
+			token.ExpiresAt = time.Now().Add(-time.Minute)
+			tokens.tokens[hash] = token
+		}
+	}
+	_, disabled, _ := s.CreateToken(asUser("bob", domain.UserRoleUser), "ci", nil, time.Time{})
+
+	for name, plain := range map[string]string{"no prefix": strings.TrimPrefix(plain, apiTokenPrefix), "unknown": apiTokenPrefix + "unknown", "expired": expired, "disabled user": disabled} {
+		if _, _, err := s.Authenticate(context.Background(), plain); !errors.Is(err, ports.ErrUnauthenticated) {
+			t.Errorf("%s: Authenticate = %v, want ErrUnauthenticated", name, err)
+		}
+	}
+}
+
+func TestRevokeToken(t *testing.T) {
+	s, _ := newTestTokenService(t, domain.User{Id: "alice"}, domain.User{Id: "bob"})
+	token, plain, err := s.CreateToken(asUser("alice", domain.UserRoleUser), "ci", nil, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Чужой токен отозвать нельзя
+	if err := s.RevokeToken(asUser("bob", domain.UserRoleUser), token.Id); err == nil {
+		t.Error("bob revoked alice's token")
+	}
+	if err := s.RevokeToken(asUser("alice", domain.UserRoleUser), token.Id); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.Authenticate(context.Background(), plain); !errors.Is(err, ports.ErrUnauthenticated) {
+		t.Errorf("Authenticate after revoke = %v, want ErrUnauthenticated", err)
+	}
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/ports"
+)
+
+type txMarker struct{}
+
+// trackingUnitOfWork помечает контекст транзакции и запоминает её исход
+type trackingUnitOfWork struct {
+	outcomes []string
+}
+
+func (u *trackingUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	err := fn(context.WithValue(ctx, txMarker{}, true))
+	if err != nil {
+		u.outcomes = append(u.outcomes, "rollback")
+	} else {
+		u.outcomes = append(u.outcomes, "commit")
+	}
+	return err
+}
+
+func inTx(ctx context.Context) bool {
+	marked, _ := ctx.Value(txMarker{}).(bool)
+	return marked
+}
+
+// lockingTodos запоминает вызовы репозитория и были ли они внутри транзакции
+type lockingTodos struct {
+	*memTodos
+	calls []string
+}
+
+func (r *lockingTodos) track(ctx context.Context, call string) {
+	if !inTx(ctx) {
+		call += " outside transaction"
+	}
+	r.calls = append(r.calls, call)
+}
+
+func (r *lockingTodos) GetTodoByIdForUpdate(ctx context.Context, id string) (domain.ToDo, error) {
+	r.track(ctx, "lock "+id)
+	return r.memTodos.GetTodoByIdForUpdate(ctx, id)
+}
+
+func (r *lockingTodos) UpdateTodo(ctx context.Context, todo domain.ToDo) error {
+	r.track(ctx, "update "+todo.Id)
+	return r.memTodos.UpdateTodo(ctx, todo)
+}
+
+// txNotifier отмечает уведомления, отправленные до фиксации транзакции
+type txNotifier struct {
+	recordingNotifier
+	early int
+}
+
+func (n *txNotifier) Notify(ctx context.Context, notification ports.Notification) error {
+	if inTx(ctx) {
+		n.early++
+	}
+	return n.recordingNotifier.Notify(ctx, notification)
+}
+
+func TestCompleteTodoInTransaction(t *testing.T) {
+	todos := &lockingTodos{memTodos: newMemTodos(domain.ToDo{Id: "t1", OwnerId: "alice", AssigneeId: "bob"})}
+	uow := &trackingUnitOfWork{}
+	notifier := &txNotifier{}
+	s := NewToDoService(todos, &memShares{}, nil, uow, notifier, newTestLogger(t))
+
+	if err := s.CompleteTodoById(asUser("alice", domain.UserRoleUser), "t1"); err != nil {
+		t.Fatal(err)
+	}
+	// Строка читается с блокировкой и обновляется в той же транзакции
+	if len(todos.calls) != 2 || todos.calls[0] != "lock t1" || todos.calls[1] != "update t1" {
+		t.Errorf("repository calls = %v", todos.calls)
+	}
+	if len(uow.outcomes) != 1 || uow.outcomes[0] != "commit" {
+		t.Errorf("transactions = %v, want one commit", uow.outcomes)
+	}
+	if !todos.todos["t1"].Complete {
+		t.Error("todo not completed")
+	}
+	// Уведомление уходит только после фиксации
+	if len(notifier.sent) != 1 || notifier.early != 0 {
+		t.Errorf("notifications = %v, sent inside the transaction: %d", notifier.events(), notifier.early)
+	}
+}
+
+func TestCompleteTodoRollsBackWhenForbidden(t *testing.T) {
+	todos := &lockingTodos{memTodos: newMemTodos(domain.ToDo{Id: "t1", OwnerId: "alice"})}
+	uow := &trackingUnitOfWork{}
+	notifier := &txNotifier{}
+	shares := &memShares{roles: map[string]string{"carol/t1": domain.RoleViewer}}
+	s := NewToDoService(todos, shares, nil, uow, notifier, newTestLogger(t))
+
+	if err := s.CompleteTodoById(asUser("carol", domain.UserRoleUser), "t1"); !errors.Is(err, ports.ErrForbidden) {
+		t.Fatalf("CompleteTodoById by viewer = %v, want ErrForbidden", err)
+	}
+	if len(todos.calls) != 1 || len(uow.outcomes) != 1 || uow.outcomes[0] != "rollback" {
+		t.Errorf("calls = %v, transactions = %v; want only the lock and a rollback", todos.calls, uow.outcomes)
+	}
+	if todos.todos["t1"].Complete || len(notifier.sent) != 0 {
+		t.Error("forbidden completion applied or notified")
+	}
+}
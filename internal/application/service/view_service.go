@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/ports"
+)
+
+type ViewService struct {
+	views  ports.ViewRepo
+	todos  ports.PostgreRepo
+	logger *logger.Logger
+}
+
+func NewViewService(views ports.ViewRepo, todos ports.PostgreRepo, logger *logger.Logger) ports.ViewService {
+	return &ViewService{
+		views:  views,
+		todos:  todos,
+		logger: logger,
+	}
+}
+
+func (s *ViewService) GetAllViews(ctx context.Context) ([]domain.View, error) {
+	s.log(ctx).Debug("Getting all views")
+	return s.views.GetAllViews(ctx)
+}
+
+func (s *ViewService) GetViewById(ctx context.Context, id string) (domain.View, error) {
+	s.log(ctx).Debug("Getting view by ID: %s", id)
+	return s.views.GetViewById(ctx, id)
+}
+
+func (s *ViewService) CreateView(ctx context.Context, view domain.View) (domain.View, error) {
+	s.log(ctx).Debug("Creating view in service")
+	return s.views.CreateView(ctx, view)
+}
+
+func (s *ViewService) UpdateView(ctx context.Context, view domain.View) error {
+	s.log(ctx).Debug("Updating view: %s", view.Id)
+	view.UpdatedAt = time.Now()
+	return s.views.UpdateView(ctx, view)
+}
+
+func (s *ViewService) DeleteView(ctx context.Context, id string) error {
+	s.log(ctx).Debug("Deleting view: %s", id)
+	return s.views.DeleteViewById(ctx, id)
+}
+
+// GetViewTodos выполняет сохранённый фильтр
+func (s *ViewService) GetViewTodos(ctx context.Context, id string) ([]domain.ToDo, error) {
+	s.log(ctx).Debug("Getting todos of view: %s", id)
+
+	view, err := s.views.GetViewById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.todos.GetAllTodosWithFilters(ctx, view.Filter)
+}
+
+// GetViewCounts считает задачи в каждом сохранённом фильтре (для бейджей в
+// сайдбаре): все счётчики - один запрос к БД
+func (s *ViewService) GetViewCounts(ctx context.Context) ([]domain.ViewCount, error) {
+	s.log(ctx).Debug("Counting todos for all views")
+
+	views, err := s.views.GetAllViews(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filters := make([]domain.TodoFilter, len(views))
+	for i, view := range views {
+		filters[i] = view.Filter
+	}
+	totals, err := s.todos.CountTodosByFilters(ctx, filters)
+	if err != nil {
+		s.log(ctx).Error("Failed to count todos for views: %v", err)
+		return nil, err
+	}
+
+	counts := make([]domain.ViewCount, len(views))
+	for i, view := range views {
+		counts[i] = domain.ViewCount{ViewId: view.Id, Count: totals[i]}
+	}
+	return counts, nil
+}
+
+// log - логгер запроса с его X-Request-ID
+func (s *ViewService) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, s.logger)
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/ports"
+)
+
+// memViews - сохранённые фильтры в памяти, в порядке сайдбара
+type memViews struct {
+	ports.ViewRepo
+	views []domain.View
+}
+
+func (r *memViews) GetAllViews(ctx context.Context) ([]domain.View, error) {
+	return r.views, nil
+}
+
+func (r *memViews) GetViewById(ctx context.Context, id string) (domain.View, error) {
+	for _, view := range r.views {
+		if view.Id == id {
+			return view, nil
+		}
+	}
+	return domain.View{}, ports.ErrViewNotFound
+}
+
+// filteringTodos выполняет фильтр по статусу и запоминает, сколько раз считались счётчики
+type filteringTodos struct {
+	*memTodos
+	filters    []domain.TodoFilter
+	countCalls int
+}
+
+func (r *filteringTodos) matches(todo domain.ToDo, filter domain.TodoFilter) bool {
+	switch filter.Status {
+	case "active":
+		return !todo.Complete
+	case "completed":
+		return todo.Complete
+	}
+	return true
+}
+
+func (r *filteringTodos) GetAllTodosWithFilters(ctx context.Context, filter domain.TodoFilter) ([]domain.ToDo, error) {
+	r.filters = append(r.filters, filter)
+	all, _ := r.memTodos.GetAllTodosWithFilters(ctx, filter)
+	todos := []domain.ToDo{}
+	for _, todo := range all {
+		if r.matches(todo, filter) {
+			todos = append(todos, todo)
+		}
+	}
+	return todos, nil
+}
+
+func (r *filteringTodos) CountTodosByFilters(ctx context.Context, filters []domain.TodoFilter) ([]int, error) {
+	r.countCalls++
+	counts := make([]int, len(filters))
+	for i, filter := range filters {
+		for _, todo := range r.todos {
+			if r.matches(todo, filter) {
+				counts[i]++
+			}
+		}
+	}
+	return counts, nil
+}
+
+func newViewFixture(t *testing.T) (ports.ViewService, *filteringTodos) {
+	t.Helper()
+	todos := &filteringTodos{memTodos: newMemTodos(
+		domain.ToDo{Id: "t1", OwnerId: "alice"},
+		domain.ToDo{Id: "t2", OwnerId: "alice", Complete: true},
+		domain.ToDo{Id: "t3", OwnerId: "alice", Complete: true},
+	)}
+	views := &memViews{views: []domain.View{
+		{Id: "done", Name: "Done", Filter: domain.TodoFilter{Status: "completed", Sort: "-completed_at"}, Pinned: true},
+		{Id: "open", Name: "Open", Filter: domain.TodoFilter{Status: "active"}},
+		{Id: "all", Name: "All"},
+	}}
+	return NewViewService(views, todos, newTestLogger(t)), todos
+}
+
+func TestGetViewTodosRunsSavedFilter(t *testing.T) {
+	s, todos := newViewFixture(t)
+
+	got, err := s.GetViewTodos(asUser("alice", domain.UserRoleUser), "done")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Id != "t2" || got[1].Id != "t3" {
+		t.Errorf("todos = %v, want t2 and t3", got)
+	}
+	// Фильтр передаётся целиком, включая сортировку
+	if len(todos.filters) != 1 || todos.filters[0].Sort != "-completed_at" {
+		t.Errorf("filters = %+v", todos.filters)
+	}
+
+	if _, err := s.GetViewTodos(asUser("alice", domain.UserRoleUser), "missing"); !errors.Is(err, ports.ErrViewNotFound) {
+		t.Errorf("unknown view = %v, want ErrViewNotFound", err)
+	}
+}
+
+func TestGetViewCounts(t *testing.T) {
+	s, todos := newViewFixture(t)
+
+	counts, err := s.GetViewCounts(asUser("alice", domain.UserRoleUser))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []domain.ViewCount{{ViewId: "done", Count: 2}, {ViewId: "open", Count: 1}, {ViewId: "all", Count: 3}}
+	if len(counts) != len(want) {
+		t.Fatalf("counts = %+v, want %+v", counts, want)
+	}
+	for i := range want {
+		if counts[i] != want[i] {
+			t.Errorf("counts[%d] = %+v, want %+v", i, counts[i], want[i])
+		}
+	}
+	// Все счётчики - один запрос
+	if todos.countCalls != 1 {
+		t.Errorf("count queries = %d, want 1", todos.countCalls)
+	}
+}
@@ -0,0 +1,324 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/identity"
+	"ToDo-List/internal/core/ports"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidSlug          = errors.New("slug must be 3-32 lowercase letters, digits or dashes")
+	ErrInvalidWorkspaceName = errors.New("workspace name must be 1-100 characters")
+	ErrInvalidMemberRole    = errors.New("role must be owner or member")
+	ErrInvalidSettings      = errors.New("invalid workspace settings")
+	ErrLastOwner            = errors.New("workspace must keep at least one owner")
+	ErrMemberNotFound       = errors.New("user to add not found")
+)
+
+// slugPattern - slug используется как поддомен, поэтому только [a-z0-9-]
+var slugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,30}[a-z0-9]$`)
+
+type WorkspaceService struct {
+	workspaces ports.WorkspaceRepo
+	users      ports.UserRepo
+	uow        ports.UnitOfWork
+	logger     *logger.Logger
+}
+
+func NewWorkspaceService(workspaces ports.WorkspaceRepo, users ports.UserRepo, uow ports.UnitOfWork, logger *logger.Logger) ports.WorkspaceService {
+	return &WorkspaceService{
+		workspaces: workspaces,
+		users:      users,
+		uow:        uow,
+		logger:     logger,
+	}
+}
+
+func (s *WorkspaceService) Resolve(ctx context.Context, userId, ref string) (domain.Workspace, string, error) {
+	if ref == "" {
+		return s.defaultWorkspace(ctx, userId)
+	}
+
+	workspace, err := s.workspaces.GetWorkspaceBySlug(ctx, strings.ToLower(ref))
+	if errors.Is(err, ports.ErrWorkspaceNotFound) {
+		if _, parseErr := uuid.Parse(ref); parseErr == nil {
+			workspace, err = s.workspaces.GetWorkspaceById(ctx, ref)
+		}
+	}
+	if err != nil {
+		return domain.Workspace{}, "", err
+	}
+
+	member, err := s.workspaces.GetMember(ctx, workspace.Id, userId)
+	if err != nil {
+		return domain.Workspace{}, "", err
+	}
+	return workspace, member.Role, nil
+}
+
+// defaultWorkspace возвращает первое пространство пользователя. Пользователь
+// без пространств (только что зарегистрированный или вошедший через SSO)
+// получает личное пространство при первом запросе.
+func (s *WorkspaceService) defaultWorkspace(ctx context.Context, userId string) (domain.Workspace, string, error) {
+	mine, err := s.workspaces.GetWorkspacesByUser(ctx, userId)
+	if err != nil {
+		return domain.Workspace{}, "", err
+	}
+	if len(mine) > 0 {
+		return mine[0].Workspace, mine[0].Role, nil
+	}
+
+	user, err := s.users.GetUserById(ctx, userId)
+	if err != nil {
+		return domain.Workspace{}, "", err
+	}
+	base := personalSlug(user.Username)
+	for i := 0; i < 10; i++ {
+		slug := base
+		if i > 0 {
+			slug = fmt.Sprintf("%s-%d", base, i+1)
+		}
+		workspace, err := s.create(ctx, userId, slug, user.Username)
+		if errors.Is(err, ports.ErrWorkspaceExists) {
+			continue
+		}
+		if err != nil {
+			return domain.Workspace{}, "", err
+		}
+		return workspace, domain.WorkspaceRoleOwner, nil
+	}
+	workspace, err := s.create(ctx, userId, base+"-"+uuid.NewString()[:8], user.Username)
+	if err != nil {
+		return domain.Workspace{}, "", err
+	}
+	return workspace, domain.WorkspaceRoleOwner, nil
+}
+
+func (s *WorkspaceService) ListWorkspaces(ctx context.Context) ([]ports.UserWorkspace, error) {
+	userId, err := identity.UserId(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.workspaces.GetWorkspacesByUser(ctx, userId)
+}
+
+func (s *WorkspaceService) CreateWorkspace(ctx context.Context, slug, name string) (domain.Workspace, error) {
+	userId, err := identity.UserId(ctx)
+	if err != nil {
+		return domain.Workspace{}, err
+	}
+
+	slug = strings.ToLower(strings.TrimSpace(slug))
+	if !slugPattern.MatchString(slug) || strings.Contains(slug, "--") {
+		return domain.Workspace{}, ErrInvalidSlug
+	}
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > 100 {
+		return domain.Workspace{}, ErrInvalidWorkspaceName
+	}
+	return s.create(ctx, userId, slug, name)
+}
+
+// create создаёт пространство; создатель становится его владельцем
+func (s *WorkspaceService) create(ctx context.Context, userId, slug, name string) (domain.Workspace, error) {
+	now := time.Now()
+	workspace := domain.Workspace{
+		Id:        uuid.NewString(),
+		Slug:      slug,
+		Name:      name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.workspaces.CreateWorkspace(ctx, workspace); err != nil {
+			return err
+		}
+		return s.workspaces.AddMember(ctx, domain.WorkspaceMember{
+			WorkspaceId: workspace.Id,
+			UserId:      userId,
+			Role:        domain.WorkspaceRoleOwner,
+			CreatedAt:   now,
+		})
+	})
+	if err != nil {
+		return domain.Workspace{}, err
+	}
+
+	s.log(ctx).Info("Workspace %s (%s) created by %s", workspace.Id, slug, userId)
+	return workspace, nil
+}
+
+// current возвращает пространство запроса; requireOwner проверяет роль owner
+func (s *WorkspaceService) current(ctx context.Context, requireOwner bool) (domain.Workspace, string, error) {
+	workspace, role, ok := identity.WorkspaceFromContext(ctx)
+	if !ok {
+		return domain.Workspace{}, "", identity.ErrNoWorkspace
+	}
+	if requireOwner && role != domain.WorkspaceRoleOwner {
+		return domain.Workspace{}, "", ports.ErrForbidden
+	}
+	return workspace, role, nil
+}
+
+func (s *WorkspaceService) UpdateSettings(ctx context.Context, settings domain.WorkspaceSettings) (domain.Workspace, error) {
+	workspace, _, err := s.current(ctx, true)
+	if err != nil {
+		return domain.Workspace{}, err
+	}
+
+	switch settings.DefaultPriority {
+	case "", "low", "medium", "high":
+	default:
+		return domain.Workspace{}, fmt.Errorf("%w: defaultPriority must be low, medium or high", ErrInvalidSettings)
+	}
+	if settings.AttachmentMaxSize < 0 {
+		return domain.Workspace{}, fmt.Errorf("%w: attachmentMaxSize must not be negative", ErrInvalidSettings)
+	}
+
+	workspace.Settings = settings
+	workspace.UpdatedAt = time.Now()
+	if err := s.workspaces.UpdateWorkspaceSettings(ctx, workspace.Id, settings, workspace.UpdatedAt); err != nil {
+		return domain.Workspace{}, err
+	}
+
+	s.log(ctx).Info("Settings of workspace %s updated: %+v", workspace.Id, settings)
+	return workspace, nil
+}
+
+func (s *WorkspaceService) ListMembers(ctx context.Context) ([]domain.WorkspaceMember, error) {
+	workspace, _, err := s.current(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	return s.workspaces.GetMembers(ctx, workspace.Id)
+}
+
+func (s *WorkspaceService) AddMember(ctx context.Context, username, email, role string) (domain.WorkspaceMember, error) {
+	workspace, _, err := s.current(ctx, true)
+	if err != nil {
+		return domain.WorkspaceMember{}, err
+	}
+	if role == "" {
+		role = domain.WorkspaceRoleMember
+	}
+	if role != domain.WorkspaceRoleOwner && role != domain.WorkspaceRoleMember {
+		return domain.WorkspaceMember{}, ErrInvalidMemberRole
+	}
+
+	username = strings.ToLower(strings.TrimSpace(username))
+	email = strings.ToLower(strings.TrimSpace(email))
+	if (username == "") == (email == "") {
+		return domain.WorkspaceMember{}, ErrShareeRequired
+	}
+	var user domain.User
+	if username != "" {
+		user, err = s.users.GetUserByUsername(ctx, username)
+	} else {
+		user, err = s.users.GetUserByEmail(ctx, email)
+	}
+	if err != nil {
+		return domain.WorkspaceMember{}, ErrMemberNotFound
+	}
+
+	member := domain.WorkspaceMember{
+		WorkspaceId: workspace.Id,
+		UserId:      user.Id,
+		Username:    user.Username,
+		Role:        role,
+		CreatedAt:   time.Now(),
+	}
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		// Понижение единственного владельца оставило бы пространство без управления
+		if role != domain.WorkspaceRoleOwner {
+			if err := s.ensureOtherOwner(ctx, workspace.Id, user.Id); err != nil {
+				return err
+			}
+		}
+		return s.workspaces.AddMember(ctx, member)
+	})
+	if err != nil {
+		return domain.WorkspaceMember{}, err
+	}
+
+	s.log(ctx).Info("User %s added to workspace %s as %s", user.Id, workspace.Id, role)
+	return member, nil
+}
+
+func (s *WorkspaceService) RemoveMember(ctx context.Context, userId string) error {
+	currentUserId, err := identity.UserId(ctx)
+	if err != nil {
+		return err
+	}
+	// Покинуть пространство может любой участник, исключить другого - только владелец
+	workspace, _, err := s.current(ctx, userId != currentUserId)
+	if err != nil {
+		return err
+	}
+
+	return s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.ensureOtherOwner(ctx, workspace.Id, userId); err != nil {
+			return err
+		}
+		return s.workspaces.RemoveMember(ctx, workspace.Id, userId)
+	})
+}
+
+// ensureOtherOwner проверяет, что без userId в пространстве останется владелец
+func (s *WorkspaceService) ensureOtherOwner(ctx context.Context, workspaceId, userId string) error {
+	members, err := s.workspaces.GetMembers(ctx, workspaceId)
+	if err != nil {
+		return err
+	}
+	for _, m := range members {
+		if m.Role == domain.WorkspaceRoleOwner && m.UserId != userId {
+			return nil
+		}
+	}
+	for _, m := range members {
+		if m.UserId == userId && m.Role == domain.WorkspaceRoleOwner {
+			return ErrLastOwner
+		}
+	}
+	return nil
+}
+
+// personalSlug строит slug личного пространства из имени пользователя
+func personalSlug(username string) string {
+	slug := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		}
+		return '-'
+	}, username)
+	for strings.Contains(slug, "--") {
+		slug = strings.ReplaceAll(slug, "--", "-")
+	}
+	slug = strings.Trim(slug, "-")
+	if len(slug) > 24 {
+		slug = strings.TrimRight(slug[:24], "-")
+	}
+	if slug == "" {
+		slug = "workspace"
+	} else if len(slug) < 3 {
+		slug = "ws-" + slug
+	}
+	return slug
+}
+
+// log - логгер запроса с его X-Request-ID
+func (s *WorkspaceService) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, s.logger)
+}
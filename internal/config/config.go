@@ -0,0 +1,457 @@
+// Package config собирает конфигурацию приложения из нескольких источников.
+//
+// Приоритет (каждый следующий переопределяет предыдущий):
+// значения по умолчанию < файл YAML (--config или CONFIG_FILE) <
+// переменные окружения < флаги командной строки.
+//
+// Секреты можно передавать файлом: DATABASE_URL_FILE=/run/secrets/db читает
+// значение DATABASE_URL из файла. Ошибки всех источников собираются и
+// возвращаются одним отчётом до запуска приложения.
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"ToDo-List/internal/adapters/blob"
+	httpadapter "ToDo-List/internal/adapters/http"
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/adapters/oidc"
+	"ToDo-List/internal/adapters/tracing"
+	"ToDo-List/internal/application/service"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Config struct {
+	DatabaseURL string
+	Server      ServerConfig
+	Log         LogConfig
+
+	SessionTTL     time.Duration
+	IdempotencyTTL time.Duration
+	// AdminUsernames получают роль admin при старте
+	AdminUsernames []string
+
+	// OIDC включается, если задан Issuer
+	OIDC             oidc.Config
+	NotifyWebhookURL string
+
+	Blob             BlobConfig
+	AttachmentLimits service.AttachmentLimits
+	RateLimits       httpadapter.RateLimits
+	Metrics          MetricsConfig
+	Tracing          tracing.Config
+
+	// PrintConfig - вывести действующую конфигурацию и выйти (--print-config)
+	PrintConfig bool
+
+	settings []*setting
+}
+
+type ServerConfig struct {
+	Port              string
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// ShutdownTimeout - сколько ждать завершения запросов при остановке
+	ShutdownTimeout time.Duration
+	// За HTTPS-прокси cookie должны быть помечены Secure
+	CookieSecure bool
+	// Пространство выбирается и по поддомену <slug>.WorkspaceDomain
+	WorkspaceDomain string
+}
+
+type LogConfig struct {
+	Level logger.Level
+	// Format - text, json или logfmt
+	Format logger.Format
+	// Stdout - писать в stdout; File - дополнительно в файл с ротацией
+	Stdout bool
+	File   logger.FileConfig
+	// PackageLevels - уровни отдельных пакетов: repo=debug
+	PackageLevels map[string]logger.Level
+	// Content - писать содержимое задач; false - только их id
+	Content bool
+	// RedactFields и RedactPatterns дополняют встроенные правила скрытия секретов
+	RedactFields   []string
+	RedactPatterns []string
+}
+
+type BlobConfig struct {
+	// Store - local (каталог Dir) или s3
+	Store      string
+	Dir        string
+	S3         blob.S3Config
+	GCInterval time.Duration
+}
+
+type MetricsConfig struct {
+	// Enabled - отдавать метрики Prometheus на /metrics
+	Enabled bool
+	// Token - токен Bearer для /metrics; пусто - без проверки
+	Token string
+	// RefreshInterval - как часто пересчитывается число задач
+	RefreshInterval time.Duration
+}
+
+// Error - отчёт обо всех ошибках конфигурации
+type Error struct {
+	Problems []string
+}
+
+func (e *Error) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// setting связывает поле Config с ключом файла, переменной окружения и флагом
+type setting struct {
+	key    string // ключ в файле: server.read_timeout
+	env    string
+	usage  string
+	secret bool // значение скрывается при печати, можно задать через <env>_FILE
+	value  flag.Value
+	source string // откуда взято действующее значение
+}
+
+// flagName - имя флага по ключу: server.read_timeout -> server-read-timeout
+func (s *setting) flagName() string {
+	return strings.NewReplacer(".", "-", "_", "-").Replace(s.key)
+}
+
+func (s *setting) set(raw, source string) error {
+	if err := s.value.Set(strings.TrimSpace(raw)); err != nil {
+		return fmt.Errorf("%s: invalid value %q: %v", source, raw, err)
+	}
+	s.source = source
+	return nil
+}
+
+func defaults() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:              "8000",
+			ReadTimeout:       time.Minute,
+			ReadHeaderTimeout: 10 * time.Second,
+			WriteTimeout:      2 * time.Minute,
+			IdleTimeout:       2 * time.Minute,
+			ShutdownTimeout:   30 * time.Second,
+		},
+		Log: LogConfig{
+			Level:   logger.LevelInfo,
+			Stdout:  true,
+			Content: true,
+			File: logger.FileConfig{
+				MaxSize:    100 << 20,
+				MaxAge:     24 * time.Hour,
+				MaxBackups: 7,
+				Compress:   true,
+			},
+		},
+		SessionTTL:       service.DefaultSessionTTL,
+		IdempotencyTTL:   service.DefaultIdempotencyTTL,
+		Blob:             BlobConfig{Store: "local", Dir: "./data/blobs", GCInterval: 10 * time.Minute},
+		AttachmentLimits: service.DefaultAttachmentLimits,
+		RateLimits:       httpadapter.DefaultRateLimits,
+		Metrics:          MetricsConfig{Enabled: true, RefreshInterval: time.Minute},
+		Tracing:          tracing.Config{Exporter: tracing.ExporterNone, ServiceName: "todo", SampleRatio: 1},
+	}
+}
+
+// bind перечисляет все параметры; порядок определяет вывод --print-config
+func (c *Config) bind() {
+	c.settings = []*setting{
+		{key: "database.url", env: "DATABASE_URL", usage: "PostgreSQL connection URL", secret: true, value: stringValue{&c.DatabaseURL}},
+
+		{key: "server.port", env: "PORT", usage: "HTTP port", value: portValue{&c.Server.Port}},
+		{key: "server.read_timeout", env: "HTTP_READ_TIMEOUT", usage: "time to read a whole request", value: durationValue{&c.Server.ReadTimeout}},
+		{key: "server.read_header_timeout", env: "HTTP_READ_HEADER_TIMEOUT", usage: "time to read request headers", value: durationValue{&c.Server.ReadHeaderTimeout}},
+		{key: "server.write_timeout", env: "HTTP_WRITE_TIMEOUT", usage: "time to write a response", value: durationValue{&c.Server.WriteTimeout}},
+		{key: "server.idle_timeout", env: "HTTP_IDLE_TIMEOUT", usage: "keep-alive idle timeout", value: durationValue{&c.Server.IdleTimeout}},
+		{key: "server.shutdown_timeout", env: "SHUTDOWN_TIMEOUT", usage: "time to drain requests on shutdown", value: durationValue{&c.Server.ShutdownTimeout}},
+		{key: "server.cookie_secure", env: "COOKIE_SECURE", usage: "mark cookies Secure (behind HTTPS)", value: boolValue{&c.Server.CookieSecure}},
+		{key: "server.workspace_domain", env: "WORKSPACE_DOMAIN", usage: "select workspace by <slug>.<domain>", value: stringValue{&c.Server.WorkspaceDomain}},
+
+		{key: "log.level", env: "LOG_LEVEL", usage: "debug, info, warn, error or fatal", value: levelValue{&c.Log.Level}},
+		{key: "log.format", env: "LOG_FORMAT", usage: "text, json or logfmt", value: formatValue{&c.Log.Format}},
+		{key: "log.levels", env: "LOG_LEVELS", usage: "per-package levels: repo=debug,service=warn", value: packageLevelsValue{&c.Log.PackageLevels}},
+		{key: "log.stdout", env: "LOG_STDOUT", usage: "write logs to stdout", value: boolValue{&c.Log.Stdout}},
+		{key: "log.file", env: "LOG_FILE", usage: "also write logs to this file", value: stringValue{&c.Log.File.Path}},
+		{key: "log.file_max_size", env: "LOG_FILE_MAX_SIZE", usage: "rotate the log file after this many bytes", value: sizeValue{&c.Log.File.MaxSize}},
+		{key: "log.file_max_age", env: "LOG_FILE_MAX_AGE", usage: "rotate the log file after this time", value: durationValue{&c.Log.File.MaxAge}},
+		{key: "log.file_max_backups", env: "LOG_FILE_MAX_BACKUPS", usage: "rotated log files to keep, 0 keeps all", value: countValue{&c.Log.File.MaxBackups}},
+		{key: "log.file_compress", env: "LOG_FILE_COMPRESS", usage: "gzip rotated log files", value: boolValue{&c.Log.File.Compress}},
+		{key: "log.content", env: "LOG_CONTENT", usage: "log todo and view contents; false logs only their ids", value: boolValue{&c.Log.Content}},
+		{key: "log.redact_fields", env: "LOG_REDACT_FIELDS", usage: "extra field names whose values are hidden", value: listValue{&c.Log.RedactFields}},
+		{key: "log.redact_patterns", env: "LOG_REDACT_PATTERNS", usage: "extra regular expressions to hide, separated by whitespace", value: patternsValue{&c.Log.RedactPatterns}},
+
+		{key: "auth.session_ttl", env: "SESSION_TTL", usage: "session lifetime", value: durationValue{&c.SessionTTL}},
+		{key: "auth.admin_usernames", env: "ADMIN_USERNAMES", usage: "users promoted to admin on start", value: listValue{&c.AdminUsernames}},
+		{key: "idempotency.ttl", env: "IDEMPOTENCY_TTL", usage: "how long Idempotency-Key responses are kept", value: durationValue{&c.IdempotencyTTL}},
+
+		{key: "oidc.issuer", env: "OIDC_ISSUER", usage: "OpenID Connect issuer; enables SSO", value: stringValue{&c.OIDC.Issuer}},
+		{key: "oidc.client_id", env: "OIDC_CLIENT_ID", usage: "OIDC client id", value: stringValue{&c.OIDC.ClientID}},
+		{key: "oidc.client_secret", env: "OIDC_CLIENT_SECRET", usage: "OIDC client secret", secret: true, value: stringValue{&c.OIDC.ClientSecret}},
+		{key: "oidc.redirect_url", env: "OIDC_REDIRECT_URL", usage: "OIDC callback URL", value: stringValue{&c.OIDC.RedirectURL}},
+		{key: "oidc.scopes", env: "OIDC_SCOPES", usage: "extra OIDC scopes", value: listValue{&c.OIDC.Scopes}},
+		{key: "oidc.username_claim", env: "OIDC_USERNAME_CLAIM", usage: "claim used as username", value: stringValue{&c.OIDC.UsernameClaim}},
+
+		{key: "notify.webhook_url", env: "NOTIFY_WEBHOOK_URL", usage: "webhook for todo notifications", secret: true, value: stringValue{&c.NotifyWebhookURL}},
+
+		{key: "blob.store", env: "BLOB_STORE", usage: "attachment storage: local or s3", value: choiceValue{&c.Blob.Store, []string{"local", "s3"}}},
+		{key: "blob.dir", env: "BLOB_DIR", usage: "directory for local attachment storage", value: stringValue{&c.Blob.Dir}},
+		{key: "blob.gc_interval", env: "BLOB_GC_INTERVAL", usage: "how often unused blobs are removed", value: durationValue{&c.Blob.GCInterval}},
+		{key: "blob.s3.endpoint", env: "S3_ENDPOINT", usage: "S3 endpoint URL", value: stringValue{&c.Blob.S3.Endpoint}},
+		{key: "blob.s3.region", env: "S3_REGION", usage: "S3 region", value: stringValue{&c.Blob.S3.Region}},
+		{key: "blob.s3.bucket", env: "S3_BUCKET", usage: "S3 bucket", value: stringValue{&c.Blob.S3.Bucket}},
+		{key: "blob.s3.access_key", env: "S3_ACCESS_KEY", usage: "S3 access key", value: stringValue{&c.Blob.S3.AccessKey}},
+		{key: "blob.s3.secret_key", env: "S3_SECRET_KEY", usage: "S3 secret key", secret: true, value: stringValue{&c.Blob.S3.SecretKey}},
+		{key: "blob.s3.prefix", env: "S3_PREFIX", usage: "prefix for S3 object keys", value: stringValue{&c.Blob.S3.Prefix}},
+
+		{key: "attachments.max_size", env: "ATTACHMENT_MAX_SIZE", usage: "max attachment size in bytes", value: sizeValue{&c.AttachmentLimits.MaxSize}},
+		{key: "attachments.types", env: "ATTACHMENT_TYPES", usage: "allowed attachment MIME types", value: listValue{&c.AttachmentLimits.AllowedTypes}},
+
+		{key: "rate_limit.read", env: "RATE_LIMIT_READ", usage: "read requests per client: <requests>/<period> or off", value: rateLimitValue{&c.RateLimits.Read}},
+		{key: "rate_limit.write", env: "RATE_LIMIT_WRITE", usage: "write requests per client: <requests>/<period> or off", value: rateLimitValue{&c.RateLimits.Write}},
+		{key: "rate_limit.trust_proxy", env: "RATE_LIMIT_TRUST_PROXY", usage: "take client address from X-Forwarded-For", value: boolValue{&c.RateLimits.TrustProxy}},
+
+		{key: "metrics.enabled", env: "METRICS_ENABLED", usage: "serve Prometheus metrics on /metrics", value: boolValue{&c.Metrics.Enabled}},
+		{key: "metrics.token", env: "METRICS_TOKEN", usage: "bearer token required by /metrics", secret: true, value: stringValue{&c.Metrics.Token}},
+		{key: "metrics.refresh_interval", env: "METRICS_REFRESH_INTERVAL", usage: "how often todo counts are recomputed", value: durationValue{&c.Metrics.RefreshInterval}},
+
+		{key: "tracing.exporter", env: "TRACING_EXPORTER", usage: "where spans are sent: none, stdout (JSON to stderr) or otlp", value: choiceValue{&c.Tracing.Exporter, []string{tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP}}},
+		{key: "tracing.otlp_endpoint", env: "TRACING_OTLP_ENDPOINT", usage: "OTLP/HTTP collector URL; empty uses OTEL_EXPORTER_OTLP_* or localhost:4318", value: stringValue{&c.Tracing.Endpoint}},
+		{key: "tracing.service_name", env: "TRACING_SERVICE_NAME", usage: "service.name of exported spans", value: stringValue{&c.Tracing.ServiceName}},
+		{key: "tracing.sample_ratio", env: "TRACING_SAMPLE_RATIO", usage: "share of new traces recorded, from 0 to 1", value: ratioValue{&c.Tracing.SampleRatio}},
+	}
+	for _, s := range c.settings {
+		s.source = "default"
+	}
+}
+
+// Load собирает конфигурацию из значений по умолчанию, файла, окружения и
+// флагов args. Возвращает flag.ErrHelp для -h и *Error при ошибках в значениях.
+func Load(args []string) (*Config, error) {
+	c := defaults()
+	c.bind()
+
+	fs := flag.NewFlagSet("todo", flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "YAML config file (env CONFIG_FILE)")
+	fs.BoolVar(&c.PrintConfig, "print-config", false, "print the effective configuration with secrets redacted and exit")
+	byFlag := make(map[string]*setting, len(c.settings))
+	for _, s := range c.settings {
+		byFlag[s.flagName()] = s
+		// Флаги применяются последними, поэтому сначала только запоминаются
+		fs.String(s.flagName(), "", fmt.Sprintf("%s (env %s)", s.usage, s.env))
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, &Error{Problems: []string{fmt.Sprintf("unexpected arguments: %s", strings.Join(fs.Args(), " "))}}
+	}
+
+	var problems []string
+	if *configFile != "" {
+		problems = append(problems, c.loadFile(*configFile)...)
+	}
+	problems = append(problems, c.loadEnv()...)
+	fs.Visit(func(f *flag.Flag) {
+		if s, ok := byFlag[f.Name]; ok {
+			if err := s.set(f.Value.String(), "flag --"+f.Name); err != nil {
+				problems = append(problems, err.Error())
+			}
+		}
+	})
+	problems = append(problems, c.validate()...)
+
+	if len(problems) > 0 {
+		return nil, &Error{Problems: problems}
+	}
+	return c, nil
+}
+
+// loadFile применяет значения из YAML-файла; секции вкладываются как в ключах:
+//
+//	server:
+//	  port: 8080
+func (c *Config) loadFile(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []string{fmt.Sprintf("config file: %v", err)}
+	}
+	var tree map[string]any
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return []string{fmt.Sprintf("config file %s: %v", path, err)}
+	}
+
+	byKey := make(map[string]*setting, len(c.settings))
+	for _, s := range c.settings {
+		byKey[s.key] = s
+	}
+	values := make(map[string]string)
+	flatten("", tree, byKey, values)
+
+	// Порядок ключей стабилен, чтобы отчёт не менялся от запуска к запуску
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var problems []string
+	for _, key := range keys {
+		s, ok := byKey[key]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("config file %s: unknown key %q", path, key))
+			continue
+		}
+		if err := s.set(values[key], "file "+path+": "+key); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	return problems
+}
+
+// flatten раскладывает вложенные секции в ключи через точку; списки
+// склеиваются через перевод строки, а карты-значения параметров (log.levels) - в
+// пары key=value
+func flatten(prefix string, tree map[string]any, known map[string]*setting, out map[string]string) {
+	for name, node := range tree {
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+		switch v := node.(type) {
+		case map[string]any:
+			if _, ok := known[key]; !ok {
+				flatten(key, v, known, out)
+				continue
+			}
+			pairs := make([]string, 0, len(v))
+			for name, value := range v {
+				pairs = append(pairs, fmt.Sprintf("%s=%v", name, value))
+			}
+			sort.Strings(pairs)
+			out[key] = strings.Join(pairs, ",")
+		case []any:
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = fmt.Sprint(item)
+			}
+			out[key] = strings.Join(items, "\n")
+		case nil:
+			out[key] = ""
+		default:
+			out[key] = fmt.Sprint(v)
+		}
+	}
+}
+
+// loadEnv применяет переменные окружения; пустая переменная не учитывается
+func (c *Config) loadEnv() []string {
+	var problems []string
+	for _, s := range c.settings {
+		value := os.Getenv(s.env)
+		source := "env " + s.env
+
+		if s.secret {
+			if file := os.Getenv(s.env + "_FILE"); file != "" {
+				if value != "" {
+					problems = append(problems, fmt.Sprintf("both %s and %s_FILE are set", s.env, s.env))
+					continue
+				}
+				data, err := os.ReadFile(file)
+				if err != nil {
+					problems = append(problems, fmt.Sprintf("%s_FILE: %v", s.env, err))
+					continue
+				}
+				value = strings.TrimRight(string(data), "\r\n")
+				source = "env " + s.env + "_FILE"
+			}
+		}
+
+		if value == "" {
+			continue
+		}
+		if err := s.set(value, source); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	return problems
+}
+
+// validate проверяет связи между параметрами
+func (c *Config) validate() []string {
+	var problems []string
+	if c.DatabaseURL == "" {
+		problems = append(problems, "database.url is required (DATABASE_URL, DATABASE_URL_FILE or --database-url)")
+	}
+	if !c.Log.Stdout && c.Log.File.Path == "" {
+		problems = append(problems, "log.file is required when log.stdout is false")
+	}
+	if c.OIDC.Issuer != "" {
+		if c.OIDC.ClientID == "" {
+			problems = append(problems, "oidc.client_id is required when oidc.issuer is set")
+		}
+		if c.OIDC.RedirectURL == "" {
+			problems = append(problems, "oidc.redirect_url is required when oidc.issuer is set")
+		}
+	}
+	switch c.Blob.Store {
+	case "local":
+		if c.Blob.Dir == "" {
+			problems = append(problems, "blob.dir is required for local storage")
+		}
+	case "s3":
+		if c.Blob.S3.Endpoint == "" || c.Blob.S3.Bucket == "" {
+			problems = append(problems, "blob.s3.endpoint and blob.s3.bucket are required for s3 storage")
+		} else if u, err := url.Parse(c.Blob.S3.Endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+			problems = append(problems, fmt.Sprintf("blob.s3.endpoint %q is not an absolute URL", c.Blob.S3.Endpoint))
+		}
+	}
+	if c.Tracing.Endpoint != "" {
+		if u, err := url.Parse(c.Tracing.Endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+			problems = append(problems, fmt.Sprintf("tracing.otlp_endpoint %q is not an absolute URL", c.Tracing.Endpoint))
+		}
+	}
+	if c.Tracing.Exporter != tracing.ExporterNone && c.Tracing.ServiceName == "" {
+		problems = append(problems, "tracing.service_name is required when tracing is enabled")
+	}
+	if len(c.AttachmentLimits.AllowedTypes) == 0 {
+		problems = append(problems, "attachments.types must list at least one MIME type")
+	}
+	return problems
+}
+
+// Print выводит действующую конфигурацию с источником каждого значения;
+// секреты скрываются
+func (c *Config) Print(w io.Writer) error {
+	for _, s := range c.settings {
+		value := s.value.String()
+		if s.secret && value != "" {
+			value = redact(value)
+		}
+		if _, err := fmt.Fprintf(w, "%-28s = %-40s # %s\n", s.key, value, s.source); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// redact скрывает пароль в URL, а любое другое значение целиком
+func redact(value string) string {
+	if u, err := url.Parse(value); err == nil && u.Scheme != "" {
+		if _, hasPassword := u.User.Password(); hasPassword {
+			return u.Redacted()
+		}
+	}
+	return "xxxxx"
+}
@@ -0,0 +1,205 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ToDo-List/internal/adapters/logger"
+)
+
+// clearEnv очищает переменные всех параметров: пустая переменная не учитывается
+func clearEnv(t *testing.T) {
+	t.Helper()
+	c := defaults()
+	c.bind()
+	t.Setenv("CONFIG_FILE", "")
+	for _, s := range c.settings {
+		t.Setenv(s.env, "")
+		if s.secret {
+			t.Setenv(s.env+"_FILE", "")
+		}
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func source(c *Config, key string) string {
+	for _, s := range c.settings {
+		if s.key == key {
+			return s.source
+		}
+	}
+	return ""
+}
+
+func problems(t *testing.T, err error) []string {
+	t.Helper()
+	var cfgErr *Error
+	if !errors.As(err, &cfgErr) {
+		t.Fatalf("Load error = %v, want *Error", err)
+	}
+	return cfgErr.Problems
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	clearEnv(t)
+	file := writeFile(t, "config.yaml", `
+database:
+  url: postgres://file@localhost/todos
+server:
+  port: 8001
+  read_timeout: 5s
+  shutdown_timeout: 7s
+log:
+  level: warn
+`)
+	t.Setenv("PORT", "8002")
+	t.Setenv("HTTP_READ_TIMEOUT", "6s")
+
+	c, err := Load([]string{"--config", file, "--server-port", "8003"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	tests := []struct {
+		key    string
+		got    interface{}
+		want   interface{}
+		source string
+	}{
+		// флаг > окружение > файл > значение по умолчанию
+		{key: "server.port", got: c.Server.Port, want: "8003", source: "flag --server-port"},
+		{key: "server.read_timeout", got: c.Server.ReadTimeout, want: 6 * time.Second, source: "env HTTP_READ_TIMEOUT"},
+		{key: "server.shutdown_timeout", got: c.Server.ShutdownTimeout, want: 7 * time.Second, source: "file " + file + ": server.shutdown_timeout"},
+		{key: "log.level", got: c.Log.Level, want: logger.LevelWarn, source: "file " + file + ": log.level"},
+		{key: "server.write_timeout", got: c.Server.WriteTimeout, want: 2 * time.Minute, source: "default"},
+		{key: "database.url", got: c.DatabaseURL, want: "postgres://file@localhost/todos", source: "file " + file + ": database.url"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.key, tt.got, tt.want)
+		}
+		if got := source(c, tt.key); got != tt.source {
+			t.Errorf("%s source = %q, want %q", tt.key, got, tt.source)
+		}
+	}
+}
+
+func TestLoadConfigFileFromEnv(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("CONFIG_FILE", writeFile(t, "config.yaml", "database:\n  url: postgres://localhost/todos\nserver:\n  port: 9000\n"))
+
+	c, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if c.Server.Port != "9000" {
+		t.Errorf("server.port = %s, want 9000", c.Server.Port)
+	}
+}
+
+func TestLoadSecretFromFile(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("DATABASE_URL_FILE", writeFile(t, "db", "postgres://todo_app:s3cret@db/todos\n"))
+	t.Setenv("METRICS_TOKEN_FILE", writeFile(t, "token", "metrics-token\r\n"))
+
+	c, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	// перевод строки в конце файла не входит в значение
+	if c.DatabaseURL != "postgres://todo_app:s3cret@db/todos" {
+		t.Errorf("database.url = %q", c.DatabaseURL)
+	}
+	if c.Metrics.Token != "metrics-token" {
+		t.Errorf("metrics.token = %q", c.Metrics.Token)
+	}
+	if got := source(c, "database.url"); got != "env DATABASE_URL_FILE" {
+		t.Errorf("database.url source = %q, want env DATABASE_URL_FILE", got)
+	}
+
+	// флаг переопределяет секрет из файла
+	c, err = Load([]string{"--database-url", "postgres://flag@db/todos"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if c.DatabaseURL != "postgres://flag@db/todos" {
+		t.Errorf("database.url = %q, want the flag value", c.DatabaseURL)
+	}
+}
+
+func TestLoadSecretFileErrors(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("DATABASE_URL", "postgres://env@db/todos")
+	t.Setenv("DATABASE_URL_FILE", writeFile(t, "db", "postgres://file@db/todos"))
+	t.Setenv("OIDC_CLIENT_SECRET_FILE", filepath.Join(t.TempDir(), "missing"))
+	// _FILE действует только для секретов
+	t.Setenv("PORT_FILE", writeFile(t, "port", "9000"))
+
+	_, err := Load(nil)
+	got := problems(t, err)
+	want := []string{"both DATABASE_URL and DATABASE_URL_FILE are set", "OIDC_CLIENT_SECRET_FILE: ", "database.url is required"}
+	if len(got) != len(want) {
+		t.Fatalf("problems = %q, want %d", got, len(want))
+	}
+	for i := range want {
+		if !strings.HasPrefix(got[i], want[i]) {
+			t.Errorf("problem %d = %q, want prefix %q", i, got[i], want[i])
+		}
+	}
+}
+
+// Все ошибки источников собираются в один отчёт
+func TestLoadReportsAllProblems(t *testing.T) {
+	clearEnv(t)
+	file := writeFile(t, "config.yaml", "server:\n  port: http\n  colour: blue\n")
+	t.Setenv("HTTP_READ_TIMEOUT", "soon")
+
+	_, err := Load([]string{"--config", file, "--log-level", "loud"})
+	got := strings.Join(problems(t, err), "\n")
+	for _, want := range []string{
+		`server.colour`,
+		`server.port: invalid value "http"`,
+		`env HTTP_READ_TIMEOUT: invalid value "soon"`,
+		`flag --log-level: invalid value "loud"`,
+		`database.url is required`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("problems do not mention %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestPrintRedactsSecrets(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("DATABASE_URL", "postgres://todo_app:s3cret@db/todos")
+	t.Setenv("METRICS_TOKEN", "metrics-token")
+
+	c, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	var out strings.Builder
+	if err := c.Print(&out); err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"s3cret", "metrics-token"} {
+		if strings.Contains(out.String(), secret) {
+			t.Errorf("Print output contains %q:\n%s", secret, out.String())
+		}
+	}
+	if !strings.Contains(out.String(), "postgres://todo_app:xxxxx@db/todos") {
+		t.Errorf("Print output lacks the redacted database URL:\n%s", out.String())
+	}
+}
@@ -0,0 +1,221 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	httpadapter "ToDo-List/internal/adapters/http"
+	"ToDo-List/internal/adapters/logger"
+)
+
+// Значения параметров реализуют flag.Value: Set разбирает строку из любого
+// источника, String печатает действующее значение
+
+type stringValue struct{ p *string }
+
+func (v stringValue) Set(s string) error { *v.p = s; return nil }
+func (v stringValue) String() string     { return *v.p }
+
+// choiceValue допускает только перечисленные значения
+type choiceValue struct {
+	p       *string
+	choices []string
+}
+
+func (v choiceValue) Set(s string) error {
+	for _, choice := range v.choices {
+		if s == choice {
+			*v.p = s
+			return nil
+		}
+	}
+	return fmt.Errorf("expected one of %s", strings.Join(v.choices, ", "))
+}
+
+func (v choiceValue) String() string { return *v.p }
+
+type portValue struct{ p *string }
+
+func (v portValue) Set(s string) error {
+	port, err := strconv.Atoi(s)
+	if err != nil || port < 1 || port > 65535 {
+		return fmt.Errorf("expected port number 1-65535")
+	}
+	*v.p = s
+	return nil
+}
+
+func (v portValue) String() string { return *v.p }
+
+// listValue - список через запятую или пробел
+type listValue struct{ p *[]string }
+
+func (v listValue) Set(s string) error {
+	*v.p = strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	})
+	return nil
+}
+
+func (v listValue) String() string { return strings.Join(*v.p, ",") }
+
+// patternsValue - регулярные выражения через пробел или перевод строки
+type patternsValue struct{ p *[]string }
+
+func (v patternsValue) Set(s string) error {
+	patterns := strings.Fields(s)
+	for _, pattern := range patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid regular expression %q: %v", pattern, err)
+		}
+	}
+	*v.p = patterns
+	return nil
+}
+
+func (v patternsValue) String() string { return strings.Join(*v.p, " ") }
+
+type boolValue struct{ p *bool }
+
+func (v boolValue) Set(s string) error {
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return fmt.Errorf("expected true or false")
+	}
+	*v.p = b
+	return nil
+}
+
+func (v boolValue) String() string { return strconv.FormatBool(*v.p) }
+
+// durationValue - положительная длительность вида 30s, 10m, 168h
+type durationValue struct{ p *time.Duration }
+
+func (v durationValue) Set(s string) error {
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return fmt.Errorf("expected positive duration like 30s")
+	}
+	*v.p = d
+	return nil
+}
+
+func (v durationValue) String() string { return v.p.String() }
+
+// sizeValue - положительный размер в байтах
+type sizeValue struct{ p *int64 }
+
+func (v sizeValue) Set(s string) error {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n <= 0 {
+		return fmt.Errorf("expected size in bytes")
+	}
+	*v.p = n
+	return nil
+}
+
+func (v sizeValue) String() string { return strconv.FormatInt(*v.p, 10) }
+
+// countValue - неотрицательное число
+type countValue struct{ p *int }
+
+func (v countValue) Set(s string) error {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return fmt.Errorf("expected non-negative number")
+	}
+	*v.p = n
+	return nil
+}
+
+func (v countValue) String() string { return strconv.Itoa(*v.p) }
+
+// ratioValue - доля от 0 до 1
+type ratioValue struct{ p *float64 }
+
+func (v ratioValue) Set(s string) error {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f < 0 || f > 1 {
+		return fmt.Errorf("expected number from 0 to 1")
+	}
+	*v.p = f
+	return nil
+}
+
+func (v ratioValue) String() string { return strconv.FormatFloat(*v.p, 'g', -1, 64) }
+
+// levelValue в отличие от logger.ParseLevel не подменяет неизвестный уровень на INFO
+type levelValue struct{ p *logger.Level }
+
+func (v levelValue) Set(s string) error {
+	level, ok := logger.LookupLevel(s)
+	if !ok {
+		return fmt.Errorf("expected debug, info, warn, error or fatal")
+	}
+	*v.p = level
+	return nil
+}
+
+func (v levelValue) String() string { return strings.ToLower(v.p.String()) }
+
+// packageLevelsValue - уровни пакетов вида repo=debug,service=warn
+type packageLevelsValue struct{ p *map[string]logger.Level }
+
+func (v packageLevelsValue) Set(s string) error {
+	levels := make(map[string]logger.Level)
+	for _, item := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' }) {
+		pkg, levelStr, found := strings.Cut(item, "=")
+		level, ok := logger.LookupLevel(levelStr)
+		if !found || pkg == "" || !ok {
+			return fmt.Errorf("expected <package>=<level>, got %q", item)
+		}
+		levels[pkg] = level
+	}
+	*v.p = levels
+	return nil
+}
+
+func (v packageLevelsValue) String() string {
+	items := make([]string, 0, len(*v.p))
+	for pkg, level := range *v.p {
+		items = append(items, pkg+"="+strings.ToLower(level.String()))
+	}
+	sort.Strings(items)
+	return strings.Join(items, ",")
+}
+
+type formatValue struct{ p *logger.Format }
+
+func (v formatValue) Set(s string) error {
+	format, err := logger.ParseFormat(s)
+	if err != nil {
+		return fmt.Errorf("expected text, json or logfmt")
+	}
+	*v.p = format
+	return nil
+}
+
+func (v formatValue) String() string { return v.p.String() }
+
+// rateLimitValue - ограничение вида 120/1m или off
+type rateLimitValue struct{ p *httpadapter.RateLimit }
+
+func (v rateLimitValue) Set(s string) error {
+	limit, err := httpadapter.ParseRateLimit(s)
+	if err != nil {
+		return err
+	}
+	*v.p = limit
+	return nil
+}
+
+func (v rateLimitValue) String() string {
+	if v.p.Requests <= 0 || v.p.Per <= 0 {
+		return "off"
+	}
+	return fmt.Sprintf("%d/%s", v.p.Requests, v.p.Per)
+}
@@ -1,9 +1,13 @@
 package domain
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 type ToDo struct {
 	Id          string    `json:"id"`
+	OwnerId     string    `json:"ownerId"`
 	Todo        string    `json:"todo"`
 	Message     string    `json:"message"`
 	CreatedAt   time.Time `json:"createdAt"`
@@ -12,4 +16,302 @@ type ToDo struct {
 	Priority    string    `json:"priority"`
 	CompletedAt time.Time `json:"completedAt"`
 	Complete    bool      `json:"complete"`
+	Tags        []string  `json:"tags"`
+	Position    string    `json:"position"`
+	AssigneeId  string    `json:"assigneeId,omitempty"` // пусто - не назначена
+	Watchers    []string  `json:"watchers"`             // id наблюдателей
+	// CommentCount - число комментариев, для бейджа в списке
+	CommentCount int `json:"commentCount"`
+}
+
+// LogSummary - задача в логах, когда её содержимое писать нельзя
+func (t ToDo) LogSummary() string {
+	return "todo " + t.Id
+}
+
+// TodoFilter - фильтр и сортировка списка задач
+type TodoFilter struct {
+	Status   string `json:"status,omitempty"`   // "all", "active", "completed", "overdue"
+	OrderBy  string `json:"orderBy,omitempty"`  // "created_at", "deadline", "priority", "completed_at"
+	OrderDir string `json:"orderDir,omitempty"` // "asc", "desc"
+	Period   string `json:"period,omitempty"`   // "today", "week", "month", "overdue"
+	Query    string `json:"q,omitempty"`        // выражение фильтра, см. пакет query
+	Sort     string `json:"sort,omitempty"`     // "priority,-deadline,created_at", заменяет OrderBy/OrderDir
+	// Assignee - "me", имя или id пользователя, "none". Если задан, ищет среди
+	// всех доступных задач (свои и открытые другими), а не только своих.
+	Assignee string `json:"assignee,omitempty"`
+}
+
+// LogSummary - фильтр в логах, когда содержимое писать нельзя: выражение
+// запроса и исполнитель заменяются признаком, заданы ли они
+func (f TodoFilter) LogSummary() string {
+	return fmt.Sprintf("filter{status=%s period=%s sort=%s orderBy=%s orderDir=%s query=%t assignee=%t}",
+		f.Status, f.Period, f.Sort, f.OrderBy, f.OrderDir, f.Query != "", f.Assignee != "")
+}
+
+// View - сохранённый фильтр (умный список)
+type View struct {
+	Id        string     `json:"id"`
+	Name      string     `json:"name"`
+	Filter    TodoFilter `json:"filter"`
+	Pinned    bool       `json:"pinned"`
+	Position  int        `json:"position"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
+
+// LogSummary - фильтр в логах, когда его содержимое писать нельзя
+func (v View) LogSummary() string {
+	return "view " + v.Id
+}
+
+// ViewCount - количество задач в сохранённом фильтре
+type ViewCount struct {
+	ViewId string `json:"viewId"`
+	Count  int    `json:"count"`
+}
+
+// Системные роли пользователя, права ролей описаны в пакете policy
+const (
+	UserRoleAdmin    = "admin"    // управление пользователями, журнал аудита, статистика
+	UserRoleUser     = "user"     // обычный пользователь
+	UserRoleReadOnly = "readonly" // только чтение задач
+)
+
+type User struct {
+	Id           string    `json:"id"`
+	Username     string    `json:"username"`
+	Email        string    `json:"email,omitempty"`
+	PasswordHash string    `json:"-"`
+	Role         string    `json:"role"`
+	Disabled     bool      `json:"disabled"` // заблокированный пользователь не может войти
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// Session - сессия браузера. В БД хранится только хеш токена из cookie.
+type Session struct {
+	Id        string    `json:"-"` // SHA-256 токена сессии
+	UserId    string    `json:"userId"`
+	CSRFToken string    `json:"-"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	// ImpersonatorId - администратор, вошедший от имени UserId; пусто - обычная сессия
+	ImpersonatorId string `json:"impersonatorId,omitempty"`
+}
+
+// Области доступа персональных токенов
+const (
+	ScopeTodosRead  = "todos:read"
+	ScopeTodosWrite = "todos:write"
+)
+
+// APIToken - персональный токен для скриптов и интеграций.
+// В БД хранится только хеш, сам токен показывается один раз при создании.
+type APIToken struct {
+	Id          string    `json:"id"`
+	UserId      string    `json:"userId"`
+	WorkspaceId string    `json:"workspaceId"` // токен действует только в этом рабочем пространстве
+	Name        string    `json:"name"`
+	Prefix      string    `json:"prefix"` // начало токена, чтобы отличать токены в списке
+	TokenHash   string    `json:"-"`
+	Scopes      []string  `json:"scopes"`
+	ExpiresAt   time.Time `json:"expiresAt"`  // нулевое значение - без срока действия
+	LastUsedAt  time.Time `json:"lastUsedAt"` // нулевое значение - ещё не использовался
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// HasScope проверяет, разрешена ли токену область доступа.
+// todos:write включает todos:read.
+func (t APIToken) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope || (s == ScopeTodosWrite && scope == ScopeTodosRead) {
+			return true
+		}
+	}
+	return false
+}
+
+// Роли совместного доступа, по возрастанию прав
+const (
+	RoleViewer = "viewer" // только чтение
+	RoleEditor = "editor" // чтение и изменение, без удаления
+	RoleOwner  = "owner"  // все действия, включая удаление и выдачу доступа
+)
+
+// RoleRank сравнивает роли: 0 - нет доступа
+func RoleRank(role string) int {
+	switch role {
+	case RoleViewer:
+		return 1
+	case RoleEditor:
+		return 2
+	case RoleOwner:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// Типы ресурсов, которыми можно поделиться
+const (
+	ShareList = "list" // весь список задач владельца, ResourceId - id владельца
+	ShareTodo = "todo" // одна задача
+)
+
+// Share - доступ пользователя UserId к списку или задаче владельца OwnerId
+type Share struct {
+	Id           string    `json:"id"`
+	ResourceType string    `json:"resourceType"`
+	ResourceId   string    `json:"resourceId"`
+	OwnerId      string    `json:"ownerId"`
+	UserId       string    `json:"userId"`
+	Username     string    `json:"username,omitempty"`
+	Role         string    `json:"role"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// Comment - комментарий к задаче. Body - markdown, хранится как есть,
+// отображает его клиент.
+type Comment struct {
+	Id         string    `json:"id"`
+	TodoId     string    `json:"todoId"`
+	AuthorId   string    `json:"authorId"`
+	AuthorName string    `json:"authorName"`
+	Body       string    `json:"body"`
+	Mentions   []Mention `json:"mentions"` // упомянутые через @имя пользователи
+	Edited     bool      `json:"edited"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// Mention - пользователь, упомянутый в комментарии
+type Mention struct {
+	UserId   string `json:"userId"`
+	Username string `json:"username"`
+}
+
+// CommentRevision - прежний текст комментария до правки
+type CommentRevision struct {
+	Id        string    `json:"id"`
+	CommentId string    `json:"commentId"`
+	Body      string    `json:"body"`
+	EditorId  string    `json:"editorId"`
+	EditedAt  time.Time `json:"editedAt"`
+}
+
+// Attachment - файл, прикреплённый к задаче. Содержимое хранится в BlobStore
+// под ключом ContentHash, одинаковые файлы хранятся один раз.
+type Attachment struct {
+	Id          string    `json:"id"`
+	TodoId      string    `json:"todoId"`
+	UploaderId  string    `json:"uploaderId"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"contentType"`
+	Size        int64     `json:"size"`
+	ContentHash string    `json:"contentHash"` // SHA-256, hex
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// Роли участников рабочего пространства
+const (
+	WorkspaceRoleOwner  = "owner"  // управляет участниками и настройками
+	WorkspaceRoleMember = "member" // работает со своими и открытыми ему задачами
+)
+
+// WorkspaceSettings - настройки рабочего пространства
+type WorkspaceSettings struct {
+	// DefaultPriority - приоритет новой задачи, если он не указан
+	DefaultPriority string `json:"defaultPriority,omitempty"`
+	// AttachmentMaxSize - лимит размера вложения в байтах; 0 - лимит экземпляра.
+	// Значение больше лимита экземпляра не действует.
+	AttachmentMaxSize int64 `json:"attachmentMaxSize,omitempty"`
+}
+
+// LogSummary - настройки в логах, когда содержимое писать нельзя: только
+// какие из них заданы
+func (s WorkspaceSettings) LogSummary() string {
+	return fmt.Sprintf("settings{defaultPriority=%t attachmentMaxSize=%t}", s.DefaultPriority != "", s.AttachmentMaxSize != 0)
+}
+
+// Workspace - рабочее пространство команды. Данные разных пространств
+// изолированы: каждая строка задач, фильтров, доступов и т.д. хранит workspace_id.
+type Workspace struct {
+	Id        string            `json:"id"`
+	Slug      string            `json:"slug"` // поддомен и значение заголовка X-Workspace
+	Name      string            `json:"name"`
+	Settings  WorkspaceSettings `json:"settings"`
+	CreatedAt time.Time         `json:"createdAt"`
+	UpdatedAt time.Time         `json:"updatedAt"`
+}
+
+// WorkspaceMember - участник рабочего пространства
+type WorkspaceMember struct {
+	WorkspaceId string    `json:"workspaceId"`
+	UserId      string    `json:"userId"`
+	Username    string    `json:"username"`
+	Role        string    `json:"role"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// Действия в журнале аудита
+const (
+	AuditUserRoleChanged      = "user.role_changed"
+	AuditUserDisabled         = "user.disabled"
+	AuditUserEnabled          = "user.enabled"
+	AuditUserPasswordReset    = "user.password_reset"
+	AuditImpersonationStarted = "impersonation.started"
+	// AuditImpersonatedRequest - изменяющий запрос в сессии администратора от чужого имени
+	AuditImpersonatedRequest = "impersonation.request"
+	AuditLogLevelChanged     = "system.log_level_changed"
+)
+
+// AuditEntry - запись журнала аудита. ImpersonatorId заполнен, если действие
+// выполнил администратор от имени ActorId.
+type AuditEntry struct {
+	Id             string            `json:"id"`
+	ActorId        string            `json:"actorId"`
+	ImpersonatorId string            `json:"impersonatorId,omitempty"`
+	Action         string            `json:"action"`
+	TargetType     string            `json:"targetType,omitempty"`
+	TargetId       string            `json:"targetId,omitempty"`
+	Details        map[string]string `json:"details,omitempty"`
+	CreatedAt      time.Time         `json:"createdAt"`
+}
+
+// SystemStats - сводка по всей инсталляции для администраторов
+type SystemStats struct {
+	Users          int   `json:"users"`
+	Admins         int   `json:"admins"`
+	DisabledUsers  int   `json:"disabledUsers"`
+	ActiveSessions int   `json:"activeSessions"`
+	APITokens      int   `json:"apiTokens"`
+	Workspaces     int   `json:"workspaces"`
+	Todos          int   `json:"todos"`
+	CompletedTodos int   `json:"completedTodos"`
+	Comments       int   `json:"comments"`
+	Attachments    int   `json:"attachments"`
+	BlobBytes      int64 `json:"blobBytes"` // объём уникального содержимого вложений
+}
+
+// TodoCounts - число задач по состояниям во всех рабочих пространствах
+type TodoCounts struct {
+	Open      int
+	Overdue   int // открытые с прошедшим сроком, входят и в Open
+	Completed int
+}
+
+// IdempotencyRecord - сохранённый ответ на запрос с заголовком Idempotency-Key.
+// StatusCode 0 - первый запрос с этим ключом ещё выполняется, до LockedUntil
+// повторы получают отказ, после ключ может занять новый запрос.
+type IdempotencyRecord struct {
+	Key         string
+	UserId      string
+	Fingerprint string // SHA-256 метода, пути, рабочего пространства и тела
+	StatusCode  int
+	Headers     map[string]string // повторяемые заголовки ответа: Content-Type, Location, ETag
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+	LockedUntil time.Time
 }
@@ -0,0 +1,82 @@
+// Package identity хранит аутентифицированного пользователя и его рабочее
+// пространство в context.Context, чтобы сервисы и репозитории могли ограничивать
+// данные владением и рабочим пространством.
+package identity
+
+import (
+	"context"
+	"errors"
+
+	"ToDo-List/internal/core/domain"
+)
+
+// ErrNoUser - в контексте нет аутентифицированного пользователя
+var ErrNoUser = errors.New("no authenticated user in context")
+
+type userKey struct{}
+
+// WithUser возвращает контекст с аутентифицированным пользователем
+func WithUser(ctx context.Context, user domain.User) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// UserFromContext возвращает пользователя запроса
+func UserFromContext(ctx context.Context) (domain.User, bool) {
+	user, ok := ctx.Value(userKey{}).(domain.User)
+	return user, ok
+}
+
+// UserId возвращает id пользователя запроса или ErrNoUser
+func UserId(ctx context.Context) (string, error) {
+	user, ok := UserFromContext(ctx)
+	if !ok || user.Id == "" {
+		return "", ErrNoUser
+	}
+	return user.Id, nil
+}
+
+// ErrNoWorkspace - в контексте нет рабочего пространства
+var ErrNoWorkspace = errors.New("no workspace in context")
+
+type workspaceKey struct{}
+
+type tenant struct {
+	workspace domain.Workspace
+	role      string
+}
+
+// WithWorkspace возвращает контекст с рабочим пространством запроса и ролью
+// пользователя в нём
+func WithWorkspace(ctx context.Context, workspace domain.Workspace, role string) context.Context {
+	return context.WithValue(ctx, workspaceKey{}, tenant{workspace: workspace, role: role})
+}
+
+// WorkspaceFromContext возвращает рабочее пространство запроса и роль пользователя
+func WorkspaceFromContext(ctx context.Context) (domain.Workspace, string, bool) {
+	t, ok := ctx.Value(workspaceKey{}).(tenant)
+	return t.workspace, t.role, ok
+}
+
+// WorkspaceId возвращает id рабочего пространства запроса или ErrNoWorkspace
+func WorkspaceId(ctx context.Context) (string, error) {
+	workspace, _, ok := WorkspaceFromContext(ctx)
+	if !ok || workspace.Id == "" {
+		return "", ErrNoWorkspace
+	}
+	return workspace.Id, nil
+}
+
+type impersonatorKey struct{}
+
+// WithImpersonator отмечает, что запрос выполняет администратор adminId от
+// имени пользователя из контекста
+func WithImpersonator(ctx context.Context, adminId string) context.Context {
+	return context.WithValue(ctx, impersonatorKey{}, adminId)
+}
+
+// ImpersonatorId возвращает id администратора, вошедшего от имени пользователя;
+// false - обычный запрос
+func ImpersonatorId(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(impersonatorKey{}).(string)
+	return id, ok && id != ""
+}
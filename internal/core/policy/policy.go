@@ -0,0 +1,65 @@
+// Package policy описывает системные роли пользователей и их права. Сервисы и
+// обработчики проверяют право через Check, а не сравнивают роли напрямую.
+package policy
+
+import (
+	"context"
+
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/identity"
+	"ToDo-List/internal/core/ports"
+)
+
+// Permission - право на класс действий
+type Permission string
+
+const (
+	TodosRead        Permission = "todos:read"
+	TodosWrite       Permission = "todos:write"
+	UsersManage      Permission = "users:manage" // список, блокировка, роли, сброс пароля
+	UsersImpersonate Permission = "users:impersonate"
+	AuditRead        Permission = "audit:read"
+	StatsRead        Permission = "stats:read"
+	LogsManage       Permission = "logs:manage" // уровни логирования на ходу
+)
+
+var rolePermissions = map[string][]Permission{
+	domain.UserRoleAdmin: {
+		TodosRead, TodosWrite, UsersManage, UsersImpersonate, AuditRead, StatsRead, LogsManage,
+	},
+	domain.UserRoleUser:     {TodosRead, TodosWrite},
+	domain.UserRoleReadOnly: {TodosRead},
+}
+
+// ValidRole проверяет, что роль известна
+func ValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// Allows проверяет, есть ли у роли право. Пустая роль - старые записи
+// без колонки role - считается обычным пользователем.
+func Allows(role string, perm Permission) bool {
+	if role == "" {
+		role = domain.UserRoleUser
+	}
+	for _, p := range rolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// Check проверяет право пользователя из контекста: ErrNoUser без пользователя,
+// ports.ErrForbidden, если права нет
+func Check(ctx context.Context, perm Permission) error {
+	user, ok := identity.UserFromContext(ctx)
+	if !ok || user.Id == "" {
+		return identity.ErrNoUser
+	}
+	if user.Disabled || !Allows(user.Role, perm) {
+		return ports.ErrForbidden
+	}
+	return nil
+}
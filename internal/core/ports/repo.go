@@ -2,22 +2,307 @@ package ports
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
 
 	"ToDo-List/internal/core/domain"
 )
 
-type TodoFilter struct {
-	Status   string // "all", "active", "completed", "overdue"
-	OrderBy  string // "created_at", "deadline", "priority", "completed_at"
-	OrderDir string // "asc", "desc"
-	Period   string // "today", "week", "month", "overdue"
+// UnitOfWork выполняет несколько вызовов репозиториев атомарно. Репозитории,
+// вызванные с контекстом, переданным в fn, работают внутри одной транзакции;
+// ошибка fn откатывает все изменения. Вложенный Do откатывает только свою часть.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+var (
+	// ErrTodoNotFound - задачи нет или она недоступна пользователю
+	ErrTodoNotFound = errors.New("todo not found")
+	// ErrConflict - ресурс с таким id уже существует
+	ErrConflict = errors.New("conflict")
+)
+
+// ConflictError - нарушение уникальности при создании ресурса с id клиента.
+// errors.Is(err, ErrConflict) для него истинно.
+type ConflictError struct {
+	Resource string
+	Id       string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s %s already exists", e.Resource, e.Id)
+}
+
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
 }
 
 type PostgreRepo interface {
-	GetAllTodosWithFilters(ctx context.Context, filter TodoFilter) ([]domain.ToDo, error)
+	GetAllTodosWithFilters(ctx context.Context, filter domain.TodoFilter) ([]domain.ToDo, error)
+	// CountTodosByFilters считает задачи для каждого фильтра одним запросом
+	CountTodosByFilters(ctx context.Context, filters []domain.TodoFilter) ([]int, error)
 	GetTodoById(ctx context.Context, id string) (domain.ToDo, error)
+	// GetTodoByIdForUpdate читает задачу с блокировкой строки до конца
+	// транзакции; вызывается внутри UnitOfWork.Do
+	GetTodoByIdForUpdate(ctx context.Context, id string) (domain.ToDo, error)
 	DeleteTodoById(ctx context.Context, id string) error
 	UpdateTodo(ctx context.Context, todo domain.ToDo) error
+	// CreateTodo сохраняет задачу; занятый id - *ConflictError
 	CreateTodo(ctx context.Context, todo domain.ToDo) (domain.ToDo, error)
+	// GetFirstPosition и GetLastPosition возвращают наименьшую и наибольшую
+	// позицию ручного порядка ("" если задач нет)
+	GetFirstPosition(ctx context.Context) (string, error)
+	GetLastPosition(ctx context.Context) (string, error)
+	// GetAdjacentPosition возвращает позицию соседа перед (before=true) или после
+	// указанной позиции, не считая задачу excludeId ("" если соседа нет)
+	GetAdjacentPosition(ctx context.Context, position string, before bool, excludeId string) (string, error)
+	UpdateTodoPosition(ctx context.Context, id string, position string) error
+	// SetAssignee назначает исполнителя; пустой assigneeId снимает назначение
+	SetAssignee(ctx context.Context, id string, assigneeId string) error
+	AddWatcher(ctx context.Context, id string, userId string) error
+	RemoveWatcher(ctx context.Context, id string, userId string) error
 	Ping() error
-}
\ No newline at end of file
+}
+
+// ErrViewNotFound - сохранённого фильтра нет или он чужой
+var ErrViewNotFound = errors.New("view not found")
+
+type ViewRepo interface {
+	GetAllViews(ctx context.Context) ([]domain.View, error)
+	GetViewById(ctx context.Context, id string) (domain.View, error)
+	CreateView(ctx context.Context, view domain.View) (domain.View, error)
+	UpdateView(ctx context.Context, view domain.View) error
+	DeleteViewById(ctx context.Context, id string) error
+}
+
+// Действия массовой операции над задачами
+const (
+	BulkComplete    = "complete"
+	BulkUncomplete  = "uncomplete"
+	BulkDelete      = "delete"
+	BulkSetPriority = "set_priority"
+	BulkSetDeadline = "set_deadline"
+	BulkMove        = "move"
+	BulkTag         = "tag"
+	BulkUntag       = "untag"
+)
+
+// BulkAction - действие и его параметры для массовой операции
+type BulkAction struct {
+	Action   string    `json:"action"`
+	Priority string    `json:"priority,omitempty"` // для set_priority
+	Deadline time.Time `json:"deadline"`           // для set_deadline
+	Tags     []string  `json:"tags,omitempty"`     // для tag/untag
+	Move     string    `json:"move,omitempty"`     // для move: "top" или "bottom"
+
+	// Positions заполняет сервис для move: новая позиция каждой задачи
+	Positions map[string]string `json:"-"`
+}
+
+// BulkItemResult - результат массовой операции для одной задачи
+type BulkItemResult struct {
+	Id     string `json:"id"`
+	Status string `json:"status"` // "ok", "not_found", "error", "skipped"
+	Error  string `json:"error,omitempty"`
+}
+
+// ErrBulkAborted - в режиме "всё или ничего" одна из задач не обработана,
+// транзакция откачена
+var ErrBulkAborted = errors.New("bulk operation aborted")
+
+type BulkRepo interface {
+	// CountExistingTodos считает, сколько из переданных id существует
+	CountExistingTodos(ctx context.Context, ids []string) (int, error)
+}
+
+var (
+	// ErrUserExists - пользователь с таким именем или email уже зарегистрирован
+	ErrUserExists = errors.New("user already exists")
+	// ErrUserNotFound - пользователь не найден
+	ErrUserNotFound = errors.New("user not found")
+)
+
+// UserFilter - поиск пользователей в админке
+type UserFilter struct {
+	Query  string // подстрока имени или email
+	Role   string
+	Limit  int
+	Offset int
+}
+
+type UserRepo interface {
+	CreateUser(ctx context.Context, user domain.User) (domain.User, error)
+	GetUserById(ctx context.Context, id string) (domain.User, error)
+	GetUserByUsername(ctx context.Context, username string) (domain.User, error)
+	GetUserByEmail(ctx context.Context, email string) (domain.User, error)
+	// GetUserByExternalIdentity ищет пользователя, привязанного к (issuer, subject)
+	GetUserByExternalIdentity(ctx context.Context, issuer, subject string) (domain.User, error)
+	LinkExternalIdentity(ctx context.Context, userId, issuer, subject string) error
+	// GetUsers возвращает пользователей по имени
+	GetUsers(ctx context.Context, filter UserFilter) ([]domain.User, error)
+	UpdateUserRole(ctx context.Context, id, role string, updatedAt time.Time) error
+	// SetUserDisabled блокирует (disabled) или разблокирует пользователя
+	SetUserDisabled(ctx context.Context, id string, disabled bool, at time.Time) error
+	UpdatePassword(ctx context.Context, id, hash string, updatedAt time.Time) error
+	// CountActiveAdmins считает незаблокированных администраторов
+	CountActiveAdmins(ctx context.Context) (int, error)
+}
+
+type SessionRepo interface {
+	CreateSession(ctx context.Context, session domain.Session) error
+	// GetSession ищет сессию по хешу токена
+	GetSession(ctx context.Context, id string) (domain.Session, error)
+	DeleteSession(ctx context.Context, id string) error
+	DeleteExpiredSessions(ctx context.Context) (int64, error)
+	// DeleteSessionsByUser завершает все сессии пользователя, в том числе
+	// открытые от его имени администратором
+	DeleteSessionsByUser(ctx context.Context, userId string) error
+	// DeleteSessionsByImpersonator завершает сессии, открытые администратором
+	// от имени других пользователей
+	DeleteSessionsByImpersonator(ctx context.Context, adminId string) error
+}
+
+// AuditFilter - выборка журнала аудита, новые записи первыми
+type AuditFilter struct {
+	ActorId  string // действия пользователя, в том числе выполненные от его имени
+	TargetId string
+	Action   string
+	Before   time.Time // нулевое значение - с самых новых
+	Limit    int
+}
+
+type AuditRepo interface {
+	CreateAuditEntry(ctx context.Context, entry domain.AuditEntry) error
+	GetAuditEntries(ctx context.Context, filter AuditFilter) ([]domain.AuditEntry, error)
+}
+
+// StatsRepo - сводные данные по всем рабочим пространствам
+type StatsRepo interface {
+	GetSystemStats(ctx context.Context) (domain.SystemStats, error)
+	// GetTodoCounts считает задачи по состояниям; просроченные - со сроком раньше now
+	GetTodoCounts(ctx context.Context, now time.Time) (domain.TodoCounts, error)
+}
+
+type APITokenRepo interface {
+	CreateAPIToken(ctx context.Context, token domain.APIToken) error
+	// GetAPITokensByUser возвращает токены пользователя в текущем рабочем пространстве
+	GetAPITokensByUser(ctx context.Context, userId string) ([]domain.APIToken, error)
+	// GetAPITokenByHash ищет токен по SHA-256; ErrUnauthenticated, если не найден
+	GetAPITokenByHash(ctx context.Context, hash string) (domain.APIToken, error)
+	DeleteAPIToken(ctx context.Context, userId, id string) error
+	TouchAPIToken(ctx context.Context, id string, usedAt time.Time) error
+}
+
+// SharedTodo - задача, доступная пользователю через совместный доступ
+type SharedTodo struct {
+	Todo    domain.ToDo `json:"todo"`
+	Role    string      `json:"role"`
+	OwnerId string      `json:"ownerId"`
+}
+
+type ShareRepo interface {
+	// CreateShare выдаёт доступ; повторная выдача тому же пользователю меняет роль
+	CreateShare(ctx context.Context, share domain.Share) (domain.Share, error)
+	GetShareById(ctx context.Context, id string) (domain.Share, error)
+	GetSharesByResource(ctx context.Context, resourceType, resourceId string) ([]domain.Share, error)
+	DeleteShare(ctx context.Context, id string) error
+	// GetTodoRole возвращает наивысшую роль пользователя для задачи через доступ
+	// к ней самой или ко всему списку владельца; "" - доступа нет
+	GetTodoRole(ctx context.Context, userId string, todo domain.ToDo) (string, error)
+	GetSharedTodos(ctx context.Context, userId string) ([]SharedTodo, error)
+}
+
+// ErrCommentNotFound - комментарий не найден у этой задачи
+var ErrCommentNotFound = errors.New("comment not found")
+
+type CommentRepo interface {
+	// CreateComment сохраняет комментарий вместе с упоминаниями
+	CreateComment(ctx context.Context, comment domain.Comment) error
+	GetCommentsByTodo(ctx context.Context, todoId string) ([]domain.Comment, error)
+	GetCommentById(ctx context.Context, todoId, id string) (domain.Comment, error)
+	// UpdateComment сохраняет прежний текст в истории и заменяет текст и упоминания
+	UpdateComment(ctx context.Context, comment domain.Comment, revision domain.CommentRevision) error
+	DeleteComment(ctx context.Context, id string) error
+	GetCommentRevisions(ctx context.Context, commentId string) ([]domain.CommentRevision, error)
+}
+
+// ErrAttachmentNotFound - вложение не найдено у этой задачи
+var ErrAttachmentNotFound = errors.New("attachment not found")
+
+type AttachmentRepo interface {
+	// CreateAttachment сохраняет вложение и запись о его содержимом (blob);
+	// повторная загрузка того же содержимого переиспользует запись
+	CreateAttachment(ctx context.Context, attachment domain.Attachment) error
+	GetAttachmentsByTodo(ctx context.Context, todoId string) ([]domain.Attachment, error)
+	GetAttachmentById(ctx context.Context, todoId, id string) (domain.Attachment, error)
+	DeleteAttachment(ctx context.Context, id string) error
+	// GetOrphanBlobs возвращает хеши содержимого, на которое не ссылается ни одно вложение
+	GetOrphanBlobs(ctx context.Context, limit int) ([]string, error)
+	// DeleteOrphanBlob удаляет запись о содержимом, если на неё всё ещё нет ссылок,
+	// и держит блокировку строки до конца транзакции; false - запись уже используется
+	DeleteOrphanBlob(ctx context.Context, hash string) (bool, error)
+}
+
+// ErrBlobNotFound - объекта с таким ключом нет в хранилище
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore - хранилище содержимого файлов (локальный диск, S3 и т.п.)
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get открывает объект; ErrBlobNotFound, если его нет
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Exists(ctx context.Context, key string) (bool, error)
+	// Delete удаляет объект; отсутствие объекта не ошибка
+	Delete(ctx context.Context, key string) error
+}
+
+var (
+	// ErrWorkspaceExists - адрес (slug) рабочего пространства уже занят
+	ErrWorkspaceExists = errors.New("workspace already exists")
+	// ErrWorkspaceNotFound - рабочее пространство не найдено
+	ErrWorkspaceNotFound = errors.New("workspace not found")
+	// ErrNotMember - пользователь не участник рабочего пространства
+	ErrNotMember = errors.New("not a member of this workspace")
+)
+
+// UserWorkspace - рабочее пространство пользователя и его роль в нём
+type UserWorkspace struct {
+	Workspace domain.Workspace `json:"workspace"`
+	Role      string           `json:"role"`
+}
+
+// WorkspaceRepo - рабочие пространства и участники. Эти таблицы читаются до
+// выбора рабочего пространства запроса, поэтому запросы ограничиваются явно.
+type WorkspaceRepo interface {
+	CreateWorkspace(ctx context.Context, workspace domain.Workspace) error
+	GetWorkspaceById(ctx context.Context, id string) (domain.Workspace, error)
+	GetWorkspaceBySlug(ctx context.Context, slug string) (domain.Workspace, error)
+	// GetWorkspacesByUser возвращает пространства пользователя в порядке вступления
+	GetWorkspacesByUser(ctx context.Context, userId string) ([]UserWorkspace, error)
+	UpdateWorkspaceSettings(ctx context.Context, id string, settings domain.WorkspaceSettings, updatedAt time.Time) error
+	// AddMember добавляет участника; повторное добавление меняет роль
+	AddMember(ctx context.Context, member domain.WorkspaceMember) error
+	// GetMember возвращает участника или ErrNotMember
+	GetMember(ctx context.Context, workspaceId, userId string) (domain.WorkspaceMember, error)
+	GetMembers(ctx context.Context, workspaceId string) ([]domain.WorkspaceMember, error)
+	// RemoveMember исключает участника и отзывает выданные ему доступы
+	RemoveMember(ctx context.Context, workspaceId, userId string) error
+}
+
+// ErrIdempotencyLeaseLost - блокировка ключа истекла, и его занял другой запрос
+var ErrIdempotencyLeaseLost = errors.New("idempotency key reservation expired")
+
+type IdempotencyRepo interface {
+	// ReserveIdempotencyKey сохраняет запись о начале запроса. Если ключ уже
+	// занят неистёкшей записью с ответом или с действующей блокировкой,
+	// возвращает её и false.
+	ReserveIdempotencyKey(ctx context.Context, record domain.IdempotencyRecord) (domain.IdempotencyRecord, bool, error)
+	// SaveIdempotentResponse сохраняет ответ, если ключ всё ещё занят этой
+	// резервацией (совпадает LockedUntil), иначе ErrIdempotencyLeaseLost.
+	SaveIdempotentResponse(ctx context.Context, record domain.IdempotencyRecord) error
+	// DeleteIdempotencyKey освобождает ключ, занятый этой резервацией
+	DeleteIdempotencyKey(ctx context.Context, record domain.IdempotencyRecord) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error)
+}
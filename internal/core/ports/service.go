@@ -2,6 +2,9 @@ package ports
 
 import (
 	"context"
+	"errors"
+	"io"
+	"time"
 
 	"ToDo-List/internal/core/domain"
 )
@@ -10,7 +13,237 @@ type ToDoService interface {
 	CreateTodo(ctx context.Context, todo domain.ToDo) (domain.ToDo, error)
 	GetTodoById(ctx context.Context, id string) (domain.ToDo, error)
 	UpdateTodo(ctx context.Context, todo domain.ToDo) error
+	// UpsertTodo обновляет задачу, а если задачи с таким id нет - создаёт её
+	// с id клиента (UUID); created=true - задача создана
+	UpsertTodo(ctx context.Context, todo domain.ToDo) (result domain.ToDo, created bool, err error)
 	DeleteTodo(ctx context.Context, id string) error
 	CompleteTodoById(ctx context.Context, id string) error
-	GetAllTodosWithFilters(ctx context.Context, filter TodoFilter) ([]domain.ToDo, error)
+	// MoveTodo ставит задачу в ручном порядке перед beforeId или после afterId
+	MoveTodo(ctx context.Context, id string, beforeId string, afterId string) (domain.ToDo, error)
+	// AssignTodo назначает исполнителя: "me", имя или id пользователя; "" снимает назначение
+	AssignTodo(ctx context.Context, id string, assignee string) (domain.ToDo, error)
+	// WatchTodo и UnwatchTodo: "" или "me" - текущий пользователь
+	WatchTodo(ctx context.Context, id string, user string) (domain.ToDo, error)
+	UnwatchTodo(ctx context.Context, id string, user string) (domain.ToDo, error)
+	GetAllTodosWithFilters(ctx context.Context, filter domain.TodoFilter) ([]domain.ToDo, error)
+}
+
+type ViewService interface {
+	GetAllViews(ctx context.Context) ([]domain.View, error)
+	GetViewById(ctx context.Context, id string) (domain.View, error)
+	CreateView(ctx context.Context, view domain.View) (domain.View, error)
+	UpdateView(ctx context.Context, view domain.View) error
+	DeleteView(ctx context.Context, id string) error
+	GetViewTodos(ctx context.Context, id string) ([]domain.ToDo, error)
+	GetViewCounts(ctx context.Context) ([]domain.ViewCount, error)
+}
+
+// BulkRequest - запрос массовой операции: задачи по списку id или по фильтру
+type BulkRequest struct {
+	Ids    []string           `json:"ids,omitempty"`
+	Filter *domain.TodoFilter `json:"filter,omitempty"`
+	BulkAction
+	Mode   string `json:"mode,omitempty"` // "atomic" (по умолчанию) или "per_item"
+	DryRun bool   `json:"dryRun,omitempty"`
+}
+
+// BulkResult - итог массовой операции
+type BulkResult struct {
+	Action    string           `json:"action"`
+	DryRun    bool             `json:"dryRun"`
+	Matched   int              `json:"matched"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Items     []BulkItemResult `json:"items,omitempty"`
+}
+
+type BulkService interface {
+	ApplyBulk(ctx context.Context, req BulkRequest) (BulkResult, error)
+}
+
+var (
+	// ErrInvalidCredentials - неверное имя пользователя или пароль
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	// ErrUnauthenticated - сессия не найдена или истекла
+	ErrUnauthenticated = errors.New("unauthenticated")
+	// ErrUserDisabled - пользователь заблокирован администратором
+	ErrUserDisabled = errors.New("user is disabled")
+)
+
+// PasswordHasher хеширует и проверяет пароли
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(password, hash string) (bool, error)
+}
+
+type AuthService interface {
+	Register(ctx context.Context, username, email, password string) (domain.User, error)
+	// Login проверяет пароль и открывает сессию; token передаётся клиенту в cookie
+	Login(ctx context.Context, username, password string) (user domain.User, session domain.Session, token string, err error)
+	Logout(ctx context.Context, token string) error
+	// Authenticate возвращает пользователя и сессию по токену из cookie
+	Authenticate(ctx context.Context, token string) (domain.User, domain.Session, error)
+	// LoginExternal открывает сессию для пользователя внешнего провайдера,
+	// при первом входе создавая локальную учётную запись
+	LoginExternal(ctx context.Context, ident ExternalIdentity) (user domain.User, session domain.Session, token string, err error)
+}
+
+// ExternalIdentity - проверенные утверждения о пользователе от провайдера OIDC
+type ExternalIdentity struct {
+	Issuer        string
+	Subject       string
+	Username      string
+	Email         string
+	EmailVerified bool
+}
+
+// IdentityProvider - внешний провайдер входа (OpenID Connect, authorization code + PKCE)
+type IdentityProvider interface {
+	// AuthCodeURL возвращает адрес страницы входа провайдера
+	AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error)
+	// Exchange обменивает код на токены и проверяет ID token (подпись, iss, aud, exp, nonce)
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (ExternalIdentity, error)
+}
+
+type APITokenService interface {
+	// CreateToken выпускает токен; открытое значение возвращается только здесь
+	CreateToken(ctx context.Context, name string, scopes []string, expiresAt time.Time) (domain.APIToken, string, error)
+	ListTokens(ctx context.Context) ([]domain.APIToken, error)
+	RevokeToken(ctx context.Context, id string) error
+	// Authenticate проверяет токен из заголовка Authorization: Bearer
+	Authenticate(ctx context.Context, token string) (domain.User, domain.APIToken, error)
+}
+
+// ErrForbidden - у пользователя недостаточно прав для действия
+var ErrForbidden = errors.New("forbidden")
+
+type AdminService interface {
+	ListUsers(ctx context.Context, filter UserFilter) ([]domain.User, error)
+	GetUser(ctx context.Context, id string) (domain.User, error)
+	SetRole(ctx context.Context, id, role string) (domain.User, error)
+	// SetDisabled блокирует пользователя и завершает его сессии или разблокирует его
+	SetDisabled(ctx context.Context, id string, disabled bool) (domain.User, error)
+	// ResetPassword задаёт новый пароль и завершает сессии пользователя
+	ResetPassword(ctx context.Context, id, password string) error
+	// Impersonate открывает сессию от имени пользователя; действия в ней
+	// записываются в журнал аудита с id администратора
+	Impersonate(ctx context.Context, id string) (user domain.User, session domain.Session, token string, err error)
+	GetAuditLog(ctx context.Context, filter AuditFilter) ([]domain.AuditEntry, error)
+	GetStats(ctx context.Context) (domain.SystemStats, error)
+}
+
+// AuditService записывает действия от имени пользователя из контекста
+type AuditService interface {
+	Record(ctx context.Context, action, targetType, targetId string, details map[string]string)
+}
+
+// ShareRequest - приглашение пользователя по имени или email
+type ShareRequest struct {
+	ResourceType string `json:"resourceType"`
+	ResourceId   string `json:"resourceId"`
+	Username     string `json:"username"`
+	Email        string `json:"email"`
+	Role         string `json:"role"`
+}
+
+type ShareService interface {
+	Share(ctx context.Context, req ShareRequest) (domain.Share, error)
+	GetShares(ctx context.Context, resourceType, resourceId string) ([]domain.Share, error)
+	// RevokeShare отзывает доступ; получатель может отказаться от своего доступа сам
+	RevokeShare(ctx context.Context, id string) error
+	GetSharedWithMe(ctx context.Context) ([]SharedTodo, error)
+}
+
+type CommentService interface {
+	ListComments(ctx context.Context, todoId string) ([]domain.Comment, error)
+	AddComment(ctx context.Context, todoId, body string) (domain.Comment, error)
+	GetComment(ctx context.Context, todoId, id string) (domain.Comment, error)
+	// EditComment меняет текст; править может только автор
+	EditComment(ctx context.Context, todoId, id, body string) (domain.Comment, error)
+	// DeleteComment удаляет комментарий; доступно автору и владельцу задачи
+	DeleteComment(ctx context.Context, todoId, id string) error
+	GetCommentHistory(ctx context.Context, todoId, id string) ([]domain.CommentRevision, error)
+}
+
+// Upload - загружаемый файл; Content читается дважды (хеш и запись в хранилище)
+type Upload struct {
+	Filename string
+	Size     int64
+	Content  io.ReadSeeker
+}
+
+type AttachmentService interface {
+	ListAttachments(ctx context.Context, todoId string) ([]domain.Attachment, error)
+	// Upload проверяет размер и тип содержимого и прикрепляет файл; нужна роль editor
+	Upload(ctx context.Context, todoId string, upload Upload) (domain.Attachment, error)
+	// Open возвращает вложение и его содержимое; вызывающий закрывает reader
+	Open(ctx context.Context, todoId, id string) (domain.Attachment, io.ReadCloser, error)
+	DeleteAttachment(ctx context.Context, todoId, id string) error
+}
+
+type WorkspaceService interface {
+	// Resolve выбирает рабочее пространство запроса по slug или id и проверяет
+	// членство. Пустой ref - первое пространство пользователя; если их нет,
+	// создаётся личное.
+	Resolve(ctx context.Context, userId, ref string) (domain.Workspace, string, error)
+	ListWorkspaces(ctx context.Context) ([]UserWorkspace, error)
+	CreateWorkspace(ctx context.Context, slug, name string) (domain.Workspace, error)
+	// UpdateSettings меняет настройки текущего пространства; только для owner
+	UpdateSettings(ctx context.Context, settings domain.WorkspaceSettings) (domain.Workspace, error)
+	ListMembers(ctx context.Context) ([]domain.WorkspaceMember, error)
+	// AddMember приглашает пользователя по имени или email; только для owner
+	AddMember(ctx context.Context, username, email, role string) (domain.WorkspaceMember, error)
+	// RemoveMember исключает участника; owner может исключить любого, участник - себя
+	RemoveMember(ctx context.Context, userId string) error
+}
+
+// События уведомлений
+const (
+	EventAssigned  = "assigned"
+	EventUpdated   = "updated"
+	EventCompleted = "completed"
+	EventDeleted   = "deleted"
+	EventCommented = "commented"
+	// EventMentioned - пользователя упомянули при правке комментария
+	EventMentioned = "mentioned"
+)
+
+// Notification - событие об изменении задачи для исполнителя и наблюдателей
+type Notification struct {
+	Event      string    `json:"event"`
+	TodoId     string    `json:"todoId"`
+	CommentId  string    `json:"commentId,omitempty"`
+	Todo       string    `json:"todo"`
+	ActorId    string    `json:"actorId"`
+	Recipients []string  `json:"recipients"`
+	At         time.Time `json:"at"`
+}
+
+// Notifier доставляет уведомления (лог, webhook и т.п.)
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// TodoGauges публикует число задач по состояниям (метрики мониторинга)
+type TodoGauges interface {
+	SetTodoCounts(counts domain.TodoCounts)
+}
+
+var (
+	// ErrIdempotencyKeyReused - ключ уже использован для запроса с другим телом
+	ErrIdempotencyKeyReused = errors.New("idempotency key reused with a different request")
+	// ErrIdempotencyInProgress - запрос с этим ключом ещё выполняется
+	ErrIdempotencyInProgress = errors.New("request with this idempotency key is in progress")
+)
+
+// IdempotencyService - повтор POST-запросов с заголовком Idempotency-Key
+type IdempotencyService interface {
+	// Begin резервирует ключ пользователя для запроса с отпечатком fingerprint.
+	// replay=true - запрос уже выполнен, record содержит сохранённый ответ.
+	Begin(ctx context.Context, key, fingerprint string) (record domain.IdempotencyRecord, replay bool, err error)
+	// Complete сохраняет ответ для повторов. reservation - запись, полученная
+	// из Begin, с заполненными StatusCode, Headers и Body.
+	Complete(ctx context.Context, reservation domain.IdempotencyRecord) error
+	// Abort освобождает ключ, чтобы запрос можно было повторить
+	Abort(ctx context.Context, reservation domain.IdempotencyRecord) error
 }
@@ -0,0 +1,116 @@
+package query
+
+import (
+	"fmt"
+	"time"
+)
+
+// Field - поле задачи, по которому можно фильтровать
+type Field string
+
+const (
+	FieldPriority  Field = "priority"
+	FieldDeadline  Field = "deadline"
+	FieldCreated   Field = "created"
+	FieldUpdated   Field = "updated"
+	FieldCompleted Field = "completed"
+	FieldTag       Field = "tag"
+	FieldText      Field = "text"
+	FieldStatus    Field = "status"
+)
+
+// Op - оператор сравнения в условии
+type Op string
+
+const (
+	OpMatch Op = ":"
+	OpEq    Op = "="
+	OpNe    Op = "!="
+	OpLt    Op = "<"
+	OpLe    Op = "<="
+	OpGt    Op = ">"
+	OpGe    Op = ">="
+)
+
+// Expr - узел дерева выражения фильтра
+type Expr interface {
+	Pos() int
+	String() string
+}
+
+// And - логическое И двух выражений
+type And struct {
+	Left, Right Expr
+	At          int
+}
+
+// Or - логическое ИЛИ двух выражений
+type Or struct {
+	Left, Right Expr
+	At          int
+}
+
+// Not - отрицание выражения
+type Not struct {
+	X  Expr
+	At int
+}
+
+// Cond - элементарное условие вида field op value.
+// После валидации заполнено одно из типизированных значений.
+type Cond struct {
+	Field Field
+	Op    Op
+	Value string
+	At    int
+
+	Time time.Time // для полей-дат (начало указанного дня или точное время)
+	Day  bool      // значение задано датой без времени
+	Rank int       // для priority: 1 - high, 2 - medium, 3 - low
+}
+
+func (e *And) Pos() int  { return e.At }
+func (e *Or) Pos() int   { return e.At }
+func (e *Not) Pos() int  { return e.At }
+func (e *Cond) Pos() int { return e.At }
+
+func (e *And) String() string { return fmt.Sprintf("(%s AND %s)", e.Left, e.Right) }
+func (e *Or) String() string  { return fmt.Sprintf("(%s OR %s)", e.Left, e.Right) }
+func (e *Not) String() string { return fmt.Sprintf("NOT %s", e.X) }
+func (e *Cond) String() string {
+	return fmt.Sprintf("%s%s%q", e.Field, e.Op, e.Value)
+}
+
+// Error - ошибка разбора или валидации с позицией (1-based) во входной строке
+type Error struct {
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("invalid query at position %d: %s", e.Pos, e.Msg)
+}
+
+// LogSummary - ошибка в логах, когда содержимое писать нельзя: Msg цитирует
+// фрагмент запроса, поэтому остаётся только позиция
+func (e *Error) LogSummary() string {
+	return fmt.Sprintf("invalid query at position %d", e.Pos)
+}
+
+func errorf(pos int, format string, v ...interface{}) *Error {
+	return &Error{Pos: pos + 1, Msg: fmt.Sprintf(format, v...)}
+}
+
+// PriorityRank возвращает порядковый номер приоритета (как в сортировке репозитория)
+func PriorityRank(priority string) int {
+	switch priority {
+	case "high":
+		return 1
+	case "medium":
+		return 2
+	case "low":
+		return 3
+	default:
+		return 4
+	}
+}
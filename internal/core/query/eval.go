@@ -0,0 +1,136 @@
+package query
+
+import (
+	"strings"
+	"time"
+
+	"ToDo-List/internal/core/domain"
+)
+
+// Match вычисляет выражение для задачи в памяти. Используется хранилищами,
+// которые не умеют компилировать выражение в свой язык запросов.
+func Match(e Expr, todo domain.ToDo, now time.Time) bool {
+	switch e := e.(type) {
+	case *And:
+		return Match(e.Left, todo, now) && Match(e.Right, todo, now)
+	case *Or:
+		return Match(e.Left, todo, now) || Match(e.Right, todo, now)
+	case *Not:
+		return !Match(e.X, todo, now)
+	case *Cond:
+		return matchCond(e, todo, now)
+	default:
+		return false
+	}
+}
+
+// Filter оставляет только задачи, удовлетворяющие выражению
+func Filter(e Expr, todos []domain.ToDo, now time.Time) []domain.ToDo {
+	var result []domain.ToDo
+	for _, todo := range todos {
+		if Match(e, todo, now) {
+			result = append(result, todo)
+		}
+	}
+	return result
+}
+
+func matchCond(c *Cond, todo domain.ToDo, now time.Time) bool {
+	switch c.Field {
+	case FieldPriority:
+		return comparePriority(PriorityRank(todo.Priority), c.Op, c.Rank)
+	case FieldDeadline:
+		return matchTime(c, todo.Deadline)
+	case FieldCreated:
+		return matchTime(c, todo.CreatedAt)
+	case FieldUpdated:
+		return matchTime(c, todo.UpdatedAt)
+	case FieldCompleted:
+		return matchTime(c, todo.CompletedAt)
+	case FieldTag:
+		found := false
+		for _, tag := range todo.Tags {
+			if tag == c.Value {
+				found = true
+				break
+			}
+		}
+		return found != (c.Op == OpNe)
+	case FieldText:
+		needle := strings.ToLower(c.Value)
+		found := strings.Contains(strings.ToLower(todo.Todo), needle) ||
+			strings.Contains(strings.ToLower(todo.Message), needle)
+		return found != (c.Op == OpNe)
+	case FieldStatus:
+		var matched bool
+		switch c.Value {
+		case "active":
+			matched = !todo.Complete
+		case "completed":
+			matched = todo.Complete
+		case "overdue":
+			matched = !todo.Complete && todo.Deadline.Before(now)
+		}
+		return matched != (c.Op == OpNe)
+	}
+	return false
+}
+
+// comparePriority сравнивает ранги: "больше" означает более высокий
+// приоритет, то есть меньший ранг
+func comparePriority(rank int, op Op, condRank int) bool {
+	left, right := condRank, rank
+	switch op {
+	case OpMatch, OpEq:
+		return left == right
+	case OpNe:
+		return left != right
+	case OpLt:
+		return left < right
+	case OpLe:
+		return left <= right
+	case OpGt:
+		return left > right
+	case OpGe:
+		return left >= right
+	}
+	return false
+}
+
+func matchTime(c *Cond, value time.Time) bool {
+	if !c.Day {
+		switch c.Op {
+		case OpMatch, OpEq:
+			return value.Equal(c.Time)
+		case OpNe:
+			return !value.Equal(c.Time)
+		case OpLt:
+			return value.Before(c.Time)
+		case OpLe:
+			return !value.After(c.Time)
+		case OpGt:
+			return value.After(c.Time)
+		case OpGe:
+			return !value.Before(c.Time)
+		}
+		return false
+	}
+
+	// Для даты без времени сравнение идёт с целым днём [начало; начало следующего)
+	inDay := !value.Before(c.Time) && value.Before(c.DayEnd())
+	switch c.Op {
+	case OpMatch, OpEq:
+		return inDay
+	case OpNe:
+		return !inDay
+	case OpLt:
+		return value.Before(c.Time)
+	case OpLe:
+		return value.Before(c.DayEnd())
+	case OpGt:
+		return !value.Before(c.DayEnd())
+	case OpGe:
+		return !value.Before(c.Time)
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"ToDo-List/internal/core/domain"
+)
+
+func TestMatch(t *testing.T) {
+	now := time.Date(2026, 11, 10, 12, 0, 0, 0, time.Local)
+	todo := domain.ToDo{
+		Todo:      "Send Invoice",
+		Message:   "to ACME",
+		Priority:  "medium",
+		Tags:      []string{"work", "billing"},
+		CreatedAt: time.Date(2026, 11, 1, 9, 0, 0, 0, time.Local),
+		UpdatedAt: time.Date(2026, 11, 2, 18, 0, 0, 0, time.Local),
+		Deadline:  time.Date(2026, 11, 5, 23, 59, 0, 0, time.Local),
+	}
+	done := todo
+	done.Complete = true
+	done.CompletedAt = time.Date(2026, 11, 4, 10, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		input string
+		todo  domain.ToDo
+		want  bool
+	}{
+		{input: "priority:medium", todo: todo, want: true},
+		{input: "priority!=medium", todo: todo, want: false},
+		// "больше" - более высокий приоритет
+		{input: "priority>low", todo: todo, want: true},
+		{input: "priority>=high", todo: todo, want: false},
+		{input: "priority<high", todo: todo, want: true},
+		{input: "priority<=medium", todo: todo, want: true},
+
+		// дата без времени - целый день
+		{input: "deadline:2026-11-05", todo: todo, want: true},
+		{input: "deadline!=2026-11-05", todo: todo, want: false},
+		{input: "deadline<2026-11-05", todo: todo, want: false},
+		{input: "deadline<=2026-11-05", todo: todo, want: true},
+		{input: "deadline>2026-11-04", todo: todo, want: true},
+		{input: "deadline>2026-11-05", todo: todo, want: false},
+		{input: "deadline>=2026-11-05", todo: todo, want: true},
+		{input: "created<2026-11-01T10:00", todo: todo, want: true},
+		{input: "created=2026-11-01T09:00", todo: todo, want: true},
+		{input: "updated>2026-11-02T18:00", todo: todo, want: false},
+		{input: "completed:2026-11-04", todo: done, want: true},
+		{input: "completed:2026-11-04", todo: todo, want: false},
+
+		{input: "tag:work", todo: todo, want: true},
+		{input: "tag:WORK", todo: todo, want: true},
+		{input: "tag!=work", todo: todo, want: false},
+		{input: "tag:home", todo: todo, want: false},
+
+		// text ищет подстроку в заголовке и описании без учёта регистра
+		{input: "text:invoice", todo: todo, want: true},
+		{input: "text:acme", todo: todo, want: true},
+		{input: `text:"invoice to"`, todo: todo, want: false},
+		{input: "text!=acme", todo: todo, want: false},
+
+		{input: "status:active", todo: todo, want: true},
+		{input: "status:completed", todo: done, want: true},
+		{input: "status:overdue", todo: todo, want: true},
+		{input: "status:overdue", todo: done, want: false},
+		{input: "status!=completed", todo: todo, want: true},
+
+		{input: "tag:home OR tag:work", todo: todo, want: true},
+		{input: "tag:home OR tag:work AND priority:high", todo: todo, want: false},
+		{input: "(tag:home OR tag:work) AND priority:medium", todo: todo, want: true},
+		{input: "-tag:home status:active", todo: todo, want: true},
+		{input: "NOT (tag:work OR tag:home)", todo: todo, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.input, err)
+			}
+			if got := Match(expr, tt.todo, now); got != tt.want {
+				t.Errorf("Match(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	todos := []domain.ToDo{
+		{Id: "1", Tags: []string{"work"}},
+		{Id: "2", Tags: []string{"home"}},
+		{Id: "3", Tags: []string{"work", "home"}},
+	}
+	expr, err := Parse("tag:work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := Filter(expr, todos, time.Now())
+	if len(got) != 2 || got[0].Id != "1" || got[1].Id != "3" {
+		t.Errorf("Filter(tag:work) = %+v, want todos 1 and 3", got)
+	}
+}
@@ -0,0 +1,189 @@
+package query
+
+import (
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+	tokAnd
+	tokOr
+	tokNot
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokEOF:
+		return "end of input"
+	case tokWord:
+		return "word"
+	case tokString:
+		return "quoted string"
+	case tokOp:
+		return "operator"
+	case tokLParen:
+		return "'('"
+	case tokRParen:
+		return "')'"
+	case tokAnd:
+		return "AND"
+	case tokOr:
+		return "OR"
+	case tokNot:
+		return "NOT"
+	default:
+		return "unknown token"
+	}
+}
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int // смещение в рунах от начала строки
+}
+
+// lexer разбивает строку на токены. После оператора следующий токен
+// читается как значение целиком до пробела или ')', чтобы значения
+// вроде 2026-11-01T10:00:00Z не разрывались на ':'. Значение должно идти
+// сразу за оператором: в "text: AND x" значение пустое, а не AND.
+type lexer struct {
+	src     []rune
+	pos     int
+	afterOp bool
+	tokens  []token
+}
+
+func tokenize(input string) ([]token, error) {
+	l := &lexer{src: []rune(input)}
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		l.tokens = append(l.tokens, tok)
+		if tok.kind == tokEOF {
+			return l.tokens, nil
+		}
+	}
+}
+
+func (l *lexer) next() (token, error) {
+	if l.afterOp {
+		l.afterOp = false
+		return l.value()
+	}
+
+	for l.pos < len(l.src) && unicode.IsSpace(l.src[l.pos]) {
+		l.pos++
+	}
+	if l.pos >= len(l.src) {
+		return token{kind: tokEOF, pos: l.pos}, nil
+	}
+
+	start := l.pos
+	c := l.src[l.pos]
+
+	if c == '"' {
+		return l.quoted()
+	}
+
+	switch c {
+	case '(':
+		l.pos++
+		return token{kind: tokLParen, text: "(", pos: start}, nil
+	case ')':
+		l.pos++
+		return token{kind: tokRParen, text: ")", pos: start}, nil
+	case ':', '=':
+		l.pos++
+		l.afterOp = true
+		return token{kind: tokOp, text: string(c), pos: start}, nil
+	case '<', '>', '!':
+		l.pos++
+		if l.pos < len(l.src) && l.src[l.pos] == '=' {
+			l.pos++
+		} else if c == '!' {
+			return token{}, errorf(start, "unexpected '!', did you mean '!='?")
+		}
+		l.afterOp = true
+		return token{kind: tokOp, text: string(l.src[start:l.pos]), pos: start}, nil
+	case '-':
+		// -tag:x - сокращение для NOT tag:x
+		l.pos++
+		return token{kind: tokNot, text: "-", pos: start}, nil
+	}
+
+	for l.pos < len(l.src) && isWordRune(l.src[l.pos]) {
+		l.pos++
+	}
+	if l.pos == start {
+		return token{}, errorf(start, "unexpected character %q", c)
+	}
+
+	word := string(l.src[start:l.pos])
+	switch strings.ToUpper(word) {
+	case "AND":
+		return token{kind: tokAnd, text: word, pos: start}, nil
+	case "OR":
+		return token{kind: tokOr, text: word, pos: start}, nil
+	case "NOT":
+		return token{kind: tokNot, text: word, pos: start}, nil
+	}
+	return token{kind: tokWord, text: word, pos: start}, nil
+}
+
+// value читает значение после оператора; пустое значение отвергает парсер
+func (l *lexer) value() (token, error) {
+	start := l.pos
+	if l.pos < len(l.src) && l.src[l.pos] == '"' {
+		return l.quoted()
+	}
+	for l.pos < len(l.src) && !unicode.IsSpace(l.src[l.pos]) && l.src[l.pos] != ')' {
+		l.pos++
+	}
+	return token{kind: tokWord, text: string(l.src[start:l.pos]), pos: start}, nil
+}
+
+func (l *lexer) quoted() (token, error) {
+	start := l.pos
+	l.pos++ // открывающая кавычка
+
+	var sb strings.Builder
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch c {
+		case '\\':
+			if l.pos+1 >= len(l.src) {
+				return token{}, errorf(l.pos, "unfinished escape sequence")
+			}
+			sb.WriteRune(l.src[l.pos+1])
+			l.pos += 2
+		case '"':
+			l.pos++
+			return token{kind: tokString, text: sb.String(), pos: start}, nil
+		default:
+			sb.WriteRune(c)
+			l.pos++
+		}
+	}
+	return token{}, errorf(start, "unterminated quoted string")
+}
+
+func isWordRune(c rune) bool {
+	if unicode.IsSpace(c) {
+		return false
+	}
+	switch c {
+	case '(', ')', '"', ':', '=', '<', '>', '!':
+		return false
+	}
+	return true
+}
@@ -0,0 +1,125 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []token
+	}{
+		{
+			input: "priority:high",
+			want: []token{
+				{kind: tokWord, text: "priority", pos: 0},
+				{kind: tokOp, text: ":", pos: 8},
+				{kind: tokWord, text: "high", pos: 9},
+				{kind: tokEOF, pos: 13},
+			},
+		},
+		{
+			// значение после оператора не разрывается на ':'
+			input: "deadline>=2026-11-01T10:00:00Z",
+			want: []token{
+				{kind: tokWord, text: "deadline", pos: 0},
+				{kind: tokOp, text: ">=", pos: 8},
+				{kind: tokWord, text: "2026-11-01T10:00:00Z", pos: 10},
+				{kind: tokEOF, pos: 30},
+			},
+		},
+		{
+			input: `(tag!=a OR -text:"x \"y\"") and not status=active`,
+			want: []token{
+				{kind: tokLParen, text: "(", pos: 0},
+				{kind: tokWord, text: "tag", pos: 1},
+				{kind: tokOp, text: "!=", pos: 4},
+				{kind: tokWord, text: "a", pos: 6},
+				{kind: tokOr, text: "OR", pos: 8},
+				{kind: tokNot, text: "-", pos: 11},
+				{kind: tokWord, text: "text", pos: 12},
+				{kind: tokOp, text: ":", pos: 16},
+				{kind: tokString, text: `x "y"`, pos: 17},
+				{kind: tokRParen, text: ")", pos: 26},
+				{kind: tokAnd, text: "and", pos: 28},
+				{kind: tokNot, text: "not", pos: 32},
+				{kind: tokWord, text: "status", pos: 36},
+				{kind: tokOp, text: "=", pos: 42},
+				{kind: tokWord, text: "active", pos: 43},
+				{kind: tokEOF, pos: 49},
+			},
+		},
+		{
+			// позиции считаются в рунах
+			input: "text:задача<x",
+			want: []token{
+				{kind: tokWord, text: "text", pos: 0},
+				{kind: tokOp, text: ":", pos: 4},
+				{kind: tokWord, text: "задача<x", pos: 5},
+				{kind: tokEOF, pos: 13},
+			},
+		},
+		{
+			// пробел сразу после оператора - пустое значение
+			input: "status: active",
+			want: []token{
+				{kind: tokWord, text: "status", pos: 0},
+				{kind: tokOp, text: ":", pos: 6},
+				{kind: tokWord, text: "", pos: 7},
+				{kind: tokWord, text: "active", pos: 8},
+				{kind: tokEOF, pos: 14},
+			},
+		},
+		{
+			input: "(tag:x)",
+			want: []token{
+				{kind: tokLParen, text: "(", pos: 0},
+				{kind: tokWord, text: "tag", pos: 1},
+				{kind: tokOp, text: ":", pos: 4},
+				{kind: tokWord, text: "x", pos: 5},
+				{kind: tokRParen, text: ")", pos: 6},
+				{kind: tokEOF, pos: 7},
+			},
+		},
+		{
+			input: "   ",
+			want:  []token{{kind: tokEOF, pos: 3}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := tokenize(tt.input)
+			if err != nil {
+				t.Fatalf("tokenize(%q): %v", tt.input, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokenize(%q)\n got %+v\nwant %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenizeErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		pos   int
+		msg   string
+	}{
+		{input: "tag!x", pos: 4, msg: "unexpected '!', did you mean '!='?"},
+		{input: `text:"abc`, pos: 6, msg: "unterminated quoted string"},
+		{input: `text:"abc\`, pos: 10, msg: "unfinished escape sequence"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := tokenize(tt.input)
+			qerr, ok := err.(*Error)
+			if !ok {
+				t.Fatalf("tokenize(%q) error = %v, want *Error", tt.input, err)
+			}
+			if qerr.Pos != tt.pos || qerr.Msg != tt.msg {
+				t.Errorf("tokenize(%q) error = %d %q, want %d %q", tt.input, qerr.Pos, qerr.Msg, tt.pos, tt.msg)
+			}
+		})
+	}
+}
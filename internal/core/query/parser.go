@@ -0,0 +1,224 @@
+// Package query реализует язык выражений для фильтрации задач:
+//
+//	priority:high AND deadline<2026-11-01 AND NOT tag:waiting OR text:"invoice"
+//
+// Приоритет операторов: NOT > AND > OR, скобки меняют порядок.
+// Соседние условия без оператора объединяются через AND.
+package query
+
+import (
+	"strings"
+	"time"
+)
+
+// MaxLength - ограничение на длину выражения
+const MaxLength = 1024
+
+// Parse разбирает и валидирует выражение фильтра
+func Parse(input string) (Expr, error) {
+	if len([]rune(input)) > MaxLength {
+		return nil, errorf(MaxLength, "query is longer than %d characters", MaxLength)
+	}
+
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	if p.peek().kind == tokEOF {
+		return nil, errorf(0, "empty query")
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, errorf(tok.pos, "unexpected %s %q", tok.kind, tok.text)
+	}
+	return expr, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) advance() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// or := and ("OR" and)*
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokOr {
+		op := p.advance()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &Or{Left: left, Right: right, At: op.pos}
+	}
+	return left, nil
+}
+
+// and := unary (["AND"] unary)*
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		switch tok.kind {
+		case tokAnd:
+			p.advance()
+		case tokWord, tokNot, tokLParen:
+			// неявный AND
+		default:
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &And{Left: left, Right: right, At: tok.pos}
+	}
+}
+
+// unary := "NOT" unary | "(" or ")" | cond
+func (p *parser) parseUnary() (Expr, error) {
+	tok := p.peek()
+	switch tok.kind {
+	case tokNot:
+		p.advance()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{X: x, At: tok.pos}, nil
+	case tokLParen:
+		p.advance()
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.peek(); closing.kind != tokRParen {
+			return nil, errorf(closing.pos, "expected ')' to close '(' at position %d, got %s", tok.pos+1, closing.kind)
+		}
+		p.advance()
+		return x, nil
+	case tokWord:
+		return p.parseCond()
+	case tokEOF:
+		return nil, errorf(tok.pos, "unexpected end of query, expected a condition")
+	default:
+		return nil, errorf(tok.pos, "unexpected %s, expected a condition like field:value", tok.kind)
+	}
+}
+
+// cond := field op value
+func (p *parser) parseCond() (Expr, error) {
+	field := p.advance()
+	op := p.peek()
+	if op.kind != tokOp {
+		return nil, errorf(op.pos, "expected operator after %q (one of : = != < <= > >=)", field.text)
+	}
+	p.advance()
+
+	value := p.advance()
+	if value.text == "" {
+		return nil, errorf(value.pos, "expected value after %q", field.text+op.text)
+	}
+
+	cond := &Cond{
+		Field: Field(strings.ToLower(field.text)),
+		Op:    Op(op.text),
+		Value: value.text,
+		At:    field.pos,
+	}
+	if err := validate(cond, op.pos, value.pos); err != nil {
+		return nil, err
+	}
+	return cond, nil
+}
+
+var dateLayouts = []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02"}
+
+// validate проверяет допустимость поля, оператора и значения и
+// заполняет типизированное значение условия
+func validate(c *Cond, opPos, valuePos int) error {
+	switch c.Field {
+	case FieldPriority:
+		value := strings.ToLower(c.Value)
+		if value != "low" && value != "medium" && value != "high" {
+			return errorf(valuePos, "invalid priority %q, expected low, medium or high", c.Value)
+		}
+		c.Value = value
+		c.Rank = PriorityRank(value)
+	case FieldDeadline, FieldCreated, FieldUpdated, FieldCompleted:
+		for _, layout := range dateLayouts {
+			t, err := time.ParseInLocation(layout, c.Value, time.Local)
+			if err == nil {
+				c.Time = t
+				c.Day = layout == "2006-01-02"
+				return nil
+			}
+		}
+		return errorf(valuePos, "invalid date %q, expected YYYY-MM-DD or RFC 3339", c.Value)
+	case FieldTag:
+		if !isEquality(c.Op) {
+			return errorf(opPos, "operator %q is not supported for %s", c.Op, c.Field)
+		}
+		c.Value = strings.ToLower(strings.TrimSpace(c.Value))
+		if c.Value == "" {
+			return errorf(valuePos, "empty tag")
+		}
+	case FieldText:
+		if !isEquality(c.Op) {
+			return errorf(opPos, "operator %q is not supported for %s", c.Op, c.Field)
+		}
+	case FieldStatus:
+		if !isEquality(c.Op) {
+			return errorf(opPos, "operator %q is not supported for %s", c.Op, c.Field)
+		}
+		value := strings.ToLower(c.Value)
+		if value != "active" && value != "completed" && value != "overdue" {
+			return errorf(valuePos, "invalid status %q, expected active, completed or overdue", c.Value)
+		}
+		c.Value = value
+	default:
+		return errorf(c.At, "unknown field %q, expected one of %s", c.Field, strings.Join(fieldNames(), ", "))
+	}
+	return nil
+}
+
+func isEquality(op Op) bool {
+	return op == OpMatch || op == OpEq || op == OpNe
+}
+
+func fieldNames() []string {
+	fields := []Field{FieldPriority, FieldDeadline, FieldCreated, FieldUpdated, FieldCompleted, FieldTag, FieldText, FieldStatus}
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = string(f)
+	}
+	return names
+}
+
+// DayEnd возвращает начало следующего дня для условий, заданных датой
+func (c *Cond) DayEnd() time.Time {
+	return c.Time.AddDate(0, 0, 1)
+}
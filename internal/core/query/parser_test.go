@@ -0,0 +1,117 @@
+package query
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParsePrecedence(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "tag:a", want: `tag:"a"`},
+		{input: "tag:a OR tag:b AND tag:c", want: `(tag:"a" OR (tag:"b" AND tag:"c"))`},
+		{input: "tag:a AND tag:b OR tag:c", want: `((tag:"a" AND tag:"b") OR tag:"c")`},
+		{input: "(tag:a OR tag:b) AND tag:c", want: `((tag:"a" OR tag:"b") AND tag:"c")`},
+		{input: "NOT tag:a AND tag:b", want: `(NOT tag:"a" AND tag:"b")`},
+		{input: "NOT (tag:a AND tag:b)", want: `NOT (tag:"a" AND tag:"b")`},
+		{input: "-tag:a OR not tag:b", want: `(NOT tag:"a" OR NOT tag:"b")`},
+		{input: "NOT NOT tag:a", want: `NOT NOT tag:"a"`},
+		// соседние условия объединяются через AND и связываются слева направо
+		{input: "tag:a tag:b tag:c", want: `((tag:"a" AND tag:"b") AND tag:"c")`},
+		{input: "tag:a tag:b OR tag:c", want: `((tag:"a" AND tag:"b") OR tag:"c")`},
+		{input: "tag:a OR tag:b OR tag:c", want: `((tag:"a" OR tag:"b") OR tag:"c")`},
+		// значения нормализуются при валидации
+		{input: "Priority:HIGH Tag:Work Status:Active", want: `((priority:"high" AND tag:"work") AND status:"active")`},
+		{input: `text:"two words"`, want: `text:"two words"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.input, err)
+			}
+			if got := expr.String(); got != tt.want {
+				t.Errorf("Parse(%q) = %s, want %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCondValues(t *testing.T) {
+	day := time.Date(2026, 11, 1, 0, 0, 0, 0, time.Local)
+	tests := []struct {
+		input string
+		want  Cond
+	}{
+		{input: "priority>=medium", want: Cond{Field: FieldPriority, Op: OpGe, Value: "medium", Rank: 2}},
+		{input: "deadline<2026-11-01", want: Cond{Field: FieldDeadline, Op: OpLt, Value: "2026-11-01", Time: day, Day: true}},
+		{input: "created=2026-11-01T10:30", want: Cond{Field: FieldCreated, Op: OpEq, Value: "2026-11-01T10:30", Time: day.Add(10*time.Hour + 30*time.Minute)}},
+		{input: "updated>2026-11-01T10:00:00Z", want: Cond{Field: FieldUpdated, Op: OpGt, Value: "2026-11-01T10:00:00Z", Time: time.Date(2026, 11, 1, 10, 0, 0, 0, time.UTC)}},
+		{input: `tag:" Work "`, want: Cond{Field: FieldTag, Op: OpMatch, Value: "work"}},
+		{input: "status!=overdue", want: Cond{Field: FieldStatus, Op: OpNe, Value: "overdue"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.input, err)
+			}
+			got, ok := expr.(*Cond)
+			if !ok {
+				t.Fatalf("Parse(%q) = %T, want *Cond", tt.input, expr)
+			}
+			if got.Field != tt.want.Field || got.Op != tt.want.Op || got.Value != tt.want.Value ||
+				got.Rank != tt.want.Rank || got.Day != tt.want.Day || !got.Time.Equal(tt.want.Time) {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.input, *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		pos   int    // 1-based
+		msg   string // начало сообщения
+	}{
+		{input: "", pos: 1, msg: "empty query"},
+		{input: "   ", pos: 1, msg: "empty query"},
+		{input: "status:", pos: 8, msg: `expected value after "status:"`},
+		{input: "status: active", pos: 8, msg: `expected value after "status:"`},
+		{input: "(status:)", pos: 9, msg: `expected value after "status:"`},
+		{input: "tag:a AND text:", pos: 16, msg: `expected value after "text:"`},
+		{input: "text: AND tag:x", pos: 6, msg: `expected value after "text:"`},
+		{input: `text:""`, pos: 6, msg: `expected value after "text:"`},
+		{input: `tag:"  "`, pos: 5, msg: "empty tag"},
+		{input: "priority", pos: 9, msg: `expected operator after "priority"`},
+		{input: "priority high", pos: 10, msg: `expected operator after "priority"`},
+		{input: "priority:urgent", pos: 10, msg: `invalid priority "urgent"`},
+		{input: "deadline<tomorrow", pos: 10, msg: `invalid date "tomorrow"`},
+		{input: "tag<x", pos: 4, msg: `operator "<" is not supported for tag`},
+		{input: "text>=x", pos: 5, msg: `operator ">=" is not supported for text`},
+		{input: "status:done", pos: 8, msg: `invalid status "done"`},
+		{input: "owner:me", pos: 1, msg: `unknown field "owner"`},
+		{input: "tag:a AND", pos: 10, msg: "unexpected end of query"},
+		{input: "tag:a OR OR tag:b", pos: 10, msg: "unexpected OR"},
+		{input: "(tag:a", pos: 7, msg: "expected ')' to close '(' at position 1"},
+		{input: "tag:a)", pos: 6, msg: `unexpected ')' ")"`},
+		{input: "NOT", pos: 4, msg: "unexpected end of query"},
+		{input: "tag:x !y", pos: 7, msg: "unexpected '!'"},
+		{input: strings.Repeat("a", MaxLength+1), pos: MaxLength + 1, msg: "query is longer than"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			qerr, ok := err.(*Error)
+			if !ok {
+				t.Fatalf("Parse(%q) error = %v, want *Error", tt.input, err)
+			}
+			if qerr.Pos != tt.pos || !strings.HasPrefix(qerr.Msg, tt.msg) {
+				t.Errorf("Parse(%q) error = %d %q, want %d %q...", tt.input, qerr.Pos, qerr.Msg, tt.pos, tt.msg)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SortKey - один ключ сортировки из параметра sort=priority,-deadline
+type SortKey struct {
+	Field string
+	Desc  bool
+}
+
+// SortFields - поля, по которым разрешена сортировка
+var SortFields = map[string]bool{
+	"created_at":   true,
+	"updated_at":   true,
+	"deadline":     true,
+	"priority":     true,
+	"completed_at": true,
+	"position":     true,
+}
+
+// ParseSort разбирает список ключей через запятую; "-" перед полем
+// означает сортировку по убыванию
+func ParseSort(input string) ([]SortKey, error) {
+	var keys []SortKey
+	seen := map[string]bool{}
+	for _, part := range strings.Split(input, ",") {
+		part = strings.TrimSpace(part)
+		key := SortKey{Field: part}
+		if strings.HasPrefix(part, "-") {
+			key = SortKey{Field: strings.TrimPrefix(part, "-"), Desc: true}
+		} else if strings.HasPrefix(part, "+") {
+			key.Field = strings.TrimPrefix(part, "+")
+		}
+		if !SortFields[key.Field] {
+			return nil, fmt.Errorf("invalid sort field %q", key.Field)
+		}
+		if seen[key.Field] {
+			return nil, fmt.Errorf("duplicate sort field %q", key.Field)
+		}
+		seen[key.Field] = true
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
@@ -0,0 +1,51 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []SortKey
+	}{
+		{input: "priority", want: []SortKey{{Field: "priority"}}},
+		{input: "-deadline", want: []SortKey{{Field: "deadline", Desc: true}}},
+		{input: "+created_at", want: []SortKey{{Field: "created_at"}}},
+		{
+			input: "priority, -deadline,created_at",
+			want:  []SortKey{{Field: "priority"}, {Field: "deadline", Desc: true}, {Field: "created_at"}},
+		},
+		{input: "position,-updated_at", want: []SortKey{{Field: "position"}, {Field: "updated_at", Desc: true}}},
+	}
+	for _, tt := range tests {
+		got, err := ParseSort(tt.input)
+		if err != nil {
+			t.Errorf("ParseSort(%q): %v", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseSort(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseSortErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "", want: `invalid sort field ""`},
+		{input: "priority,", want: `invalid sort field ""`},
+		{input: "title", want: `invalid sort field "title"`},
+		{input: "--deadline", want: `invalid sort field "-deadline"`},
+		{input: "priority,-priority", want: `duplicate sort field "priority"`},
+	}
+	for _, tt := range tests {
+		_, err := ParseSort(tt.input)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("ParseSort(%q) error = %v, want %s", tt.input, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// Package rank генерирует строковые ключи для ручного порядка задач
+// (дробная индексация, как lexorank): между любыми двумя ключами всегда
+// можно вставить новый, не перенумеровывая остальные записи.
+//
+// Ключи сравниваются побайтово, поэтому в БД колонка должна иметь
+// COLLATE "C".
+package rank
+
+import (
+	"fmt"
+	"strings"
+)
+
+const digits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+const base = len(digits)
+
+// Between возвращает ключ строго между before и after.
+// Пустой before означает начало списка, пустой after - конец.
+func Between(before, after string) (string, error) {
+	if err := validate(before); err != nil {
+		return "", err
+	}
+	if err := validate(after); err != nil {
+		return "", err
+	}
+	if after != "" && before >= after {
+		return "", fmt.Errorf("rank: %q is not less than %q", before, after)
+	}
+	return midpoint(before, after), nil
+}
+
+// After возвращает ключ после key (для добавления в конец списка).
+// В отличие от Between(key, "") увеличивает первую возможную цифру,
+// поэтому при последовательных добавлениях ключи растут медленно.
+func After(key string) (string, error) {
+	if err := validate(key); err != nil {
+		return "", err
+	}
+	for i := 0; i < len(key); i++ {
+		if d := strings.IndexByte(digits, key[i]); d < base-1 {
+			return key[:i] + string(digits[d+1]), nil
+		}
+	}
+	return midpoint(key, ""), nil
+}
+
+// Before возвращает ключ перед key (для добавления в начало списка)
+func Before(key string) (string, error) {
+	if err := validate(key); err != nil {
+		return "", err
+	}
+	if key != "" {
+		if d := strings.IndexByte(digits, key[0]); d > 1 {
+			return string(digits[d-1]), nil
+		}
+	}
+	return Between("", key)
+}
+
+// midpoint - a < b, пустой b означает +бесконечность.
+// Ключи никогда не заканчиваются на '0', иначе между "x" и "x0" нет места.
+func midpoint(a, b string) string {
+	if b != "" {
+		// Общий префикс переносится как есть
+		n := 0
+		for n < len(b) && digitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			return b[:n] + midpoint(tail(a, n), b[n:])
+		}
+	}
+
+	digitA := 0
+	if a != "" {
+		digitA = strings.IndexByte(digits, a[0])
+	}
+	digitB := base
+	if b != "" {
+		digitB = strings.IndexByte(digits, b[0])
+	}
+
+	if digitB-digitA > 1 {
+		return string(digits[(digitA+digitB+1)/2])
+	}
+
+	// Соседние цифры: если у b есть продолжение, подходит его первая цифра
+	if b != "" && len(b) > 1 {
+		return b[:1]
+	}
+	return string(digits[digitA]) + midpoint(tail(a, 1), "")
+}
+
+func digitAt(s string, i int) byte {
+	if i < len(s) {
+		return s[i]
+	}
+	return digits[0]
+}
+
+func tail(s string, n int) string {
+	if n >= len(s) {
+		return ""
+	}
+	return s[n:]
+}
+
+func validate(key string) error {
+	for i := 0; i < len(key); i++ {
+		if strings.IndexByte(digits, key[i]) < 0 {
+			return fmt.Errorf("rank: invalid character %q in key %q", key[i], key)
+		}
+	}
+	if strings.HasSuffix(key, digits[:1]) {
+		return fmt.Errorf("rank: key %q must not end with %q", key, digits[:1])
+	}
+	return nil
+}
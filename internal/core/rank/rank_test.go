@@ -0,0 +1,135 @@
+package rank
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		before, after string
+		want          string
+	}{
+		{before: "", after: "", want: "V"},
+		{before: "", after: "1", want: "0V"},
+		{before: "1", after: "", want: "W"},
+		{before: "1", after: "2", want: "1V"},
+		{before: "a", after: "b", want: "aV"},
+		{before: "a", after: "a1", want: "a0V"},
+		{before: "az", after: "b", want: "azV"},
+		{before: "z", after: "", want: "zV"},
+		{before: "V0000000011", after: "V0000000021", want: "V000000002"},
+	}
+	for _, tt := range tests {
+		got, err := Between(tt.before, tt.after)
+		if err != nil {
+			t.Errorf("Between(%q, %q): %v", tt.before, tt.after, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Between(%q, %q) = %q, want %q", tt.before, tt.after, got, tt.want)
+		}
+	}
+}
+
+func TestBetweenErrors(t *testing.T) {
+	tests := []struct {
+		before, after string
+	}{
+		{before: "b", after: "a"},
+		{before: "a", after: "a"},
+		{before: "a0", after: ""},
+		{before: "", after: "a-b"},
+		{before: "é", after: ""},
+	}
+	for _, tt := range tests {
+		if got, err := Between(tt.before, tt.after); err == nil {
+			t.Errorf("Between(%q, %q) = %q, want error", tt.before, tt.after, got)
+		}
+	}
+}
+
+func TestAfterBefore(t *testing.T) {
+	tests := []struct {
+		key    string
+		after  string
+		before string
+	}{
+		{key: "", after: "V", before: "V"},
+		{key: "a", after: "b", before: "Z"},
+		{key: "z", after: "zV", before: "y"},
+		{key: "zz", after: "zzV", before: "y"},
+		{key: "1", after: "2", before: "0V"},
+		{key: "V0000000011", after: "W", before: "U"},
+	}
+	for _, tt := range tests {
+		if got, err := After(tt.key); err != nil || got != tt.after {
+			t.Errorf("After(%q) = %q, %v, want %q", tt.key, got, err, tt.after)
+		}
+		if got, err := Before(tt.key); err != nil || got != tt.before {
+			t.Errorf("Before(%q) = %q, %v, want %q", tt.key, got, err, tt.before)
+		}
+	}
+}
+
+// Вставки в случайные места списка всегда дают ключ строго между соседями,
+// допустимый для следующих вставок
+func TestRandomInsertsKeepOrder(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	keys := []string{}
+	for i := 0; i < 2000; i++ {
+		at := rng.Intn(len(keys) + 1)
+		var before, after string
+		if at > 0 {
+			before = keys[at-1]
+		}
+		if at < len(keys) {
+			after = keys[at]
+		}
+
+		var key string
+		var err error
+		switch {
+		case after == "" && before != "" && rng.Intn(2) == 0:
+			key, err = After(before)
+		case before == "" && after != "" && rng.Intn(2) == 0:
+			key, err = Before(after)
+		default:
+			key, err = Between(before, after)
+		}
+		if err != nil {
+			t.Fatalf("insert between %q and %q: %v", before, after, err)
+		}
+		if key <= before || (after != "" && key >= after) {
+			t.Fatalf("key %q is not between %q and %q", key, before, after)
+		}
+		if strings.HasSuffix(key, "0") {
+			t.Fatalf("key %q ends with 0", key)
+		}
+		keys = append(keys[:at], append([]string{key}, keys[at:]...)...)
+	}
+	if !sort.StringsAreSorted(keys) {
+		t.Error("keys are not sorted")
+	}
+}
+
+// При последовательном добавлении в конец ключ удлиняется на символ не
+// чаще, чем раз в 30 добавлений
+func TestAfterGrowsSlowly(t *testing.T) {
+	key := ""
+	for i := 0; i < 1000; i++ {
+		next, err := After(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if next <= key {
+			t.Fatalf("After(%q) = %q is not greater", key, next)
+		}
+		key = next
+	}
+	if len(key) > 1000/30+1 {
+		t.Errorf("key after 1000 appends is %d characters long: %q", len(key), key)
+	}
+}
@@ -0,0 +1,176 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/identity"
+	"ToDo-List/internal/core/ports"
+
+	"github.com/lib/pq"
+)
+
+const apiTokenColumns = `id, user_id, workspace_id, name, prefix, token_hash, scopes, expires_at, last_used_at, created_at`
+
+type PostgreAPITokenRepo struct {
+	db     *sql.DB
+	logger *logger.Logger
+}
+
+func NewPostgreAPITokenRepo(db *sql.DB, logger *logger.Logger) ports.APITokenRepo {
+	return &PostgreAPITokenRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func scanAPIToken(row rowScanner) (domain.APIToken, error) {
+	var token domain.APIToken
+	var expiresAt, lastUsedAt sql.NullTime
+	err := row.Scan(
+		&token.Id,
+		&token.UserId,
+		&token.WorkspaceId,
+		&token.Name,
+		&token.Prefix,
+		&token.TokenHash,
+		pq.Array(&token.Scopes),
+		&expiresAt,
+		&lastUsedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		return domain.APIToken{}, err
+	}
+	token.ExpiresAt = expiresAt.Time
+	token.LastUsedAt = lastUsedAt.Time
+	return token, nil
+}
+
+// nullTime сохраняет нулевое время как NULL
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+func (r *PostgreAPITokenRepo) CreateAPIToken(ctx context.Context, token domain.APIToken) error {
+	r.log(ctx).Debug("Executing CreateAPIToken: user=%s, name=%s", token.UserId, token.Name)
+
+	query := `
+		INSERT INTO api_tokens (id, user_id, workspace_id, name, prefix, token_hash, scopes, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query,
+		token.Id,
+		token.UserId,
+		token.WorkspaceId,
+		token.Name,
+		token.Prefix,
+		token.TokenHash,
+		pq.Array(token.Scopes),
+		nullTime(token.ExpiresAt),
+		token.CreatedAt,
+	)
+	if err != nil {
+		r.log(ctx).Error("Insert failed: %v", err)
+		return err
+	}
+
+	r.log(ctx).Info("API token created successfully: %s", token.Id)
+	return nil
+}
+
+func (r *PostgreAPITokenRepo) GetAPITokensByUser(ctx context.Context, userId string) ([]domain.APIToken, error) {
+	r.log(ctx).Debug("Executing GetAPITokensByUser: user=%s", userId)
+
+	workspaceId, err := identity.WorkspaceId(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + apiTokenColumns + ` FROM api_tokens WHERE user_id = $1 AND workspace_id = $2 ORDER BY created_at DESC`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, userId, workspaceId)
+	if err != nil {
+		r.log(ctx).Error("Query failed: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := []domain.APIToken{}
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			r.log(ctx).Error("Row scan failed: %v", err)
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	if err := rows.Err(); err != nil {
+		r.log(ctx).Error("Rows error: %v", err)
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (r *PostgreAPITokenRepo) GetAPITokenByHash(ctx context.Context, hash string) (domain.APIToken, error) {
+	query := `SELECT ` + apiTokenColumns + ` FROM api_tokens WHERE token_hash = $1`
+
+	token, err := scanAPIToken(conn(ctx, r.db).QueryRowContext(ctx, query, hash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.APIToken{}, ports.ErrUnauthenticated
+		}
+		r.log(ctx).Error("Scan failed: %v", err)
+		return domain.APIToken{}, err
+	}
+	return token, nil
+}
+
+func (r *PostgreAPITokenRepo) DeleteAPIToken(ctx context.Context, userId, id string) error {
+	r.log(ctx).Debug("Executing DeleteAPIToken: id=%s", id)
+
+	workspaceId, err := identity.WorkspaceId(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `DELETE FROM api_tokens WHERE id = $1 AND user_id = $2 AND workspace_id = $3`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id, userId, workspaceId)
+	if err != nil {
+		r.log(ctx).Error("Delete failed: %v", err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.log(ctx).Error("RowsAffected failed: %v", err)
+		return err
+	}
+	if rowsAffected == 0 {
+		r.log(ctx).Warn("API token not found for deletion: %s", id)
+		return fmt.Errorf("api token with id %s not found", id)
+	}
+
+	r.log(ctx).Info("API token revoked: %s", id)
+	return nil
+}
+
+func (r *PostgreAPITokenRepo) TouchAPIToken(ctx context.Context, id string, usedAt time.Time) error {
+	query := `UPDATE api_tokens SET last_used_at = $1 WHERE id = $2`
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, usedAt, id); err != nil {
+		r.log(ctx).Error("Update last_used_at failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+// log - логгер запроса с его X-Request-ID
+func (r *PostgreAPITokenRepo) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, r.logger)
+}
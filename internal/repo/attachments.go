@@ -0,0 +1,219 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/identity"
+	"ToDo-List/internal/core/ports"
+)
+
+const attachmentColumns = `id, todo_id, uploader_id, filename, content_type, size, content_hash, created_at`
+
+type PostgreAttachmentRepo struct {
+	db     *sql.DB
+	logger *logger.Logger
+}
+
+func NewPostgreAttachmentRepo(db *sql.DB, logger *logger.Logger) ports.AttachmentRepo {
+	return &PostgreAttachmentRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func scanAttachment(row rowScanner) (domain.Attachment, error) {
+	var attachment domain.Attachment
+	err := row.Scan(
+		&attachment.Id,
+		&attachment.TodoId,
+		&attachment.UploaderId,
+		&attachment.Filename,
+		&attachment.ContentType,
+		&attachment.Size,
+		&attachment.ContentHash,
+		&attachment.CreatedAt,
+	)
+	return attachment, err
+}
+
+func (r *PostgreAttachmentRepo) CreateAttachment(ctx context.Context, attachment domain.Attachment) error {
+	r.log(ctx).Debug("Executing CreateAttachment: todo=%s, hash=%s", attachment.TodoId, attachment.ContentHash)
+
+	workspaceId, err := identity.WorkspaceId(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Строка blobs блокируется до конца транзакции, поэтому сборщик мусора
+	// не удалит содержимое, пока вложение не сохранено. Таблица blobs общая
+	// для всех пространств: одинаковое содержимое хранится один раз.
+	_, err = conn(ctx, r.db).ExecContext(ctx, `
+		INSERT INTO blobs (hash, size, content_type, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (hash) DO UPDATE SET hash = EXCLUDED.hash
+	`, attachment.ContentHash, attachment.Size, attachment.ContentType, attachment.CreatedAt)
+	if err != nil {
+		r.log(ctx).Error("Insert blob failed: %v", err)
+		return err
+	}
+
+	query := `
+		INSERT INTO attachments (` + attachmentColumns + `, workspace_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
+		attachment.Id,
+		attachment.TodoId,
+		attachment.UploaderId,
+		attachment.Filename,
+		attachment.ContentType,
+		attachment.Size,
+		attachment.ContentHash,
+		attachment.CreatedAt,
+		workspaceId,
+	)
+	if err != nil {
+		r.log(ctx).Error("Insert attachment failed: %v", err)
+		return err
+	}
+
+	r.log(ctx).Info("Attachment created successfully: %s", attachment.Id)
+	return nil
+}
+
+func (r *PostgreAttachmentRepo) GetAttachmentsByTodo(ctx context.Context, todoId string) ([]domain.Attachment, error) {
+	r.log(ctx).Debug("Executing GetAttachmentsByTodo: todo=%s", todoId)
+
+	workspaceId, err := identity.WorkspaceId(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + attachmentColumns + ` FROM attachments WHERE todo_id = $1 AND workspace_id = $2 ORDER BY created_at, id`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, todoId, workspaceId)
+	if err != nil {
+		r.log(ctx).Error("Query failed: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	attachments := []domain.Attachment{}
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			r.log(ctx).Error("Row scan failed: %v", err)
+			return nil, err
+		}
+		attachments = append(attachments, attachment)
+	}
+	return attachments, rows.Err()
+}
+
+func (r *PostgreAttachmentRepo) GetAttachmentById(ctx context.Context, todoId, id string) (domain.Attachment, error) {
+	workspaceId, err := identity.WorkspaceId(ctx)
+	if err != nil {
+		return domain.Attachment{}, err
+	}
+
+	query := `SELECT ` + attachmentColumns + ` FROM attachments WHERE id = $1 AND todo_id = $2 AND workspace_id = $3`
+
+	attachment, err := scanAttachment(conn(ctx, r.db).QueryRowContext(ctx, query, id, todoId, workspaceId))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.Attachment{}, fmt.Errorf("%w: %s", ports.ErrAttachmentNotFound, id)
+		}
+		r.log(ctx).Error("Scan failed: %v", err)
+		return domain.Attachment{}, err
+	}
+	return attachment, nil
+}
+
+func (r *PostgreAttachmentRepo) DeleteAttachment(ctx context.Context, id string) error {
+	r.log(ctx).Debug("Executing DeleteAttachment: id=%s", id)
+
+	workspaceId, err := identity.WorkspaceId(ctx)
+	if err != nil {
+		return err
+	}
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM attachments WHERE id = $1 AND workspace_id = $2`, id, workspaceId)
+	if err != nil {
+		r.log(ctx).Error("Delete failed: %v", err)
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s", ports.ErrAttachmentNotFound, id)
+	}
+	return nil
+}
+
+// GetOrphanBlobs и DeleteOrphanBlob проверяют ссылки из всех рабочих
+// пространств, поэтому выполняются без ограничений RLS: иначе содержимое,
+// общее для двух пространств, выглядело бы неиспользуемым
+func (r *PostgreAttachmentRepo) GetOrphanBlobs(ctx context.Context, limit int) ([]string, error) {
+	query := `
+		SELECT b.hash FROM blobs b
+		WHERE NOT EXISTS (SELECT 1 FROM attachments a WHERE a.content_hash = b.hash)
+		ORDER BY b.created_at
+		LIMIT $1
+	`
+
+	hashes := []string{}
+	err := unscoped(ctx, r.db, func(q dbtx) error {
+		rows, err := q.QueryContext(ctx, query, limit)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var hash string
+			if err := rows.Scan(&hash); err != nil {
+				return err
+			}
+			hashes = append(hashes, hash)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		r.log(ctx).Error("Query orphan blobs failed: %v", err)
+		return nil, err
+	}
+	return hashes, nil
+}
+
+func (r *PostgreAttachmentRepo) DeleteOrphanBlob(ctx context.Context, hash string) (bool, error) {
+	query := `
+		DELETE FROM blobs b
+		WHERE b.hash = $1 AND NOT EXISTS (SELECT 1 FROM attachments a WHERE a.content_hash = b.hash)
+	`
+
+	var rowsAffected int64
+	err := unscoped(ctx, r.db, func(q dbtx) error {
+		result, err := q.ExecContext(ctx, query, hash)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err = result.RowsAffected()
+		return err
+	})
+	if err != nil {
+		r.log(ctx).Error("Delete blob failed: %v", err)
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+// log - логгер запроса с его X-Request-ID
+func (r *PostgreAttachmentRepo) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, r.logger)
+}
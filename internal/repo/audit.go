@@ -0,0 +1,128 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/ports"
+)
+
+// defaultAuditLimit - размер страницы журнала, если лимит не задан
+const defaultAuditLimit = 100
+
+// PostgreAuditRepo - журнал аудита. Он общий для экземпляра и не делится по
+// рабочим пространствам.
+type PostgreAuditRepo struct {
+	db     *sql.DB
+	logger *logger.Logger
+}
+
+func NewPostgreAuditRepo(db *sql.DB, logger *logger.Logger) ports.AuditRepo {
+	return &PostgreAuditRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *PostgreAuditRepo) CreateAuditEntry(ctx context.Context, entry domain.AuditEntry) error {
+	r.log(ctx).Debug("Executing CreateAuditEntry: action=%s, actor=%s", entry.Action, entry.ActorId)
+
+	details, err := json.Marshal(entry.Details)
+	if err != nil {
+		return err
+	}
+	if entry.Details == nil {
+		details = []byte("{}")
+	}
+
+	query := `
+		INSERT INTO audit_log (id, actor_id, impersonator_id, action, target_type, target_id, details, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
+		entry.Id,
+		entry.ActorId,
+		nullIfEmpty(entry.ImpersonatorId),
+		entry.Action,
+		entry.TargetType,
+		entry.TargetId,
+		details,
+		entry.CreatedAt,
+	)
+	if err != nil {
+		r.log(ctx).Error("Insert failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *PostgreAuditRepo) GetAuditEntries(ctx context.Context, filter ports.AuditFilter) ([]domain.AuditEntry, error) {
+	r.log(ctx).Debug("Executing GetAuditEntries: actor=%s, target=%s, action=%s", filter.ActorId, filter.TargetId, filter.Action)
+
+	query := `SELECT id, actor_id, COALESCE(impersonator_id, ''), action, target_type, target_id, details, created_at
+	          FROM audit_log WHERE 1=1`
+	var args []interface{}
+	if filter.ActorId != "" {
+		args = append(args, filter.ActorId)
+		query += fmt.Sprintf(" AND (actor_id = $%d OR impersonator_id = $%d)", len(args), len(args))
+	}
+	if filter.TargetId != "" {
+		args = append(args, filter.TargetId)
+		query += fmt.Sprintf(" AND target_id = $%d", len(args))
+	}
+	if filter.Action != "" {
+		args = append(args, filter.Action)
+		query += fmt.Sprintf(" AND action = $%d", len(args))
+	}
+	if !filter.Before.IsZero() {
+		args = append(args, filter.Before)
+		query += fmt.Sprintf(" AND created_at < $%d", len(args))
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultAuditLimit
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf(" ORDER BY created_at DESC, id LIMIT $%d", len(args))
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		r.log(ctx).Error("Query failed: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []domain.AuditEntry{}
+	for rows.Next() {
+		var entry domain.AuditEntry
+		var details []byte
+		err := rows.Scan(
+			&entry.Id,
+			&entry.ActorId,
+			&entry.ImpersonatorId,
+			&entry.Action,
+			&entry.TargetType,
+			&entry.TargetId,
+			&details,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			r.log(ctx).Error("Scan failed: %v", err)
+			return nil, err
+		}
+		if err := json.Unmarshal(details, &entry.Details); err != nil {
+			r.log(ctx).Warn("Invalid details in audit entry %s: %v", entry.Id, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// log - логгер запроса с его X-Request-ID
+func (r *PostgreAuditRepo) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, r.logger)
+}
@@ -0,0 +1,46 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/ports"
+
+	"github.com/lib/pq"
+)
+
+type PostgreBulkRepo struct {
+	db     *sql.DB
+	logger *logger.Logger
+}
+
+func NewPostgreBulkRepo(db *sql.DB, logger *logger.Logger) ports.BulkRepo {
+	return &PostgreBulkRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *PostgreBulkRepo) CountExistingTodos(ctx context.Context, ids []string) (int, error) {
+	r.log(ctx).Debug("Executing CountExistingTodos: %d ids", len(ids))
+
+	ownerId, workspaceId, err := tenant(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	query := `SELECT COUNT(*) FROM todo WHERE id = ANY($1) AND owner_id = $2 AND workspace_id = $3`
+
+	var count int
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, pq.Array(ids), ownerId, workspaceId).Scan(&count); err != nil {
+		r.log(ctx).Error("Count query failed: %v", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+// log - логгер запроса с его X-Request-ID
+func (r *PostgreBulkRepo) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, r.logger)
+}
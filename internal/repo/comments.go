@@ -0,0 +1,255 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/identity"
+	"ToDo-List/internal/core/ports"
+
+	"github.com/lib/pq"
+)
+
+const commentColumns = `c.id, c.todo_id, c.author_id, u.username, c.body, c.created_at, c.updated_at,
+	EXISTS (SELECT 1 FROM comment_revisions r WHERE r.comment_id = c.id),
+	ARRAY(SELECT m.user_id FROM comment_mentions m JOIN users mu ON mu.id = m.user_id
+	      WHERE m.comment_id = c.id ORDER BY mu.username),
+	ARRAY(SELECT mu.username FROM comment_mentions m JOIN users mu ON mu.id = m.user_id
+	      WHERE m.comment_id = c.id ORDER BY mu.username)`
+
+type PostgreCommentRepo struct {
+	db     *sql.DB
+	logger *logger.Logger
+}
+
+func NewPostgreCommentRepo(db *sql.DB, logger *logger.Logger) ports.CommentRepo {
+	return &PostgreCommentRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func scanComment(row rowScanner) (domain.Comment, error) {
+	var comment domain.Comment
+	var mentionIds, mentionNames []string
+	err := row.Scan(
+		&comment.Id,
+		&comment.TodoId,
+		&comment.AuthorId,
+		&comment.AuthorName,
+		&comment.Body,
+		&comment.CreatedAt,
+		&comment.UpdatedAt,
+		&comment.Edited,
+		pq.Array(&mentionIds),
+		pq.Array(&mentionNames),
+	)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+
+	comment.Mentions = make([]domain.Mention, len(mentionIds))
+	for i := range mentionIds {
+		comment.Mentions[i] = domain.Mention{UserId: mentionIds[i], Username: mentionNames[i]}
+	}
+	return comment, nil
+}
+
+func (r *PostgreCommentRepo) CreateComment(ctx context.Context, comment domain.Comment) error {
+	r.log(ctx).Debug("Executing CreateComment: todo=%s, author=%s", comment.TodoId, comment.AuthorId)
+
+	workspaceId, err := identity.WorkspaceId(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO comments (id, todo_id, author_id, body, created_at, updated_at, workspace_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
+		comment.Id,
+		comment.TodoId,
+		comment.AuthorId,
+		comment.Body,
+		comment.CreatedAt,
+		comment.UpdatedAt,
+		workspaceId,
+	)
+	if err != nil {
+		r.log(ctx).Error("Insert failed: %v", err)
+		return err
+	}
+
+	if err := r.setMentions(ctx, comment, workspaceId); err != nil {
+		return err
+	}
+
+	r.log(ctx).Info("Comment created successfully: %s", comment.Id)
+	return nil
+}
+
+func (r *PostgreCommentRepo) GetCommentsByTodo(ctx context.Context, todoId string) ([]domain.Comment, error) {
+	r.log(ctx).Debug("Executing GetCommentsByTodo: todo=%s", todoId)
+
+	workspaceId, err := identity.WorkspaceId(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + commentColumns + ` FROM comments c JOIN users u ON u.id = c.author_id
+	          WHERE c.todo_id = $1 AND c.workspace_id = $2 ORDER BY c.created_at, c.id`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, todoId, workspaceId)
+	if err != nil {
+		r.log(ctx).Error("Query failed: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := []domain.Comment{}
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			r.log(ctx).Error("Row scan failed: %v", err)
+			return nil, err
+		}
+		comments = append(comments, comment)
+	}
+	return comments, rows.Err()
+}
+
+func (r *PostgreCommentRepo) GetCommentById(ctx context.Context, todoId, id string) (domain.Comment, error) {
+	workspaceId, err := identity.WorkspaceId(ctx)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+
+	query := `SELECT ` + commentColumns + ` FROM comments c JOIN users u ON u.id = c.author_id
+	          WHERE c.id = $1 AND c.todo_id = $2 AND c.workspace_id = $3`
+
+	comment, err := scanComment(conn(ctx, r.db).QueryRowContext(ctx, query, id, todoId, workspaceId))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.Comment{}, fmt.Errorf("%w: %s", ports.ErrCommentNotFound, id)
+		}
+		r.log(ctx).Error("Scan failed: %v", err)
+		return domain.Comment{}, err
+	}
+	return comment, nil
+}
+
+func (r *PostgreCommentRepo) UpdateComment(ctx context.Context, comment domain.Comment, revision domain.CommentRevision) error {
+	r.log(ctx).Debug("Executing UpdateComment: id=%s", comment.Id)
+
+	workspaceId, err := identity.WorkspaceId(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn(ctx, r.db).ExecContext(ctx, `
+		INSERT INTO comment_revisions (id, comment_id, body, editor_id, edited_at, workspace_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, revision.Id, revision.CommentId, revision.Body, revision.EditorId, revision.EditedAt, workspaceId)
+	if err != nil {
+		r.log(ctx).Error("Insert revision failed: %v", err)
+		return err
+	}
+
+	result, err := conn(ctx, r.db).ExecContext(ctx,
+		`UPDATE comments SET body = $1, updated_at = $2 WHERE id = $3 AND workspace_id = $4`,
+		comment.Body, comment.UpdatedAt, comment.Id, workspaceId)
+	if err != nil {
+		r.log(ctx).Error("Update failed: %v", err)
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s", ports.ErrCommentNotFound, comment.Id)
+	}
+
+	return r.setMentions(ctx, comment, workspaceId)
+}
+
+// setMentions заменяет упоминания комментария
+func (r *PostgreCommentRepo) setMentions(ctx context.Context, comment domain.Comment, workspaceId string) error {
+	if _, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM comment_mentions WHERE comment_id = $1 AND workspace_id = $2`, comment.Id, workspaceId); err != nil {
+		r.log(ctx).Error("Delete mentions failed: %v", err)
+		return err
+	}
+	for _, mention := range comment.Mentions {
+		_, err := conn(ctx, r.db).ExecContext(ctx,
+			`INSERT INTO comment_mentions (comment_id, user_id, workspace_id) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`,
+			comment.Id, mention.UserId, workspaceId)
+		if err != nil {
+			r.log(ctx).Error("Insert mention failed: %v", err)
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *PostgreCommentRepo) DeleteComment(ctx context.Context, id string) error {
+	r.log(ctx).Debug("Executing DeleteComment: id=%s", id)
+
+	workspaceId, err := identity.WorkspaceId(ctx)
+	if err != nil {
+		return err
+	}
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM comments WHERE id = $1 AND workspace_id = $2`, id, workspaceId)
+	if err != nil {
+		r.log(ctx).Error("Delete failed: %v", err)
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s", ports.ErrCommentNotFound, id)
+	}
+	return nil
+}
+
+func (r *PostgreCommentRepo) GetCommentRevisions(ctx context.Context, commentId string) ([]domain.CommentRevision, error) {
+	r.log(ctx).Debug("Executing GetCommentRevisions: comment=%s", commentId)
+
+	workspaceId, err := identity.WorkspaceId(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT id, comment_id, body, editor_id, edited_at FROM comment_revisions
+	          WHERE comment_id = $1 AND workspace_id = $2 ORDER BY edited_at, id`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, commentId, workspaceId)
+	if err != nil {
+		r.log(ctx).Error("Query failed: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []domain.CommentRevision{}
+	for rows.Next() {
+		var rev domain.CommentRevision
+		if err := rows.Scan(&rev.Id, &rev.CommentId, &rev.Body, &rev.EditorId, &rev.EditedAt); err != nil {
+			r.log(ctx).Error("Row scan failed: %v", err)
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+	return revisions, rows.Err()
+}
+
+// log - логгер запроса с его X-Request-ID
+func (r *PostgreCommentRepo) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, r.logger)
+}
@@ -0,0 +1,147 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/ports"
+)
+
+// PostgreIdempotencyRepo хранит ответы на запросы с Idempotency-Key.
+// Ключи принадлежат пользователю, рабочее пространство входит в отпечаток.
+type PostgreIdempotencyRepo struct {
+	db     *sql.DB
+	logger *logger.Logger
+}
+
+func NewPostgreIdempotencyRepo(db *sql.DB, logger *logger.Logger) ports.IdempotencyRepo {
+	return &PostgreIdempotencyRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *PostgreIdempotencyRepo) ReserveIdempotencyKey(ctx context.Context, record domain.IdempotencyRecord) (domain.IdempotencyRecord, bool, error) {
+	r.log(ctx).Debug("Executing ReserveIdempotencyKey: user=%s", record.UserId)
+
+	// Истёкшая запись и запись без ответа с истёкшей блокировкой перезаписываются
+	query := `
+		INSERT INTO idempotency_keys (user_id, key, fingerprint, status_code, headers, body, created_at, expires_at, locked_until)
+		VALUES ($1, $2, $3, 0, '{}', NULL, $4, $5, $6)
+		ON CONFLICT (user_id, key) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint, status_code = 0, headers = '{}', body = NULL,
+		    created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at, locked_until = EXCLUDED.locked_until
+		WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+		   OR (idempotency_keys.status_code = 0
+		       AND COALESCE(idempotency_keys.locked_until, idempotency_keys.created_at) <= EXCLUDED.created_at)
+		RETURNING user_id
+	`
+	var userId string
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		record.UserId,
+		record.Key,
+		record.Fingerprint,
+		record.CreatedAt,
+		record.ExpiresAt,
+		record.LockedUntil,
+	).Scan(&userId)
+	if err == nil {
+		return record, true, nil
+	}
+	if err != sql.ErrNoRows {
+		r.log(ctx).Error("Insert failed: %v", err)
+		return domain.IdempotencyRecord{}, false, err
+	}
+
+	query = `
+		SELECT key, user_id, fingerprint, status_code, headers, COALESCE(body, ''::bytea), created_at, expires_at, locked_until
+		FROM idempotency_keys WHERE user_id = $1 AND key = $2
+	`
+	var existing domain.IdempotencyRecord
+	var headers []byte
+	var lockedUntil sql.NullTime
+	err = conn(ctx, r.db).QueryRowContext(ctx, query, record.UserId, record.Key).Scan(
+		&existing.Key,
+		&existing.UserId,
+		&existing.Fingerprint,
+		&existing.StatusCode,
+		&headers,
+		&existing.Body,
+		&existing.CreatedAt,
+		&existing.ExpiresAt,
+		&lockedUntil,
+	)
+	if err != nil {
+		r.log(ctx).Error("Scan failed: %v", err)
+		return domain.IdempotencyRecord{}, false, err
+	}
+	if err := json.Unmarshal(headers, &existing.Headers); err != nil {
+		r.log(ctx).Error("Failed to decode stored headers: %v", err)
+		return domain.IdempotencyRecord{}, false, err
+	}
+	existing.LockedUntil = lockedUntil.Time
+	return existing, false, nil
+}
+
+func (r *PostgreIdempotencyRepo) SaveIdempotentResponse(ctx context.Context, record domain.IdempotencyRecord) error {
+	r.log(ctx).Debug("Executing SaveIdempotentResponse: user=%s, status=%d", record.UserId, record.StatusCode)
+
+	headers, err := json.Marshal(record.Headers)
+	if err != nil {
+		return err
+	}
+	// Блокировка снимается, ответ сохраняется только своей резервации
+	query := `
+		UPDATE idempotency_keys SET status_code = $4, headers = $5, body = $6, locked_until = NULL
+		WHERE user_id = $1 AND key = $2 AND status_code = 0 AND locked_until = $3
+	`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query,
+		record.UserId,
+		record.Key,
+		record.LockedUntil,
+		record.StatusCode,
+		headers,
+		record.Body,
+	)
+	if err != nil {
+		r.log(ctx).Error("Update failed: %v", err)
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ports.ErrIdempotencyLeaseLost
+	}
+	return nil
+}
+
+func (r *PostgreIdempotencyRepo) DeleteIdempotencyKey(ctx context.Context, record domain.IdempotencyRecord) error {
+	r.log(ctx).Debug("Executing DeleteIdempotencyKey: user=%s", record.UserId)
+
+	query := `DELETE FROM idempotency_keys WHERE user_id = $1 AND key = $2 AND status_code = 0 AND locked_until = $3`
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, record.UserId, record.Key, record.LockedUntil); err != nil {
+		r.log(ctx).Error("Delete failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *PostgreIdempotencyRepo) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error) {
+	query := `DELETE FROM idempotency_keys WHERE expires_at <= $1`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, now)
+	if err != nil {
+		r.log(ctx).Error("Delete expired idempotency keys failed: %v", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// log - логгер запроса с его X-Request-ID
+func (r *PostgreIdempotencyRepo) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, r.logger)
+}
@@ -0,0 +1,199 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// QueryObserver получает длительность и результат каждого запроса к БД.
+// repo и method - тип и метод репозитория, из которого выполнен запрос,
+// например PostgreRepo и CreateTodo.
+type QueryObserver interface {
+	ObserveQuery(repo, method string, duration time.Duration, err error)
+}
+
+// queryObserver задаётся один раз при старте, до первых запросов
+var queryObserver QueryObserver
+
+// ObserveQueries включает наблюдение за запросами всех репозиториев пакета
+func ObserveQueries(o QueryObserver) {
+	queryObserver = o
+}
+
+// tracer - span запросов к БД; без настроенной трассировки ничего не записывает
+var tracer = otel.Tracer("ToDo-List/internal/repo")
+
+// observed оборачивает соединение: каждый запрос получает span и, если
+// наблюдение включено, попадает в метрики
+func observed(c dbtx) dbtx {
+	return observedConn{next: c, observer: queryObserver}
+}
+
+// observedConn замеряет запросы; метод репозитория определяется по адресу вызова
+type observedConn struct {
+	next     dbtx
+	observer QueryObserver
+}
+
+func (c observedConn) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	q := c.start(ctx, callerPC(), query)
+	result, err := c.next.ExecContext(q.ctx, query, args...)
+	q.finish(err)
+	return result, err
+}
+
+func (c observedConn) QueryContext(ctx context.Context, query string, args ...interface{}) (rowsIter, error) {
+	q := c.start(ctx, callerPC(), query)
+	rows, err := c.next.QueryContext(q.ctx, query, args...)
+	q.finish(err)
+	return rows, err
+}
+
+// QueryRowContext - ошибка запроса становится известна только в Scan,
+// поэтому замер и span завершаются там
+func (c observedConn) QueryRowContext(ctx context.Context, query string, args ...interface{}) rowScanner {
+	q := c.start(ctx, callerPC(), query)
+	return observedRow{row: c.next.QueryRowContext(q.ctx, query, args...), query: q}
+}
+
+// observedQuery - начатый запрос: span и время начала
+type observedQuery struct {
+	ctx          context.Context
+	span         trace.Span
+	observer     QueryObserver
+	repo, method string
+	start        time.Time
+}
+
+// start открывает span запроса с именем метода репозитория: PostgreRepo.CreateTodo
+func (c observedConn) start(ctx context.Context, pc uintptr, query string) observedQuery {
+	repo, method := callerMethod(pc)
+	name := method
+	if repo != "" {
+		name = repo + "." + method
+	}
+	ctx, span := tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+	if span.IsRecording() {
+		span.SetAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationName(sqlOperation(query)),
+			semconv.DBQueryText(sanitizeSQL(query)),
+		)
+	}
+	return observedQuery{ctx: ctx, span: span, observer: c.observer, repo: repo, method: method, start: time.Now()}
+}
+
+func (q observedQuery) finish(err error) {
+	// Отсутствие строки - обычный результат, а не сбой БД
+	if errors.Is(err, sql.ErrNoRows) {
+		err = nil
+	}
+	if q.observer != nil {
+		q.observer.ObserveQuery(q.repo, q.method, time.Since(q.start), err)
+	}
+	if err != nil {
+		q.span.RecordError(err)
+		q.span.SetStatus(codes.Error, err.Error())
+	}
+	q.span.End()
+}
+
+type observedRow struct {
+	row   rowScanner
+	query observedQuery
+}
+
+func (r observedRow) Scan(dest ...interface{}) error {
+	err := r.row.Scan(dest...)
+	r.query.finish(err)
+	return err
+}
+
+var (
+	// sqlLiteral - строковые и числовые литералы; параметры $1 не трогаются
+	sqlLiteral = regexp.MustCompile(`'(?:[^']|'')*'|\$\d+|\b\d+(?:\.\d+)?\b`)
+	sqlSpace   = regexp.MustCompile(`\s+`)
+)
+
+// sanitizeSQL заменяет литералы на ? и схлопывает пробелы: значения
+// передаются параметрами, но в текст запроса могут попасть константы
+func sanitizeSQL(query string) string {
+	query = sqlLiteral.ReplaceAllStringFunc(query, func(literal string) string {
+		if strings.HasPrefix(literal, "$") {
+			return literal
+		}
+		return "?"
+	})
+	return strings.TrimSpace(sqlSpace.ReplaceAllString(query, " "))
+}
+
+// sqlOperation - первое слово запроса: SELECT, INSERT, WITH...
+func sqlOperation(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToUpper(fields[0])
+}
+
+// callerPC - адрес вызова метода observedConn из кода репозитория
+func callerPC() uintptr {
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+	return pcs[0]
+}
+
+// callerMethods кеширует тип и метод репозитория по адресу вызова
+var callerMethods sync.Map
+
+type repoMethod struct {
+	repo, method string
+}
+
+func callerMethod(pc uintptr) (string, string) {
+	if cached, ok := callerMethods.Load(pc); ok {
+		m := cached.(repoMethod)
+		return m.repo, m.method
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	repo, method := splitFunction(frame.Function)
+	callerMethods.Store(pc, repoMethod{repo: repo, method: method})
+	return repo, method
+}
+
+// splitFunction - ToDo-List/internal/repo.(*PostgreStatsRepo).GetSystemStats.func1
+// -> PostgreStatsRepo, GetSystemStats; для функций без типа repo пустой
+func splitFunction(function string) (string, string) {
+	if i := strings.LastIndex(function, "/"); i >= 0 {
+		function = function[i+1:]
+	}
+	_, function, _ = strings.Cut(function, ".")
+	repo := ""
+	if strings.HasPrefix(function, "(") {
+		var found bool
+		repo, function, found = strings.Cut(function[1:], ").")
+		if !found {
+			return "", "unknown"
+		}
+		repo = strings.TrimPrefix(repo, "*")
+	} else if name, rest, found := strings.Cut(function, "."); found && !strings.HasPrefix(rest, "func") {
+		// Метод с получателем-значением: PostgreRepo.CreateTodo
+		repo, function = name, rest
+	}
+	method, _, _ := strings.Cut(function, ".")
+	if method == "" {
+		method = "unknown"
+	}
+	return repo, method
+}
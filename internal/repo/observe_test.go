@@ -0,0 +1,167 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	spanExporter = tracetest.NewInMemoryExporter()
+	setupTracing sync.Once
+)
+
+// recordSpans включает запись span в память; TracerProvider задаётся один раз,
+// потому что глобальный tracer пакета привязывается к первому
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	setupTracing.Do(func() {
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(spanExporter)))
+	})
+	spanExporter.Reset()
+	return spanExporter
+}
+
+// stubConn отвечает на любой запрос ошибкой err
+type stubConn struct {
+	err error
+}
+
+func (c stubConn) ExecContext(context.Context, string, ...interface{}) (sql.Result, error) {
+	return nil, c.err
+}
+
+func (c stubConn) QueryContext(context.Context, string, ...interface{}) (rowsIter, error) {
+	return nil, c.err
+}
+
+func (c stubConn) QueryRowContext(context.Context, string, ...interface{}) rowScanner {
+	return errRow{c.err}
+}
+
+// recordingObserver запоминает замеры запросов
+type recordingObserver struct {
+	queries []string
+	errs    []error
+}
+
+func (o *recordingObserver) ObserveQuery(repo, method string, _ time.Duration, err error) {
+	o.queries = append(o.queries, repo+"."+method)
+	o.errs = append(o.errs, err)
+}
+
+// stubRepo - метод репозитория, из которого берётся имя span
+type stubRepo struct {
+	conn dbtx
+}
+
+func (r *stubRepo) GetTodoById(ctx context.Context) error {
+	var id string
+	return r.conn.QueryRowContext(ctx, "SELECT id FROM todos WHERE id = $1 AND title = 'secret' LIMIT 10", "t1").Scan(&id)
+}
+
+func TestObservedQuerySpan(t *testing.T) {
+	exporter := recordSpans(t)
+	observer := &recordingObserver{}
+	r := &stubRepo{conn: observedConn{next: stubConn{err: errors.New("connection reset")}, observer: observer}}
+
+	parentCtx, parent := otel.Tracer("test").Start(context.Background(), "TodoService.GetTodoById")
+	if err := r.GetTodoById(parentCtx); err == nil {
+		t.Fatal("stub error not returned")
+	}
+	parent.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want query and parent", len(spans))
+	}
+	query := spans[0]
+	if query.Name != "stubRepo.GetTodoById" {
+		t.Errorf("span name = %q, want stubRepo.GetTodoById", query.Name)
+	}
+	if query.SpanKind != trace.SpanKindClient || query.Parent.SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("query span is not a client child of the service span: %v %v", query.SpanKind, query.Parent)
+	}
+	want := map[attribute.Key]string{
+		"db.system":         "postgresql",
+		"db.operation.name": "SELECT",
+		// Литералы не попадают в трассировку
+		"db.query.text": "SELECT id FROM todos WHERE id = $1 AND title = ? LIMIT ?",
+	}
+	for _, attr := range query.Attributes {
+		if value, ok := want[attr.Key]; ok {
+			if attr.Value.AsString() != value {
+				t.Errorf("%s = %q, want %q", attr.Key, attr.Value.AsString(), value)
+			}
+			delete(want, attr.Key)
+		}
+	}
+	if len(want) > 0 {
+		t.Errorf("missing attributes %v", want)
+	}
+	if query.Status.Code != codes.Error {
+		t.Errorf("span status = %v, want error", query.Status.Code)
+	}
+	if len(observer.queries) != 1 || observer.queries[0] != "stubRepo.GetTodoById" || observer.errs[0] == nil {
+		t.Errorf("observer = %v %v", observer.queries, observer.errs)
+	}
+}
+
+func TestObservedNoRowsIsNotAnError(t *testing.T) {
+	exporter := recordSpans(t)
+	observer := &recordingObserver{}
+	r := &stubRepo{conn: observedConn{next: stubConn{err: sql.ErrNoRows}, observer: observer}}
+	if err := r.GetTodoById(context.Background()); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("GetTodoById = %v, want sql.ErrNoRows", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || spans[0].Status.Code == codes.Error {
+		t.Errorf("spans = %v, want one without error status", spans)
+	}
+	if len(observer.errs) != 1 || observer.errs[0] != nil {
+		t.Errorf("observer errors = %v, want nil", observer.errs)
+	}
+}
+
+func TestSanitizeSQL(t *testing.T) {
+	tests := map[string]string{
+		"SELECT *\n\t FROM todos WHERE id = $1":               "SELECT * FROM todos WHERE id = $1",
+		"UPDATE todos SET title = 'it''s' WHERE priority > 2": "UPDATE todos SET title = ? WHERE priority > ?",
+		"SELECT set_config('app.bypass_rls', 'on', true)":     "SELECT set_config(?, ?, true)",
+		"INSERT INTO t2 (id) VALUES ($1), ($12)":              "INSERT INTO t2 (id) VALUES ($1), ($12)",
+		"SELECT 1.5":                                          "SELECT ?",
+	}
+	for query, want := range tests {
+		if got := sanitizeSQL(query); got != want {
+			t.Errorf("sanitizeSQL(%q) = %q, want %q", query, got, want)
+		}
+	}
+}
+
+func TestSplitFunction(t *testing.T) {
+	tests := []struct {
+		function, repo, method string
+	}{
+		{function: "ToDo-List/internal/repo.(*PostgreRepo).CreateTodo", repo: "PostgreRepo", method: "CreateTodo"},
+		{function: "ToDo-List/internal/repo.(*PostgreStatsRepo).GetSystemStats.func1", repo: "PostgreStatsRepo", method: "GetSystemStats"},
+		{function: "ToDo-List/internal/repo.PostgreRepo.CreateTodo", repo: "PostgreRepo", method: "CreateTodo"},
+		{function: "ToDo-List/internal/repo.migrate", repo: "", method: "migrate"},
+		{function: "ToDo-List/internal/repo.migrate.func2", repo: "", method: "migrate"},
+	}
+	for _, tt := range tests {
+		if repo, method := splitFunction(tt.function); repo != tt.repo || method != tt.method {
+			t.Errorf("splitFunction(%q) = %q, %q; want %q, %q", tt.function, repo, method, tt.repo, tt.method)
+		}
+	}
+}
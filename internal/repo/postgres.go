@@ -4,13 +4,60 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
-"strings"
+
 	"ToDo-List/internal/adapters/logger"
 	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/identity"
 	"ToDo-List/internal/core/ports"
+	"ToDo-List/internal/core/query"
+
+	"github.com/lib/pq"
 )
 
+const todoColumns = `id, owner_id, todo, message, created_at, updated_at, deadline, priority, completed_at, complete, tags, position,
+	COALESCE(assignee_id, ''),
+	ARRAY(SELECT w.user_id FROM todo_watchers w WHERE w.todo_id = todo.id ORDER BY w.user_id),
+	(SELECT COUNT(*) FROM comments c WHERE c.todo_id = todo.id)`
+
+// rowScanner - общий интерфейс для *sql.Row и *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTodo(row rowScanner) (domain.ToDo, error) {
+	var todo domain.ToDo
+	err := row.Scan(
+		&todo.Id,
+		&todo.OwnerId,
+		&todo.Todo,
+		&todo.Message,
+		&todo.CreatedAt,
+		&todo.UpdatedAt,
+		&todo.Deadline,
+		&todo.Priority,
+		&todo.CompletedAt,
+		&todo.Complete,
+		pq.Array(&todo.Tags),
+		&todo.Position,
+		&todo.AssigneeId,
+		pq.Array(&todo.Watchers),
+		&todo.CommentCount,
+	)
+	return todo, err
+}
+
+// tagsArray не даёт записать NULL в колонку tags
+func tagsArray(tags []string) interface{} {
+	if tags == nil {
+		tags = []string{}
+	}
+	return pq.Array(tags)
+}
+
 type PostgreRepo struct {
 	db     *sql.DB
 	logger *logger.Logger
@@ -23,14 +70,129 @@ func NewPostgreRepo(db *sql.DB, logger *logger.Logger) ports.PostgreRepo {
 	}
 }
 
-func (r *PostgreRepo) GetAllTodosWithFilters(ctx context.Context, filter ports.TodoFilter) ([]domain.ToDo, error) {
-	r.logger.Debug("Executing GetAllTodosWithFilters: %+v", filter)
+func (r *PostgreRepo) GetAllTodosWithFilters(ctx context.Context, filter domain.TodoFilter) ([]domain.ToDo, error) {
+	r.log(ctx).Debug("Executing GetAllTodosWithFilters: %+v", filter)
 	
-	query := `SELECT id, todo, message, created_at, updated_at, deadline, priority, completed_at, complete 
+	ownerId, workspaceId, err := tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	
+	query := `SELECT ` + todoColumns + ` 
               FROM todo`
 	
-	args := []interface{}{}
-	conditions := []string{}
+	where, args, err := buildFilterWhere(filter, ownerId, workspaceId)
+	if err != nil {
+		r.log(ctx).Warn("Invalid filter query: %v", err)
+		return nil, err
+	}
+	query += where
+	
+	query += buildOrderBy(filter)
+	
+	r.log(ctx).Debug("SQL Query: %s, Args: %v", query, sqlArgs(args))
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		r.log(ctx).Error("Query failed: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+	
+	var todos []domain.ToDo
+	for rows.Next() {
+		todo, err := scanTodo(rows)
+		if err != nil {
+			r.log(ctx).Error("Row scan failed: %v", err)
+			return nil, err
+		}
+		todos = append(todos, todo)
+	}
+	
+	if err := rows.Err(); err != nil {
+		r.log(ctx).Error("Rows error: %v", err)
+		return nil, err
+	}
+	
+	r.log(ctx).Info("Retrieved %d todos", len(todos))
+	return todos, nil
+}
+
+// CountTodosByFilters возвращает количество задач для каждого фильтра одним
+// запросом: SELECT (SELECT COUNT(*) ...), (SELECT COUNT(*) ...), ...
+func (r *PostgreRepo) CountTodosByFilters(ctx context.Context, filters []domain.TodoFilter) ([]int, error) {
+	r.log(ctx).Debug("Executing CountTodosByFilters: %d filters", len(filters))
+
+	counts := make([]int, len(filters))
+	if len(filters) == 0 {
+		return counts, nil
+	}
+
+	ownerId, workspaceId, err := tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]string, 0, len(filters))
+	var args []interface{}
+	for _, filter := range filters {
+		where, filterArgs, err := buildFilterWhere(filter, ownerId, workspaceId)
+		if err != nil {
+			r.log(ctx).Warn("Invalid filter query: %v", err)
+			return nil, err
+		}
+		// У каждого условия свои $1..$n, в общем запросе они идут после предыдущих
+		columns = append(columns, `(SELECT COUNT(*) FROM todo`+shiftPlaceholders(where, len(args))+`)`)
+		args = append(args, filterArgs...)
+	}
+	query := `SELECT ` + strings.Join(columns, ", ")
+
+	r.log(ctx).Debug("SQL Query: %s, Args: %v", query, sqlArgs(args))
+	dest := make([]interface{}, len(counts))
+	for i := range counts {
+		dest[i] = &counts[i]
+	}
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(dest...); err != nil {
+		r.log(ctx).Error("Count query failed: %v", err)
+		return nil, err
+	}
+	return counts, nil
+}
+
+var placeholderPattern = regexp.MustCompile(`\$(\d+)`)
+
+// shiftPlaceholders сдвигает номера параметров $n на offset. Условия фильтра
+// передают все значения параметрами, других $ в их тексте нет.
+func shiftPlaceholders(sql string, offset int) string {
+	if offset == 0 {
+		return sql
+	}
+	return placeholderPattern.ReplaceAllStringFunc(sql, func(p string) string {
+		n, _ := strconv.Atoi(p[1:])
+		return "$" + strconv.Itoa(n+offset)
+	})
+}
+
+// buildFilterWhere строит условие WHERE для фильтра списка задач владельца
+// в рабочем пространстве
+func buildFilterWhere(filter domain.TodoFilter, ownerId, workspaceId string) (string, []interface{}, error) {
+	args := []interface{}{ownerId, workspaceId}
+	conditions := []string{"owner_id = $1", "workspace_id = $2"}
+
+	// Фильтр по исполнителю ищет и в чужих списках, открытых пользователю
+	switch filter.Assignee {
+	case "":
+	case "me":
+		conditions = []string{accessibleTodoSQL("$1", "$2"), "assignee_id = $1"}
+	case "none":
+		conditions = []string{accessibleTodoSQL("$1", "$2"), "assignee_id IS NULL"}
+	default:
+		// Имя одного пользователя может совпасть с id другого, поэтому IN:
+		// подзапрос вернёт обе строки, а не ошибку
+		placeholder := "$" + fmt.Sprint(len(args)+1)
+		conditions = []string{accessibleTodoSQL("$1", "$2"),
+			"assignee_id IN (SELECT id FROM users WHERE username = " + placeholder + " OR id = " + placeholder + ")"}
+		args = append(args, filter.Assignee)
+	}
 	
 	// Фильтрация по статусу
 	switch filter.Status {
@@ -40,7 +202,7 @@ func (r *PostgreRepo) GetAllTodosWithFilters(ctx context.Context, filter ports.T
 		conditions = append(conditions, "complete = true")
 	case "overdue":
 		now := time.Now().Format("2006-01-02 15:04:05")
-		conditions = append(conditions, "complete = false AND deadline < $1")
+		conditions = append(conditions, "complete = false AND deadline < $"+fmt.Sprint(len(args)+1))
 		args = append(args, now)
 	}
 	
@@ -60,135 +222,130 @@ func (r *PostgreRepo) GetAllTodosWithFilters(ctx context.Context, filter ports.T
 		args = append(args, monthAgo)
 	}
 	
-	// Добавляем условия WHERE
-	if len(conditions) > 0 {
-		query += " WHERE " + strings.Join(conditions, " AND ")
-	}
-	
-	// Сортировка
-	orderBy := "created_at"
-	if filter.OrderBy != "" {
-		orderBy = filter.OrderBy
-	}
-	
-	orderDir := "DESC"
-	if filter.OrderDir == "asc" {
-		orderDir = "ASC"
+	// Фильтрация по выражению (параметр q)
+	if filter.Query != "" {
+		condition, queryArgs, err := compileFilterQuery(filter.Query, args)
+		if err != nil {
+			return "", nil, err
+		}
+		conditions = append(conditions, condition)
+		args = queryArgs
 	}
 	
-	if orderBy == "priority" {
-		query += ` ORDER BY 
-			CASE priority 
-				WHEN 'high' THEN 1 
-				WHEN 'medium' THEN 2 
-				WHEN 'low' THEN 3 
-				ELSE 4 
-			END ` + orderDir
+	// Добавляем условия WHERE
+	return " WHERE " + strings.Join(conditions, " AND "), args, nil
+}
+
+// buildOrderBy строит ORDER BY: либо по списку ключей sort, либо по
+// orderBy/orderDir. В конце всегда id, чтобы порядок был детерминированным.
+func buildOrderBy(filter domain.TodoFilter) string {
+	keys := []query.SortKey{}
+	if filter.Sort != "" {
+		parsed, err := query.ParseSort(filter.Sort)
+		if err == nil {
+			keys = parsed
+		}
 	} else {
-		query += " ORDER BY " + orderBy + " " + orderDir
-	}
-	
-	r.logger.Debug("SQL Query: %s, Args: %v", query, args)
-	rows, err := r.db.QueryContext(ctx, query, args...)
-	if err != nil {
-		r.logger.Error("Query failed: %v", err)
-		return nil, err
-	}
-	defer rows.Close()
-	
-	var todos []domain.ToDo
-	for rows.Next() {
-		var todo domain.ToDo
-		err := rows.Scan(
-			&todo.Id,
-			&todo.Todo,
-			&todo.Message,
-			&todo.CreatedAt,
-			&todo.UpdatedAt,
-			&todo.Deadline,
-			&todo.Priority,
-			&todo.CompletedAt,
-			&todo.Complete,
-		)
-		if err != nil {
-			r.logger.Error("Row scan failed: %v", err)
-			return nil, err
+		orderBy := "created_at"
+		if filter.OrderBy != "" {
+			orderBy = filter.OrderBy
 		}
-		todos = append(todos, todo)
+		keys = append(keys, query.SortKey{Field: orderBy, Desc: filter.OrderDir != "asc"})
 	}
-	
-	if err := rows.Err(); err != nil {
-		r.logger.Error("Rows error: %v", err)
-		return nil, err
+
+	terms := make([]string, 0, len(keys)+1)
+	for _, key := range keys {
+		column := key.Field
+		if column == "priority" {
+			column = priorityRankSQL
+		}
+		dir := "ASC"
+		if key.Desc {
+			dir = "DESC"
+		}
+		terms = append(terms, column+" "+dir)
 	}
-	
-	r.logger.Info("Retrieved %d todos", len(todos))
-	return todos, nil
+	terms = append(terms, "id ASC")
+	return " ORDER BY " + strings.Join(terms, ", ")
 }
 
 func (r *PostgreRepo) GetTodoById(ctx context.Context, id string) (domain.ToDo, error) {
-	r.logger.Debug("Executing GetTodoById: id=%s", id)
-	
-	query := `SELECT id, todo, message, created_at, updated_at, deadline, priority, completed_at, complete 
-	          FROM todo WHERE id = $1`
+	r.log(ctx).Debug("Executing GetTodoById: id=%s", id)
+	return r.getTodoById(ctx, id, "")
+}
 
-	r.logger.Debug("SQL Query: %s, Arg: %s", query, id)
-	row := r.db.QueryRowContext(ctx, query, id)
+// GetTodoByIdForUpdate блокирует строку задачи до конца текущей транзакции
+func (r *PostgreRepo) GetTodoByIdForUpdate(ctx context.Context, id string) (domain.ToDo, error) {
+	r.log(ctx).Debug("Executing GetTodoByIdForUpdate: id=%s", id)
+	return r.getTodoById(ctx, id, " FOR UPDATE")
+}
 
-	var todo domain.ToDo
-	err := row.Scan(
-		&todo.Id,
-		&todo.Todo,
-		&todo.Message,
-		&todo.CreatedAt,
-		&todo.UpdatedAt,
-		&todo.Deadline,
-		&todo.Priority,
-		&todo.CompletedAt,
-		&todo.Complete,
-	)
+func (r *PostgreRepo) getTodoById(ctx context.Context, id string, lock string) (domain.ToDo, error) {
+	userId, workspaceId, err := tenant(ctx)
+	if err != nil {
+		return domain.ToDo{}, err
+	}
+
+	query := `SELECT ` + todoColumns + ` 
+	          FROM todo WHERE id = $1 AND ` + accessibleTodoSQL("$2", "$3") + lock
+
+	r.log(ctx).Debug("SQL Query: %s, Arg: %s", query, id)
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, id, userId, workspaceId)
+
+	todo, err := scanTodo(row)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			r.logger.Warn("Todo not found: %s", id)
-			return domain.ToDo{}, fmt.Errorf("todo not found: %s", id)
+			r.log(ctx).Warn("Todo not found: %s", id)
+			return domain.ToDo{}, fmt.Errorf("%w: %s", ports.ErrTodoNotFound, id)
 		}
-		r.logger.Error("Scan failed: %v", err)
+		r.log(ctx).Error("Scan failed: %v", err)
 		return domain.ToDo{}, err
 	}
 
-	r.logger.Debug("Todo found: %s", id)
+	r.log(ctx).Debug("Todo found: %s", id)
 	return todo, nil
 }
 
 func (r *PostgreRepo) DeleteTodoById(ctx context.Context, id string) error {
-	r.logger.Debug("Executing DeleteTodoById: id=%s", id)
+	r.log(ctx).Debug("Executing DeleteTodoById: id=%s", id)
+	
+	userId, workspaceId, err := tenant(ctx)
+	if err != nil {
+		return err
+	}
 	
-	query := `DELETE FROM todo WHERE id = $1`
-	r.logger.Debug("SQL Query: %s, Arg: %s", query, id)
+	// Право на удаление проверяет сервис по роли пользователя
+	query := `DELETE FROM todo WHERE id = $1 AND ` + accessibleTodoSQL("$2", "$3")
+	r.log(ctx).Debug("SQL Query: %s, Arg: %s", query, id)
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id, userId, workspaceId)
 	if err != nil {
-		r.logger.Error("Delete failed: %v", err)
+		r.log(ctx).Error("Delete failed: %v", err)
 		return err
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		r.logger.Error("RowsAffected failed: %v", err)
+		r.log(ctx).Error("RowsAffected failed: %v", err)
 		return err
 	}
 
 	if rowsAffected == 0 {
-		r.logger.Warn("Todo not found for deletion: %s", id)
+		r.log(ctx).Warn("Todo not found for deletion: %s", id)
 		return fmt.Errorf("todo with id %s not found", id)
 	}
 
-	r.logger.Info("Todo deleted successfully: %s", id)
+	r.log(ctx).Info("Todo deleted successfully: %s", id)
 	return nil
 }
 
 func (r *PostgreRepo) UpdateTodo(ctx context.Context, todo domain.ToDo) error {
-	r.logger.Debug("Executing UpdateTodo: id=%s", todo.Id)
+	r.log(ctx).Debug("Executing UpdateTodo: id=%s", todo.Id)
+	
+	userId, workspaceId, err := tenant(ctx)
+	if err != nil {
+		return err
+	}
 	
 	query := `
 		UPDATE todo 
@@ -199,14 +356,14 @@ func (r *PostgreRepo) UpdateTodo(ctx context.Context, todo domain.ToDo) error {
 			deadline = $4,
 			priority = $5,
 			completed_at = $6,
-			complete = $7
-		WHERE id = $8
-	`
+			complete = $7,
+			tags = $8
+		WHERE id = $9 AND ` + accessibleTodoSQL("$10", "$11")
 
 	todo.UpdatedAt = time.Now()
 
-	r.logger.Debug("SQL Query: %s, Args: %+v", query, todo)
-	result, err := r.db.ExecContext(ctx, query,
+	r.log(ctx).Debug("SQL Query: %s, Args: %+v", query, todo)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query,
 		todo.Todo,
 		todo.Message,
 		todo.UpdatedAt,
@@ -214,41 +371,52 @@ func (r *PostgreRepo) UpdateTodo(ctx context.Context, todo domain.ToDo) error {
 		todo.Priority,
 		todo.CompletedAt,
 		todo.Complete,
+		tagsArray(todo.Tags),
 		todo.Id,
+		userId,
+		workspaceId,
 	)
 	if err != nil {
-		r.logger.Error("Update failed: %v", err)
+		r.log(ctx).Error("Update failed: %v", err)
 		return err
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		r.logger.Error("RowsAffected failed: %v", err)
+		r.log(ctx).Error("RowsAffected failed: %v", err)
 		return err
 	}
 
 	if rowsAffected == 0 {
-		r.logger.Warn("Todo not found for update: %s", todo.Id)
+		r.log(ctx).Warn("Todo not found for update: %s", todo.Id)
 		return fmt.Errorf("todo with id %s not found", todo.Id)
 	}
 
-	r.logger.Info("Todo updated successfully: %s", todo.Id)
+	r.log(ctx).Info("Todo updated successfully: %s", todo.Id)
 	return nil
 }
 
 func (r *PostgreRepo) CreateTodo(ctx context.Context, todo domain.ToDo) (domain.ToDo, error) {
-	r.logger.Debug("Executing CreateTodo: %+v", todo)
+	r.log(ctx).Debug("Executing CreateTodo: %+v", todo)
+	
+	// Владелец - всегда пользователь запроса, а не значение из тела
+	ownerId, workspaceId, err := tenant(ctx)
+	if err != nil {
+		return domain.ToDo{}, err
+	}
+	todo.OwnerId = ownerId
 	
 	query := `
 		INSERT INTO todo (
-			id, todo, message, created_at, updated_at, deadline, priority, completed_at, complete
+			id, owner_id, todo, message, created_at, updated_at, deadline, priority, completed_at, complete, tags, position, workspace_id
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 	`
 
-	r.logger.Debug("SQL Query: %s, Args: %+v", query, todo)
-	_, err := r.db.ExecContext(ctx, query,
+	r.log(ctx).Debug("SQL Query: %s, Args: %+v", query, todo)
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
 		todo.Id,
+		todo.OwnerId,
 		todo.Todo,
 		todo.Message,
 		todo.CreatedAt,
@@ -257,17 +425,121 @@ func (r *PostgreRepo) CreateTodo(ctx context.Context, todo domain.ToDo) (domain.
 		todo.Priority,
 		todo.CompletedAt,
 		todo.Complete,
+		tagsArray(todo.Tags),
+		todo.Position,
+		workspaceId,
 	)
 	if err != nil {
-		r.logger.Error("Insert failed: %v", err)
+		if isUniqueViolation(err) {
+			r.log(ctx).Warn("Todo id already in use: %s", todo.Id)
+			return domain.ToDo{}, &ports.ConflictError{Resource: "todo", Id: todo.Id}
+		}
+		r.log(ctx).Error("Insert failed: %v", err)
 		return domain.ToDo{}, err
 	}
 
-	r.logger.Info("Todo created successfully: %s", todo.Id)
+	r.log(ctx).Info("Todo created successfully: %s", todo.Id)
 	return todo, nil
 }
 
+// GetFirstPosition возвращает наименьшую непустую позицию ручного порядка
+func (r *PostgreRepo) GetFirstPosition(ctx context.Context) (string, error) {
+	r.log(ctx).Debug("Executing GetFirstPosition")
+
+	ownerId, workspaceId, err := tenant(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	query := `SELECT COALESCE(MIN(position), '') FROM todo WHERE owner_id = $1 AND workspace_id = $2 AND position <> ''`
+
+	var position string
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, ownerId, workspaceId).Scan(&position); err != nil {
+		r.log(ctx).Error("Query failed: %v", err)
+		return "", err
+	}
+	return position, nil
+}
+
+// GetLastPosition возвращает наибольшую позицию ручного порядка
+func (r *PostgreRepo) GetLastPosition(ctx context.Context) (string, error) {
+	r.log(ctx).Debug("Executing GetLastPosition")
 
+	ownerId, workspaceId, err := tenant(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	query := `SELECT COALESCE(MAX(position), '') FROM todo WHERE owner_id = $1 AND workspace_id = $2`
+
+	var position string
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, ownerId, workspaceId).Scan(&position); err != nil {
+		r.log(ctx).Error("Query failed: %v", err)
+		return "", err
+	}
+	return position, nil
+}
+
+// GetAdjacentPosition возвращает позицию ближайшей задачи перед или после position
+func (r *PostgreRepo) GetAdjacentPosition(ctx context.Context, position string, before bool, excludeId string) (string, error) {
+	r.log(ctx).Debug("Executing GetAdjacentPosition: position=%s, before=%t", position, before)
+
+	userId, workspaceId, err := tenant(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// Соседи ищутся в списке владельца перемещаемой задачи
+	query := `SELECT position FROM todo WHERE workspace_id = $4 AND owner_id = (SELECT owner_id FROM todo WHERE id = $2 AND ` + accessibleTodoSQL("$3", "$4") + `)
+	          AND position > $1 AND id <> $2 ORDER BY position ASC LIMIT 1`
+	if before {
+		query = `SELECT position FROM todo WHERE workspace_id = $4 AND owner_id = (SELECT owner_id FROM todo WHERE id = $2 AND ` + accessibleTodoSQL("$3", "$4") + `)
+		         AND position < $1 AND id <> $2 ORDER BY position DESC LIMIT 1`
+	}
+
+	r.log(ctx).Debug("SQL Query: %s, Args: %s, %s", query, position, excludeId)
+	var adjacent string
+	err = conn(ctx, r.db).QueryRowContext(ctx, query, position, excludeId, userId, workspaceId).Scan(&adjacent)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		r.log(ctx).Error("Query failed: %v", err)
+		return "", err
+	}
+	return adjacent, nil
+}
+
+// UpdateTodoPosition меняет только позицию задачи, не трогая остальные поля
+func (r *PostgreRepo) UpdateTodoPosition(ctx context.Context, id string, position string) error {
+	r.log(ctx).Debug("Executing UpdateTodoPosition: id=%s, position=%s", id, position)
+
+	userId, workspaceId, err := tenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE todo SET position = $1, updated_at = $2 WHERE id = $3 AND ` + accessibleTodoSQL("$4", "$5")
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, position, time.Now(), id, userId, workspaceId)
+	if err != nil {
+		r.log(ctx).Error("Update failed: %v", err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.log(ctx).Error("RowsAffected failed: %v", err)
+		return err
+	}
+	if rowsAffected == 0 {
+		r.log(ctx).Warn("Todo not found for move: %s", id)
+		return fmt.Errorf("todo with id %s not found", id)
+	}
+
+	r.log(ctx).Info("Todo moved successfully: %s", id)
+	return nil
+}
 
 func (r *PostgreRepo) Ping() error {
 	r.logger.Debug("Pinging database")
@@ -278,4 +550,76 @@ func (r *PostgreRepo) Ping() error {
 	}
 	r.logger.Debug("Database ping successful")
 	return nil
-}
\ No newline at end of file
+}
+func (r *PostgreRepo) SetAssignee(ctx context.Context, id string, assigneeId string) error {
+	r.log(ctx).Debug("Executing SetAssignee: id=%s, assignee=%s", id, assigneeId)
+
+	userId, workspaceId, err := tenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE todo SET assignee_id = $1, updated_at = $2 WHERE id = $3 AND ` + accessibleTodoSQL("$4", "$5")
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, nullIfEmpty(assigneeId), time.Now(), id, userId, workspaceId)
+	if err != nil {
+		r.log(ctx).Error("Update failed: %v", err)
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.log(ctx).Error("RowsAffected failed: %v", err)
+		return err
+	}
+	if rowsAffected == 0 {
+		r.log(ctx).Warn("Todo not found for assignment: %s", id)
+		return fmt.Errorf("todo with id %s not found", id)
+	}
+	return nil
+}
+
+func (r *PostgreRepo) AddWatcher(ctx context.Context, id string, userId string) error {
+	r.log(ctx).Debug("Executing AddWatcher: id=%s, user=%s", id, userId)
+
+	workspaceId, err := identity.WorkspaceId(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO todo_watchers (todo_id, user_id, created_at, workspace_id) VALUES ($1, $2, $3, $4)
+	          ON CONFLICT (todo_id, user_id) DO NOTHING`
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, id, userId, time.Now(), workspaceId); err != nil {
+		r.log(ctx).Error("Insert failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *PostgreRepo) RemoveWatcher(ctx context.Context, id string, userId string) error {
+	r.log(ctx).Debug("Executing RemoveWatcher: id=%s, user=%s", id, userId)
+
+	workspaceId, err := identity.WorkspaceId(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `DELETE FROM todo_watchers WHERE todo_id = $1 AND user_id = $2 AND workspace_id = $3`
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, id, userId, workspaceId); err != nil {
+		r.log(ctx).Error("Delete failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+// sqlArgs - параметры запроса для лога. В них значения фильтра (шаблоны
+// поиска, имена исполнителей), поэтому при OmitContent пишется только их число.
+type sqlArgs []interface{}
+
+func (a sqlArgs) LogSummary() string {
+	return fmt.Sprintf("%d args", len(a))
+}
+
+// log - логгер запроса с его X-Request-ID
+func (r *PostgreRepo) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, r.logger)
+}
@@ -0,0 +1,145 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"strings"
+	"testing"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/identity"
+)
+
+// Поисковый запрос и исполнитель не попадают в лог ни из фильтра, ни из
+// параметров SQL, ни из ошибки разбора, когда содержимое писать нельзя
+func TestFilterContentOmittedFromLogs(t *testing.T) {
+	const term, assignee = "quarterlyreport", "alicecooper"
+
+	for _, tt := range []struct {
+		name        string
+		omitContent bool
+		wantTerm    bool
+	}{
+		{name: "content logged", omitContent: false, wantTerm: true},
+		{name: "content omitted", omitContent: true, wantTerm: false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := os.CreateTemp(t.TempDir(), "log")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer output.Close()
+			appLogger, err := logger.New(logger.Config{
+				Level:     logger.LevelDebug,
+				Output:    output,
+				Redaction: logger.RedactionConfig{OmitContent: tt.omitContent},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Базы по этому адресу нет: запрос завершается ошибкой уже после записи в лог
+			db, err := sql.Open("postgres", "postgres://todo@127.0.0.1:1/todos?sslmode=disable&connect_timeout=1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+
+			ctx := identity.WithUser(context.Background(), domain.User{Id: "u1"})
+			ctx = identity.WithWorkspace(ctx, domain.Workspace{Id: "w1"}, domain.WorkspaceRoleOwner)
+			filter := domain.TodoFilter{Status: "active", Query: "text:" + term, Assignee: assignee}
+			repo := NewPostgreRepo(db, appLogger)
+			if _, err := repo.GetAllTodosWithFilters(ctx, filter); err == nil {
+				t.Fatal("GetAllTodosWithFilters succeeded without a database")
+			}
+			if _, err := repo.CountTodosByFilters(ctx, []domain.TodoFilter{filter}); err == nil {
+				t.Fatal("CountTodosByFilters succeeded without a database")
+			}
+			// Ошибка разбора цитирует выражение запроса
+			filter.Query = term
+			if _, err := repo.GetAllTodosWithFilters(ctx, filter); err == nil {
+				t.Fatal("GetAllTodosWithFilters accepted an invalid query")
+			}
+
+			logged, err := os.ReadFile(output.Name())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(logged), "SQL Query") {
+				t.Fatalf("query was not logged:\n%s", logged)
+			}
+			for _, value := range []string{term, assignee} {
+				if got := strings.Contains(string(logged), value); got != tt.wantTerm {
+					t.Errorf("log contains %q = %v, want %v:\n%s", value, got, tt.wantTerm, logged)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildOrderBy(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter domain.TodoFilter
+		want   string
+	}{
+		{name: "default", filter: domain.TodoFilter{}, want: " ORDER BY created_at DESC, id ASC"},
+		{name: "order by", filter: domain.TodoFilter{OrderBy: "deadline", OrderDir: "asc"}, want: " ORDER BY deadline ASC, id ASC"},
+		{
+			name:   "priority rank",
+			filter: domain.TodoFilter{OrderBy: "priority"},
+			want:   " ORDER BY " + priorityRankSQL + " DESC, id ASC",
+		},
+		{
+			// sort заменяет OrderBy и OrderDir
+			name:   "sort keys",
+			filter: domain.TodoFilter{Sort: "priority,-deadline,position", OrderBy: "created_at", OrderDir: "asc"},
+			want:   " ORDER BY " + priorityRankSQL + " ASC, deadline DESC, position ASC, id ASC",
+		},
+		{name: "invalid sort", filter: domain.TodoFilter{Sort: "title"}, want: " ORDER BY id ASC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildOrderBy(tt.filter); got != tt.want {
+				t.Errorf("buildOrderBy(%+v)\n got %s\nwant %s", tt.filter, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildFilterWhereAssignee(t *testing.T) {
+	tests := []struct {
+		assignee string
+		want     string
+		args     int
+	}{
+		{assignee: "me", want: "assignee_id = $1", args: 2},
+		{assignee: "none", want: "assignee_id IS NULL", args: 2},
+		{assignee: "bob", want: "assignee_id IN (SELECT id FROM users WHERE username = $3 OR id = $3)", args: 3},
+	}
+	for _, tt := range tests {
+		where, args, err := buildFilterWhere(domain.TodoFilter{Assignee: tt.assignee}, "u1", "w1")
+		if err != nil {
+			t.Fatalf("buildFilterWhere(assignee %s): %v", tt.assignee, err)
+		}
+		if !strings.Contains(where, tt.want) {
+			t.Errorf("buildFilterWhere(assignee %s) = %s, want it to contain %s", tt.assignee, where, tt.want)
+		}
+		if len(args) != tt.args {
+			t.Errorf("buildFilterWhere(assignee %s) args = %v, want %d", tt.assignee, args, tt.args)
+		}
+	}
+}
+
+func TestShiftPlaceholders(t *testing.T) {
+	where := " WHERE owner_id = $1 AND workspace_id = $2 AND deadline < $3 AND priority = 'high'"
+	want := " WHERE owner_id = $4 AND workspace_id = $5 AND deadline < $6 AND priority = 'high'"
+	if got := shiftPlaceholders(where, 3); got != want {
+		t.Errorf("shiftPlaceholders\n got %s\nwant %s", got, want)
+	}
+	if got := shiftPlaceholders("a = $1 OR b = $12", 9); got != "a = $10 OR b = $21" {
+		t.Errorf("shiftPlaceholders with two-digit numbers = %s", got)
+	}
+}
@@ -0,0 +1,127 @@
+package repo
+
+import (
+	"fmt"
+	"strings"
+
+	"ToDo-List/internal/core/query"
+)
+
+const priorityRankSQL = `CASE priority WHEN 'high' THEN 1 WHEN 'medium' THEN 2 WHEN 'low' THEN 3 ELSE 4 END`
+
+// sqlBuilder собирает параметризованное SQL-условие, продолжая нумерацию
+// плейсхолдеров после уже добавленных аргументов
+type sqlBuilder struct {
+	args []interface{}
+}
+
+func (b *sqlBuilder) arg(v interface{}) string {
+	b.args = append(b.args, v)
+	return "$" + fmt.Sprint(len(b.args))
+}
+
+// compileFilterQuery разбирает выражение фильтра и переводит его в условие WHERE
+func compileFilterQuery(input string, args []interface{}) (string, []interface{}, error) {
+	expr, err := query.Parse(input)
+	if err != nil {
+		return "", nil, err
+	}
+	b := &sqlBuilder{args: args}
+	return b.compile(expr), b.args, nil
+}
+
+func (b *sqlBuilder) compile(e query.Expr) string {
+	switch e := e.(type) {
+	case *query.And:
+		return "(" + b.compile(e.Left) + " AND " + b.compile(e.Right) + ")"
+	case *query.Or:
+		return "(" + b.compile(e.Left) + " OR " + b.compile(e.Right) + ")"
+	case *query.Not:
+		return "NOT " + b.compile(e.X)
+	case *query.Cond:
+		return b.cond(e)
+	default:
+		return "FALSE"
+	}
+}
+
+func (b *sqlBuilder) cond(c *query.Cond) string {
+	switch c.Field {
+	case query.FieldPriority:
+		// Больший приоритет - меньший ранг, поэтому оператор зеркалится
+		return fmt.Sprintf("%s %s %s", b.arg(c.Rank), sqlOp(c.Op), priorityRankSQL)
+	case query.FieldDeadline:
+		return b.timeCond("deadline", c)
+	case query.FieldCreated:
+		return b.timeCond("created_at", c)
+	case query.FieldUpdated:
+		return b.timeCond("updated_at", c)
+	case query.FieldCompleted:
+		return b.timeCond("completed_at", c)
+	case query.FieldTag:
+		expr := b.arg(c.Value) + " = ANY(tags)"
+		if c.Op == query.OpNe {
+			return "NOT (" + expr + ")"
+		}
+		return expr
+	case query.FieldText:
+		pattern := b.arg("%" + escapeLike(c.Value) + "%")
+		expr := fmt.Sprintf("(todo ILIKE %s OR COALESCE(message, '') ILIKE %s)", pattern, pattern)
+		if c.Op == query.OpNe {
+			return "NOT " + expr
+		}
+		return expr
+	case query.FieldStatus:
+		var expr string
+		switch c.Value {
+		case "active":
+			expr = "complete = false"
+		case "completed":
+			expr = "complete = true"
+		default:
+			expr = "(complete = false AND deadline < NOW())"
+		}
+		if c.Op == query.OpNe {
+			return "NOT (" + expr + ")"
+		}
+		return expr
+	}
+	return "FALSE"
+}
+
+func (b *sqlBuilder) timeCond(column string, c *query.Cond) string {
+	if !c.Day {
+		return fmt.Sprintf("%s %s %s", column, sqlOp(c.Op), b.arg(c.Time))
+	}
+
+	// Дата без времени означает целый день
+	switch c.Op {
+	case query.OpMatch, query.OpEq:
+		return fmt.Sprintf("(%s >= %s AND %s < %s)", column, b.arg(c.Time), column, b.arg(c.DayEnd()))
+	case query.OpNe:
+		return fmt.Sprintf("(%s < %s OR %s >= %s)", column, b.arg(c.Time), column, b.arg(c.DayEnd()))
+	case query.OpLt:
+		return fmt.Sprintf("%s < %s", column, b.arg(c.Time))
+	case query.OpLe:
+		return fmt.Sprintf("%s < %s", column, b.arg(c.DayEnd()))
+	case query.OpGt:
+		return fmt.Sprintf("%s >= %s", column, b.arg(c.DayEnd()))
+	default:
+		return fmt.Sprintf("%s >= %s", column, b.arg(c.Time))
+	}
+}
+
+func sqlOp(op query.Op) string {
+	switch op {
+	case query.OpMatch, query.OpEq:
+		return "="
+	case query.OpNe:
+		return "<>"
+	default:
+		return string(op)
+	}
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
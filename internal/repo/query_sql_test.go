@@ -0,0 +1,117 @@
+package repo
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCompileFilterQuery(t *testing.T) {
+	day := time.Date(2026, 11, 1, 0, 0, 0, 0, time.Local)
+	nextDay := day.AddDate(0, 0, 1)
+	// первые два параметра - владелец и пространство из buildFilterWhere
+	base := []interface{}{"u1", "w1"}
+
+	tests := []struct {
+		input    string
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{
+			input:    "priority>=medium",
+			wantSQL:  "$3 >= " + priorityRankSQL,
+			wantArgs: []interface{}{2},
+		},
+		{
+			input:    "priority!=high",
+			wantSQL:  "$3 <> " + priorityRankSQL,
+			wantArgs: []interface{}{1},
+		},
+		{
+			input:    "deadline:2026-11-01",
+			wantSQL:  "(deadline >= $3 AND deadline < $4)",
+			wantArgs: []interface{}{day, nextDay},
+		},
+		{
+			input:    "deadline!=2026-11-01",
+			wantSQL:  "(deadline < $3 OR deadline >= $4)",
+			wantArgs: []interface{}{day, nextDay},
+		},
+		{
+			input:    "created<=2026-11-01",
+			wantSQL:  "created_at < $3",
+			wantArgs: []interface{}{nextDay},
+		},
+		{
+			input:    "updated>2026-11-01",
+			wantSQL:  "updated_at >= $3",
+			wantArgs: []interface{}{nextDay},
+		},
+		{
+			input:    "completed<2026-11-01T10:30",
+			wantSQL:  "completed_at < $3",
+			wantArgs: []interface{}{day.Add(10*time.Hour + 30*time.Minute)},
+		},
+		{
+			input:    "tag:Work",
+			wantSQL:  "$3 = ANY(tags)",
+			wantArgs: []interface{}{"work"},
+		},
+		{
+			input:    "tag!=work",
+			wantSQL:  "NOT ($3 = ANY(tags))",
+			wantArgs: []interface{}{"work"},
+		},
+		{
+			// символы LIKE экранируются
+			input:    `text:"50%_off\\"`,
+			wantSQL:  "(todo ILIKE $3 OR COALESCE(message, '') ILIKE $3)",
+			wantArgs: []interface{}{`%50\%\_off\\%`},
+		},
+		{
+			input:    "text!=x",
+			wantSQL:  "NOT (todo ILIKE $3 OR COALESCE(message, '') ILIKE $3)",
+			wantArgs: []interface{}{"%x%"},
+		},
+		{
+			input:    "status:overdue",
+			wantSQL:  "(complete = false AND deadline < NOW())",
+			wantArgs: []interface{}{},
+		},
+		{
+			input:    "status!=completed",
+			wantSQL:  "NOT (complete = true)",
+			wantArgs: []interface{}{},
+		},
+		{
+			input:    "tag:a OR tag:b AND NOT tag:c",
+			wantSQL:  "($3 = ANY(tags) OR ($4 = ANY(tags) AND NOT $5 = ANY(tags)))",
+			wantArgs: []interface{}{"a", "b", "c"},
+		},
+		{
+			input:    "(tag:a OR tag:b) status:active",
+			wantSQL:  "(($3 = ANY(tags) OR $4 = ANY(tags)) AND complete = false)",
+			wantArgs: []interface{}{"a", "b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			sql, args, err := compileFilterQuery(tt.input, append([]interface{}(nil), base...))
+			if err != nil {
+				t.Fatalf("compileFilterQuery(%q): %v", tt.input, err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("compileFilterQuery(%q) SQL\n got %s\nwant %s", tt.input, sql, tt.wantSQL)
+			}
+			if wantArgs := append(append([]interface{}(nil), base...), tt.wantArgs...); !reflect.DeepEqual(args, wantArgs) {
+				t.Errorf("compileFilterQuery(%q) args = %v, want %v", tt.input, args, wantArgs)
+			}
+		})
+	}
+}
+
+func TestCompileFilterQueryError(t *testing.T) {
+	if _, _, err := compileFilterQuery("status:", nil); err == nil {
+		t.Error("compileFilterQuery accepted an empty value")
+	}
+}
@@ -0,0 +1,118 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/ports"
+)
+
+type PostgreSessionRepo struct {
+	db     *sql.DB
+	logger *logger.Logger
+}
+
+func NewPostgreSessionRepo(db *sql.DB, logger *logger.Logger) ports.SessionRepo {
+	return &PostgreSessionRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *PostgreSessionRepo) CreateSession(ctx context.Context, session domain.Session) error {
+	r.log(ctx).Debug("Executing CreateSession: user=%s", session.UserId)
+
+	query := `
+		INSERT INTO sessions (id, user_id, csrf_token, created_at, expires_at, impersonator_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query,
+		session.Id,
+		session.UserId,
+		session.CSRFToken,
+		session.CreatedAt,
+		session.ExpiresAt,
+		nullIfEmpty(session.ImpersonatorId),
+	)
+	if err != nil {
+		r.log(ctx).Error("Insert failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *PostgreSessionRepo) GetSession(ctx context.Context, id string) (domain.Session, error) {
+	query := `SELECT id, user_id, csrf_token, created_at, expires_at, COALESCE(impersonator_id, '')
+	          FROM sessions WHERE id = $1`
+
+	var session domain.Session
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
+		&session.Id,
+		&session.UserId,
+		&session.CSRFToken,
+		&session.CreatedAt,
+		&session.ExpiresAt,
+		&session.ImpersonatorId,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.Session{}, ports.ErrUnauthenticated
+		}
+		r.log(ctx).Error("Scan failed: %v", err)
+		return domain.Session{}, err
+	}
+	return session, nil
+}
+
+func (r *PostgreSessionRepo) DeleteSession(ctx context.Context, id string) error {
+	r.log(ctx).Debug("Executing DeleteSession")
+
+	query := `DELETE FROM sessions WHERE id = $1`
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, id); err != nil {
+		r.log(ctx).Error("Delete failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *PostgreSessionRepo) DeleteExpiredSessions(ctx context.Context) (int64, error) {
+	query := `DELETE FROM sessions WHERE expires_at < $1`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, time.Now())
+	if err != nil {
+		r.log(ctx).Error("Delete expired sessions failed: %v", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *PostgreSessionRepo) DeleteSessionsByUser(ctx context.Context, userId string) error {
+	r.log(ctx).Debug("Executing DeleteSessionsByUser: user=%s", userId)
+
+	query := `DELETE FROM sessions WHERE user_id = $1`
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, userId); err != nil {
+		r.log(ctx).Error("Delete failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *PostgreSessionRepo) DeleteSessionsByImpersonator(ctx context.Context, adminId string) error {
+	r.log(ctx).Debug("Executing DeleteSessionsByImpersonator: admin=%s", adminId)
+
+	query := `DELETE FROM sessions WHERE impersonator_id = $1`
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, adminId); err != nil {
+		r.log(ctx).Error("Delete failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+// log - логгер запроса с его X-Request-ID
+func (r *PostgreSessionRepo) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, r.logger)
+}
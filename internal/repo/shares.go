@@ -0,0 +1,247 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/identity"
+	"ToDo-List/internal/core/ports"
+)
+
+// roleRankSQL упорядочивает роли так же, как domain.RoleRank
+const roleRankSQL = `CASE role WHEN 'owner' THEN 3 WHEN 'editor' THEN 2 WHEN 'viewer' THEN 1 ELSE 0 END`
+
+// accessibleTodoSQL - условие "задача рабочего пространства $w доступна
+// пользователю $n": своя или открыта ему напрямую либо через доступ ко всему
+// списку владельца. Доступ к списку действует только внутри пространства.
+func accessibleTodoSQL(userParam, workspaceParam string) string {
+	return `(todo.workspace_id = ` + workspaceParam + ` AND (owner_id = ` + userParam + ` OR EXISTS (
+		SELECT 1 FROM shares s WHERE s.workspace_id = todo.workspace_id AND s.user_id = ` + userParam + ` AND (
+			(s.resource_type = 'todo' AND s.resource_id = todo.id) OR
+			(s.resource_type = 'list' AND s.resource_id = todo.owner_id)))))`
+}
+
+const shareColumns = `s.id, s.resource_type, s.resource_id, s.owner_id, s.user_id, u.username, s.role, s.created_at`
+
+type PostgreShareRepo struct {
+	db     *sql.DB
+	logger *logger.Logger
+}
+
+func NewPostgreShareRepo(db *sql.DB, logger *logger.Logger) ports.ShareRepo {
+	return &PostgreShareRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func scanShare(row rowScanner) (domain.Share, error) {
+	var share domain.Share
+	err := row.Scan(
+		&share.Id,
+		&share.ResourceType,
+		&share.ResourceId,
+		&share.OwnerId,
+		&share.UserId,
+		&share.Username,
+		&share.Role,
+		&share.CreatedAt,
+	)
+	return share, err
+}
+
+func (r *PostgreShareRepo) CreateShare(ctx context.Context, share domain.Share) (domain.Share, error) {
+	r.log(ctx).Debug("Executing CreateShare: %s %s -> %s (%s)", share.ResourceType, share.ResourceId, share.UserId, share.Role)
+
+	workspaceId, err := identity.WorkspaceId(ctx)
+	if err != nil {
+		return domain.Share{}, err
+	}
+
+	query := `
+		INSERT INTO shares (id, resource_type, resource_id, owner_id, user_id, role, created_at, workspace_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (workspace_id, resource_type, resource_id, user_id) DO UPDATE SET role = EXCLUDED.role
+		RETURNING id, created_at
+	`
+
+	err = conn(ctx, r.db).QueryRowContext(ctx, query,
+		share.Id,
+		share.ResourceType,
+		share.ResourceId,
+		share.OwnerId,
+		share.UserId,
+		share.Role,
+		share.CreatedAt,
+		workspaceId,
+	).Scan(&share.Id, &share.CreatedAt)
+	if err != nil {
+		r.log(ctx).Error("Insert failed: %v", err)
+		return domain.Share{}, err
+	}
+
+	r.log(ctx).Info("Share saved: %s", share.Id)
+	return share, nil
+}
+
+func (r *PostgreShareRepo) GetShareById(ctx context.Context, id string) (domain.Share, error) {
+	workspaceId, err := identity.WorkspaceId(ctx)
+	if err != nil {
+		return domain.Share{}, err
+	}
+
+	query := `SELECT ` + shareColumns + ` FROM shares s JOIN users u ON u.id = s.user_id WHERE s.id = $1 AND s.workspace_id = $2`
+
+	share, err := scanShare(conn(ctx, r.db).QueryRowContext(ctx, query, id, workspaceId))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.Share{}, fmt.Errorf("share not found: %s", id)
+		}
+		r.log(ctx).Error("Scan failed: %v", err)
+		return domain.Share{}, err
+	}
+	return share, nil
+}
+
+func (r *PostgreShareRepo) GetSharesByResource(ctx context.Context, resourceType, resourceId string) ([]domain.Share, error) {
+	r.log(ctx).Debug("Executing GetSharesByResource: %s %s", resourceType, resourceId)
+
+	workspaceId, err := identity.WorkspaceId(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + shareColumns + ` FROM shares s JOIN users u ON u.id = s.user_id
+	          WHERE s.resource_type = $1 AND s.resource_id = $2 AND s.workspace_id = $3 ORDER BY s.created_at`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, resourceType, resourceId, workspaceId)
+	if err != nil {
+		r.log(ctx).Error("Query failed: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	shares := []domain.Share{}
+	for rows.Next() {
+		share, err := scanShare(rows)
+		if err != nil {
+			r.log(ctx).Error("Row scan failed: %v", err)
+			return nil, err
+		}
+		shares = append(shares, share)
+	}
+	return shares, rows.Err()
+}
+
+func (r *PostgreShareRepo) DeleteShare(ctx context.Context, id string) error {
+	r.log(ctx).Debug("Executing DeleteShare: id=%s", id)
+
+	workspaceId, err := identity.WorkspaceId(ctx)
+	if err != nil {
+		return err
+	}
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM shares WHERE id = $1 AND workspace_id = $2`, id, workspaceId)
+	if err != nil {
+		r.log(ctx).Error("Delete failed: %v", err)
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("share with id %s not found", id)
+	}
+	return nil
+}
+
+func (r *PostgreShareRepo) GetTodoRole(ctx context.Context, userId string, todo domain.ToDo) (string, error) {
+	workspaceId, err := identity.WorkspaceId(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	query := `
+		SELECT role FROM shares
+		WHERE user_id = $1 AND workspace_id = $4 AND (
+			(resource_type = 'todo' AND resource_id = $2) OR
+			(resource_type = 'list' AND resource_id = $3))
+		ORDER BY ` + roleRankSQL + ` DESC
+		LIMIT 1
+	`
+
+	var role string
+	err = conn(ctx, r.db).QueryRowContext(ctx, query, userId, todo.Id, todo.OwnerId, workspaceId).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		r.log(ctx).Error("Role query failed: %v", err)
+		return "", err
+	}
+	return role, nil
+}
+
+// GetSharedTodos возвращает чужие задачи, открытые пользователю, с его наивысшей ролью
+func (r *PostgreShareRepo) GetSharedTodos(ctx context.Context, userId string) ([]ports.SharedTodo, error) {
+	r.log(ctx).Debug("Executing GetSharedTodos: user=%s", userId)
+
+	workspaceId, err := identity.WorkspaceId(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT ` + todoColumns + `, best.role FROM todo
+		JOIN LATERAL (
+			SELECT role FROM shares s
+			WHERE s.user_id = $1 AND s.workspace_id = todo.workspace_id AND (
+				(s.resource_type = 'todo' AND s.resource_id = todo.id) OR
+				(s.resource_type = 'list' AND s.resource_id = todo.owner_id))
+			ORDER BY ` + roleRankSQL + ` DESC
+			LIMIT 1
+		) best ON true
+		WHERE todo.owner_id <> $1 AND todo.workspace_id = $2
+		ORDER BY todo.owner_id, todo.position, todo.id
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, userId, workspaceId)
+	if err != nil {
+		r.log(ctx).Error("Query failed: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []ports.SharedTodo{}
+	for rows.Next() {
+		var item ports.SharedTodo
+		todo, err := scanTodo(sharedRow{rows, &item.Role})
+		if err != nil {
+			r.log(ctx).Error("Row scan failed: %v", err)
+			return nil, err
+		}
+		item.Todo = todo
+		item.OwnerId = todo.OwnerId
+		result = append(result, item)
+	}
+	return result, rows.Err()
+}
+
+// sharedRow дочитывает роль после колонок задачи
+type sharedRow struct {
+	rows rowsIter
+	role *string
+}
+
+func (s sharedRow) Scan(dest ...interface{}) error {
+	return s.rows.Scan(append(dest, s.role)...)
+}
+
+// log - логгер запроса с его X-Request-ID
+func (r *PostgreShareRepo) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, r.logger)
+}
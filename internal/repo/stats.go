@@ -0,0 +1,93 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/ports"
+)
+
+type PostgreStatsRepo struct {
+	db     *sql.DB
+	logger *logger.Logger
+}
+
+func NewPostgreStatsRepo(db *sql.DB, logger *logger.Logger) ports.StatsRepo {
+	return &PostgreStatsRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetSystemStats считает данные всех рабочих пространств, поэтому обходит RLS
+func (r *PostgreStatsRepo) GetSystemStats(ctx context.Context) (domain.SystemStats, error) {
+	r.log(ctx).Debug("Executing GetSystemStats")
+
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM users),
+			(SELECT COUNT(*) FROM users WHERE role = $1 AND disabled_at IS NULL),
+			(SELECT COUNT(*) FROM users WHERE disabled_at IS NOT NULL),
+			(SELECT COUNT(*) FROM sessions WHERE expires_at > $2),
+			(SELECT COUNT(*) FROM api_tokens),
+			(SELECT COUNT(*) FROM workspaces),
+			(SELECT COUNT(*) FROM todo),
+			(SELECT COUNT(*) FROM todo WHERE complete = true),
+			(SELECT COUNT(*) FROM comments),
+			(SELECT COUNT(*) FROM attachments),
+			(SELECT COALESCE(SUM(size), 0) FROM blobs)
+	`
+
+	var stats domain.SystemStats
+	err := unscoped(ctx, r.db, func(q dbtx) error {
+		return q.QueryRowContext(ctx, query, domain.UserRoleAdmin, time.Now()).Scan(
+			&stats.Users,
+			&stats.Admins,
+			&stats.DisabledUsers,
+			&stats.ActiveSessions,
+			&stats.APITokens,
+			&stats.Workspaces,
+			&stats.Todos,
+			&stats.CompletedTodos,
+			&stats.Comments,
+			&stats.Attachments,
+			&stats.BlobBytes,
+		)
+	})
+	if err != nil {
+		r.log(ctx).Error("Stats query failed: %v", err)
+		return domain.SystemStats{}, err
+	}
+	return stats, nil
+}
+
+// GetTodoCounts считает задачи всех рабочих пространств для метрик
+func (r *PostgreStatsRepo) GetTodoCounts(ctx context.Context, now time.Time) (domain.TodoCounts, error) {
+	r.log(ctx).Debug("Executing GetTodoCounts")
+
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE complete = false),
+			COUNT(*) FILTER (WHERE complete = false AND deadline < $1),
+			COUNT(*) FILTER (WHERE complete = true)
+		FROM todo
+	`
+
+	var counts domain.TodoCounts
+	err := unscoped(ctx, r.db, func(q dbtx) error {
+		return q.QueryRowContext(ctx, query, now).Scan(&counts.Open, &counts.Overdue, &counts.Completed)
+	})
+	if err != nil {
+		r.log(ctx).Error("Todo counts query failed: %v", err)
+		return domain.TodoCounts{}, err
+	}
+	return counts, nil
+}
+
+// log - логгер запроса с его X-Request-ID
+func (r *PostgreStatsRepo) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, r.logger)
+}
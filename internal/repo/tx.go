@@ -0,0 +1,297 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/identity"
+	"ToDo-List/internal/core/ports"
+)
+
+// dbtx - общий интерфейс пула, транзакции и соединения рабочего пространства
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (rowsIter, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) rowScanner
+}
+
+// rowsIter - результат запроса со множеством строк (*sql.Rows)
+type rowsIter interface {
+	Next() bool
+	Scan(dest ...interface{}) error
+	Close() error
+	Err() error
+}
+
+// sqlQuerier - методы, общие у *sql.DB и *sql.Tx
+type sqlQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// sqlConn приводит *sql.DB и *sql.Tx к dbtx
+type sqlConn struct {
+	q sqlQuerier
+}
+
+func (c sqlConn) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return c.q.ExecContext(ctx, query, args...)
+}
+
+func (c sqlConn) QueryContext(ctx context.Context, query string, args ...interface{}) (rowsIter, error) {
+	rows, err := c.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (c sqlConn) QueryRowContext(ctx context.Context, query string, args ...interface{}) rowScanner {
+	return c.q.QueryRowContext(ctx, query, args...)
+}
+
+// setWorkspaceSQL задаёт рабочее пространство для политик RLS до конца транзакции
+const setWorkspaceSQL = `SELECT set_config('app.workspace_id', $1, true)`
+
+// setBypassSQL отключает политики RLS до конца транзакции; нужен только
+// служебным запросам, которые по смыслу видят все рабочие пространства
+const setBypassSQL = `SELECT set_config('app.bypass_rls', 'on', true)`
+
+// tenantConn выполняет каждый запрос вне UnitOfWork в отдельной короткой
+// транзакции с app.workspace_id: настройка уровня сессии осталась бы на
+// соединении пула и досталась бы следующему запросу
+type tenantConn struct {
+	db          *sql.DB
+	workspaceId string
+}
+
+func (c tenantConn) begin(ctx context.Context) (*sql.Tx, error) {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, setWorkspaceSQL, c.workspaceId); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (c tenantConn) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	tx, err := c.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return result, tx.Commit()
+}
+
+func (c tenantConn) QueryContext(ctx context.Context, query string, args ...interface{}) (rowsIter, error) {
+	tx, err := c.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return &tenantRows{Rows: rows, tx: tx}, nil
+}
+
+func (c tenantConn) QueryRowContext(ctx context.Context, query string, args ...interface{}) rowScanner {
+	tx, err := c.begin(ctx)
+	if err != nil {
+		return errRow{err}
+	}
+	return tenantRow{row: tx.QueryRowContext(ctx, query, args...), tx: tx}
+}
+
+// tenantRows завершает транзакцию запроса при закрытии строк
+type tenantRows struct {
+	*sql.Rows
+	tx *sql.Tx
+}
+
+func (r *tenantRows) Close() error {
+	err := r.Rows.Close()
+	if cErr := r.tx.Commit(); err == nil && cErr != sql.ErrTxDone {
+		err = cErr
+	}
+	return err
+}
+
+// tenantRow завершает транзакцию запроса после чтения строки
+type tenantRow struct {
+	row *sql.Row
+	tx  *sql.Tx
+}
+
+func (r tenantRow) Scan(dest ...interface{}) error {
+	err := r.row.Scan(dest...)
+	if cErr := r.tx.Commit(); err == nil {
+		err = cErr
+	}
+	return err
+}
+
+type errRow struct {
+	err error
+}
+
+func (r errRow) Scan(dest ...interface{}) error {
+	return r.err
+}
+
+type txKey struct{}
+
+// txState - открытая транзакция и глубина вложенных Do (для имён точек сохранения)
+type txState struct {
+	tx    *sql.Tx
+	depth int
+}
+
+// conn возвращает транзакцию из контекста, если запрос выполняется внутри
+// UnitOfWork.Do, иначе - пул соединений. Запрос с рабочим пространством
+// выполняется с app.workspace_id для политик RLS.
+func conn(ctx context.Context, db *sql.DB) dbtx {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return observed(sqlConn{state.tx})
+	}
+	if workspaceId, err := identity.WorkspaceId(ctx); err == nil {
+		return observed(tenantConn{db: db, workspaceId: workspaceId})
+	}
+	return observed(sqlConn{db})
+}
+
+// tenant возвращает пользователя и рабочее пространство запроса. Каждый запрос
+// к данным пространства фильтрует по workspace_id явно, RLS лишь страхует.
+func tenant(ctx context.Context) (string, string, error) {
+	userId, err := identity.UserId(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	workspaceId, err := identity.WorkspaceId(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	return userId, workspaceId, nil
+}
+
+// unscoped выполняет fn без ограничений RLS: в текущей транзакции или в новой.
+// Нужен запросам, которые должны видеть все пространства (сборка мусора).
+func unscoped(ctx context.Context, db *sql.DB, fn func(q dbtx) error) (err error) {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		if _, err := state.tx.ExecContext(ctx, setBypassSQL); err != nil {
+			return err
+		}
+		return fn(observed(sqlConn{state.tx}))
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+	if _, err := tx.ExecContext(ctx, setBypassSQL); err != nil {
+		return err
+	}
+	return fn(observed(sqlConn{tx}))
+}
+
+type PostgreUnitOfWork struct {
+	db     *sql.DB
+	logger *logger.Logger
+}
+
+func NewPostgreUnitOfWork(db *sql.DB, logger *logger.Logger) ports.UnitOfWork {
+	return &PostgreUnitOfWork{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Do выполняет fn в транзакции. Вложенный вызов открывает точку сохранения:
+// ошибка fn откатывает только её, внешняя транзакция продолжается.
+func (u *PostgreUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return u.savepoint(ctx, state, fn)
+	}
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		u.log(ctx).Error("Begin transaction failed: %v", err)
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+				u.log(ctx).Error("Rollback failed: %v", rbErr)
+			}
+			return
+		}
+		if err = tx.Commit(); err != nil {
+			u.log(ctx).Error("Commit failed: %v", err)
+		}
+	}()
+
+	if workspaceId, wsErr := identity.WorkspaceId(ctx); wsErr == nil {
+		if _, err = tx.ExecContext(ctx, setWorkspaceSQL, workspaceId); err != nil {
+			u.log(ctx).Error("Set workspace failed: %v", err)
+			return err
+		}
+	}
+
+	return fn(context.WithValue(ctx, txKey{}, &txState{tx: tx}))
+}
+
+func (u *PostgreUnitOfWork) savepoint(ctx context.Context, parent *txState, fn func(ctx context.Context) error) (err error) {
+	state := &txState{tx: parent.tx, depth: parent.depth + 1}
+	name := fmt.Sprintf("uow_%d", state.depth)
+
+	if _, err := state.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		u.log(ctx).Error("Savepoint failed: %v", err)
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			state.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+			panic(p)
+		}
+		if err != nil {
+			if _, rbErr := state.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); rbErr != nil {
+				u.log(ctx).Error("Rollback to savepoint failed: %v", rbErr)
+			}
+			return
+		}
+		if _, err = state.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name); err != nil {
+			u.log(ctx).Error("Release savepoint failed: %v", err)
+		}
+	}()
+
+	return fn(context.WithValue(ctx, txKey{}, state))
+}
+
+// log - логгер запроса с его X-Request-ID
+func (u *PostgreUnitOfWork) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, u.logger)
+}
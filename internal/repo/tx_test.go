@@ -0,0 +1,186 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/identity"
+)
+
+// recordingDriver - драйвер database/sql, который ничего не выполняет, а
+// записывает команды транзакций и запросы по порядку
+type recordingDriver struct {
+	mu         sync.Mutex
+	statements []string
+}
+
+func (d *recordingDriver) record(statement string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.statements = append(d.statements, statement)
+}
+
+func (d *recordingDriver) log() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.statements...)
+}
+
+func (d *recordingDriver) Connect(context.Context) (driver.Conn, error) { return recordingConn{d}, nil }
+func (d *recordingDriver) Driver() driver.Driver                        { return nil }
+
+type recordingConn struct {
+	d *recordingDriver
+}
+
+func (c recordingConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare is not supported")
+}
+func (c recordingConn) Close() error { return nil }
+func (c recordingConn) Begin() (driver.Tx, error) {
+	c.d.record("BEGIN")
+	return recordingTx(c), nil
+}
+
+func (c recordingConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	statement := query
+	for _, arg := range args {
+		statement += " [" + arg.Value.(string) + "]"
+	}
+	c.d.record(statement)
+	return driver.RowsAffected(1), nil
+}
+
+type recordingTx recordingConn
+
+func (tx recordingTx) Commit() error   { tx.d.record("COMMIT"); return nil }
+func (tx recordingTx) Rollback() error { tx.d.record("ROLLBACK"); return nil }
+
+func newRecordingDB(t *testing.T) (*sql.DB, *recordingDriver) {
+	t.Helper()
+	d := &recordingDriver{}
+	db := sql.OpenDB(d)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db, d
+}
+
+func inWorkspace() context.Context {
+	ctx := identity.WithUser(context.Background(), domain.User{Id: "u1"})
+	return identity.WithWorkspace(ctx, domain.Workspace{Id: "w1"}, domain.WorkspaceRoleMember)
+}
+
+// update выполняет запрос через conn, как это делают репозитории
+func update(ctx context.Context, db *sql.DB, query string) error {
+	_, err := conn(ctx, db).ExecContext(ctx, query)
+	return err
+}
+
+func assertStatements(t *testing.T, got []string, want ...string) {
+	t.Helper()
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("statements:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestUnitOfWorkCommit(t *testing.T) {
+	db, d := newRecordingDB(t)
+	uow := NewPostgreUnitOfWork(db, newTestLogger(t))
+
+	err := uow.Do(inWorkspace(), func(ctx context.Context) error {
+		if err := update(ctx, db, "UPDATE a"); err != nil {
+			return err
+		}
+		return update(ctx, db, "UPDATE b")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Оба запроса в одной транзакции с рабочим пространством для RLS
+	assertStatements(t, d.log(), "BEGIN", setWorkspaceSQL+" [w1]", "UPDATE a", "UPDATE b", "COMMIT")
+}
+
+func TestUnitOfWorkRollback(t *testing.T) {
+	db, d := newRecordingDB(t)
+	uow := NewPostgreUnitOfWork(db, newTestLogger(t))
+	failure := errors.New("todo not found")
+
+	err := uow.Do(inWorkspace(), func(ctx context.Context) error {
+		update(ctx, db, "UPDATE a")
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Errorf("Do = %v, want the error of fn", err)
+	}
+	assertStatements(t, d.log(), "BEGIN", setWorkspaceSQL+" [w1]", "UPDATE a", "ROLLBACK")
+}
+
+func TestUnitOfWorkPanicRollsBack(t *testing.T) {
+	db, d := newRecordingDB(t)
+	uow := NewPostgreUnitOfWork(db, newTestLogger(t))
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("panic was swallowed")
+			}
+		}()
+		uow.Do(context.Background(), func(ctx context.Context) error {
+			update(ctx, db, "UPDATE a")
+			panic("boom")
+		})
+	}()
+	assertStatements(t, d.log(), "BEGIN", "UPDATE a", "ROLLBACK")
+}
+
+func TestUnitOfWorkNestedSavepoints(t *testing.T) {
+	db, d := newRecordingDB(t)
+	uow := NewPostgreUnitOfWork(db, newTestLogger(t))
+
+	err := uow.Do(context.Background(), func(ctx context.Context) error {
+		if err := uow.Do(ctx, func(ctx context.Context) error { return update(ctx, db, "UPDATE ok") }); err != nil {
+			return err
+		}
+		// Ошибка вложенного Do откатывает только его точку сохранения
+		if err := uow.Do(ctx, func(ctx context.Context) error {
+			update(ctx, db, "UPDATE failed")
+			return errors.New("item failed")
+		}); err == nil {
+			t.Error("nested error lost")
+		}
+		return uow.Do(ctx, func(ctx context.Context) error {
+			return uow.Do(ctx, func(ctx context.Context) error { return update(ctx, db, "UPDATE deep") })
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertStatements(t, d.log(),
+		"BEGIN",
+		"SAVEPOINT uow_1", "UPDATE ok", "RELEASE SAVEPOINT uow_1",
+		"SAVEPOINT uow_1", "UPDATE failed", "ROLLBACK TO SAVEPOINT uow_1",
+		"SAVEPOINT uow_1", "SAVEPOINT uow_2", "UPDATE deep", "RELEASE SAVEPOINT uow_2", "RELEASE SAVEPOINT uow_1",
+		"COMMIT",
+	)
+}
+
+func TestConnOutsideUnitOfWork(t *testing.T) {
+	db, d := newRecordingDB(t)
+
+	// Запрос пространства вне UnitOfWork получает свою короткую транзакцию:
+	// app.workspace_id не остаётся на соединении пула
+	if err := update(inWorkspace(), db, "UPDATE a"); err != nil {
+		t.Fatal(err)
+	}
+	// Служебный запрос без пространства идёт в пул напрямую
+	if err := update(context.Background(), db, "DELETE FROM sessions"); err != nil {
+		t.Fatal(err)
+	}
+	assertStatements(t, d.log(), "BEGIN", setWorkspaceSQL+" [w1]", "UPDATE a", "COMMIT", "DELETE FROM sessions")
+}
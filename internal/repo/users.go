@@ -0,0 +1,263 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/ports"
+
+	"github.com/lib/pq"
+)
+
+const userColumns = `id, username, COALESCE(email, ''), password_hash, role, disabled_at IS NOT NULL, created_at, updated_at`
+
+type PostgreUserRepo struct {
+	db     *sql.DB
+	logger *logger.Logger
+}
+
+func NewPostgreUserRepo(db *sql.DB, logger *logger.Logger) ports.UserRepo {
+	return &PostgreUserRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func scanUser(row rowScanner) (domain.User, error) {
+	var user domain.User
+	err := row.Scan(
+		&user.Id,
+		&user.Username,
+		&user.Email,
+		&user.PasswordHash,
+		&user.Role,
+		&user.Disabled,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	return user, err
+}
+
+// isUniqueViolation проверяет код ошибки Postgres 23505 (unique_violation)
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// nullIfEmpty сохраняет пустую строку как NULL (для необязательных уникальных колонок)
+func nullIfEmpty(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func (r *PostgreUserRepo) CreateUser(ctx context.Context, user domain.User) (domain.User, error) {
+	r.log(ctx).Debug("Executing CreateUser: username=%s", user.Username)
+
+	query := `
+		INSERT INTO users (id, username, email, password_hash, role, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	if user.Role == "" {
+		user.Role = domain.UserRoleUser
+	}
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query,
+		user.Id,
+		user.Username,
+		nullIfEmpty(user.Email),
+		user.PasswordHash,
+		user.Role,
+		user.CreatedAt,
+		user.UpdatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			r.log(ctx).Warn("User already exists: %s", user.Username)
+			return domain.User{}, ports.ErrUserExists
+		}
+		r.log(ctx).Error("Insert failed: %v", err)
+		return domain.User{}, err
+	}
+
+	r.log(ctx).Info("User created successfully: %s", user.Id)
+	return user, nil
+}
+
+func (r *PostgreUserRepo) GetUserById(ctx context.Context, id string) (domain.User, error) {
+	r.log(ctx).Debug("Executing GetUserById: id=%s", id)
+
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+	user, err := scanUser(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			r.log(ctx).Warn("User not found: %s", id)
+			return domain.User{}, fmt.Errorf("%w: %s", ports.ErrUserNotFound, id)
+		}
+		r.log(ctx).Error("Scan failed: %v", err)
+		return domain.User{}, err
+	}
+	return user, nil
+}
+
+func (r *PostgreUserRepo) GetUserByUsername(ctx context.Context, username string) (domain.User, error) {
+	r.log(ctx).Debug("Executing GetUserByUsername: username=%s", username)
+
+	query := `SELECT ` + userColumns + ` FROM users WHERE username = $1`
+	user, err := scanUser(conn(ctx, r.db).QueryRowContext(ctx, query, username))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			r.log(ctx).Warn("User not found: %s", username)
+			return domain.User{}, fmt.Errorf("%w: %s", ports.ErrUserNotFound, username)
+		}
+		r.log(ctx).Error("Scan failed: %v", err)
+		return domain.User{}, err
+	}
+	return user, nil
+}
+
+func (r *PostgreUserRepo) GetUserByExternalIdentity(ctx context.Context, issuer, subject string) (domain.User, error) {
+	r.log(ctx).Debug("Executing GetUserByExternalIdentity: issuer=%s, subject=%s", issuer, subject)
+
+	query := `SELECT ` + userColumns + ` FROM users
+	          WHERE id = (SELECT user_id FROM user_identities WHERE issuer = $1 AND subject = $2)`
+	user, err := scanUser(conn(ctx, r.db).QueryRowContext(ctx, query, issuer, subject))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.User{}, fmt.Errorf("no user linked to %s at %s", subject, issuer)
+		}
+		r.log(ctx).Error("Scan failed: %v", err)
+		return domain.User{}, err
+	}
+	return user, nil
+}
+
+func (r *PostgreUserRepo) LinkExternalIdentity(ctx context.Context, userId, issuer, subject string) error {
+	r.log(ctx).Debug("Executing LinkExternalIdentity: user=%s, issuer=%s", userId, issuer)
+
+	query := `INSERT INTO user_identities (issuer, subject, user_id, created_at) VALUES ($1, $2, $3, $4)`
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, issuer, subject, userId, time.Now()); err != nil {
+		if isUniqueViolation(err) {
+			return ports.ErrUserExists
+		}
+		r.log(ctx).Error("Insert failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *PostgreUserRepo) GetUserByEmail(ctx context.Context, email string) (domain.User, error) {
+	r.log(ctx).Debug("Executing GetUserByEmail")
+
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = $1`
+	user, err := scanUser(conn(ctx, r.db).QueryRowContext(ctx, query, email))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.User{}, fmt.Errorf("user not found by email")
+		}
+		r.log(ctx).Error("Scan failed: %v", err)
+		return domain.User{}, err
+	}
+	return user, nil
+}
+
+func (r *PostgreUserRepo) GetUsers(ctx context.Context, filter ports.UserFilter) ([]domain.User, error) {
+	r.log(ctx).Debug("Executing GetUsers: query=%q, role=%s", filter.Query, filter.Role)
+
+	query := `SELECT ` + userColumns + ` FROM users WHERE 1=1`
+	var args []interface{}
+	if filter.Query != "" {
+		args = append(args, "%"+escapeLike(strings.ToLower(filter.Query))+"%")
+		query += fmt.Sprintf(" AND (username LIKE $%d OR email LIKE $%d)", len(args), len(args))
+	}
+	if filter.Role != "" {
+		args = append(args, filter.Role)
+		query += fmt.Sprintf(" AND role = $%d", len(args))
+	}
+	query += " ORDER BY username"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if filter.Offset > 0 {
+		args = append(args, filter.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		r.log(ctx).Error("Query failed: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []domain.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			r.log(ctx).Error("Scan failed: %v", err)
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+func (r *PostgreUserRepo) UpdateUserRole(ctx context.Context, id, role string, updatedAt time.Time) error {
+	r.log(ctx).Debug("Executing UpdateUserRole: id=%s, role=%s", id, role)
+
+	query := `UPDATE users SET role = $2, updated_at = $3 WHERE id = $1`
+	return r.updateUser(ctx, query, id, role, updatedAt)
+}
+
+func (r *PostgreUserRepo) SetUserDisabled(ctx context.Context, id string, disabled bool, at time.Time) error {
+	r.log(ctx).Debug("Executing SetUserDisabled: id=%s, disabled=%t", id, disabled)
+
+	query := `UPDATE users SET disabled_at = NULL, updated_at = $2 WHERE id = $1`
+	if disabled {
+		// Повторная блокировка сохраняет исходное время
+		query = `UPDATE users SET disabled_at = COALESCE(disabled_at, $2), updated_at = $2 WHERE id = $1`
+	}
+	return r.updateUser(ctx, query, id, at)
+}
+
+func (r *PostgreUserRepo) UpdatePassword(ctx context.Context, id, hash string, updatedAt time.Time) error {
+	r.log(ctx).Debug("Executing UpdatePassword: id=%s", id)
+
+	query := `UPDATE users SET password_hash = $2, updated_at = $3 WHERE id = $1`
+	return r.updateUser(ctx, query, id, hash, updatedAt)
+}
+
+// updateUser выполняет UPDATE одной строки users; ErrUserNotFound, если строки нет
+func (r *PostgreUserRepo) updateUser(ctx context.Context, query string, id string, args ...interface{}) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, append([]interface{}{id}, args...)...)
+	if err != nil {
+		r.log(ctx).Error("Update failed: %v", err)
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("%w: %s", ports.ErrUserNotFound, id)
+	}
+	return nil
+}
+
+func (r *PostgreUserRepo) CountActiveAdmins(ctx context.Context) (int, error) {
+	query := `SELECT COUNT(*) FROM users WHERE role = $1 AND disabled_at IS NULL`
+
+	var n int
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, domain.UserRoleAdmin).Scan(&n); err != nil {
+		r.log(ctx).Error("Count admins failed: %v", err)
+		return 0, err
+	}
+	return n, nil
+}
+
+// log - логгер запроса с его X-Request-ID
+func (r *PostgreUserRepo) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, r.logger)
+}
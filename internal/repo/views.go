@@ -0,0 +1,244 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/ports"
+)
+
+const viewColumns = `id, name, filter, pinned, position, created_at, updated_at`
+
+type PostgreViewRepo struct {
+	db     *sql.DB
+	logger *logger.Logger
+}
+
+func NewPostgreViewRepo(db *sql.DB, logger *logger.Logger) ports.ViewRepo {
+	return &PostgreViewRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func scanView(row rowScanner) (domain.View, error) {
+	var view domain.View
+	var filter []byte
+	err := row.Scan(
+		&view.Id,
+		&view.Name,
+		&filter,
+		&view.Pinned,
+		&view.Position,
+		&view.CreatedAt,
+		&view.UpdatedAt,
+	)
+	if err != nil {
+		return domain.View{}, err
+	}
+	if err := json.Unmarshal(filter, &view.Filter); err != nil {
+		return domain.View{}, fmt.Errorf("decode filter of view %s: %w", view.Id, err)
+	}
+	return view, nil
+}
+
+// GetAllViews возвращает сохранённые фильтры: сначала закреплённые, затем по позиции
+func (r *PostgreViewRepo) GetAllViews(ctx context.Context) ([]domain.View, error) {
+	r.log(ctx).Debug("Executing GetAllViews")
+
+	ownerId, workspaceId, err := tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + viewColumns + ` FROM saved_view WHERE owner_id = $1 AND workspace_id = $2
+	          ORDER BY pinned DESC, position ASC, created_at ASC`
+
+	r.log(ctx).Debug("SQL Query: %s", query)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, ownerId, workspaceId)
+	if err != nil {
+		r.log(ctx).Error("Query failed: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	views := []domain.View{}
+	for rows.Next() {
+		view, err := scanView(rows)
+		if err != nil {
+			r.log(ctx).Error("Row scan failed: %v", err)
+			return nil, err
+		}
+		views = append(views, view)
+	}
+	if err := rows.Err(); err != nil {
+		r.log(ctx).Error("Rows error: %v", err)
+		return nil, err
+	}
+
+	r.log(ctx).Info("Retrieved %d views", len(views))
+	return views, nil
+}
+
+func (r *PostgreViewRepo) GetViewById(ctx context.Context, id string) (domain.View, error) {
+	r.log(ctx).Debug("Executing GetViewById: id=%s", id)
+
+	ownerId, workspaceId, err := tenant(ctx)
+	if err != nil {
+		return domain.View{}, err
+	}
+
+	query := `SELECT ` + viewColumns + ` FROM saved_view WHERE id = $1 AND owner_id = $2 AND workspace_id = $3`
+
+	r.log(ctx).Debug("SQL Query: %s, Arg: %s", query, id)
+	view, err := scanView(conn(ctx, r.db).QueryRowContext(ctx, query, id, ownerId, workspaceId))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			r.log(ctx).Warn("View not found: %s", id)
+			return domain.View{}, ports.ErrViewNotFound
+		}
+		r.log(ctx).Error("Scan failed: %v", err)
+		return domain.View{}, err
+	}
+	return view, nil
+}
+
+// CreateView сохраняет фильтр; без явной позиции он встаёт в конец списка
+func (r *PostgreViewRepo) CreateView(ctx context.Context, view domain.View) (domain.View, error) {
+	r.log(ctx).Debug("Executing CreateView: %+v", view)
+
+	ownerId, workspaceId, err := tenant(ctx)
+	if err != nil {
+		return domain.View{}, err
+	}
+
+	filter, err := json.Marshal(view.Filter)
+	if err != nil {
+		return domain.View{}, err
+	}
+
+	query := `
+		INSERT INTO saved_view (id, owner_id, name, filter, pinned, position, created_at, updated_at, workspace_id)
+		VALUES ($1, $8, $2, $3, $4,
+			CASE WHEN $5 > 0 THEN $5 ELSE (SELECT COALESCE(MAX(position), 0) + 1 FROM saved_view WHERE owner_id = $8 AND workspace_id = $9) END,
+			$6, $7, $9)
+		RETURNING position
+	`
+
+	r.log(ctx).Debug("SQL Query: %s, Args: %+v", query, view)
+	err = conn(ctx, r.db).QueryRowContext(ctx, query,
+		view.Id,
+		view.Name,
+		filter,
+		view.Pinned,
+		view.Position,
+		view.CreatedAt,
+		view.UpdatedAt,
+		ownerId,
+		workspaceId,
+	).Scan(&view.Position)
+	if err != nil {
+		r.log(ctx).Error("Insert failed: %v", err)
+		return domain.View{}, err
+	}
+
+	r.log(ctx).Info("View created successfully: %s", view.Id)
+	return view, nil
+}
+
+func (r *PostgreViewRepo) UpdateView(ctx context.Context, view domain.View) error {
+	r.log(ctx).Debug("Executing UpdateView: id=%s", view.Id)
+
+	ownerId, workspaceId, err := tenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	filter, err := json.Marshal(view.Filter)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE saved_view
+		SET
+			name = $1,
+			filter = $2,
+			pinned = $3,
+			position = $4,
+			updated_at = $5
+		WHERE id = $6 AND owner_id = $7 AND workspace_id = $8
+	`
+
+	view.UpdatedAt = time.Now()
+
+	r.log(ctx).Debug("SQL Query: %s, Args: %+v", query, view)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query,
+		view.Name,
+		filter,
+		view.Pinned,
+		view.Position,
+		view.UpdatedAt,
+		view.Id,
+		ownerId,
+		workspaceId,
+	)
+	if err != nil {
+		r.log(ctx).Error("Update failed: %v", err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.log(ctx).Error("RowsAffected failed: %v", err)
+		return err
+	}
+	if rowsAffected == 0 {
+		r.log(ctx).Warn("View not found for update: %s", view.Id)
+		return ports.ErrViewNotFound
+	}
+
+	r.log(ctx).Info("View updated successfully: %s", view.Id)
+	return nil
+}
+
+func (r *PostgreViewRepo) DeleteViewById(ctx context.Context, id string) error {
+	r.log(ctx).Debug("Executing DeleteViewById: id=%s", id)
+
+	ownerId, workspaceId, err := tenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `DELETE FROM saved_view WHERE id = $1 AND owner_id = $2 AND workspace_id = $3`
+	r.log(ctx).Debug("SQL Query: %s, Arg: %s", query, id)
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id, ownerId, workspaceId)
+	if err != nil {
+		r.log(ctx).Error("Delete failed: %v", err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.log(ctx).Error("RowsAffected failed: %v", err)
+		return err
+	}
+	if rowsAffected == 0 {
+		r.log(ctx).Warn("View not found for deletion: %s", id)
+		return ports.ErrViewNotFound
+	}
+
+	r.log(ctx).Info("View deleted successfully: %s", id)
+	return nil
+}
+
+// log - логгер запроса с его X-Request-ID
+func (r *PostgreViewRepo) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, r.logger)
+}
@@ -0,0 +1,113 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/identity"
+)
+
+// Запрос без рабочего пространства в контексте не доходит до базы: иначе
+// он читал бы или писал строки всех пространств
+func TestReposRequireWorkspace(t *testing.T) {
+	// Базы по этому адресу нет: ошибка соединения отличается от ErrNoWorkspace
+	db, err := sql.Open("postgres", "postgres://todo@127.0.0.1:1/todos?sslmode=disable&connect_timeout=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	appLogger := newTestLogger(t)
+
+	ctx := identity.WithUser(context.Background(), domain.User{Id: "u1"})
+	todos := NewPostgreRepo(db, appLogger)
+	views := NewPostgreViewRepo(db, appLogger)
+	comments := NewPostgreCommentRepo(db, appLogger)
+	shares := NewPostgreShareRepo(db, appLogger)
+	attachments := NewPostgreAttachmentRepo(db, appLogger)
+	bulk := NewPostgreBulkRepo(db, appLogger)
+
+	calls := map[string]func() error{
+		"GetAllTodosWithFilters": func() error {
+			_, err := todos.GetAllTodosWithFilters(ctx, domain.TodoFilter{})
+			return err
+		},
+		"GetTodoById":  func() error { _, err := todos.GetTodoById(ctx, "t1"); return err },
+		"UpdateTodo":   func() error { return todos.UpdateTodo(ctx, domain.ToDo{Id: "t1"}) },
+		"GetAllViews":  func() error { _, err := views.GetAllViews(ctx); return err },
+		"GetComments":  func() error { _, err := comments.GetCommentsByTodo(ctx, "t1"); return err },
+		"GetTodoRole":  func() error { _, err := shares.GetTodoRole(ctx, "u1", domain.ToDo{Id: "t1"}); return err },
+		"Attachments":  func() error { _, err := attachments.GetAttachmentsByTodo(ctx, "t1"); return err },
+		"CountBulkIds": func() error { _, err := bulk.CountExistingTodos(ctx, []string{"t1"}); return err },
+	}
+	for name, call := range calls {
+		if err := call(); !errors.Is(err, identity.ErrNoWorkspace) {
+			t.Errorf("%s without workspace = %v, want ErrNoWorkspace", name, err)
+		}
+	}
+}
+
+func TestFilterWhereScopedToWorkspace(t *testing.T) {
+	where, args, err := buildFilterWhere(domain.TodoFilter{Status: "active"}, "u1", "w1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(where, "workspace_id = $2") || len(args) < 2 || args[1] != "w1" {
+		t.Errorf("buildFilterWhere = %s %v, want workspace_id bound to w1", where, args)
+	}
+}
+
+// Каждая таблица с workspace_id, кроме читаемых до выбора пространства,
+// защищена политикой RLS
+func TestRowLevelSecurityCoversWorkspaceTables(t *testing.T) {
+	schema, err := os.ReadFile("../../init.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// workspace_members и api_tokens читаются при выборе пространства запроса
+	exempt := map[string]bool{"workspace_members": true, "api_tokens": true}
+
+	rls := regexp.MustCompile(`(?s)FOREACH t IN ARRAY ARRAY\[(.*?)\]`).FindSubmatch(schema)
+	if rls == nil {
+		t.Fatal("RLS table list not found in init.sql")
+	}
+	protected := map[string]bool{}
+	for _, name := range regexp.MustCompile(`'(\w+)'`).FindAllSubmatch(rls[1], -1) {
+		protected[string(name[1])] = true
+	}
+
+	tables := regexp.MustCompile(`(?s)CREATE TABLE IF NOT EXISTS (\w+) \((.*?)\n\);`).FindAllSubmatch(schema, -1)
+	if len(tables) == 0 {
+		t.Fatal("no tables found in init.sql")
+	}
+	for _, table := range tables {
+		name := string(table[1])
+		hasWorkspace := regexp.MustCompile(`(?m)^\s*workspace_id `).Match(table[2])
+		if hasWorkspace && !exempt[name] && !protected[name] {
+			t.Errorf("table %s has workspace_id but no RLS policy", name)
+		}
+		if !hasWorkspace && protected[name] {
+			t.Errorf("table %s has an RLS policy but no workspace_id", name)
+		}
+	}
+}
+
+func newTestLogger(t *testing.T) *logger.Logger {
+	t.Helper()
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { devNull.Close() })
+	appLogger, err := logger.New(logger.Config{Level: logger.LevelError, Output: devNull})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return appLogger
+}
@@ -0,0 +1,256 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/ports"
+)
+
+const workspaceColumns = `w.id, w.slug, w.name, w.settings, w.created_at, w.updated_at`
+
+// PostgreWorkspaceRepo работает с таблицами workspaces и workspace_members.
+// Они не попадают под RLS: по ним выбирается пространство запроса.
+type PostgreWorkspaceRepo struct {
+	db     *sql.DB
+	logger *logger.Logger
+}
+
+func NewPostgreWorkspaceRepo(db *sql.DB, logger *logger.Logger) ports.WorkspaceRepo {
+	return &PostgreWorkspaceRepo{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func scanWorkspace(row rowScanner, extra ...interface{}) (domain.Workspace, error) {
+	var workspace domain.Workspace
+	var settings []byte
+	dest := append([]interface{}{
+		&workspace.Id,
+		&workspace.Slug,
+		&workspace.Name,
+		&settings,
+		&workspace.CreatedAt,
+		&workspace.UpdatedAt,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return domain.Workspace{}, err
+	}
+	if err := json.Unmarshal(settings, &workspace.Settings); err != nil {
+		return domain.Workspace{}, fmt.Errorf("decode settings of workspace %s: %w", workspace.Id, err)
+	}
+	return workspace, nil
+}
+
+func (r *PostgreWorkspaceRepo) CreateWorkspace(ctx context.Context, workspace domain.Workspace) error {
+	r.log(ctx).Debug("Executing CreateWorkspace: slug=%s", workspace.Slug)
+
+	settings, err := json.Marshal(workspace.Settings)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO workspaces (id, slug, name, settings, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
+		workspace.Id,
+		workspace.Slug,
+		workspace.Name,
+		settings,
+		workspace.CreatedAt,
+		workspace.UpdatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			r.log(ctx).Warn("Workspace already exists: %s", workspace.Slug)
+			return ports.ErrWorkspaceExists
+		}
+		r.log(ctx).Error("Insert failed: %v", err)
+		return err
+	}
+
+	r.log(ctx).Info("Workspace created successfully: %s (%s)", workspace.Id, workspace.Slug)
+	return nil
+}
+
+func (r *PostgreWorkspaceRepo) GetWorkspaceById(ctx context.Context, id string) (domain.Workspace, error) {
+	return r.getWorkspace(ctx, `w.id = $1`, id)
+}
+
+func (r *PostgreWorkspaceRepo) GetWorkspaceBySlug(ctx context.Context, slug string) (domain.Workspace, error) {
+	return r.getWorkspace(ctx, `w.slug = $1`, slug)
+}
+
+func (r *PostgreWorkspaceRepo) getWorkspace(ctx context.Context, condition string, arg string) (domain.Workspace, error) {
+	query := `SELECT ` + workspaceColumns + ` FROM workspaces w WHERE ` + condition
+
+	workspace, err := scanWorkspace(conn(ctx, r.db).QueryRowContext(ctx, query, arg))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.Workspace{}, fmt.Errorf("%w: %s", ports.ErrWorkspaceNotFound, arg)
+		}
+		r.log(ctx).Error("Scan failed: %v", err)
+		return domain.Workspace{}, err
+	}
+	return workspace, nil
+}
+
+func (r *PostgreWorkspaceRepo) GetWorkspacesByUser(ctx context.Context, userId string) ([]ports.UserWorkspace, error) {
+	r.log(ctx).Debug("Executing GetWorkspacesByUser: user=%s", userId)
+
+	query := `SELECT ` + workspaceColumns + `, m.role FROM workspaces w
+	          JOIN workspace_members m ON m.workspace_id = w.id
+	          WHERE m.user_id = $1 ORDER BY m.created_at, w.slug`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, userId)
+	if err != nil {
+		r.log(ctx).Error("Query failed: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []ports.UserWorkspace{}
+	for rows.Next() {
+		var item ports.UserWorkspace
+		item.Workspace, err = scanWorkspace(rows, &item.Role)
+		if err != nil {
+			r.log(ctx).Error("Row scan failed: %v", err)
+			return nil, err
+		}
+		result = append(result, item)
+	}
+	return result, rows.Err()
+}
+
+func (r *PostgreWorkspaceRepo) UpdateWorkspaceSettings(ctx context.Context, id string, settings domain.WorkspaceSettings, updatedAt time.Time) error {
+	r.log(ctx).Debug("Executing UpdateWorkspaceSettings: id=%s", id)
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+
+	result, err := conn(ctx, r.db).ExecContext(ctx,
+		`UPDATE workspaces SET settings = $1, updated_at = $2 WHERE id = $3`, data, updatedAt, id)
+	if err != nil {
+		r.log(ctx).Error("Update failed: %v", err)
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s", ports.ErrWorkspaceNotFound, id)
+	}
+	return nil
+}
+
+func (r *PostgreWorkspaceRepo) AddMember(ctx context.Context, member domain.WorkspaceMember) error {
+	r.log(ctx).Debug("Executing AddMember: workspace=%s, user=%s, role=%s", member.WorkspaceId, member.UserId, member.Role)
+
+	query := `
+		INSERT INTO workspace_members (workspace_id, user_id, role, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (workspace_id, user_id) DO UPDATE SET role = EXCLUDED.role
+	`
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, member.WorkspaceId, member.UserId, member.Role, member.CreatedAt)
+	if err != nil {
+		r.log(ctx).Error("Insert failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+const memberColumns = `m.workspace_id, m.user_id, u.username, m.role, m.created_at`
+
+func scanMember(row rowScanner) (domain.WorkspaceMember, error) {
+	var member domain.WorkspaceMember
+	err := row.Scan(&member.WorkspaceId, &member.UserId, &member.Username, &member.Role, &member.CreatedAt)
+	return member, err
+}
+
+func (r *PostgreWorkspaceRepo) GetMember(ctx context.Context, workspaceId, userId string) (domain.WorkspaceMember, error) {
+	query := `SELECT ` + memberColumns + ` FROM workspace_members m JOIN users u ON u.id = m.user_id
+	          WHERE m.workspace_id = $1 AND m.user_id = $2`
+
+	member, err := scanMember(conn(ctx, r.db).QueryRowContext(ctx, query, workspaceId, userId))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.WorkspaceMember{}, ports.ErrNotMember
+		}
+		r.log(ctx).Error("Scan failed: %v", err)
+		return domain.WorkspaceMember{}, err
+	}
+	return member, nil
+}
+
+func (r *PostgreWorkspaceRepo) GetMembers(ctx context.Context, workspaceId string) ([]domain.WorkspaceMember, error) {
+	r.log(ctx).Debug("Executing GetMembers: workspace=%s", workspaceId)
+
+	query := `SELECT ` + memberColumns + ` FROM workspace_members m JOIN users u ON u.id = m.user_id
+	          WHERE m.workspace_id = $1 ORDER BY m.created_at, u.username`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, workspaceId)
+	if err != nil {
+		r.log(ctx).Error("Query failed: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []domain.WorkspaceMember{}
+	for rows.Next() {
+		member, err := scanMember(rows)
+		if err != nil {
+			r.log(ctx).Error("Row scan failed: %v", err)
+			return nil, err
+		}
+		members = append(members, member)
+	}
+	return members, rows.Err()
+}
+
+// RemoveMember исключает участника. Доступы, выданные ему в этом пространстве,
+// удаляются вместе с членством; его собственные задачи остаются в пространстве.
+func (r *PostgreWorkspaceRepo) RemoveMember(ctx context.Context, workspaceId, userId string) error {
+	r.log(ctx).Debug("Executing RemoveMember: workspace=%s, user=%s", workspaceId, userId)
+
+	_, err := conn(ctx, r.db).ExecContext(ctx,
+		`DELETE FROM shares WHERE workspace_id = $1 AND user_id = $2`, workspaceId, userId)
+	if err != nil {
+		r.log(ctx).Error("Delete shares failed: %v", err)
+		return err
+	}
+
+	result, err := conn(ctx, r.db).ExecContext(ctx,
+		`DELETE FROM workspace_members WHERE workspace_id = $1 AND user_id = $2`, workspaceId, userId)
+	if err != nil {
+		r.log(ctx).Error("Delete failed: %v", err)
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ports.ErrNotMember
+	}
+
+	r.log(ctx).Info("User %s removed from workspace %s", userId, workspaceId)
+	return nil
+}
+
+// log - логгер запроса с его X-Request-ID
+func (r *PostgreWorkspaceRepo) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, r.logger)
+}
@@ -1,51 +1,320 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
+	"ToDo-List/internal/adapters/blob"
 	httpadapter "ToDo-List/internal/adapters/http"
 	"ToDo-List/internal/adapters/logger"
+	"ToDo-List/internal/adapters/metrics"
+	"ToDo-List/internal/adapters/notify"
+	"ToDo-List/internal/adapters/oidc"
+	"ToDo-List/internal/adapters/password"
+	"ToDo-List/internal/adapters/tracing"
+	"ToDo-List/internal/application/service"
+	"ToDo-List/internal/config"
+	"ToDo-List/internal/core/domain"
+	"ToDo-List/internal/core/ports"
 	"ToDo-List/internal/repo"
 
 	_ "github.com/lib/pq"
 )
 
 func main() {
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if cfg.PrintConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Инициализация логгера
 	loggerConfig := logger.Config{
-		Level: logger.ParseLevel(os.Getenv("LOG_LEVEL")),
+		Level:         cfg.Log.Level,
+		Format:        cfg.Log.Format,
+		File:          cfg.Log.File,
+		FileOnly:      !cfg.Log.Stdout,
+		PackageLevels: cfg.Log.PackageLevels,
+		Redaction: logger.RedactionConfig{
+			Fields:      cfg.Log.RedactFields,
+			Patterns:    cfg.Log.RedactPatterns,
+			OmitContent: !cfg.Log.Content,
+		},
+	}
+	appLogger, err := logger.New(loggerConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
+		os.Exit(1)
 	}
-	appLogger := logger.New(loggerConfig)
+	// Пакеты log и slog сторонних библиотек пишут через тот же логгер
+	slog.SetDefault(appLogger.Slog())
 
 	appLogger.Info("Starting ToDo application...")
 
-	databaseURL := os.Getenv("DATABASE_URL")
-	if databaseURL == "" {
-		appLogger.Fatal("DATABASE_URL environment variable is not set")
+	// Трассировка: traceparent принимается и передаётся всегда, span
+	// экспортируются, если задан TRACING_EXPORTER
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, appLogger)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize tracing: %v", err)
+	}
+	if cfg.Tracing.Exporter != tracing.ExporterNone {
+		appLogger.Info("Exporting traces to %s", cfg.Tracing.Exporter)
 	}
 
-	db := waitForDatabase(databaseURL, appLogger)
-	defer db.Close()
+	db := waitForDatabase(cfg.DatabaseURL, appLogger)
 
 	appLogger.Info("Successfully connected to the database!")
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8000"
-		appLogger.Info("Using default port: %s", port)
+	// SSO включается, если задан OIDC_ISSUER
+	var identityProvider ports.IdentityProvider
+	if cfg.OIDC.Issuer != "" {
+		identityProvider = oidc.NewProvider(cfg.OIDC, appLogger)
+		appLogger.Info("OIDC login enabled, issuer: %s", cfg.OIDC.Issuer)
+	}
+
+	// Уведомления пишутся в лог и, если задан NOTIFY_WEBHOOK_URL, уходят на webhook
+	notifier := notify.Multi{notify.NewLogNotifier(appLogger)}
+	if cfg.NotifyWebhookURL != "" {
+		notifier = append(notifier, notify.NewWebhookNotifier(cfg.NotifyWebhookURL, appLogger))
 	}
 
-	todoRepo := repo.NewPostgreRepo(db, appLogger)
-	router := httpadapter.NewRouter(todoRepo, appLogger)
+	blobs := newBlobStore(cfg.Blob, appLogger)
 
-	appLogger.Info("Starting server on port %s...", port)
-	err := http.ListenAndServe(":"+port, router)
-	if err != nil {
+	// Метрики Prometheus: HTTP, запросы к БД, пул соединений и число задач
+	var appMetrics *metrics.Metrics
+	if cfg.Metrics.Enabled {
+		appMetrics = metrics.New()
+		appMetrics.RegisterDBStats(db)
+		repo.ObserveQueries(appMetrics)
+	}
+
+	uow := repo.NewPostgreUnitOfWork(db, appLogger)
+	attachments := repo.NewPostgreAttachmentRepo(db, appLogger)
+	users := repo.NewPostgreUserRepo(db, appLogger)
+
+	// Перечисленные в ADMIN_USERNAMES пользователи получают роль admin при старте
+	promoteAdmins(users, cfg.AdminUsernames, appLogger)
+
+	// SIGINT/SIGTERM запускают остановку: сервер дорабатывает начатые запросы,
+	// затем останавливаются фоновые задачи и последней закрывается БД
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+
+	// SIGHUP перечитывает уровни логирования и заново открывает файл лога
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		reloadOnHangup(workersCtx, appLogger)
+	}()
+
+	// Файлы удалённых задач и вложений убираются из хранилища в фоне
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		service.NewBlobCollector(attachments, blobs, uow, appLogger).Run(workersCtx, cfg.Blob.GCInterval)
+	}()
+
+	// Число открытых, просроченных и выполненных задач для метрик считается в фоне
+	stats := repo.NewPostgreStatsRepo(db, appLogger)
+	if appMetrics != nil {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			service.NewTodoStatsCollector(stats, appMetrics, appLogger).Run(workersCtx, cfg.Metrics.RefreshInterval)
+		}()
+	}
+
+	router := httpadapter.NewRouter(httpadapter.Dependencies{
+		Todos:    repo.NewPostgreRepo(db, appLogger),
+		Views:    repo.NewPostgreViewRepo(db, appLogger),
+		Bulk:     repo.NewPostgreBulkRepo(db, appLogger),
+		Users:    users,
+		Sessions: repo.NewPostgreSessionRepo(db, appLogger),
+		Tokens:   repo.NewPostgreAPITokenRepo(db, appLogger),
+		Shares:   repo.NewPostgreShareRepo(db, appLogger),
+		Comments: repo.NewPostgreCommentRepo(db, appLogger),
+		Notifier: notifier,
+		UoW:      uow,
+		Hasher:   password.NewArgon2Hasher(password.DefaultParams),
+		OIDC:     identityProvider,
+
+		Attachments: attachments,
+		Blobs:       blobs,
+		Workspaces:  repo.NewPostgreWorkspaceRepo(db, appLogger),
+		Audit:       repo.NewPostgreAuditRepo(db, appLogger),
+		Stats:       stats,
+		Idempotency: repo.NewPostgreIdempotencyRepo(db, appLogger),
+
+		SessionTTL:       cfg.SessionTTL,
+		IdempotencyTTL:   cfg.IdempotencyTTL,
+		SecureCookie:     cfg.Server.CookieSecure,
+		AttachmentLimits: cfg.AttachmentLimits,
+		WorkspaceDomain:  cfg.Server.WorkspaceDomain,
+		RateLimits:       cfg.RateLimits,
+		Metrics:          appMetrics,
+		MetricsToken:     cfg.Metrics.Token,
+	}, appLogger)
+
+	server := &http.Server{
+		Addr:    ":" + cfg.Server.Port,
+		Handler: router,
+		// Таймауты не дают медленным клиентам держать соединения бесконечно
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+	// Сервер останавливается первым, затем по порядку фоновые задачи,
+	// уведомления, трассировка и последней - БД
+	steps := []shutdownStep{
+		{failure: "Failed to stop background workers", run: func(context.Context) error {
+			stopWorkers()
+			workers.Wait()
+			return nil
+		}},
+		{failure: "Failed to deliver pending notifications", run: notifier.Close},
+		// Накопленные span отправляются после остановки сервера и фоновых задач
+		{failure: "Failed to flush traces", run: shutdownTracing},
+		{failure: "Failed to close database", run: func(context.Context) error { return db.Close() }},
+	}
+	// Повторный сигнал во время остановки завершает процесс сразу
+	context.AfterFunc(ctx, stop)
+	if err := run(ctx, server, server.ListenAndServe, cfg.Server.ShutdownTimeout, steps, appLogger); err != nil {
 		appLogger.Fatal("Server failed: %v", err)
 	}
+	appLogger.Info("Server stopped")
+	appLogger.Close()
+}
+
+// shutdownStep - этап остановки, выполняемый после сервера
+type shutdownStep struct {
+	failure string
+	run     func(ctx context.Context) error
+}
+
+// run запускает сервер через serve и ждёт отмены ctx. Затем сервер
+// дорабатывает начатые запросы, но не дольше shutdownTimeout, и по порядку
+// выполняются steps. Ошибка - только если сервер не смог запуститься.
+func run(ctx context.Context, server *http.Server, serve func() error, shutdownTimeout time.Duration, steps []shutdownStep, appLogger *logger.Logger) error {
+	serverErr := make(chan error, 1)
+	go func() {
+		appLogger.Info("Starting server on %s...", server.Addr)
+		serverErr <- serve()
+	}()
+
+	select {
+	case err := <-serverErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+	case <-ctx.Done():
+		appLogger.Info("Shutdown signal received, draining connections (timeout %s)...", shutdownTimeout)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		appLogger.Error("Server did not shut down cleanly: %v", err)
+	}
+	for _, step := range steps {
+		if err := step.run(shutdownCtx); err != nil {
+			appLogger.Warn("%s: %v", step.failure, err)
+		}
+	}
+	return nil
+}
+
+// reloadOnHangup по SIGHUP заново читает конфигурацию и применяет уровни
+// логирования; остальные параметры требуют перезапуска
+func reloadOnHangup(ctx context.Context, appLogger *logger.Logger) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+		}
+
+		if err := appLogger.Reopen(); err != nil {
+			appLogger.Error("Failed to reopen log file: %v", err)
+		}
+		cfg, err := config.Load(os.Args[1:])
+		if err != nil {
+			appLogger.Error("Configuration reload failed, keeping current log levels: %v", err)
+			continue
+		}
+		appLogger.SetLevel(cfg.Log.Level)
+		appLogger.SetPackageLevels(cfg.Log.PackageLevels)
+		appLogger.Info("Log level reloaded: %s", cfg.Log.Level)
+	}
+}
+
+// promoteAdmins выдаёт роль admin существующим пользователям из списка
+func promoteAdmins(users ports.UserRepo, usernames []string, appLogger *logger.Logger) {
+	ctx := context.Background()
+	for _, username := range usernames {
+		username = strings.ToLower(strings.TrimSpace(username))
+		if username == "" {
+			continue
+		}
+		user, err := users.GetUserByUsername(ctx, username)
+		if err != nil {
+			appLogger.Warn("Cannot promote %s to admin: %v", username, err)
+			continue
+		}
+		if user.Role == domain.UserRoleAdmin {
+			continue
+		}
+		if err := users.UpdateUserRole(ctx, user.Id, domain.UserRoleAdmin, time.Now()); err != nil {
+			appLogger.Error("Failed to promote %s to admin: %v", username, err)
+			continue
+		}
+		appLogger.Info("User %s promoted to admin", username)
+	}
+}
+
+// newBlobStore выбирает хранилище вложений: local (по умолчанию) или s3
+func newBlobStore(cfg config.BlobConfig, appLogger *logger.Logger) ports.BlobStore {
+	if cfg.Store == "s3" {
+		store, err := blob.NewS3Store(cfg.S3)
+		if err != nil {
+			appLogger.Fatal("Failed to initialize S3 storage: %v", err)
+		}
+		appLogger.Info("Storing attachments in S3 bucket %s at %s", cfg.S3.Bucket, cfg.S3.Endpoint)
+		return store
+	}
+	store, err := blob.NewLocalStore(cfg.Dir)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize blob storage: %v", err)
+	}
+	appLogger.Info("Storing attachments in %s", cfg.Dir)
+	return store
 }
 
 func waitForDatabase(databaseURL string, appLogger *logger.Logger) *sql.DB {
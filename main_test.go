@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"ToDo-List/internal/adapters/logger"
+)
+
+func newTestLogger(t *testing.T) *logger.Logger {
+	t.Helper()
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { devNull.Close() })
+	appLogger, err := logger.New(logger.Config{Level: logger.LevelError, Output: devNull})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return appLogger
+}
+
+// stepRecorder запоминает порядок этапов остановки
+type stepRecorder struct {
+	mu    sync.Mutex
+	steps []string
+}
+
+func (r *stepRecorder) step(name string) shutdownStep {
+	return shutdownStep{failure: name + " failed", run: func(context.Context) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.steps = append(r.steps, name)
+		return nil
+	}}
+}
+
+func (r *stepRecorder) done() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.steps...)
+}
+
+// startServer запускает run на свободном порту; release отпускает начатый запрос
+func startServer(t *testing.T, shutdownTimeout time.Duration, steps []shutdownStep) (ctx context.Context, cancel func(), url string, started, release chan struct{}, result chan error) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	started, release = make(chan struct{}), make(chan struct{})
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "done")
+	})}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	result = make(chan error, 1)
+	go func() {
+		result <- run(ctx, server, func() error { return server.Serve(listener) }, shutdownTimeout, steps, newTestLogger(t))
+	}()
+	return ctx, cancel, "http://" + listener.Addr().String(), started, release, result
+}
+
+func TestShutdownDrainsRequestsBeforeSteps(t *testing.T) {
+	recorder := &stepRecorder{}
+	steps := []shutdownStep{recorder.step("workers"), recorder.step("notifier"), recorder.step("tracing"), recorder.step("database")}
+	_, cancel, url, started, release, result := startServer(t, 5*time.Second, steps)
+
+	response := make(chan string, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			response <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		response <- string(body)
+	}()
+	<-started
+
+	cancel()
+	// Пока запрос выполняется, остальные этапы ждут
+	time.Sleep(50 * time.Millisecond)
+	if got := recorder.done(); len(got) != 0 {
+		t.Fatalf("steps %v ran before the request finished", got)
+	}
+	if _, err := http.Get(url); err == nil {
+		t.Error("server accepted a new connection after the shutdown signal")
+	}
+
+	close(release)
+	if got := <-response; got != "done" {
+		t.Errorf("in-flight request got %q, want done", got)
+	}
+	if err := <-result; err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	want := []string{"workers", "notifier", "tracing", "database"}
+	got := recorder.done()
+	if len(got) != len(want) {
+		t.Fatalf("steps = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("steps = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	recorder := &stepRecorder{}
+	failing := shutdownStep{failure: "flush failed", run: func(context.Context) error { return errors.New("exporter unavailable") }}
+	_, cancel, url, started, release, result := startServer(t, 50*time.Millisecond, []shutdownStep{failing, recorder.step("database")})
+	defer close(release)
+
+	go http.Get(url)
+	<-started
+	cancel()
+
+	// Зависший запрос не держит остановку дольше таймаута, ошибка этапа не
+	// отменяет следующие
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Fatalf("run: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("shutdown did not finish after the timeout")
+	}
+	if got := recorder.done(); len(got) != 1 || got[0] != "database" {
+		t.Errorf("steps = %v, want database closed", got)
+	}
+}
+
+func TestServeFailure(t *testing.T) {
+	recorder := &stepRecorder{}
+	listenErr := errors.New("address already in use")
+	server := &http.Server{Addr: ":0"}
+
+	err := run(context.Background(), server, func() error { return listenErr }, time.Second, []shutdownStep{recorder.step("database")}, newTestLogger(t))
+	if !errors.Is(err, listenErr) {
+		t.Errorf("run = %v, want the listen error", err)
+	}
+	if got := recorder.done(); len(got) != 0 {
+		t.Errorf("steps %v ran although the server never started", got)
+	}
+}
+
+func TestReloadOnHangup(t *testing.T) {
+	// Свой обработчик SIGHUP, чтобы сигнал до подписки reloadOnHangup не
+	// завершил тестовый процесс
+	hangup := make(chan os.Signal, 16)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	args := os.Args
+	os.Args = []string{"todo"}
+	defer func() { os.Args = args }()
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("DATABASE_URL", "postgres://todo@localhost/todos")
+	t.Setenv("LOG_LEVEL", "debug")
+	t.Setenv("LOG_LEVELS", "repo=warn")
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "todo.log")
+	appLogger, err := logger.New(logger.Config{Level: logger.LevelError, FileOnly: true, File: logger.FileConfig{Path: path}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer appLogger.Close()
+	appLogger.Error("before rotation")
+	// Внешний logrotate переименовал файл
+	if err := os.Rename(path, filepath.Join(dir, "todo.log.1")); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		reloadOnHangup(ctx, appLogger)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// Сигнал повторяется, пока reloadOnHangup не подпишется и не применит уровни
+	deadline := time.Now().Add(5 * time.Second)
+	for appLogger.Level() != logger.LevelDebug || appLogger.PackageLevels()["repo"] != logger.LevelWarn {
+		if time.Now().After(deadline) {
+			t.Fatalf("levels not reloaded: %v %v", appLogger.Level(), appLogger.PackageLevels())
+		}
+		syscall.Kill(os.Getpid(), syscall.SIGHUP)
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	appLogger.Debug("after reload")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("log file not reopened: %v", err)
+	}
+	if strings.Contains(string(data), "before rotation") || !strings.Contains(string(data), "after reload") {
+		t.Errorf("reopened file = %q", data)
+	}
+}
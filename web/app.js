@@ -24,6 +24,36 @@ const selectors = {
   tasksContainer: document.getElementById("tasks-container")
 };
 
+// apiFetch - fetch с cookie сессии и CSRF-заголовком; при 401 ведёт на страницу входа
+async function apiFetch(url, options = {}) {
+  const headers = new Headers(options.headers || {});
+  const method = (options.method || "GET").toUpperCase();
+  if (!["GET", "HEAD", "OPTIONS"].includes(method)) {
+    const csrf = getCookie("csrf_token");
+    if (csrf) headers.set("X-CSRF-Token", csrf);
+  }
+
+  const res = await fetch(url, {...options, headers, credentials: "include"});
+  if (res.status === 401) {
+    window.location.href = "/login.html";
+    throw new Error("Unauthorized");
+  }
+  return res;
+}
+
+function getCookie(name) {
+  const match = document.cookie.split("; ").find(c => c.startsWith(name + "="));
+  return match ? decodeURIComponent(match.slice(name.length + 1)) : "";
+}
+
+async function logout() {
+  try {
+    await apiFetch(`${API_BASE}/auth/logout`, {method: "POST"});
+  } finally {
+    window.location.href = "/login.html";
+  }
+}
+
 // Глобальные переменные
 let todos = [];
 let todoToDelete = null;
@@ -71,6 +101,7 @@ function initEventListeners() {
   
   // Тема
   selectors.themeToggle.addEventListener("click", toggleTheme);
+  document.getElementById("btn-logout")?.addEventListener("click", logout);
   
   // Модальное окно удаления
   selectors.modalCancel.addEventListener("click", hideDeleteModal);
@@ -103,7 +134,7 @@ async function fetchTodos() {
     const url = `${API_BASE}/todos${params.toString() ? "?" + params.toString() : ""}`;
     console.log('Fetching URL:', url);
     
-    const res = await fetch(url);
+    const res = await apiFetch(url);
     
     if (!res.ok) throw new Error(`HTTP error! status: ${res.status}`);
     
@@ -116,7 +147,7 @@ async function fetchTodos() {
 }
 async function fetchTodoById(id) {
   try {
-    const res = await fetch(`${API_BASE}/todo/${encodeURIComponent(id)}`);
+    const res = await apiFetch(`${API_BASE}/todo/${encodeURIComponent(id)}`);
     if (!res.ok) throw new Error("Fetch todo by id failed");
     return await res.json();
   } catch (error) {
@@ -127,7 +158,7 @@ async function fetchTodoById(id) {
 
 async function createTodo(payload) {
   try {
-    const res = await fetch(`${API_BASE}/todo`, {
+    const res = await apiFetch(`${API_BASE}/todo`, {
       method: "POST",
       headers: {"Content-Type": "application/json"},
       body: JSON.stringify(payload),
@@ -147,7 +178,7 @@ async function createTodo(payload) {
 
 async function updateTodo(id, payload) {
   try {
-    const res = await fetch(`${API_BASE}/todo/${encodeURIComponent(id)}`, {
+    const res = await apiFetch(`${API_BASE}/todo/${encodeURIComponent(id)}`, {
       method: "PUT",
       headers: {"Content-Type": "application/json"},
       body: JSON.stringify(payload),
@@ -167,7 +198,7 @@ async function updateTodo(id, payload) {
 
 async function deleteTodo(id) {
   try {
-    const res = await fetch(`${API_BASE}/todo/${encodeURIComponent(id)}`, {
+    const res = await apiFetch(`${API_BASE}/todo/${encodeURIComponent(id)}`, {
       method: "DELETE",
     });
     